@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+)
+
+// AccessHandlers connects HTTP requests to the AccessMethods subsystem
+type AccessHandlers struct {
+	*lib.AccessMethods
+}
+
+// NewAccessHandlers constructs an AccessHandlers struct
+func NewAccessHandlers(inst *lib.Instance) AccessHandlers {
+	return AccessHandlers{AccessMethods: lib.NewAccessMethods(inst)}
+}
+
+// ListHandler is an HTTP handler function for listing a dataset's access
+// control list
+func (h AccessHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.AccessListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.AccessMethods.List(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// GrantHandler is an HTTP handler function for granting dataset rights to a
+// profile
+func (h AccessHandlers) GrantHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.grantHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h AccessHandlers) grantHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.AccessParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.AccessMethods.Grant(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// RevokeHandler is an HTTP handler function for revoking dataset rights from
+// a profile
+func (h AccessHandlers) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.revokeHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h AccessHandlers) revokeHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.AccessParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.AccessMethods.Revoke(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
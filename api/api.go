@@ -198,8 +198,54 @@ func NewServerRoutes(s Server) *mux.Router {
 	handleRefRoute(m, lib.AERemove, s.Middleware(dsh.RemoveHandler))
 	m.Handle(lib.AERename.String(), s.Middleware(dsh.RenameHandler))
 	m.Handle(lib.AEDiff.String(), s.Middleware(dsh.DiffHandler))
+	m.Handle(lib.AEApplyPatch.String(), s.Middleware(dsh.ApplyPatchHandler))
+	m.Handle(lib.AESquash.String(), s.Middleware(dsh.SquashHandler))
 	m.Handle(lib.AEChanges.String(), s.Middleware(dsh.ChangesHandler))
 	m.Handle(lib.AEUnpack.String(), s.Middleware(dsh.UnpackHandler))
+	m.Handle(lib.AEImportSQLite.String(), s.Middleware(dsh.ImportSQLiteHandler))
+	m.Handle(lib.AEImportDataPackage.String(), s.Middleware(dsh.ImportDataPackageHandler))
+
+	bh := NewBranchHandlers(s.Instance)
+	m.Handle(lib.AEBranches.String(), s.Middleware(bh.ListHandler))
+	m.Handle(lib.AEBranchCreate.String(), s.Middleware(bh.CreateHandler))
+	m.Handle(lib.AEBranchSwitch.String(), s.Middleware(bh.SwitchHandler))
+	m.Handle(lib.AEMerge.String(), s.Middleware(bh.MergeHandler))
+
+	ach := NewAccessHandlers(s.Instance)
+	m.Handle(lib.AEAccessList.String(), s.Middleware(ach.ListHandler))
+	m.Handle(lib.AEAccessGrant.String(), s.Middleware(ach.GrantHandler))
+	m.Handle(lib.AEAccessRevoke.String(), s.Middleware(ach.RevokeHandler))
+
+	jh := NewJobHandlers(s.Instance)
+	m.Handle(lib.AEJobs.String(), s.Middleware(jh.ListHandler))
+	m.Handle(lib.AEJob.String(), s.Middleware(jh.GetHandler))
+	m.Handle(lib.AEJobCancel.String(), s.Middleware(jh.CancelHandler))
+
+	schh := NewScheduleHandlers(s.Instance, cfg.API.ReadOnly)
+	m.Handle(lib.AESchedules.String(), s.Middleware(schh.ListHandler))
+	m.Handle(lib.AEScheduleAdd.String(), s.Middleware(schh.AddHandler))
+	m.Handle(lib.AEScheduleRemove.String(), s.Middleware(schh.RemoveHandler))
+	m.Handle(lib.AEScheduleRun.String(), s.Middleware(schh.RunHandler))
+
+	rh := NewRunHandlers(s.Instance)
+	m.Handle(lib.AERuns.String(), s.Middleware(rh.ListHandler))
+	m.Handle(lib.AERun.String(), s.Middleware(rh.GetHandler))
+
+	sech := NewSecretHandlers(s.Instance, cfg.API.ReadOnly)
+	m.Handle(lib.AESecrets.String(), s.Middleware(sech.ListHandler))
+	m.Handle(lib.AESecretSet.String(), s.Middleware(sech.SetHandler))
+	m.Handle(lib.AESecretRemove.String(), s.Middleware(sech.RemoveHandler))
+	m.Handle(lib.AESecretGrant.String(), s.Middleware(sech.GrantHandler))
+	m.Handle(lib.AESecretRevoke.String(), s.Middleware(sech.RevokeHandler))
+
+	sth := NewStatsHandlers(s.Instance)
+	m.Handle(lib.AEStatsCache.String(), s.Middleware(sth.CacheListHandler))
+	m.Handle(lib.AEStatsCacheClear.String(), s.Middleware(sth.CacheClearHandler))
+	m.Handle(lib.AEStatsCachePrune.String(), s.Middleware(sth.CachePruneHandler))
+
+	rph := NewRepoHandlers(s.Instance)
+	m.Handle(lib.AEGC.String(), s.Middleware(rph.GCHandler))
+	m.Handle(lib.AEFsck.String(), s.Middleware(rph.FsckHandler))
 
 	remClientH := NewRemoteClientHandlers(s.Instance, cfg.API.ReadOnly)
 	m.Handle(lib.AEPush.String(), s.Middleware(remClientH.PushHandler))
@@ -234,6 +280,7 @@ func NewServerRoutes(s Server) *mux.Router {
 
 	tfh := NewTransformHandlers(s.Instance)
 	m.Handle(lib.AEApply.String(), s.Middleware(tfh.ApplyHandler(lib.AEApply.NoTrailingSlash())))
+	m.Handle(lib.AEApplyTest.String(), s.Middleware(tfh.TestHandler))
 
 	if !cfg.API.DisableWebui {
 		m.Handle(lib.AEWebUI.String(), s.Middleware(WebuiHandler))
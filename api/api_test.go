@@ -172,6 +172,25 @@ func TestHealthCheck(t *testing.T) {
 	runHandlerTestCases(t, "health check", HealthCheckHandler, healthCheckCases, true)
 }
 
+func TestIsLoopback(t *testing.T) {
+	cases := []struct {
+		remoteAddr string
+		expect     bool
+	}{
+		{"127.0.0.1:2503", true},
+		{"[::1]:2503", true},
+		{"192.0.2.1:1234", false},
+		{"not an address", false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("POST", "/apply", nil)
+		r.RemoteAddr = c.remoteAddr
+		if got := isLoopback(r); got != c.expect {
+			t.Errorf("%q: expected %t, got %t", c.remoteAddr, c.expect, got)
+		}
+	}
+}
+
 func TestServerReadOnlyRoutes(t *testing.T) {
 	if err := confirmQriNotRunning(); err != nil {
 		t.Skip(err.Error())
@@ -245,6 +264,12 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"DELETE", "/remove", 403},
 		{"POST", "/rename", 403},
 		{"PUT", "/rename", 403},
+		{"POST", "/import/sqlite", 403},
+		{"POST", "/import/datapackage", 403},
+		{"POST", "/patch", 403},
+		{"POST", "/squash", 403},
+		{"POST", "/gc", 403},
+		{"POST", "/fsck", 403},
 		{"POST", "/diff", 403},
 		{"GET", "/diff", 403},
 		{"POST", "/registry/profile/new", 403},
@@ -252,6 +277,18 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"GET", "/checkout/", 403},
 		{"GET", "/status/", 403},
 		{"GET", "/init/", 403},
+		{"POST", "/branch/create", 403},
+		{"GET", "/branch/create", 404},
+		{"POST", "/branch/switch", 403},
+		{"GET", "/branch/switch", 404},
+		{"POST", "/merge", 403},
+		{"GET", "/merge", 404},
+		{"POST", "/access/grant", 403},
+		{"GET", "/access/grant", 404},
+		{"POST", "/access/revoke", 403},
+		{"GET", "/access/revoke", 404},
+		{"POST", "/job/cancel", 403},
+		{"GET", "/job/cancel", 404},
 
 		// active endpoints:
 		{"GET", "/health", 200},
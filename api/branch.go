@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+)
+
+// BranchHandlers connects HTTP requests to the BranchMethods subsystem
+type BranchHandlers struct {
+	*lib.BranchMethods
+}
+
+// NewBranchHandlers constructs a BranchHandlers struct
+func NewBranchHandlers(inst *lib.Instance) BranchHandlers {
+	return BranchHandlers{BranchMethods: lib.NewBranchMethods(inst)}
+}
+
+// ListHandler is an HTTP handler function for listing the branches of a
+// dataset history
+func (h BranchHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.BranchListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.BranchMethods.List(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// CreateHandler is an HTTP handler function for creating a new branch
+func (h BranchHandlers) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.createHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h BranchHandlers) createHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.BranchParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.BranchMethods.Create(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// SwitchHandler is an HTTP handler function for changing the active branch of
+// a dataset
+func (h BranchHandlers) SwitchHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.switchHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h BranchHandlers) switchHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.BranchParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.BranchMethods.Switch(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// MergeHandler is an HTTP handler function for merging a branch into the
+// active branch of a dataset
+func (h BranchHandlers) MergeHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.mergeHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h BranchHandlers) mergeHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.MergeParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.BranchMethods.Merge(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
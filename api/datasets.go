@@ -155,6 +155,47 @@ func (h *DatasetHandlers) UnpackHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// ImportSQLiteHandler creates datasets from the tables of a SQLite database
+func (h *DatasetHandlers) ImportSQLiteHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.importSQLiteHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// ImportDataPackageHandler creates datasets from the resources of a data
+// package
+func (h *DatasetHandlers) ImportDataPackageHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.importDataPackageHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// ApplyPatchHandler applies a patch file to a dataset
+func (h *DatasetHandlers) ApplyPatchHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.applyPatchHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// SquashHandler collapses runs of versions in a dataset history
+func (h *DatasetHandlers) SquashHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.squashHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
 func extensionToMimeType(ext string) string {
 	switch ext {
 	case ".csv":
@@ -167,6 +208,10 @@ func extensionToMimeType(ext string) string {
 		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	case ".zip":
 		return "application/zip"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	case ".arrow":
+		return "application/vnd.apache.arrow.file"
 	default:
 		return ""
 	}
@@ -406,6 +451,17 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if params.Async {
+		job, err := h.SaveAsync(r.Context(), params)
+		if err != nil {
+			log.Debugw("save dataset async error", "err", err)
+			util.WriteErrResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		util.WriteResponse(w, job)
+		return
+	}
+
 	scriptOutput := &bytes.Buffer{}
 	params.ScriptOutput = scriptOutput
 
@@ -481,6 +537,78 @@ func (h DatasetHandlers) renameHandler(w http.ResponseWriter, r *http.Request) {
 	util.WriteResponse(w, res)
 }
 
+func (h DatasetHandlers) importSQLiteHandler(w http.ResponseWriter, r *http.Request) {
+	params := &lib.ImportSQLiteParams{}
+	err := UnmarshalParams(r, params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ImportSQLite(r.Context(), params)
+	if err != nil {
+		log.Infof("error importing sqlite database: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	util.WriteResponse(w, res)
+}
+
+func (h DatasetHandlers) importDataPackageHandler(w http.ResponseWriter, r *http.Request) {
+	params := &lib.ImportDataPackageParams{}
+	err := UnmarshalParams(r, params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ImportDataPackage(r.Context(), params)
+	if err != nil {
+		log.Infof("error importing data package: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	util.WriteResponse(w, res)
+}
+
+func (h DatasetHandlers) applyPatchHandler(w http.ResponseWriter, r *http.Request) {
+	params := &lib.ApplyPatchParams{}
+	err := UnmarshalParams(r, params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ApplyPatch(r.Context(), params)
+	if err != nil {
+		log.Infof("error applying patch: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	util.WriteResponse(w, res)
+}
+
+func (h DatasetHandlers) squashHandler(w http.ResponseWriter, r *http.Request) {
+	params := &lib.SquashParams{}
+	err := UnmarshalParams(r, params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Squash(r.Context(), params)
+	if err != nil {
+		log.Infof("error squashing dataset: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	util.WriteResponse(w, res)
+}
+
 func loadFileIfPath(path string) (file *os.File, err error) {
 	if path == "" {
 		return nil, nil
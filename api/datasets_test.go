@@ -16,7 +16,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/gorilla/mux"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/qri/base/columnar"
 	"github.com/qri-io/qri/lib"
 )
 
@@ -198,6 +200,34 @@ func TestSaveWithInferredNewName(t *testing.T) {
 	}
 }
 
+func TestApplyPatchHandler(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	h := NewDatasetHandlers(run.Inst, false)
+	run.SaveDataset(&dataset.Dataset{Name: "test_ds", Meta: &dataset.Meta{Title: "title one"}}, "testdata/cities/data.csv")
+	head, err := h.Get(run.Ctx, &lib.GetParams{Refstr: "peer/test_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patch document is sent in the request body
+	body := fmt.Sprintf(`{"ref":"peer/test_ds","patch":{"qri":"pt:0","base":%q,"selector":"meta","diff":[["~","title","title two"]]}}`, head.Dataset.Path)
+	w := httptest.NewRecorder()
+	h.ApplyPatchHandler(w, postJSONRequest("/patch", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, resultText(w))
+	}
+
+	res, err := h.Get(run.Ctx, &lib.GetParams{Refstr: "peer/test_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Dataset.Meta == nil || res.Dataset.Meta.Title != "title two" {
+		t.Errorf("expected patched meta title. got: %v", res.Dataset.Meta)
+	}
+}
+
 func postJSONRequest(url, jsonBody string) *http.Request {
 	req := httptest.NewRequest("POST", url, bytes.NewBuffer([]byte(jsonBody)))
 	req.Header.Set("Content-Type", "application/json")
@@ -276,6 +306,28 @@ func TestParseGetParams(t *testing.T) {
 			},
 			&map[string]string{"peername": "peer", "name": "my_ds", "selector": "body"},
 		},
+		{
+			"body.parquet path suffix",
+			"/get/peer/my_ds/body.parquet",
+			&lib.GetParams{
+				Refstr:   "peer/my_ds",
+				Format:   "parquet",
+				Selector: "body",
+				All:      true,
+			},
+			&map[string]string{"peername": "peer", "name": "my_ds", "selector": "body.parquet"},
+		},
+		{
+			"download body as arrow",
+			"/get/peer/my_ds/body?format=arrow",
+			&lib.GetParams{
+				Refstr:   "peer/my_ds",
+				Format:   "arrow",
+				Selector: "body",
+				All:      true,
+			},
+			&map[string]string{"peername": "peer", "name": "my_ds", "selector": "body"},
+		},
 		{
 			"zip format",
 			"/get/peer/my_ds?format=zip",
@@ -424,6 +476,33 @@ func TestGetZip(t *testing.T) {
 	}
 }
 
+func TestGetBodyColumnar(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	ds := run.BuildDataset("test_ds")
+	run.SaveDataset(ds, "testdata/cities/data.csv")
+
+	dsHandler := NewDatasetHandlers(run.Inst, false)
+	for _, format := range []string{columnar.ParquetFormat, columnar.ArrowFormat} {
+		gotStatusCode, gotBody := APICall("/get/peer/test_ds/body?format="+format, dsHandler.GetHandler, &map[string]string{"peername": "peer", "name": "test_ds", "selector": "body"})
+		if gotStatusCode != 200 {
+			t.Fatalf("%s: expected status code 200, got %d: %s", format, gotStatusCode, gotBody)
+		}
+		r, err := columnar.NewEntryReader(format, strings.NewReader(gotBody))
+		if err != nil {
+			t.Fatalf("%s: reading response body: %s", format, err)
+		}
+		rows, err := dsio.ReadAllArray(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 5 {
+			t.Errorf("%s: expected 5 rows, got %d", format, len(rows))
+		}
+	}
+}
+
 func trimGetOrBodyPrefix(text string) string {
 	if strings.HasPrefix(text, "/get/") {
 		text = strings.TrimPrefix(text, "/get/")
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+)
+
+// JobHandlers connects HTTP requests to the JobMethods subsystem
+type JobHandlers struct {
+	*lib.JobMethods
+}
+
+// NewJobHandlers constructs a JobHandlers struct
+func NewJobHandlers(inst *lib.Instance) JobHandlers {
+	return JobHandlers{JobMethods: lib.NewJobMethods(inst)}
+}
+
+// ListHandler is an HTTP handler function for listing background jobs
+func (h JobHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.JobListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.JobMethods.List(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// GetHandler is an HTTP handler function for fetching the status of a job
+func (h JobHandlers) GetHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.JobParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.JobMethods.Get(r.Context(), &p)
+	if errors.Is(err, lib.ErrJobNotFound) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// CancelHandler is an HTTP handler function for canceling a running job
+func (h JobHandlers) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.cancelHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h JobHandlers) cancelHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.JobParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.JobMethods.Cancel(r.Context(), &p)
+	if errors.Is(err, lib.ErrJobNotFound) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
 )
 
 // Middleware handles request logging
@@ -32,6 +34,10 @@ func (s Server) mwFunc(handler http.HandlerFunc, shouldLog bool) http.HandlerFun
 			return
 		}
 
+		if !isLoopback(r) {
+			r = r.WithContext(lib.WithRemoteCaller(r.Context()))
+		}
+
 		if ok := s.readOnlyCheck(r); ok {
 			handler(w, r)
 		} else {
@@ -40,6 +46,17 @@ func (s Server) mwFunc(handler http.HandlerFunc, shouldLog bool) http.HandlerFun
 	}
 }
 
+// isLoopback reports whether a request comes from the same machine, like
+// requests the qri command line makes to a running qri node
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func (s *Server) readOnlyCheck(r *http.Request) bool {
 	return !s.Config().API.ReadOnly || r.Method == "GET" || r.Method == "OPTIONS"
 }
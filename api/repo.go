@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+)
+
+// RepoHandlers connects HTTP requests to the RepoMethods subsystem
+type RepoHandlers struct {
+	*lib.RepoMethods
+}
+
+// NewRepoHandlers constructs a RepoHandlers struct
+func NewRepoHandlers(inst *lib.Instance) RepoHandlers {
+	return RepoHandlers{RepoMethods: lib.NewRepoMethods(inst)}
+}
+
+// GCHandler is an HTTP handler function for collecting unreferenced data
+func (h RepoHandlers) GCHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	p := lib.GCParams{}
+	if err := UnmarshalParams(r, &p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := h.RepoMethods.GC(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// FsckHandler is an HTTP handler function for checking the repo for
+// inconsistencies
+func (h RepoHandlers) FsckHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	p := lib.FsckParams{}
+	if err := UnmarshalParams(r, &p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := h.RepoMethods.Fsck(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/transform/run"
+)
+
+// RunHandlers connects HTTP requests to the RunMethods subsystem
+type RunHandlers struct {
+	*lib.RunMethods
+}
+
+// NewRunHandlers constructs a RunHandlers struct
+func NewRunHandlers(inst *lib.Instance) RunHandlers {
+	return RunHandlers{RunMethods: lib.NewRunMethods(inst)}
+}
+
+// ListHandler is an HTTP handler function for listing the transform runs of
+// a dataset
+func (h RunHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.RunListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.RunMethods.List(r.Context(), &p)
+	if err != nil {
+		util.RespondWithError(w, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// GetHandler is an HTTP handler function for fetching a transform run
+func (h RunHandlers) GetHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.RunParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.RunMethods.Get(r.Context(), &p)
+	if errors.Is(err, run.ErrNotFound) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/scheduler"
+)
+
+// ScheduleHandlers connects HTTP requests to the ScheduleMethods subsystem
+type ScheduleHandlers struct {
+	*lib.ScheduleMethods
+	ReadOnly bool
+}
+
+// NewScheduleHandlers constructs a ScheduleHandlers struct
+func NewScheduleHandlers(inst *lib.Instance, readOnly bool) ScheduleHandlers {
+	return ScheduleHandlers{ScheduleMethods: lib.NewScheduleMethods(inst), ReadOnly: readOnly}
+}
+
+// ListHandler is an HTTP handler function for listing scheduled datasets
+func (h ScheduleHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.ScheduleListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ScheduleMethods.List(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// AddHandler is an HTTP handler function for scheduling a dataset
+func (h ScheduleHandlers) AddHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AEScheduleAdd.String())
+		return
+	}
+	p := lib.ScheduleParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ScheduleMethods.Add(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// RemoveHandler is an HTTP handler function for removing a dataset schedule
+func (h ScheduleHandlers) RemoveHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AEScheduleRemove.String())
+		return
+	}
+	p := lib.ScheduleParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ScheduleMethods.Remove(r.Context(), &p)
+	if errors.Is(err, scheduler.ErrNotScheduled) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// RunHandler is an HTTP handler function for running a scheduled dataset
+// immediately
+func (h ScheduleHandlers) RunHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AEScheduleRun.String())
+		return
+	}
+	p := lib.ScheduleParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.ScheduleMethods.RunNow(r.Context(), &p)
+	if errors.Is(err, scheduler.ErrNotScheduled) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
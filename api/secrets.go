@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/secrets"
+)
+
+// SecretHandlers connects HTTP requests to the SecretMethods subsystem
+type SecretHandlers struct {
+	*lib.SecretMethods
+	ReadOnly bool
+}
+
+// NewSecretHandlers constructs a SecretHandlers struct
+func NewSecretHandlers(inst *lib.Instance, readOnly bool) SecretHandlers {
+	return SecretHandlers{SecretMethods: lib.NewSecretMethods(inst), ReadOnly: readOnly}
+}
+
+// ListHandler is an HTTP handler function for listing the names of stored
+// secrets
+func (h SecretHandlers) ListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.SecretListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SecretMethods.List(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// SetHandler is an HTTP handler function for storing a secret
+func (h SecretHandlers) SetHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AESecretSet.String())
+		return
+	}
+	p := lib.SecretParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SecretMethods.Set(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// RemoveHandler is an HTTP handler function for removing a stored secret
+func (h SecretHandlers) RemoveHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AESecretRemove.String())
+		return
+	}
+	p := lib.SecretParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SecretMethods.Remove(r.Context(), &p)
+	if errors.Is(err, secrets.ErrNotFound) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// GrantHandler is an HTTP handler function for allowing a dataset's
+// transforms to read a stored secret
+func (h SecretHandlers) GrantHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AESecretGrant.String())
+		return
+	}
+	p := lib.SecretGrantParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SecretMethods.Grant(r.Context(), &p)
+	if errors.Is(err, secrets.ErrNotFound) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// RevokeHandler is an HTTP handler function for stopping a dataset's
+// transforms from reading a stored secret
+func (h SecretHandlers) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, lib.AESecretRevoke.String())
+		return
+	}
+	p := lib.SecretGrantParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SecretMethods.Revoke(r.Context(), &p)
+	if errors.Is(err, secrets.ErrNotGranted) {
+		util.WriteErrResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -61,6 +61,8 @@ func (h *SQLHandlers) queryHandler(routePrefix string) http.HandlerFunc {
 			if format := r.FormValue("output_format"); format != "" {
 				p.OutputFormat = format
 			}
+			p.ResolverMode = r.FormValue("remote")
+			p.SaveTo = r.FormValue("save")
 		}
 
 		var res []byte
@@ -69,7 +71,8 @@ func (h *SQLHandlers) queryHandler(routePrefix string) http.HandlerFunc {
 			return
 		}
 
-		if p.OutputFormat == "json" {
+		// saved results are always a JSON dataset
+		if p.OutputFormat == "json" || p.SaveTo != "" {
 			util.WriteResponse(w, json.RawMessage(res))
 			return
 		}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/lib"
+)
+
+// StatsHandlers connects HTTP requests to the StatsMethods subsystem
+type StatsHandlers struct {
+	*lib.StatsMethods
+}
+
+// NewStatsHandlers constructs a StatsHandlers struct
+func NewStatsHandlers(inst *lib.Instance) StatsHandlers {
+	return StatsHandlers{StatsMethods: lib.NewStatsMethods(inst)}
+}
+
+// CacheListHandler is an HTTP handler function for listing stats cache contents
+func (h StatsHandlers) CacheListHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.StatsCacheParams{}
+	res, err := h.StatsMethods.CacheList(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// CacheClearHandler is an HTTP handler function for dropping all cached stats
+func (h StatsHandlers) CacheClearHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	p := lib.StatsCacheParams{}
+	if err := h.StatsMethods.CacheClear(r.Context(), &p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, true)
+}
+
+// CachePruneHandler is an HTTP handler function for dropping unreferenced &
+// stale cached stats
+func (h StatsHandlers) CachePruneHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	p := lib.StatsCacheParams{}
+	res, err := h.StatsMethods.CachePrune(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -36,3 +36,20 @@ func (h TransformHandlers) ApplyHandler(prefix string) http.HandlerFunc {
 		util.WriteResponse(w, res)
 	}
 }
+
+// TestHandler is an HTTP handler function for running the tests of a
+// transform script
+func (h TransformHandlers) TestHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.TransformTestParams{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.TransformMethods.Test(r.Context(), &p)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/columnar"
 	"github.com/qri-io/qri/dsref"
 )
 
@@ -45,10 +46,16 @@ func Export(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset, refStr,
 		}
 	}
 
+	// data packages are written as zip archives
+	fileExt := format
+	if format == DataPackageFormat {
+		fileExt = "zip"
+	}
+
 	var fileWritten string
 	if output == "" || isDirectory(output) {
 		// If output is blank or a directory, derive filename from repo name and commit timestamp.
-		baseName, err := GenerateFilename(ds, format)
+		baseName, err := GenerateFilename(ds, fileExt)
 		if err != nil {
 			return "", err
 		}
@@ -64,9 +71,10 @@ func Export(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset, refStr,
 		// from file extension.
 		if outputFormat == "" && !zipped {
 			format = ext
+			fileExt = ext
 		}
 		// Make sure the format doesn't contradict the file extension.
-		if ext != format {
+		if ext != fileExt {
 			return "", fmt.Errorf("file extension doesn't match format %s <> %s", ext, format)
 		}
 		fileWritten = output
@@ -82,7 +90,7 @@ func Export(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset, refStr,
 	}
 
 	// If output is a format wrapped in a zip file, fixup the output name.
-	if zipped && format != "zip" {
+	if zipped && fileExt != "zip" {
 		outputPath = replaceExt(outputPath, ".zip")
 		fileWritten = replaceExt(fileWritten, ".zip")
 	}
@@ -100,7 +108,7 @@ func Export(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset, refStr,
 	}
 
 	// If outputting a wrapped zip file, create the zip wrapper.
-	if zipped && format != "zip" {
+	if zipped && fileExt != "zip" {
 		zipWriter := zip.NewWriter(writer)
 
 		writer, err = zipWriter.Create(fmt.Sprintf("dataset.%s", format))
@@ -186,6 +194,23 @@ func Export(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset, refStr,
 		}
 		return fileWritten, w.Close()
 
+	case columnar.ParquetFormat, columnar.ArrowFormat:
+		w, err := columnar.NewEntryWriter(format, ds.Structure, writer)
+		if err != nil {
+			return "", err
+		}
+
+		if err := dsio.Copy(reader, w); err != nil {
+			return "", err
+		}
+		return fileWritten, w.Close()
+
+	case DataPackageFormat:
+		if err := writeDataPackage(ds, reader, writer); err != nil {
+			return "", err
+		}
+		return fileWritten, nil
+
 	case "zip":
 		ref, err := dsref.Parse(refStr)
 		if err != nil {
@@ -2,14 +2,19 @@ package archive
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/columnar"
 	"github.com/qri-io/qri/base/dsfs"
 	testPeers "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/event"
@@ -106,6 +111,67 @@ func TestGenerateFilename(t *testing.T) {
 	}
 }
 
+func TestExportColumnar(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "archive_export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, format := range []string{columnar.ParquetFormat, columnar.ArrowFormat} {
+		ds := &dataset.Dataset{
+			Peername: "me",
+			Name:     "movies",
+			Structure: &dataset.Structure{
+				Format:       "csv",
+				FormatConfig: map[string]interface{}{"headerRow": true},
+				Schema: map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "array",
+						"items": []interface{}{
+							map[string]interface{}{"title": "movie", "type": "string"},
+							map[string]interface{}{"title": "year", "type": "integer"},
+						},
+					},
+				},
+			},
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.csv", []byte("movie,year\nup,2009\nthe incredibles,2004\n")))
+
+		output := "movies." + format
+		written, err := Export(ctx, nil, ds, "me/movies", dir, output, "", false)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if written != output {
+			t.Errorf("%s: expected written filename %q, got %q", format, output, written)
+		}
+
+		f, err := os.Open(filepath.Join(dir, output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := columnar.NewEntryReader(format, f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		rows, err := dsio.ReadAllArray(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []interface{}{
+			[]interface{}{"up", int64(2009)},
+			[]interface{}{"the incredibles", int64(2004)},
+		}
+		if diff := cmp.Diff(expect, rows); diff != "" {
+			t.Errorf("%s: exported rows mismatch (-want +got):\n%s", format, diff)
+		}
+	}
+}
+
 func testFS() (qfs.Filesystem, map[string]string, error) {
 	ctx := context.Background()
 	dataf := qfs.NewMemfileBytes("/body.csv", []byte("movie\nup\nthe incredibles"))
@@ -0,0 +1,633 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+)
+
+const (
+	// DataPackageFormat is the export format for Frictionless Data Packages.
+	// Data packages are exported as zip archives
+	DataPackageFormat = "datapackage"
+	// DataPackageFilename is the name of the descriptor file of a data package
+	DataPackageFilename = "datapackage.json"
+)
+
+// ErrNotDataPackage indicates an archive doesn't contain a data package
+// descriptor
+var ErrNotDataPackage = errors.New("not a data package")
+
+// DataPackage is a Frictionless Data Package descriptor, as specified by
+// https://specs.frictionlessdata.io/data-package/
+type DataPackage struct {
+	Profile      string                   `json:"profile,omitempty"`
+	Name         string                   `json:"name,omitempty"`
+	ID           string                   `json:"id,omitempty"`
+	Title        string                   `json:"title,omitempty"`
+	Description  string                   `json:"description,omitempty"`
+	Homepage     string                   `json:"homepage,omitempty"`
+	Version      string                   `json:"version,omitempty"`
+	Keywords     []string                 `json:"keywords,omitempty"`
+	Licenses     []DataPackageLicense     `json:"licenses,omitempty"`
+	Sources      []DataPackageSource      `json:"sources,omitempty"`
+	Contributors []DataPackageContributor `json:"contributors,omitempty"`
+	Resources    []DataResource           `json:"resources"`
+}
+
+// DataPackageLicense is a license that applies to a package or resource
+type DataPackageLicense struct {
+	Name  string `json:"name,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// DataPackageSource is a raw source of a package or resource
+type DataPackageSource struct {
+	Title string `json:"title,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// DataPackageContributor is a person or organization that contributed to a
+// package
+type DataPackageContributor struct {
+	Title string `json:"title,omitempty"`
+	Email string `json:"email,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+// DataResource describes a single data file of a package, as specified by
+// https://specs.frictionlessdata.io/data-resource/. Path may be a string or a
+// list of strings, only single file resources are supported. Data holds
+// inline resource data
+type DataResource struct {
+	Profile     string               `json:"profile,omitempty"`
+	Name        string               `json:"name"`
+	Path        interface{}          `json:"path,omitempty"`
+	Data        interface{}          `json:"data,omitempty"`
+	Title       string               `json:"title,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Format      string               `json:"format,omitempty"`
+	Mediatype   string               `json:"mediatype,omitempty"`
+	Encoding    string               `json:"encoding,omitempty"`
+	Schema      *TableSchema         `json:"schema,omitempty"`
+	Dialect     *CSVDialect          `json:"dialect,omitempty"`
+	Licenses    []DataPackageLicense `json:"licenses,omitempty"`
+	Sources     []DataPackageSource  `json:"sources,omitempty"`
+}
+
+// TableSchema describes the columns of a tabular resource, as specified by
+// https://specs.frictionlessdata.io/table-schema/
+type TableSchema struct {
+	Fields        []TableField `json:"fields"`
+	PrimaryKey    interface{}  `json:"primaryKey,omitempty"`
+	MissingValues []string     `json:"missingValues,omitempty"`
+}
+
+// TableField describes a single column of a table schema
+type TableField struct {
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Constraints map[string]interface{} `json:"constraints,omitempty"`
+}
+
+// CSVDialect describes the layout of a CSV resource, as specified by
+// https://specs.frictionlessdata.io/csv-dialect/
+type CSVDialect struct {
+	Delimiter string `json:"delimiter,omitempty"`
+	Header    *bool  `json:"header,omitempty"`
+}
+
+// constraints shared by table schema fields & JSON schema
+var sharedConstraints = []string{"minimum", "maximum", "minLength", "maxLength", "pattern", "enum"}
+
+// WriteDataPackage writes a zipped data package of a dataset. The package
+// descriptor carries dataset metadata, and the body is written as a single
+// resource. Tabular bodies are written as CSV with a table schema, all other
+// bodies as JSON
+func WriteDataPackage(ds *dataset.Dataset, w io.Writer) error {
+	if ds.BodyFile() == nil || ds.Structure == nil {
+		return fmt.Errorf("a body & structure are required to write a data package")
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return err
+	}
+	return writeDataPackage(ds, r, w)
+}
+
+func writeDataPackage(ds *dataset.Dataset, r dsio.EntryReader, w io.Writer) error {
+	name := ds.Name
+	if name == "" {
+		name = "dataset"
+	}
+	pkg := dataPackageFromMeta(name, ds.Meta)
+
+	res := DataResource{Name: name, Encoding: "utf-8"}
+	var st *dataset.Structure
+	if schema, err := tableSchema(ds.Structure.Schema); err == nil {
+		header := true
+		pkg.Profile = "tabular-data-package"
+		res.Profile = "tabular-data-resource"
+		res.Path = fmt.Sprintf("data/%s.csv", name)
+		res.Format = "csv"
+		res.Mediatype = "text/csv"
+		res.Schema = schema
+		res.Dialect = &CSVDialect{Header: &header}
+		st = &dataset.Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Schema:       ds.Structure.Schema,
+		}
+	} else {
+		res.Path = fmt.Sprintf("data/%s.json", name)
+		res.Format = "json"
+		res.Mediatype = "application/json"
+		st = &dataset.Structure{Format: "json", Schema: ds.Structure.Schema}
+	}
+	pkg.Resources = []DataResource{res}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(DataPackageFilename)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(pkg); err != nil {
+		return err
+	}
+
+	if f, err = zw.Create(res.Path.(string)); err != nil {
+		return err
+	}
+	bw, err := dsio.NewEntryWriter(st, f)
+	if err != nil {
+		return err
+	}
+	if err := dsio.Copy(r, bw); err != nil {
+		return err
+	}
+	if err := bw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// dataPackageFromMeta maps dataset metadata to package metadata
+func dataPackageFromMeta(name string, md *dataset.Meta) *DataPackage {
+	pkg := &DataPackage{Profile: "data-package", Name: name}
+	if md == nil {
+		return pkg
+	}
+	pkg.ID = md.Identifier
+	pkg.Title = md.Title
+	pkg.Description = md.Description
+	pkg.Homepage = md.HomeURL
+	pkg.Version = md.Version
+	pkg.Keywords = md.Keywords
+	if md.License != nil {
+		pkg.Licenses = []DataPackageLicense{{Name: md.License.Type, Path: md.License.URL}}
+	}
+	for _, c := range md.Citations {
+		if c != nil {
+			pkg.Sources = append(pkg.Sources, DataPackageSource{Title: c.Name, Path: c.URL, Email: c.Email})
+		}
+	}
+	for _, u := range md.Contributors {
+		if u != nil {
+			pkg.Contributors = append(pkg.Contributors, DataPackageContributor{Title: u.Fullname, Email: u.Email, Path: u.ID})
+		}
+	}
+	return pkg
+}
+
+// tableSchema converts a tabular JSON schema to a table schema. Columns that
+// accept more than one type are typed "any"
+func tableSchema(sch map[string]interface{}) (*TableSchema, error) {
+	if sch == nil {
+		return nil, fmt.Errorf("no schema")
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(sch)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("schema has no columns")
+	}
+
+	ts := &TableSchema{Fields: make([]TableField, len(cols))}
+	for i, col := range cols {
+		f := TableField{Name: col.Title, Description: col.Description, Type: "any"}
+		if col.Type != nil {
+			types := []string{}
+			for _, t := range *col.Type {
+				if t != "null" {
+					types = append(types, t)
+				}
+			}
+			if len(types) == 1 {
+				f.Type = types[0]
+			}
+		}
+		format, _ := col.Validation["format"].(string)
+		if f.Type == "string" {
+			switch format {
+			case "date":
+				f.Type = "date"
+			case "date-time":
+				f.Type = "datetime"
+			case "time":
+				f.Type = "time"
+			case "email", "uri":
+				f.Format = format
+			}
+		}
+		for _, key := range sharedConstraints {
+			if v, ok := col.Validation[key]; ok {
+				if f.Constraints == nil {
+					f.Constraints = map[string]interface{}{}
+				}
+				f.Constraints[key] = v
+			}
+		}
+		ts.Fields[i] = f
+	}
+	if key, ok := sch["primaryKey"]; ok {
+		ts.PrimaryKey = key
+	}
+	return ts, nil
+}
+
+// jsonSchema converts a table schema to a tabular JSON schema
+func (ts *TableSchema) jsonSchema() map[string]interface{} {
+	items := make([]interface{}, len(ts.Fields))
+	for i, f := range ts.Fields {
+		col := map[string]interface{}{"title": f.Name}
+		if f.Description != "" {
+			col["description"] = f.Description
+		}
+		switch f.Type {
+		case "", "string":
+			col["type"] = "string"
+			if f.Format == "email" || f.Format == "uri" {
+				col["format"] = f.Format
+			}
+		case "integer", "number", "boolean", "object", "array":
+			col["type"] = f.Type
+		case "date":
+			col["type"] = "string"
+			col["format"] = "date"
+		case "datetime":
+			col["type"] = "string"
+			col["format"] = "date-time"
+		case "time":
+			col["type"] = "string"
+			col["format"] = "time"
+		case "year":
+			col["type"] = "integer"
+		case "geojson":
+			col["type"] = "object"
+		case "any":
+			// any type is valid, leave the column untyped
+		default:
+			// yearmonth, duration, geopoint & unknown types are read as strings
+			col["type"] = "string"
+		}
+		for _, key := range sharedConstraints {
+			if v, ok := f.Constraints[key]; ok {
+				col[key] = v
+			}
+		}
+		items[i] = col
+	}
+	sch := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	}
+	if ts.PrimaryKey != nil {
+		sch["primaryKey"] = ts.PrimaryKey
+	}
+	return sch
+}
+
+// ReadDataPackageFile reads a datapackage.json descriptor from the local
+// filesystem, returning a dataset for each resource in the package. Resource
+// paths are relative to the descriptor, or http(s) URLs
+func ReadDataPackageFile(filename string) ([]*dataset.Dataset, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(filename)
+	return readDataPackage(data, func(p string) (string, []byte, error) {
+		if qfs.PathKind(p) == "http" {
+			data, err := fetch(p)
+			return p, data, err
+		}
+		abs := filepath.Join(dir, filepath.FromSlash(p))
+		data, err := ioutil.ReadFile(abs)
+		return abs, data, err
+	})
+}
+
+// UnzipDataPackageBytes reads a zipped data package, returning a dataset for
+// each resource. Archives without a datapackage.json descriptor return
+// ErrNotDataPackage
+func UnzipDataPackageBytes(zipData []byte) ([]*dataset.Dataset, error) {
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	desc, ok := files[DataPackageFilename]
+	if !ok {
+		return nil, ErrNotDataPackage
+	}
+	data, err := readZipFile(desc)
+	if err != nil {
+		return nil, err
+	}
+	return readDataPackage(data, func(p string) (string, []byte, error) {
+		if qfs.PathKind(p) == "http" {
+			data, err := fetch(p)
+			return p, data, err
+		}
+		f, ok := files[p]
+		if !ok {
+			return "", nil, fmt.Errorf("resource %q is not in the archive", p)
+		}
+		data, err := readZipFile(f)
+		return "", data, err
+	})
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func fetch(url string) ([]byte, error) {
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// resourceOpener reads the data of a resource path, returning the location to
+// record as the dataset body path, if any
+type resourceOpener func(path string) (string, []byte, error)
+
+func readDataPackage(descriptor []byte, open resourceOpener) ([]*dataset.Dataset, error) {
+	pkg := &DataPackage{}
+	if err := json.Unmarshal(descriptor, pkg); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", DataPackageFilename, err)
+	}
+	if len(pkg.Resources) == 0 {
+		return nil, fmt.Errorf("data package has no resources")
+	}
+
+	dss := make([]*dataset.Dataset, len(pkg.Resources))
+	for i, res := range pkg.Resources {
+		ds, err := resourceDataset(pkg, res, open)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", res.Name, err)
+		}
+		dss[i] = ds
+	}
+	return dss, nil
+}
+
+// resourceDataset creates a dataset from a single resource. Package metadata
+// is mapped to dataset metadata, with the title, description, licenses &
+// sources of the resource taking precedence
+func resourceDataset(pkg *DataPackage, res DataResource, open resourceOpener) (*dataset.Dataset, error) {
+	name := res.Name
+	if name == "" {
+		name = pkg.Name
+	}
+	ds := &dataset.Dataset{
+		Name: dsref.GenerateName(name, "dataset_"),
+		Meta: metaFromDataPackage(pkg, res),
+	}
+
+	format := strings.ToLower(res.Format)
+	var data []byte
+	switch p := res.Path.(type) {
+	case nil:
+		if res.Data == nil {
+			return nil, fmt.Errorf("resource has no path or data")
+		}
+		if s, ok := res.Data.(string); ok {
+			// inline strings are CSV
+			data = []byte(s)
+			if format == "" {
+				format = "csv"
+			}
+		} else {
+			rows, err := inlineRows(res.Data, res.Schema)
+			if err != nil {
+				return nil, err
+			}
+			if data, err = json.Marshal(rows); err != nil {
+				return nil, err
+			}
+			format = "json"
+		}
+	case string:
+		if err := checkResourcePath(p); err != nil {
+			return nil, err
+		}
+		bodyPath, d, err := open(p)
+		if err != nil {
+			return nil, err
+		}
+		data = d
+		ds.BodyPath = bodyPath
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(path.Ext(p)), ".")
+		}
+	case []interface{}:
+		return nil, fmt.Errorf("resources with multiple data files aren't supported")
+	default:
+		return nil, fmt.Errorf("invalid resource path: %v", p)
+	}
+
+	st := &dataset.Structure{}
+	switch format {
+	case "csv", "tsv":
+		header := res.Dialect == nil || res.Dialect.Header == nil || *res.Dialect.Header
+		st.Format = "csv"
+		st.FormatConfig = map[string]interface{}{"headerRow": header, "lazyQuotes": true}
+		if format == "tsv" {
+			st.FormatConfig["separator"] = "\t"
+		} else if res.Dialect != nil && res.Dialect.Delimiter != "" && res.Dialect.Delimiter != "," {
+			st.FormatConfig["separator"] = res.Dialect.Delimiter
+		}
+	case "json":
+		st.Format = "json"
+		if res.Schema != nil {
+			// JSON tabular data may be an array of objects
+			var rows interface{}
+			if err := json.Unmarshal(data, &rows); err != nil {
+				return nil, err
+			}
+			arrays, err := inlineRows(rows, res.Schema)
+			if err != nil {
+				return nil, err
+			}
+			if data, err = json.Marshal(arrays); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resource format %q, data packages can be read from csv & json resources", format)
+	}
+	if res.Schema != nil && len(res.Schema.Fields) > 0 {
+		st.Schema = res.Schema.jsonSchema()
+	}
+	ds.Structure = st
+	ds.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("%s.%s", ds.Name, st.Format), data))
+	return ds, nil
+}
+
+// checkResourcePath rejects absolute & parent-relative resource paths, which
+// the data package spec forbids for security
+func checkResourcePath(p string) error {
+	if qfs.PathKind(p) == "http" {
+		return nil
+	}
+	if strings.HasPrefix(p, "/") || filepath.IsAbs(p) {
+		return fmt.Errorf("resource path %q must be relative", p)
+	}
+	for _, part := range strings.Split(path.Clean(p), "/") {
+		if part == ".." {
+			return fmt.Errorf("resource path %q can't refer to a parent directory", p)
+		}
+	}
+	return nil
+}
+
+// inlineRows arranges JSON tabular data as an array of arrays. Rows that are
+// objects are ordered by schema field names. A leading row of field names is
+// a header, and is dropped
+func inlineRows(data interface{}, ts *TableSchema) ([]interface{}, error) {
+	rows, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tabular data must be an array")
+	}
+	if ts == nil || len(ts.Fields) == 0 {
+		return rows, nil
+	}
+
+	out := make([]interface{}, 0, len(rows))
+	for i, row := range rows {
+		switch r := row.(type) {
+		case []interface{}:
+			if i == 0 && isHeaderRow(r, ts) {
+				continue
+			}
+			out = append(out, r)
+		case map[string]interface{}:
+			arr := make([]interface{}, len(ts.Fields))
+			for j, f := range ts.Fields {
+				arr[j] = r[f.Name]
+			}
+			out = append(out, arr)
+		default:
+			return nil, fmt.Errorf("row %d: tabular rows must be arrays or objects", i)
+		}
+	}
+	return out, nil
+}
+
+func isHeaderRow(row []interface{}, ts *TableSchema) bool {
+	if len(row) != len(ts.Fields) {
+		return false
+	}
+	for i, v := range row {
+		if s, ok := v.(string); !ok || s != ts.Fields[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// metaFromDataPackage maps package & resource metadata to dataset metadata
+func metaFromDataPackage(pkg *DataPackage, res DataResource) *dataset.Meta {
+	md := &dataset.Meta{
+		Identifier:  pkg.ID,
+		Title:       pkg.Title,
+		Description: pkg.Description,
+		HomeURL:     pkg.Homepage,
+		Version:     pkg.Version,
+		Keywords:    pkg.Keywords,
+	}
+	if res.Title != "" {
+		md.Title = res.Title
+	}
+	if res.Description != "" {
+		md.Description = res.Description
+	}
+
+	licenses := pkg.Licenses
+	if len(res.Licenses) > 0 {
+		licenses = res.Licenses
+	}
+	if len(licenses) > 0 {
+		md.License = &dataset.License{Type: licenses[0].Name, URL: licenses[0].Path}
+		if md.License.Type == "" {
+			md.License.Type = licenses[0].Title
+		}
+	}
+
+	sources := pkg.Sources
+	if len(res.Sources) > 0 {
+		sources = res.Sources
+	}
+	for _, s := range sources {
+		md.Citations = append(md.Citations, &dataset.Citation{Name: s.Title, URL: s.Path, Email: s.Email})
+	}
+	for _, c := range pkg.Contributors {
+		md.Contributors = append(md.Contributors, &dataset.User{ID: c.Path, Fullname: c.Title, Email: c.Email})
+	}
+
+	if md.IsEmpty() {
+		return nil
+	}
+	return md
+}
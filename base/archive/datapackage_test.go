@@ -0,0 +1,242 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func TestExportDataPackageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "archive_datapackage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	schema := map[string]interface{}{
+		"type":       "array",
+		"primaryKey": []interface{}{"movie"},
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "movie", "type": "string", "description": "movie title"},
+				map[string]interface{}{"title": "year", "type": "integer", "minimum": float64(1900)},
+				map[string]interface{}{"title": "released", "type": "string", "format": "date"},
+			},
+		},
+	}
+	meta := &dataset.Meta{
+		Title:       "pixar movies",
+		Description: "movies made by pixar",
+		Keywords:    []string{"movies", "animation"},
+		License:     &dataset.License{Type: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"},
+		Citations:   []*dataset.Citation{{Name: "wikipedia", URL: "https://en.wikipedia.org/wiki/Pixar"}},
+	}
+	ds := &dataset.Dataset{
+		Peername: "me",
+		Name:     "movies",
+		Meta:     meta,
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: schema,
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[["up",2009,"2009-05-29"],["the incredibles",2004,"2004-11-05"]]`)))
+
+	written, err := Export(ctx, nil, ds, "me/movies", dir, "movies.zip", DataPackageFormat, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != "movies.zip" {
+		t.Errorf("expected written filename movies.zip, got %q", written)
+	}
+	if _, err := Export(ctx, nil, ds, "me/movies", dir, "movies.json", DataPackageFormat, false); err == nil {
+		t.Error("expected exporting a data package to a non-zip file to fail")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, written))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dss, err := UnzipDataPackageBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dss) != 1 {
+		t.Fatalf("expected 1 dataset, got %d", len(dss))
+	}
+	got := dss[0]
+	if got.Name != "movies" {
+		t.Errorf("expected name to be movies. got: %q", got.Name)
+	}
+	if diff := cmp.Diff(meta, got.Meta, cmp.AllowUnexported(dataset.Meta{})); diff != "" {
+		t.Errorf("meta mismatch (-want +got):\n%s", diff)
+	}
+	if got.Structure.Format != "csv" {
+		t.Errorf("expected csv format. got: %q", got.Structure.Format)
+	}
+	if diff := cmp.Diff(schema, got.Structure.Schema); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+	body, err := ioutil.ReadAll(got.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody := "movie,year,released\nup,2009,2009-05-29\nthe incredibles,2004,2004-11-05\n"
+	if diff := cmp.Diff(expectBody, string(body)); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	if _, err := zw.Create("dataset.json"); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	if _, err := UnzipDataPackageBytes(buf.Bytes()); err != ErrNotDataPackage {
+		t.Errorf("expected a zip without a descriptor to return ErrNotDataPackage. got: %v", err)
+	}
+}
+
+func TestReadDataPackageFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "read_datapackage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pkg := map[string]interface{}{
+		"name":     "world",
+		"title":    "world reference data",
+		"licenses": []interface{}{map[string]interface{}{"name": "ODC-PDDL-1.0"}},
+		"contributors": []interface{}{
+			map[string]interface{}{"title": "Jane Doe", "email": "jane@example.com"},
+		},
+		"resources": []interface{}{
+			map[string]interface{}{
+				"name": "countries",
+				"path": "data/countries.tsv",
+				"schema": map[string]interface{}{
+					"fields": []interface{}{
+						map[string]interface{}{"name": "code", "type": "string"},
+						map[string]interface{}{"name": "founded", "type": "year"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name":        "Currency-Codes",
+				"description": "iso currency codes",
+				"data": []interface{}{
+					map[string]interface{}{"code": "CAD", "minor_units": 2},
+					map[string]interface{}{"code": "JPY", "minor_units": 0},
+				},
+				"schema": map[string]interface{}{
+					"fields": []interface{}{
+						map[string]interface{}{"name": "code"},
+						map[string]interface{}{"name": "minor_units", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "data", "countries.tsv"), []byte("code\tfounded\nCA\t1867\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	descPath := filepath.Join(dir, DataPackageFilename)
+	if err := ioutil.WriteFile(descPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dss, err := ReadDataPackageFile(descPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dss) != 2 {
+		t.Fatalf("expected 2 datasets, got %d", len(dss))
+	}
+
+	countries := dss[0]
+	if countries.Name != "countries" {
+		t.Errorf("expected name countries, got %q", countries.Name)
+	}
+	if countries.BodyPath != filepath.Join(dir, "data", "countries.tsv") {
+		t.Errorf("expected body path to be the resource file. got: %q", countries.BodyPath)
+	}
+	expectConfig := map[string]interface{}{"headerRow": true, "lazyQuotes": true, "separator": "\t"}
+	if diff := cmp.Diff(expectConfig, countries.Structure.FormatConfig); diff != "" {
+		t.Errorf("format config mismatch (-want +got):\n%s", diff)
+	}
+	expectMeta := &dataset.Meta{
+		Title:        "world reference data",
+		License:      &dataset.License{Type: "ODC-PDDL-1.0"},
+		Contributors: []*dataset.User{{Fullname: "Jane Doe", Email: "jane@example.com"}},
+	}
+	if diff := cmp.Diff(expectMeta, countries.Meta, cmp.AllowUnexported(dataset.Meta{})); diff != "" {
+		t.Errorf("meta mismatch (-want +got):\n%s", diff)
+	}
+
+	currencies := dss[1]
+	if currencies.Name != "currency-codes" {
+		t.Errorf("expected name currency-codes, got %q", currencies.Name)
+	}
+	if currencies.Meta.Description != "iso currency codes" {
+		t.Errorf("expected resource description to override package description. got: %q", currencies.Meta.Description)
+	}
+	expectSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "code", "type": "string"},
+				map[string]interface{}{"title": "minor_units", "type": "integer"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectSchema, currencies.Structure.Schema); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+	body, err := ioutil.ReadAll(currencies.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// inline objects are arranged as rows in schema field order
+	if expect := `[["CAD",2],["JPY",0]]`; string(body) != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, body)
+	}
+}
+
+func TestReadDataPackageBadResources(t *testing.T) {
+	open := func(p string) (string, []byte, error) { return p, []byte("a\n1\n"), nil }
+	bad := map[string]string{
+		"no resources":     `{"resources":[]}`,
+		"absolute path":    `{"resources":[{"name":"a","path":"/etc/passwd"}]}`,
+		"parent path":      `{"resources":[{"name":"a","path":"data/../../secret.csv"}]}`,
+		"multipart path":   `{"resources":[{"name":"a","path":["a.csv","b.csv"]}]}`,
+		"no path or data":  `{"resources":[{"name":"a"}]}`,
+		"unknown format":   `{"resources":[{"name":"a","path":"a.xlsx"}]}`,
+		"non-array data":   `{"resources":[{"name":"a","data":{"a":1}}]}`,
+		"invalid document": `{"resources":`,
+	}
+	for desc, descriptor := range bad {
+		if _, err := readDataPackage([]byte(descriptor), open); err == nil {
+			t.Errorf("%s: expected error, got nil", desc)
+		}
+	}
+}
@@ -2,13 +2,18 @@ package base
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"strings"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/columnar"
+	"github.com/qri-io/qri/base/sqlite"
 )
 
 // ErrNoBodyToInline is an error returned when a dataset has no body for inlining
@@ -45,6 +50,147 @@ func ReadBody(ds *dataset.Dataset, format dataset.DataFormat, fcfg dataset.Forma
 	return data, nil
 }
 
+// ReadColumnarBody grabs some or all of a dataset's body, writing an output in
+// a column-oriented format like parquet. The dataset must have a tabular schema
+func ReadColumnarBody(ds *dataset.Dataset, format string, limit, offset int, all bool) ([]byte, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("can't load body from a nil dataset")
+	}
+	file := ds.BodyFile()
+	if file == nil {
+		return nil, fmt.Errorf("no body file to read")
+	}
+
+	var rr dsio.EntryReader
+	rr, err := dsio.NewEntryReader(ds.Structure, file)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating data reader: %w", err)
+	}
+	if !all {
+		rr = &dsio.PagedReader{Reader: rr, Limit: limit, Offset: offset}
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := columnar.NewEntryWriter(format, ds.Structure, buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := dsio.Copy(rr, w); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("writing %s body: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ConvertColumnarBody replaces a body file in a column-oriented format like
+// parquet with a JSON body. qri doesn't store column-oriented formats, so
+// these bodies are converted before saving. Structure format is set to JSON,
+// and a tabular schema is inferred from the body's columns if no schema is set
+func ConvertColumnarBody(ds *dataset.Dataset) error {
+	file := ds.BodyFile()
+	if file == nil {
+		return nil
+	}
+	format := columnar.FilenameFormat(file.FileName())
+	if format == "" && ds.Structure != nil && columnar.IsFormat(ds.Structure.Format) {
+		format = ds.Structure.Format
+	}
+	if format == "" {
+		return nil
+	}
+
+	r, err := columnar.NewEntryReader(format, file)
+	if err != nil {
+		return err
+	}
+	if ds.Structure == nil {
+		ds.Structure = &dataset.Structure{}
+	}
+	ds.Structure.Format = dataset.JSONDataFormat.String()
+	ds.Structure.FormatConfig = nil
+	if ds.Structure.Schema == nil {
+		ds.Structure.Schema = r.Structure().Schema
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := dsio.NewEntryWriter(ds.Structure, buf)
+	if err != nil {
+		return err
+	}
+	if err := dsio.Copy(r, w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(file.FileName(), filepath.Ext(file.FileName())) + ".json"
+	ds.SetBodyFile(qfs.NewMemfileReader(name, buf))
+	return nil
+}
+
+// ReadSQLiteBody replaces a body path that points to a SQLite database file
+// with a JSON body read from a table or the result of a query. If neither is
+// given the database must have exactly one table. Structure format is set to
+// JSON, and a schema is mapped from the column types of the result if no
+// schema is set
+func ReadSQLiteBody(ctx context.Context, ds *dataset.Dataset, table, query string) error {
+	if ds.BodyPath == "" {
+		if table != "" || query != "" {
+			return fmt.Errorf("reading a sqlite table or query requires a body path to a sqlite database")
+		}
+		return nil
+	}
+	if !sqlite.IsDatabase(ds.BodyPath) && table == "" && query == "" {
+		return nil
+	}
+	if table != "" && query != "" {
+		return fmt.Errorf("can't read both a sqlite table and a query, use one or the other")
+	}
+	if qfs.PathKind(ds.BodyPath) != "local" {
+		return fmt.Errorf("sqlite databases must be read from the local filesystem")
+	}
+
+	if table == "" && query == "" {
+		tables, err := sqlite.Tables(ctx, ds.BodyPath)
+		if err != nil {
+			return err
+		}
+		if len(tables) != 1 {
+			return fmt.Errorf("database has %d tables, specify a table to read. tables: %s", len(tables), strings.Join(tables, ", "))
+		}
+		table = tables[0]
+	}
+
+	var (
+		file qfs.File
+		st   *dataset.Structure
+		err  error
+	)
+	if query != "" {
+		file, st, err = sqlite.ReadQuery(ctx, ds.BodyPath, query)
+	} else {
+		file, st, err = sqlite.ReadTable(ctx, ds.BodyPath, table)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ds.Structure == nil {
+		ds.Structure = st
+	} else {
+		ds.Structure.Format = st.Format
+		ds.Structure.FormatConfig = nil
+		if ds.Structure.Schema == nil {
+			ds.Structure.Schema = st.Schema
+		}
+	}
+	ds.SetBodyFile(file)
+	return InferStructure(ds)
+}
+
 // ReadEntries reads entries and returns them as a native go array or map
 func ReadEntries(reader dsio.EntryReader) (interface{}, error) {
 	obj := make(map[string]interface{})
@@ -3,13 +3,18 @@ package base
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/tabular"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/columnar"
+	"github.com/qri-io/qri/base/sqlite"
 )
 
 func TestReadBody(t *testing.T) {
@@ -113,3 +118,127 @@ func TestConvertBodyFormat(t *testing.T) {
 		t.Error(fmt.Errorf("converted body didn't match, got: %s", data))
 	}
 }
+
+func TestColumnarBodyRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	ref := addCitiesDataset(t, r)
+
+	ds, err := ReadDataset(ctx, r, ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = OpenDataset(ctx, r.Filesystem(), ds); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadColumnarBody(ds, columnar.ParquetFormat, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := &dataset.Dataset{}
+	imported.SetBodyFile(qfs.NewMemfileBytes("cities.parquet", data))
+	if err := ConvertColumnarBody(imported); err != nil {
+		t.Fatal(err)
+	}
+	if imported.Structure.Format != "json" {
+		t.Errorf("expected converted body format to be json. got: %q", imported.Structure.Format)
+	}
+	if name := imported.BodyFile().FileName(); name != "cities.json" {
+		t.Errorf("expected converted body filename to be cities.json. got: %q", name)
+	}
+	body, err := ioutil.ReadAll(imported.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, []byte(`[["new york",8500000,44.4,true]]`)) {
+		t.Errorf("converted body mismatch. got: %s", body)
+	}
+}
+
+func TestReadSQLiteBody(t *testing.T) {
+	if !sqlite.Supported {
+		t.Skip("sqlite requires cgo")
+	}
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "read_sqlite_body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "reference.sqlite")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`CREATE TABLE cities (city TEXT, pop INTEGER, avg_age REAL, in_usa BOOLEAN)`,
+		`INSERT INTO cities VALUES ('toronto', 40000000, 55.5, 0), ('new york', 8500000, 44.4, 1)`,
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	// a database with one table doesn't need a table name
+	ds := &dataset.Dataset{BodyPath: dbPath}
+	if err := ReadSQLiteBody(ctx, ds, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if name := ds.BodyFile().FileName(); name != "cities.json" {
+		t.Errorf("expected body filename to be cities.json. got: %q", name)
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cols.Titles(); fmt.Sprintf("%v", got) != "[city pop avg_age in_usa]" {
+		t.Errorf("unexpected column titles: %v", got)
+	}
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[["toronto",40000000,55.5,false],["new york",8500000,44.4,true]]`; string(body) != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, body)
+	}
+
+	// an existing structure keeps its schema, format is always json
+	ds = &dataset.Dataset{
+		BodyPath:  dbPath,
+		Structure: &dataset.Structure{Format: "csv", Schema: dataset.BaseSchemaArray},
+	}
+	if err := ReadSQLiteBody(ctx, ds, "", "SELECT city FROM cities WHERE in_usa"); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Structure.Format != "json" {
+		t.Errorf("expected format to be json. got: %q", ds.Structure.Format)
+	}
+	if ds.Structure.Schema["type"] != "array" || ds.Structure.Schema["items"] != nil {
+		t.Errorf("expected existing schema to be kept. got: %v", ds.Structure.Schema)
+	}
+
+	bad := []struct {
+		bodyPath, table, query string
+	}{
+		{"", "cities", ""},
+		{dbPath, "cities", "SELECT * FROM cities"},
+		{dbPath, "towns", ""},
+		{"https://example.com/reference.sqlite", "cities", ""},
+	}
+	for _, c := range bad {
+		ds := &dataset.Dataset{BodyPath: c.bodyPath}
+		if err := ReadSQLiteBody(ctx, ds, c.table, c.query); err == nil {
+			t.Errorf("expected reading body %q table %q query %q to fail", c.bodyPath, c.table, c.query)
+		}
+	}
+
+	// non-sqlite bodies are left alone
+	ds = &dataset.Dataset{BodyPath: "body.csv"}
+	if err := ReadSQLiteBody(ctx, ds, "", ""); err != nil || ds.BodyFile() != nil {
+		t.Errorf("expected non-sqlite body path to be ignored. err: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/checks"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+)
+
+// CheckReferenceLoader creates a loader for datasets named by reference
+// checks. Referenced datasets must be in the local repo. References without
+// a path use the latest version
+func CheckReferenceLoader(r repo.Repo) checks.ReferenceLoader {
+	return func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		ref, err := dsref.Parse(refstr)
+		if err != nil {
+			return nil, err
+		}
+		if ref.Username == "me" {
+			ref.Username = r.Profiles().Owner().Peername
+		}
+		if ref.Path == "" {
+			if _, err = r.ResolveRef(ctx, &ref); err != nil {
+				return nil, err
+			}
+		}
+		if ref.Path == "" {
+			return nil, fmt.Errorf("%q has no versions", refstr)
+		}
+
+		ds, err := dsfs.LoadDataset(ctx, r.Filesystem(), ref.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err = ds.OpenBodyFile(ctx, r.Filesystem()); err != nil {
+			return nil, fmt.Errorf("opening body file: %w", err)
+		}
+		return ds, nil
+	}
+}
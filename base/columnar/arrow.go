@@ -0,0 +1,220 @@
+package columnar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// arrowBatchSize is the number of rows buffered before writing a record batch
+const arrowBatchSize = 8192
+
+// arrowWriter writes rows to an Arrow IPC file in batches of records
+type arrowWriter struct {
+	st   *dataset.Structure
+	cols []column
+	bldr *array.RecordBuilder
+	fw   *ipc.FileWriter
+	rows int
+}
+
+var _ dsio.EntryWriter = (*arrowWriter)(nil)
+
+func newArrowWriter(st *dataset.Structure, cols []column, w io.Writer) (*arrowWriter, error) {
+	sch := arrowSchema(cols)
+	fw, err := ipc.NewFileWriter(&offsetWriter{w: w}, ipc.WithSchema(sch))
+	if err != nil {
+		return nil, err
+	}
+	return &arrowWriter{
+		st:   st,
+		cols: cols,
+		bldr: array.NewRecordBuilder(memory.DefaultAllocator, sch),
+		fw:   fw,
+	}, nil
+}
+
+// arrowSchema maps columns to nullable arrow fields. integer columns are
+// int64, number columns are float64, boolean columns are bool & everything
+// else is utf8
+func arrowSchema(cols []column) *arrow.Schema {
+	fields := make([]arrow.Field, len(cols))
+	for i, col := range cols {
+		fields[i] = arrow.Field{Name: col.name, Nullable: true}
+		switch col.typ {
+		case typeInteger:
+			fields[i].Type = arrow.PrimitiveTypes.Int64
+		case typeNumber:
+			fields[i].Type = arrow.PrimitiveTypes.Float64
+		case typeBoolean:
+			fields[i].Type = arrow.FixedWidthTypes.Boolean
+		default:
+			fields[i].Type = arrow.BinaryTypes.String
+		}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// Structure gives the structure being written
+func (w *arrowWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteEntry adds a row to the current batch, writing the batch when full
+func (w *arrowWriter) WriteEntry(ent dsio.Entry) error {
+	row, err := rowValues(w.cols, ent)
+	if err != nil {
+		return err
+	}
+	for i, v := range row {
+		fb := w.bldr.Field(i)
+		if v == nil {
+			fb.AppendNull()
+			continue
+		}
+		switch b := fb.(type) {
+		case *array.Int64Builder:
+			b.Append(v.(int64))
+		case *array.Float64Builder:
+			b.Append(v.(float64))
+		case *array.BooleanBuilder:
+			b.Append(v.(bool))
+		case *array.StringBuilder:
+			b.Append(v.(string))
+		}
+	}
+	w.rows++
+	if w.rows == arrowBatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *arrowWriter) flush() error {
+	rec := w.bldr.NewRecord()
+	defer rec.Release()
+	w.rows = 0
+	return w.fw.Write(rec)
+}
+
+// Close writes any buffered rows & the file footer
+func (w *arrowWriter) Close() error {
+	defer w.bldr.Release()
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	return w.fw.Close()
+}
+
+// offsetWriter adapts a writer to the io.WriteSeeker the arrow file writer
+// requires. The arrow writer only seeks to find the current offset
+type offsetWriter struct {
+	w   io.Writer
+	off int64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.Write(p)
+	ow.off += int64(n)
+	return n, err
+}
+
+func (ow *offsetWriter) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, fmt.Errorf("arrow writer can only seek to the current offset")
+	}
+	return ow.off, nil
+}
+
+// readArrow reads all rows of an Arrow IPC file. Only scalar numeric, boolean
+// & string columns are supported
+func readArrow(data []byte) ([]column, [][]interface{}, error) {
+	fr, err := ipc.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading arrow: %w", err)
+	}
+	defer fr.Close()
+
+	fields := fr.Schema().Fields()
+	cols := make([]column, len(fields))
+	for i, f := range fields {
+		cols[i] = column{name: f.Name}
+		switch f.Type.ID() {
+		case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+			arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+			cols[i].typ = typeInteger
+		case arrow.FLOAT32, arrow.FLOAT64:
+			cols[i].typ = typeNumber
+		case arrow.BOOL:
+			cols[i].typ = typeBoolean
+		case arrow.STRING, arrow.BINARY:
+			cols[i].typ = typeString
+		default:
+			return nil, nil, fmt.Errorf("reading arrow: column %q has unsupported type %s", f.Name, f.Type)
+		}
+	}
+
+	rows := [][]interface{}{}
+	for r := 0; r < fr.NumRecords(); r++ {
+		rec, err := fr.Record(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading arrow: %w", err)
+		}
+		start := len(rows)
+		for i := int64(0); i < rec.NumRows(); i++ {
+			rows = append(rows, make([]interface{}, len(cols)))
+		}
+		for j, arr := range rec.Columns() {
+			for i := 0; i < arr.Len(); i++ {
+				if arr.IsNull(i) {
+					continue
+				}
+				rows[start+i][j] = arrowValue(arr, i)
+			}
+		}
+	}
+	return cols, rows, nil
+}
+
+// arrowValue returns the value at index i of an array as an int64, float64,
+// bool or string
+func arrowValue(arr array.Interface, i int) interface{} {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return int64(a.Value(i))
+	case *array.Int16:
+		return int64(a.Value(i))
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return int64(a.Value(i))
+	case *array.Uint16:
+		return int64(a.Value(i))
+	case *array.Uint32:
+		return int64(a.Value(i))
+	case *array.Uint64:
+		return int64(a.Value(i))
+	case *array.Float32:
+		return float64(a.Value(i))
+	case *array.Float64:
+		return a.Value(i)
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.Binary:
+		return string(a.Value(i))
+	}
+	return nil
+}
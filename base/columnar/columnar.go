@@ -0,0 +1,281 @@
+// Package columnar reads & writes tabular dataset bodies in column-oriented
+// formats: Apache Parquet & the Arrow IPC file format. Neither format is a
+// qri body format, so column-oriented bodies are converted to JSON on import,
+// and exported from stored bodies using the columns of a tabular schema
+package columnar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+const (
+	// ParquetFormat is the format name & file extension of Apache Parquet files
+	ParquetFormat = "parquet"
+	// ArrowFormat is the format name & file extension of Arrow IPC files
+	ArrowFormat = "arrow"
+)
+
+// column types map to a single JSON schema type
+const (
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+)
+
+// ErrUnsupportedFormat indicates a format isn't column-oriented
+var ErrUnsupportedFormat = fmt.Errorf("unsupported columnar format")
+
+// IsFormat returns true if format names a column-oriented format
+func IsFormat(format string) bool {
+	return format == ParquetFormat || format == ArrowFormat
+}
+
+// FilenameFormat returns the column-oriented format of a filename based on
+// its extension, or an empty string if the extension isn't columnar
+func FilenameFormat(filename string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if IsFormat(ext) {
+		return ext
+	}
+	return ""
+}
+
+// NewEntryWriter allocates an EntryWriter that writes a tabular body in a
+// column-oriented format. The structure must have a tabular schema, column
+// types are derived from the schema
+func NewEntryWriter(format string, st *dataset.Structure, w io.Writer) (dsio.EntryWriter, error) {
+	cols, err := schemaColumns(st)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ParquetFormat:
+		return newParquetWriter(st, cols, w)
+	case ArrowFormat:
+		return newArrowWriter(st, cols, w)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// NewEntryReader allocates an EntryReader for a column-oriented body. Both
+// formats require random access, so the entire body is read into memory.
+// The reader's structure describes the body with a tabular schema
+func NewEntryReader(format string, r io.Reader) (dsio.EntryReader, error) {
+	if !IsFormat(format) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cols []column
+		rows [][]interface{}
+	)
+	if format == ParquetFormat {
+		cols, rows, err = readParquet(data)
+	} else {
+		cols, rows, err = readArrow(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tableReader{
+		st:   &dataset.Structure{Format: format, Schema: tabularSchema(cols)},
+		rows: rows,
+	}, nil
+}
+
+// column is a name & type pair for a single column of a table. columns are
+// always nullable
+type column struct {
+	name string
+	typ  string
+}
+
+// schemaColumns derives columns from the tabular schema of a structure.
+// columns that accept more than one non-null type, or types like "object" &
+// "array" that don't map to a scalar column are encoded as strings
+func schemaColumns(st *dataset.Structure) ([]column, error) {
+	if st == nil || st.Schema == nil {
+		return nil, fmt.Errorf("a tabular schema is required to write columnar data")
+	}
+	tcols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]column, len(tcols))
+	for i, tc := range tcols {
+		cols[i] = column{name: tc.Title, typ: typeString}
+		if cols[i].name == "" {
+			cols[i].name = fmt.Sprintf("col_%d", i)
+		}
+		if tc.Type == nil {
+			continue
+		}
+		types := make([]string, 0, len(*tc.Type))
+		for _, t := range *tc.Type {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 1 {
+			switch types[0] {
+			case typeInteger, typeNumber, typeBoolean:
+				cols[i].typ = types[0]
+			}
+		}
+	}
+	return cols, nil
+}
+
+// tabularSchema creates an array-of-arrays JSON schema from a set of columns
+func tabularSchema(cols []column) map[string]interface{} {
+	items := make([]interface{}, len(cols))
+	for i, col := range cols {
+		items[i] = map[string]interface{}{
+			"title": col.name,
+			"type":  col.typ,
+		}
+	}
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	}
+}
+
+// rowValues arranges the value of an entry into a row of column values,
+// coercing each value to the type of its column. Missing trailing values are
+// null
+func rowValues(cols []column, ent dsio.Entry) ([]interface{}, error) {
+	vals, ok := ent.Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("entry %d: expected an array row, got %T", ent.Index, ent.Value)
+	}
+
+	row := make([]interface{}, len(cols))
+	var err error
+	for i, col := range cols {
+		if i >= len(vals) {
+			continue
+		}
+		if row[i], err = coerce(col.typ, vals[i]); err != nil {
+			return nil, fmt.Errorf("entry %d, column %q: %w", ent.Index, col.name, err)
+		}
+	}
+	return row, nil
+}
+
+// coerce converts a value to the go type of a column type: int64, float64,
+// bool or string. Null values & empty strings in non-string columns coerce
+// to nil
+func coerce(typ string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if s, ok := v.(string); ok && s == "" && typ != typeString {
+		return nil, nil
+	}
+
+	switch typ {
+	case typeInteger:
+		switch x := v.(type) {
+		case int:
+			return int64(x), nil
+		case int32:
+			return int64(x), nil
+		case int64:
+			return x, nil
+		case float64:
+			if x == math.Trunc(x) && math.Abs(x) <= math.MaxInt64 {
+				return int64(x), nil
+			}
+		case json.Number:
+			return x.Int64()
+		case string:
+			return strconv.ParseInt(x, 10, 64)
+		}
+	case typeNumber:
+		switch x := v.(type) {
+		case int:
+			return float64(x), nil
+		case int32:
+			return float64(x), nil
+		case int64:
+			return float64(x), nil
+		case float32:
+			return float64(x), nil
+		case float64:
+			return x, nil
+		case json.Number:
+			return x.Float64()
+		case string:
+			return strconv.ParseFloat(x, 64)
+		}
+	case typeBoolean:
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case string:
+			return strconv.ParseBool(x)
+		}
+	default:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(x)
+			return string(data), err
+		default:
+			return fmt.Sprintf("%v", x), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %v (%T) to %s", v, v, typ)
+}
+
+// tableReader is an EntryReader for a table that has been read into memory
+type tableReader struct {
+	st   *dataset.Structure
+	rows [][]interface{}
+	i    int
+}
+
+var _ dsio.EntryReader = (*tableReader)(nil)
+
+// Structure gives the structure being read
+func (r *tableReader) Structure() *dataset.Structure {
+	return r.st
+}
+
+// ReadEntry reads one row of the table
+func (r *tableReader) ReadEntry() (dsio.Entry, error) {
+	if r.i >= len(r.rows) {
+		return dsio.Entry{}, io.EOF
+	}
+	ent := dsio.Entry{Index: r.i, Value: r.rows[r.i]}
+	r.i++
+	return ent, nil
+}
+
+// Close finalizes the reader
+func (r *tableReader) Close() error {
+	return nil
+}
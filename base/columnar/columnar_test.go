@@ -0,0 +1,172 @@
+package columnar
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+const citiesCSV = `city,pop,avg_age,in_usa
+toronto,40000000,55.5,false
+new york,,44.4,true
+chicago,300000,,
+`
+
+func citiesStructure() *dataset.Structure {
+	return &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "city", "type": "string"},
+					map[string]interface{}{"title": "pop", "type": "integer"},
+					map[string]interface{}{"title": "avg_age", "type": "number"},
+					map[string]interface{}{"title": "in_usa", "type": "boolean"},
+				},
+			},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	expectSchema := tabularSchema([]column{
+		{name: "city", typ: typeString},
+		{name: "pop", typ: typeInteger},
+		{name: "avg_age", typ: typeNumber},
+		{name: "in_usa", typ: typeBoolean},
+	})
+	expectRows := []interface{}{
+		[]interface{}{"toronto", int64(40000000), 55.5, false},
+		[]interface{}{"new york", nil, 44.4, true},
+		[]interface{}{"chicago", int64(300000), nil, nil},
+	}
+
+	for _, format := range []string{ParquetFormat, ArrowFormat} {
+		t.Run(format, func(t *testing.T) {
+			st := citiesStructure()
+			r, err := dsio.NewEntryReader(st, strings.NewReader(citiesCSV))
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			w, err := NewEntryWriter(format, st, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := dsio.Copy(r, w); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			cr, err := NewEntryReader(format, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(expectSchema, cr.Structure().Schema); diff != "" {
+				t.Errorf("schema mismatch (-want +got):\n%s", diff)
+			}
+			rows, err := dsio.ReadAllArray(cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(expectRows, rows); diff != "" {
+				t.Errorf("rows mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteNonScalarColumns(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string"},
+					map[string]interface{}{"title": "tags", "type": "array"},
+					map[string]interface{}{"title": "count", "type": []interface{}{"integer", "string"}},
+				},
+			},
+		},
+	}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(`[["a",["x","y"],1],["b",null,"many"],["c"]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewEntryWriter(ArrowFormat, st, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dsio.Copy(r, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := NewEntryReader(ArrowFormat, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := dsio.ReadAllArray(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// columns of non-scalar & mixed types are written as strings, arrays &
+	// objects as JSON
+	expect := []interface{}{
+		[]interface{}{"a", `["x","y"]`, "1"},
+		[]interface{}{"b", nil, "many"},
+		[]interface{}{"c", nil, nil},
+	}
+	if diff := cmp.Diff(expect, rows); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	if _, err := NewEntryWriter(ParquetFormat, &dataset.Structure{Format: "json"}, &bytes.Buffer{}); err == nil {
+		t.Error("expected writing without a schema to fail")
+	}
+	if _, err := NewEntryWriter("orc", citiesStructure(), &bytes.Buffer{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected an unknown format to return ErrUnsupportedFormat. got: %v", err)
+	}
+
+	st := citiesStructure()
+	w, err := NewEntryWriter(ParquetFormat, st, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.WriteEntry(dsio.Entry{Value: []interface{}{"x", "not a number", 1.0, true}})
+	if err == nil || !strings.Contains(err.Error(), `column "pop"`) {
+		t.Errorf("expected a value that doesn't match its column type to fail. got: %v", err)
+	}
+}
+
+func TestFilenameFormat(t *testing.T) {
+	cases := map[string]string{
+		"body.parquet":     ParquetFormat,
+		"/a/b/DATA.ARROW":  ArrowFormat,
+		"body.csv":         "",
+		"parquet":          "",
+		"body.parquet.zip": "",
+	}
+	for filename, expect := range cases {
+		if got := FilenameFormat(filename); got != expect {
+			t.Errorf("%s: expected %q, got %q", filename, expect, got)
+		}
+	}
+}
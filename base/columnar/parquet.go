@@ -0,0 +1,155 @@
+package columnar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetParallelism is the number of goroutines parquet-go uses to encode &
+// decode pages
+const parquetParallelism = 4
+
+// parquetWriter writes rows to a parquet file as a single flat group of
+// optional columns
+type parquetWriter struct {
+	st   *dataset.Structure
+	cols []column
+	pw   *writer.CSVWriter
+}
+
+var _ dsio.EntryWriter = (*parquetWriter)(nil)
+
+func newParquetWriter(st *dataset.Structure, cols []column, w io.Writer) (*parquetWriter, error) {
+	md, err := parquetMetadata(cols)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := writer.NewCSVWriterFromWriter(md, w, parquetParallelism)
+	if err != nil {
+		return nil, err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetWriter{st: st, cols: cols, pw: pw}, nil
+}
+
+// parquetMetadata maps columns to parquet-go schema tags for optional
+// columns. integer columns are INT64, number columns are DOUBLE, boolean
+// columns are BOOLEAN & everything else is UTF8 annotated BYTE_ARRAY
+func parquetMetadata(cols []column) ([]string, error) {
+	md := make([]string, len(cols))
+	seen := map[string]string{}
+	for i, col := range cols {
+		// schema tags are comma-delimited & whitespace-trimmed
+		name := strings.TrimSpace(strings.Replace(col.name, ",", "_", -1))
+		if name == "" {
+			name = fmt.Sprintf("col_%d", i)
+		}
+		// parquet-go requires column names to be unique as go identifiers
+		inName := common.StringToVariableName(name)
+		if prev, ok := seen[inName]; ok {
+			return nil, fmt.Errorf("columns %q and %q can't both be written to parquet, rename one of them", prev, col.name)
+		}
+		seen[inName] = col.name
+
+		switch col.typ {
+		case typeInteger:
+			md[i] = fmt.Sprintf("name=%s, type=INT64, repetitiontype=OPTIONAL", name)
+		case typeNumber:
+			md[i] = fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", name)
+		case typeBoolean:
+			md[i] = fmt.Sprintf("name=%s, type=BOOLEAN, repetitiontype=OPTIONAL", name)
+		default:
+			md[i] = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", name)
+		}
+	}
+	return md, nil
+}
+
+// Structure gives the structure being written
+func (w *parquetWriter) Structure() *dataset.Structure {
+	return w.st
+}
+
+// WriteEntry writes one row of the table
+func (w *parquetWriter) WriteEntry(ent dsio.Entry) error {
+	row, err := rowValues(w.cols, ent)
+	if err != nil {
+		return err
+	}
+	return w.pw.Write(row)
+}
+
+// Close writes the parquet footer
+func (w *parquetWriter) Close() error {
+	return w.pw.WriteStop()
+}
+
+// readParquet reads all rows of a parquet file. Only flat schemas of
+// scalar columns are supported
+func readParquet(data []byte) ([]column, [][]interface{}, error) {
+	pf, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr, err := reader.NewParquetColumnReader(pf, parquetParallelism)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading parquet: %w", err)
+	}
+	defer pr.ReadStop()
+
+	elems := pr.SchemaHandler.SchemaElements
+	cols := make([]column, 0, len(elems))
+	for i, el := range elems {
+		if i == 0 {
+			// root
+			continue
+		}
+		if el.GetNumChildren() > 0 || el.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return nil, nil, fmt.Errorf("reading parquet: column %q is nested. only flat parquet schemas are supported", pr.SchemaHandler.Infos[i].ExName)
+		}
+		col := column{name: pr.SchemaHandler.Infos[i].ExName, typ: typeString}
+		switch el.GetType() {
+		case parquet.Type_BOOLEAN:
+			col.typ = typeBoolean
+		case parquet.Type_INT32, parquet.Type_INT64:
+			col.typ = typeInteger
+		case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+			col.typ = typeNumber
+		}
+		cols = append(cols, col)
+	}
+
+	numRows := pr.GetNumRows()
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(cols))
+	}
+	if numRows == 0 {
+		return cols, rows, nil
+	}
+
+	for j, col := range cols {
+		vals, _, _, err := pr.ReadColumnByIndex(int64(j), numRows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading parquet column %q: %w", col.name, err)
+		}
+		if int64(len(vals)) != numRows {
+			return nil, nil, fmt.Errorf("reading parquet column %q: expected %d values, got %d", col.name, numRows, len(vals))
+		}
+		for i, v := range vals {
+			if rows[i][j], err = coerce(col.typ, v); err != nil {
+				return nil, nil, fmt.Errorf("reading parquet column %q: %w", col.name, err)
+			}
+		}
+	}
+	return cols, rows, nil
+}
@@ -0,0 +1,59 @@
+package dsfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/checks"
+)
+
+// LoadCheckResults loads the results of data quality checks recorded when a
+// dataset version was saved. Versions saved without checks have no results
+// file, loading results for them returns an error
+func LoadCheckResults(ctx context.Context, fs qfs.Filesystem, path string) (checks.Results, error) {
+	data, err := fileBytes(fs.Get(ctx, PackageFilepath(fs, path, PackageFileChecks)))
+	if err != nil {
+		log.Debug(err.Error())
+		return nil, fmt.Errorf("loading check results file: %w", err)
+	}
+	res := checks.Results{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("reading check results: %w", err)
+	}
+	return res, nil
+}
+
+type checkResultsFile interface {
+	// HasChecks is true when the file evaluates data quality checks
+	HasChecks() bool
+	// CheckResults gives the outcome of each check once the body has been read
+	CheckResults() checks.Results
+}
+
+func addChecksFile(ds *dataset.Dataset, wfs *writeFiles) error {
+	if wfs.structure == nil {
+		return nil
+	}
+
+	// checks are evaluated while body rows are processed
+	checksFile, ok := wfs.body.(checkResultsFile)
+	if !ok || !checksFile.HasChecks() {
+		return nil
+	}
+
+	hook := func(ctx context.Context, f qfs.File, added map[string]string) (io.Reader, error) {
+		data, err := json.Marshal(checksFile.CheckResults())
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	}
+
+	wfs.checks = qfs.NewWriteHookFile(emptyFile(PackageFileChecks.Filename()), hook, wfs.structure.FullPath())
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/qri-io/dataset/dsstats"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/checks"
 	"github.com/qri-io/qri/event"
 )
 
@@ -28,6 +29,10 @@ type computeFieldsFile struct {
 
 	// body statistics accumulator
 	acc *dsstats.Accumulator
+	// data quality checks declared in the meta component, nil if there
+	// are no checks
+	checks       *checks.Runner
+	checkResults checks.Results
 
 	// buffer of entries for diffing small datasets. will be set to nil if
 	// body reads more than BodySizeSmallEnoughToDiff bytes
@@ -46,6 +51,7 @@ type computeFieldsFile struct {
 var (
 	_ doneProcessingFile = (*computeFieldsFile)(nil)
 	_ statsComponentFile = (*computeFieldsFile)(nil)
+	_ checkResultsFile   = (*computeFieldsFile)(nil)
 )
 
 func newComputeFieldsFile(
@@ -75,6 +81,17 @@ func newComputeFieldsFile(
 		bf = bfPrev
 	}
 
+	declared, err := checks.FromMeta(ds.Meta)
+	if err != nil {
+		return nil, err
+	}
+	var runner *checks.Runner
+	if len(declared) > 0 {
+		if runner, err = checks.NewRunner(ctx, declared, ds.Structure, sw.LoadCheckReference); err != nil {
+			return nil, err
+		}
+	}
+
 	pr, pw := io.Pipe()
 	tr := io.TeeReader(bf, pw)
 
@@ -86,6 +103,7 @@ func newComputeFieldsFile(
 		ds:         ds,
 		prev:       prev,
 		bodyAct:    BodyDefault,
+		checks:     runner,
 		pipeReader: pr,
 		pipeWriter: pw,
 		teeReader:  dsio.NewTrackedReader(tr),
@@ -155,6 +173,18 @@ func (cff *computeFieldsFile) StatsComponent() (*dataset.Stats, error) {
 	}, nil
 }
 
+// HasChecks is true if the dataset declares data quality checks
+func (cff *computeFieldsFile) HasChecks() bool {
+	return cff.checks != nil
+}
+
+// CheckResults gives the outcome of data quality checks
+func (cff *computeFieldsFile) CheckResults() checks.Results {
+	cff.Lock()
+	defer cff.Unlock()
+	return cff.checkResults
+}
+
 func (cff *computeFieldsFile) handleRows(ctx context.Context, pub event.Publisher) {
 	var (
 		batchBuf      *dsio.EntryBuffer
@@ -234,6 +264,11 @@ func (cff *computeFieldsFile) handleRows(ctx context.Context, pub event.Publishe
 			if err := cff.acc.WriteEntry(ent); err != nil {
 				return err
 			}
+			if cff.checks != nil {
+				if err := cff.checks.WriteEntry(ent); err != nil {
+					return err
+				}
+			}
 
 			if i%batchSize == 0 && i != 0 {
 				numValErrs, flushErr := cff.flushBatch(ctx, batchBuf, st, jsch)
@@ -296,6 +331,18 @@ func (cff *computeFieldsFile) handleRows(ctx context.Context, pub event.Publishe
 		// to manually close the accumulator to finalize results before write
 		cff.acc.Close()
 
+		if cff.checks != nil {
+			cff.checkResults = cff.checks.Results()
+			for _, w := range cff.checkResults.Warnings() {
+				log.Infof("check warning: %s", w.Message)
+			}
+			if err := cff.checkResults.Err(); err != nil {
+				log.Debugf("checks failed: %s", err)
+				cff.done <- err
+				return
+			}
+		}
+
 		// If the body exists and is small enough, deserialize it and assign it
 		if cff.diffMessageBuf != nil {
 			if err := cff.diffMessageBuf.Close(); err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/validate"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/checks"
 	"github.com/qri-io/qri/event"
 )
 
@@ -134,6 +135,9 @@ type SaveSwitches struct {
 	FileHint string
 	// Drop is a string of components to remove before saving
 	Drop string
+	// LoadCheckReference loads datasets named by reference checks. saves
+	// with reference checks fail if LoadCheckReference is nil
+	LoadCheckReference checks.ReferenceLoader
 }
 
 // CreateDataset places a dataset into the store.
@@ -284,6 +288,7 @@ func WriteDataset(
 		addTransformFile,
 		structureFileAddFunc(destination),
 		addStatsFile,
+		addChecksFile,
 		addReadmeFile,
 		vizFilesAddFunc(destination, sw),
 		commitFileAddFunc(pk, pub),
@@ -314,6 +319,7 @@ type writeFiles struct {
 	transform   qfs.File // requires transformScript if it exists
 	structure   qfs.File // requires body if it exists
 	stats       qfs.File // requires body, structure if they exist
+	checks      qfs.File // requires body, structure if they exist
 	vizRendered qfs.File // requires body, meta, transform, structure, stats, readme if they exist
 
 	commit  qfs.File // requires meta, transform, body, structure, stats, readme, vizScript, vizRendered if they exist
@@ -331,6 +337,7 @@ func (wfs *writeFiles) files() []qfs.File {
 		wfs.transform,
 		wfs.structure,
 		wfs.stats,
+		wfs.checks,
 		wfs.vizRendered,
 		wfs.commit,
 		wfs.dataset,
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -21,6 +22,7 @@ import (
 	"github.com/qri-io/dataset/validate"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/toqtype"
+	"github.com/qri-io/qri/checks"
 	testPeers "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/event"
 )
@@ -368,6 +370,50 @@ func TestDatasetSaveEvents(t *testing.T) {
 	}
 }
 
+func TestCreateDatasetChecks(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testPeers.GetTestPeerInfo(10).PrivKey
+
+	newDataset := func(level string) *dataset.Dataset {
+		md := &dataset.Meta{Title: "checked"}
+		md.Set(checks.MetaKey, []interface{}{
+			map[string]interface{}{"type": "unique", "column": "id", "level": level},
+		})
+		ds := &dataset.Dataset{
+			Commit:    &dataset.Commit{Title: "initial commit"},
+			Meta:      md,
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(`[{"id":1},{"id":2},{"id":2}]`)))
+		return ds
+	}
+
+	_, err := CreateDataset(ctx, fs, fs, event.NilBus, newDataset("error"), nil, privKey, SaveSwitches{})
+	if !errors.Is(err, checks.ErrCheckFailed) {
+		t.Errorf("expected failing error-level check to fail save with ErrCheckFailed, got: %v", err)
+	}
+
+	path, err := CreateDataset(ctx, fs, fs, event.NilBus, newDataset("warn"), nil, privKey, SaveSwitches{})
+	if err != nil {
+		t.Fatalf("expected failing warn-level check not to fail save, got: %s", err)
+	}
+	got, err := LoadDataset(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got.Commit.Message, "checks") {
+		t.Errorf("expected check results to be left out of the commit message, got: %q", got.Commit.Message)
+	}
+	results, err := LoadCheckResults(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Passed || len(results.Warnings()) != 1 {
+		t.Errorf("expected a single failed warn-level check result, got: %v", results)
+	}
+}
+
 // Test that if the body is too large, the commit message just assumes the body changed
 func TestCreateDatasetBodyTooLarge(t *testing.T) {
 	ctx := context.Background()
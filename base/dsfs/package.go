@@ -66,6 +66,9 @@ const (
 	PackageFileRenderedReadme
 	// PackageFileStats isolates the statistical metadata component
 	PackageFileStats
+	// PackageFileChecks records the results of data quality checks evaluated
+	// against the body
+	PackageFileChecks
 )
 
 // filenames maps PackageFile to their filename counterparts
@@ -86,6 +89,7 @@ var filenames = map[PackageFile]string{
 	PackageFileReadmeScript:      "readme.md",
 	PackageFileRenderedReadme:    "readme.html",
 	PackageFileStats:             "stats.json",
+	PackageFileChecks:            "checks.json",
 }
 
 // String implements the io.Stringer interface for PackageFile
@@ -0,0 +1,376 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qfs/qipfs"
+	"github.com/qri-io/qri/dscache"
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+const (
+	// FsckSignature marks logs with signatures that don't match their author
+	FsckSignature = "signature"
+	// FsckBlocks marks dataset versions with blocks missing from the repo
+	// filesystem
+	FsckBlocks = "blocks"
+	// FsckDscache marks dscache entries that disagree with the logbook
+	FsckDscache = "dscache"
+	// FsckRefstore marks refstore entries that disagree with the logbook
+	FsckRefstore = "refstore"
+)
+
+// FsckProblem is an inconsistency found in a repo
+type FsckProblem struct {
+	// Kind is the part of the repo the problem is in, one of FsckSignature,
+	// FsckBlocks, FsckDscache or FsckRefstore
+	Kind string `json:"kind"`
+	// Ref is the dataset or log the problem concerns
+	Ref string `json:"ref"`
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+func (p FsckProblem) String() string {
+	return fmt.Sprintf("%s\t%s\t%s", p.Kind, p.Ref, p.Message)
+}
+
+// Fsck checks a repo for inconsistencies: logbook signatures must match their
+// authors, the head of every dataset in the refstore must have all blocks in
+// the local filesystem, and the dscache & refstore must agree with the
+// logbook. The logbook is treated as the source of truth. Datasets with heads
+// that aren't stored locally, like datasets with logs fetched from a remote,
+// aren't expected in the dscache or refstore. If the dscache or refstore does
+// hold one of them, the head's blocks are missing
+func Fsck(ctx context.Context, r repo.Repo) ([]FsckProblem, error) {
+	problems := []FsckProblem{}
+
+	sigProblems, err := checkLogSignatures(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, sigProblems...)
+
+	refs, err := allRefstoreRefs(r)
+	if err != nil {
+		return nil, err
+	}
+
+	blockProblems, err := checkVersionBlocks(ctx, r, refs)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, blockProblems...)
+
+	expect, notLocal, err := logbookRefs(ctx, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := append([]reporef.DatasetRef{}, refs...)
+
+	if cache := r.Dscache(); !cache.IsEmpty() {
+		cached, err := cache.ListRefs()
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, cached...)
+		problems = append(problems, compareRefs(FsckDscache, expect, withoutKeys(cached, notLocal))...)
+	}
+
+	problems = append(problems, missingHeads(notLocal, refs, claimed)...)
+	problems = append(problems, compareRefs(FsckRefstore, expect, withoutKeys(refs, notLocal))...)
+
+	return problems, nil
+}
+
+// RepairRefs rebuilds the refstore & dscache from the logbook, keeping
+// working directory links of datasets that are in the logbook. Datasets with
+// heads that aren't stored locally are left as they are. The dscache is only
+// rebuilt if the repo uses one
+func RepairRefs(ctx context.Context, r repo.Repo) error {
+	existing, err := allRefstoreRefs(r)
+	if err != nil {
+		return err
+	}
+	expect, notLocal, err := logbookRefs(ctx, r, existing)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]reporef.DatasetRef{}
+	for _, ref := range expect {
+		keep[refKey(ref)] = ref
+	}
+	for _, ref := range withoutKeys(existing, notLocal) {
+		if _, ok := keep[refKey(ref)]; !ok {
+			if err := r.DeleteRef(ref); err != nil {
+				return fmt.Errorf("removing %s from refstore: %w", ref.AliasString(), err)
+			}
+		}
+	}
+	for _, ref := range expect {
+		ref.Dataset = nil
+		if err := r.PutRef(ref); err != nil {
+			return fmt.Errorf("adding %s to refstore: %w", ref.AliasString(), err)
+		}
+	}
+
+	if cache := r.Dscache(); !cache.IsEmpty() {
+		refs, err := allRefstoreRefs(r)
+		if err != nil {
+			return err
+		}
+		built, err := dscache.BuildDscacheFromLogbookAndProfilesAndDsref(ctx, refs, r.Profiles(), r.Logbook(), r.Filesystem())
+		if err != nil {
+			return err
+		}
+		return cache.Assign(built)
+	}
+	return nil
+}
+
+// checkLogSignatures verifies the signature of every signed log in the
+// logbook against the public key of the log's author. Logs written locally
+// aren't signed until they're sent to another peer
+func checkLogSignatures(ctx context.Context, r repo.Repo) ([]FsckProblem, error) {
+	logs, err := r.Logbook().ListAllLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []FsckProblem{}
+	for _, lg := range logs {
+		authorID := lg.FirstOpAuthorID()
+		var pro *profile.Profile
+		if id, err := profile.IDB58Decode(authorID); err == nil {
+			pro, _ = r.Profiles().GetProfile(id)
+		}
+		problems = append(problems, verifyLog(lg, pro, lg.Name())...)
+	}
+	return problems, nil
+}
+
+func verifyLog(lg *oplog.Log, author *profile.Profile, name string) []FsckProblem {
+	problems := []FsckProblem{}
+	if len(lg.Signature) > 0 {
+		if author == nil || author.PubKey == nil {
+			problems = append(problems, FsckProblem{
+				Kind:    FsckSignature,
+				Ref:     name,
+				Message: fmt.Sprintf("can't verify log %s, author %s is unknown", lg.ID(), lg.FirstOpAuthorID()),
+			})
+		} else if err := lg.Verify(author.PubKey); err != nil {
+			problems = append(problems, FsckProblem{
+				Kind:    FsckSignature,
+				Ref:     name,
+				Message: fmt.Sprintf("log %s: %s", lg.ID(), err),
+			})
+		}
+	}
+	for _, child := range lg.Logs {
+		problems = append(problems, verifyLog(child, author, fmt.Sprintf("%s/%s", name, child.Name()))...)
+	}
+	return problems
+}
+
+// checkVersionBlocks confirms the head of every dataset in the refstore is
+// stored in full on the local filesystem. Older versions aren't checked,
+// they're often left on the remote a dataset was pulled from
+func checkVersionBlocks(ctx context.Context, r repo.Repo, refs []reporef.DatasetRef) ([]FsckProblem, error) {
+	paths := map[string]bool{}
+	for _, ref := range refs {
+		if ref.Path != "" {
+			paths[ref.Path] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	fst, isIPFS := r.Filesystem().Filesystem(qipfs.FilestoreType).(*qipfs.Filestore)
+	problems := []FsckProblem{}
+	for _, path := range sorted {
+		if !isIPFS {
+			// without a DAG to walk, settle for the version being present
+			if has, err := r.Filesystem().Has(ctx, path); err != nil || !has {
+				problems = append(problems, FsckProblem{Kind: FsckBlocks, Ref: path, Message: "version isn't in the repo filesystem"})
+			}
+			continue
+		}
+
+		id, err := cid.Parse(path)
+		if err != nil {
+			problems = append(problems, FsckProblem{Kind: FsckBlocks, Ref: path, Message: fmt.Sprintf("invalid path: %s", err)})
+			continue
+		}
+		node := fst.Node()
+		ng := localNodeGetter{has: node.Blockstore.Has, ng: dag.NewNodeGetter(node.DAG)}
+		if _, err := dag.NewManifest(ctx, ng, id); err != nil {
+			problems = append(problems, FsckProblem{Kind: FsckBlocks, Ref: path, Message: fmt.Sprintf("incomplete version: %s", err)})
+		}
+	}
+	return problems, nil
+}
+
+// localNodeGetter only gets nodes that are stored locally, never fetching
+// from the network
+type localNodeGetter struct {
+	has func(cid.Cid) (bool, error)
+	ng  ipld.NodeGetter
+}
+
+func (g localNodeGetter) Get(ctx context.Context, id cid.Cid) (ipld.Node, error) {
+	has, err := g.has(id)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, fmt.Errorf("block %s: %w", id, ipld.ErrNotFound)
+	}
+	return g.ng.Get(ctx, id)
+}
+
+func (g localNodeGetter) GetMany(ctx context.Context, ids []cid.Cid) <-chan *ipld.NodeOption {
+	ch := make(chan *ipld.NodeOption, len(ids))
+	go func() {
+		defer close(ch)
+		for _, id := range ids {
+			n, err := g.Get(ctx, id)
+			ch <- &ipld.NodeOption{Node: n, Err: err}
+		}
+	}()
+	return ch
+}
+
+// logbookRefs lists the head of every dataset in the logbook that's stored
+// locally, taking working directory links from refs. notLocal holds the heads
+// that aren't in the local filesystem, keyed by refKey
+func logbookRefs(ctx context.Context, r repo.Repo, refs []reporef.DatasetRef) (heads []reporef.DatasetRef, notLocal map[string]reporef.DatasetRef, err error) {
+	notLocal = map[string]reporef.DatasetRef{}
+	built, err := dscache.BuildDscacheFromLogbookAndProfilesAndDsref(ctx, nil, r.Profiles(), r.Logbook(), r.Filesystem())
+	if err != nil {
+		return nil, nil, err
+	}
+	if built.IsEmpty() || built.Root.RefsLength() == 0 {
+		return []reporef.DatasetRef{}, notLocal, nil
+	}
+	all, err := built.ListRefs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fsiPaths := map[string]string{}
+	for _, ref := range refs {
+		fsiPaths[refKey(ref)] = ref.FSIPath
+	}
+	heads = make([]reporef.DatasetRef, 0, len(all))
+	for _, ref := range all {
+		// datasets without versions aren't stored as references
+		if ref.Path == "" {
+			continue
+		}
+		if has, err := r.Filesystem().Has(ctx, ref.Path); err != nil || !has {
+			notLocal[refKey(ref)] = ref
+			continue
+		}
+		ref.FSIPath = fsiPaths[refKey(ref)]
+		heads = append(heads, ref)
+	}
+	return heads, notLocal, nil
+}
+
+func allRefstoreRefs(r repo.Repo) ([]reporef.DatasetRef, error) {
+	num, err := r.RefCount()
+	if err != nil {
+		return nil, err
+	}
+	return r.References(0, num)
+}
+
+// missingHeads reports logbook heads that aren't stored locally for datasets
+// the dscache or refstore claim are local. Heads that are the path of a
+// refstore entry are left out, checkVersionBlocks already reports them
+func missingHeads(notLocal map[string]reporef.DatasetRef, refs, claimed []reporef.DatasetRef) []FsckProblem {
+	checked := map[string]bool{}
+	for _, ref := range refs {
+		checked[ref.Path] = true
+	}
+
+	missing := map[string]reporef.DatasetRef{}
+	for _, ref := range claimed {
+		if head, ok := notLocal[refKey(ref)]; ok && !checked[head.Path] {
+			missing[refKey(ref)] = head
+		}
+	}
+	heads := make([]reporef.DatasetRef, 0, len(missing))
+	for _, head := range missing {
+		heads = append(heads, head)
+	}
+	sort.Slice(heads, func(i, j int) bool { return heads[i].AliasString() < heads[j].AliasString() })
+
+	problems := make([]FsckProblem, 0, len(heads))
+	for _, head := range heads {
+		problems = append(problems, FsckProblem{Kind: FsckBlocks, Ref: head.AliasString(), Message: fmt.Sprintf("logbook head %s isn't in the repo filesystem", head.Path)})
+	}
+	return problems
+}
+
+// withoutKeys drops references with keys in a set
+func withoutKeys(refs []reporef.DatasetRef, keys map[string]reporef.DatasetRef) []reporef.DatasetRef {
+	res := make([]reporef.DatasetRef, 0, len(refs))
+	for _, ref := range refs {
+		if _, ok := keys[refKey(ref)]; !ok {
+			res = append(res, ref)
+		}
+	}
+	return res
+}
+
+// refKey identifies a reference by profileID & name
+func refKey(ref reporef.DatasetRef) string {
+	return fmt.Sprintf("%s/%s", ref.ProfileID, ref.Name)
+}
+
+// compareRefs reports the differences between references derived from the
+// logbook & a set of stored references
+func compareRefs(kind string, expect, got []reporef.DatasetRef) []FsckProblem {
+	stored := map[string]reporef.DatasetRef{}
+	for _, ref := range got {
+		stored[refKey(ref)] = ref
+	}
+
+	problems := []FsckProblem{}
+	for _, want := range expect {
+		key := refKey(want)
+		ref, ok := stored[key]
+		delete(stored, key)
+		if !ok {
+			problems = append(problems, FsckProblem{Kind: kind, Ref: want.AliasString(), Message: "missing dataset"})
+			continue
+		}
+		if ref.Path != want.Path {
+			problems = append(problems, FsckProblem{Kind: kind, Ref: want.AliasString(), Message: fmt.Sprintf("head is %s, logbook head is %s", ref.Path, want.Path)})
+		}
+	}
+
+	extra := make([]reporef.DatasetRef, 0, len(stored))
+	for _, ref := range stored {
+		extra = append(extra, ref)
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].AliasString() < extra[j].AliasString() })
+	for _, ref := range extra {
+		problems = append(problems, FsckProblem{Kind: kind, Ref: ref.AliasString(), Message: "dataset isn't in the logbook"})
+	}
+	return problems
+}
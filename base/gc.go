@@ -0,0 +1,192 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/core/corerepo"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qfs/qipfs"
+	"github.com/qri-io/qri/repo"
+)
+
+// GCResult describes the outcome of collecting garbage from a repo filesystem
+type GCResult struct {
+	// Unpinned lists dataset versions that aren't referenced by the logbook
+	Unpinned []string `json:"unpinned"`
+	// Blocks is the number of blocks that aren't reachable from any pin once
+	// unreferenced versions are unpinned
+	Blocks int `json:"blocks"`
+	// Reclaimed is the total size of collected blocks, in bytes
+	Reclaimed uint64 `json:"reclaimed"`
+}
+
+// GarbageCollect unpins dataset versions the logbook doesn't reference &
+// removes blocks that are no longer reachable from a pin, including blocks
+// left behind by removed versions, failed saves & abandoned pulls. Data pinned
+// outside of qri is left alone. When dryRun is true nothing is removed, and
+// the result describes what would be collected. Repos without an IPFS
+// filestore return repo.ErrNotPinner
+func GarbageCollect(ctx context.Context, r repo.Repo, dryRun bool) (*GCResult, error) {
+	fst, ok := r.Filesystem().Filesystem(qipfs.FilestoreType).(*qipfs.Filestore)
+	if !ok {
+		return nil, repo.ErrNotPinner
+	}
+
+	unpin, err := unreferencedVersions(ctx, r, fst)
+	if err != nil {
+		return nil, err
+	}
+
+	node := fst.Node()
+	sizes, err := unreachableBlocks(ctx, node, unpin)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GCResult{Unpinned: unpin}
+	if dryRun {
+		for _, size := range sizes {
+			res.Blocks++
+			res.Reclaimed += size
+		}
+		return res, nil
+	}
+
+	for _, path := range unpin {
+		if err := fst.Unpin(ctx, path, true); err != nil {
+			return nil, fmt.Errorf("unpinning %s: %w", path, err)
+		}
+	}
+
+	removed := corerepo.GarbageCollectAsync(node, ctx)
+	err = corerepo.CollectResult(ctx, removed, func(id cid.Cid) {
+		res.Blocks++
+		res.Reclaimed += sizes[id.Hash().B58String()]
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugw("garbage collected", "unpinned", len(res.Unpinned), "blocks", res.Blocks, "reclaimed", res.Reclaimed)
+	return res, nil
+}
+
+// unreferencedVersions lists pinned dataset versions the logbook doesn't
+// reference
+func unreferencedVersions(ctx context.Context, r repo.Repo, fst *qipfs.Filestore) ([]string, error) {
+	book := r.Logbook()
+	versions, err := book.AllReferencedDatasetPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recordings, err := book.AllReferencedHTTPRecordingPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// pins are listed with "/ipld/" prefixes
+	keep := map[string]struct{}{}
+	for _, paths := range []map[string]struct{}{versions, recordings} {
+		for p := range paths {
+			keep[strings.Replace(p, "/ipfs/", "/ipld/", 1)] = struct{}{}
+		}
+	}
+
+	unknown, err := fst.PinsetDifference(ctx, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	unreferenced := []string{}
+	for path := range unknown {
+		path = strings.Replace(path, "/ipld/", "/ipfs/", 1)
+		// only versions of qri datasets have a dataset.json file, other pinned
+		// data isn't qri's to remove
+		if f, err := fst.Get(ctx, fmt.Sprintf("%s/dataset.json", path)); err == nil {
+			f.Close()
+			unreferenced = append(unreferenced, path)
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced, nil
+}
+
+// unreachableBlocks maps the multihash of each block that won't be reachable
+// from a pin once the given paths are unpinned to the size of the block.
+// blocks are matched by multihash because the blockstore & DAGs can refer to
+// the same block with different CID versions
+func unreachableBlocks(ctx context.Context, node *core.IpfsNode, unpin []string) (map[string]uint64, error) {
+	dropped := map[cid.Cid]struct{}{}
+	for _, path := range unpin {
+		id, err := cid.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		dropped[id] = struct{}{}
+	}
+
+	recursive, err := node.Pinning.RecursiveKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := node.Pinning.InternalPins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	direct, err := node.Pinning.DirectKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []cid.Cid
+	for _, id := range recursive {
+		if _, ok := dropped[id]; !ok {
+			roots = append(roots, id)
+		}
+	}
+	roots = append(roots, internal...)
+	if mfsRoots, err := corerepo.BestEffortRoots(node.FilesRoot); err == nil {
+		roots = append(roots, mfsRoots...)
+	}
+
+	reachable := map[string]struct{}{}
+	for _, id := range direct {
+		reachable[id.Hash().B58String()] = struct{}{}
+	}
+	ng := dag.NewNodeGetter(node.DAG)
+	for _, id := range roots {
+		mf, err := dag.NewManifest(ctx, ng, id)
+		if err != nil {
+			return nil, fmt.Errorf("reading pinned DAG %s: %w", id, err)
+		}
+		for _, n := range mf.Nodes {
+			nid, err := cid.Parse(n)
+			if err != nil {
+				return nil, err
+			}
+			reachable[nid.Hash().B58String()] = struct{}{}
+		}
+	}
+
+	keys, err := node.Blockstore.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizes := map[string]uint64{}
+	for id := range keys {
+		if _, ok := reachable[id.Hash().B58String()]; ok {
+			continue
+		}
+		size, err := node.Blockstore.GetSize(id)
+		if err != nil {
+			log.Debugw("reading block size", "cid", id, "err", err)
+			continue
+		}
+		sizes[id.Hash().B58String()] = uint64(size)
+	}
+	return sizes, nil
+}
@@ -0,0 +1,524 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/dsfs"
+)
+
+// ErrMergeConflict indicates two dataset versions made incompatible changes
+var ErrMergeConflict = fmt.Errorf("merge conflict")
+
+// MergeConflict describes a location where two dataset versions made
+// incompatible changes to the same value, relative to a common ancestor
+type MergeConflict struct {
+	// Component the conflict occurred in: "meta", "structure" or "body"
+	Component string `json:"component"`
+	// Path is a slash-delimited address of the conflicting value within the
+	// component
+	Path string `json:"path"`
+	// value at Path in the common ancestor
+	Base interface{} `json:"base,omitempty"`
+	// value at Path in the version being merged into
+	Ours interface{} `json:"ours,omitempty"`
+	// value at Path in the version being merged from
+	Theirs interface{} `json:"theirs,omitempty"`
+}
+
+// String formats a conflict for display
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s%s", c.Component, c.Path)
+}
+
+// MergeDatasets performs a three-way merge of the meta, structure, and body
+// components of two dataset versions that descend from a common ancestor
+// version. All other components are taken from the "ours" version.
+// The returned dataset is a complete replacement for the "ours" version with
+// a body file set, ready to be saved on top of oursPath with the Replace save
+// switch. If any conflicts are found
+// MergeDatasets returns all conflicts alongside ErrMergeConflict
+func MergeDatasets(ctx context.Context, fs qfs.Filesystem, basePath, oursPath, theirsPath string) (*dataset.Dataset, []MergeConflict, error) {
+	var (
+		base   = &dataset.Dataset{}
+		ours   *dataset.Dataset
+		theirs *dataset.Dataset
+		err    error
+	)
+
+	if basePath != "" {
+		if base, err = dsfs.LoadDataset(ctx, fs, basePath); err != nil {
+			return nil, nil, fmt.Errorf("loading merge base: %w", err)
+		}
+	}
+	if ours, err = dsfs.LoadDataset(ctx, fs, oursPath); err != nil {
+		return nil, nil, err
+	}
+	if theirs, err = dsfs.LoadDataset(ctx, fs, theirsPath); err != nil {
+		return nil, nil, err
+	}
+
+	// bodies must be read before dropping derived values, which removes paths
+	baseBody, err := mergeBodyValue(ctx, fs, base)
+	if err != nil {
+		return nil, nil, err
+	}
+	oursBody, err := mergeBodyValue(ctx, fs, ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirsBody, err := mergeBodyValue(ctx, fs, theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// only compare user-provided values of merged components
+	for _, ds := range []*dataset.Dataset{base, ours, theirs} {
+		if ds.Meta != nil {
+			ds.Meta.DropDerivedValues()
+		}
+		if ds.Structure != nil {
+			ds.Structure.DropDerivedValues()
+		}
+	}
+
+	conflicts := []MergeConflict{}
+	res := ours
+	res.Path = ""
+	res.Commit = nil
+	res.PreviousPath = ""
+
+	meta, cs, err := mergeComponent("meta", base.Meta, ours.Meta, theirs.Meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts = append(conflicts, cs...)
+	res.Meta = nil
+	if meta != nil {
+		res.Meta = &dataset.Meta{}
+		if err := remarshal(meta, res.Meta); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	st, cs, err := mergeComponent("structure", base.Structure, ours.Structure, theirs.Structure)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts = append(conflicts, cs...)
+	res.Structure = nil
+	if st != nil {
+		res.Structure = &dataset.Structure{}
+		if err := remarshal(st, res.Structure); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	body := Merge3("body", baseBody, oursBody, theirsBody, &conflicts)
+
+	if len(conflicts) > 0 {
+		return nil, conflicts, ErrMergeConflict
+	}
+
+	if body != nil {
+		if res.Structure == nil {
+			return nil, nil, fmt.Errorf("merged dataset has a body but no structure")
+		}
+		f, err := mergeBodyFile(res.Structure, body)
+		if err != nil {
+			return nil, nil, err
+		}
+		res.SetBodyFile(f)
+	}
+	res.BodyPath = ""
+
+	return res, nil, nil
+}
+
+// Merge3 performs a three-way merge of plain-old-data values (maps, slices and
+// scalars produced by JSON decoding). Both sides are diffed against base with
+// deepdiff, and changes made on only one side are applied to ours. When both
+// sides change the same value in different ways, or one side changes a value
+// the other side changed within, conflicts are appended to conflicts and ours
+// is returned without merging. ours may be modified by the merge
+func Merge3(component string, base, ours, theirs interface{}, conflicts *[]MergeConflict) interface{} {
+	// index the paths ours changed, and the paths leading to them
+	changed := map[string]mergeChange{}
+	within := map[string]bool{}
+	for _, c := range mergeChanges(base, ours) {
+		changed[mergePath(c.path)] = c
+		for i := range c.path {
+			within[mergePath(c.path[:i])] = true
+		}
+	}
+
+	found := []MergeConflict{}
+	seen := map[string]bool{}
+	addConflict := func(path []deepdiff.Addr) {
+		p := mergePath(path)
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		found = append(found, MergeConflict{
+			Component: component,
+			Path:      p,
+			Base:      mergeValueAt(base, path),
+			Ours:      mergeValueAt(ours, path),
+			Theirs:    mergeValueAt(theirs, path),
+		})
+	}
+
+	apply := []mergeChange{}
+theirsChanges:
+	for _, c := range mergeChanges(base, theirs) {
+		p := mergePath(c.path)
+		if o, ok := changed[p]; ok {
+			if o.op != c.op || !reflect.DeepEqual(o.value, c.value) {
+				addConflict(c.path)
+			}
+			// both sides made the same change
+			continue
+		}
+		if within[p] {
+			addConflict(c.path)
+			continue
+		}
+		for i := range c.path {
+			if _, ok := changed[mergePath(c.path[:i])]; ok {
+				addConflict(c.path[:i])
+				continue theirsChanges
+			}
+		}
+		apply = append(apply, c)
+	}
+
+	if len(found) > 0 {
+		*conflicts = append(*conflicts, found...)
+		return ours
+	}
+	for _, c := range apply {
+		ours = applyMergeChange(ours, c.path, c)
+	}
+	return ours
+}
+
+// mergeChange is a single change one side of a merge made to the base value.
+// op is deepdiff.DTUpdate to set the value at path, deepdiff.DTInsert to
+// append it to a list, or deepdiff.DTDelete to remove a key
+type mergeChange struct {
+	path  []deepdiff.Addr
+	op    deepdiff.Operation
+	value interface{}
+}
+
+// mergeChanges lists the changes made to base by side
+func mergeChanges(base, side interface{}) []mergeChange {
+	if reflect.DeepEqual(base, side) {
+		return nil
+	}
+	if base == nil {
+		if _, ok := side.(map[string]interface{}); ok {
+			base = map[string]interface{}{}
+		}
+	}
+
+	replace := []mergeChange{{op: deepdiff.DTUpdate, value: side}}
+	switch base.(type) {
+	case map[string]interface{}:
+		if _, ok := side.(map[string]interface{}); !ok {
+			return replace
+		}
+	case []interface{}:
+		if _, ok := side.([]interface{}); !ok {
+			return replace
+		}
+	default:
+		return replace
+	}
+
+	deltas, err := deepdiff.New().Diff(context.Background(), base, side)
+	if err != nil {
+		return replace
+	}
+	for _, d := range deltas {
+		if _, ok := d.Path.(deepdiff.RootAddr); ok {
+			// deepdiff replaces roots it can't match to each other. compare the
+			// keys of maps instead, so changes to different keys don't conflict
+			if _, ok := base.(map[string]interface{}); ok {
+				return deltaChanges(nil, base, side, nil)
+			}
+			return replace
+		}
+	}
+	return deltaChanges(nil, base, side, deltas)
+}
+
+// deltaChanges converts the deltas deepdiff found between base & side at
+// path into changes. deltas describe a changed value as a deletion followed
+// by an insertion, and can describe a value that moved to a different key as
+// unchanged, so values are read from side instead of deltas
+func deltaChanges(path []deepdiff.Addr, base, side interface{}, deltas deepdiff.Deltas) []mergeChange {
+	at := func(addr deepdiff.Addr) []deepdiff.Addr {
+		return append(append([]deepdiff.Addr{}, path...), addr)
+	}
+	nested := map[interface{}]deepdiff.Deltas{}
+	for _, d := range deltas {
+		if d.Type == deepdiff.DTContext && len(d.Deltas) > 0 {
+			nested[d.Path.Value()] = d.Deltas
+		}
+	}
+
+	changes := []mergeChange{}
+	switch b := base.(type) {
+	case map[string]interface{}:
+		s, ok := side.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(s))
+		for k := range b {
+			keys = append(keys, k)
+		}
+		for k := range s {
+			if _, ok := b[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			bv, inBase := b[k]
+			sv, inSide := s[k]
+			switch {
+			case !inSide:
+				changes = append(changes, mergeChange{path: at(deepdiff.StringAddr(k)), op: deepdiff.DTDelete})
+			case inBase && nested[k] != nil:
+				changes = append(changes, deltaChanges(at(deepdiff.StringAddr(k)), bv, sv, nested[k])...)
+			case !inBase || !reflect.DeepEqual(bv, sv):
+				changes = append(changes, mergeChange{path: at(deepdiff.StringAddr(k)), op: deepdiff.DTUpdate, value: sv})
+			}
+		}
+		return changes
+
+	case []interface{}:
+		s, ok := side.([]interface{})
+		if !ok {
+			break
+		}
+		// replay deepdiff's insertions & deletions to find where each base
+		// element ended up. -1 marks an inserted element
+		pos := make([]int, len(b))
+		for i := range pos {
+			pos[i] = i
+		}
+		for _, d := range deltas {
+			i, _ := d.Path.Value().(int)
+			if i > len(pos) {
+				i = len(pos)
+			}
+			switch d.Type {
+			case deepdiff.DTDelete:
+				if i < len(pos) {
+					pos = append(pos[:i:i], pos[i+1:]...)
+				}
+			case deepdiff.DTInsert:
+				pos = append(pos[:i:i], append([]int{-1}, pos[i:]...)...)
+			}
+		}
+		if len(pos) != len(s) || len(s) < len(b) {
+			break
+		}
+		for i, p := range pos {
+			if p != -1 && p != i {
+				// elements moved, indices of the two sides no longer line up
+				return []mergeChange{{path: path, op: deepdiff.DTUpdate, value: side}}
+			}
+		}
+
+		for i, p := range pos {
+			switch {
+			case i >= len(b):
+				changes = append(changes, mergeChange{path: at(deepdiff.IndexAddr(i)), op: deepdiff.DTInsert, value: s[i]})
+			case p == -1:
+				changes = append(changes, mergeChange{path: at(deepdiff.IndexAddr(i)), op: deepdiff.DTUpdate, value: s[i]})
+			case nested[i] != nil:
+				changes = append(changes, deltaChanges(at(deepdiff.IndexAddr(i)), b[i], s[i], nested[i])...)
+			case !reflect.DeepEqual(b[i], s[i]):
+				changes = append(changes, mergeChange{path: at(deepdiff.IndexAddr(i)), op: deepdiff.DTUpdate, value: s[i]})
+			}
+		}
+		return changes
+	}
+
+	return []mergeChange{{path: path, op: deepdiff.DTUpdate, value: side}}
+}
+
+// applyMergeChange makes change c at path within doc
+func applyMergeChange(doc interface{}, path []deepdiff.Addr, c mergeChange) interface{} {
+	if len(path) == 0 {
+		return c.value
+	}
+	if _, ok := path[0].(deepdiff.StringAddr); ok && doc == nil {
+		// keys added to a value ours doesn't have
+		doc = map[string]interface{}{}
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		key, _ := path[0].Value().(string)
+		if len(path) == 1 && c.op == deepdiff.DTDelete {
+			delete(d, key)
+			return d
+		}
+		d[key] = applyMergeChange(d[key], path[1:], c)
+	case []interface{}:
+		i, _ := path[0].Value().(int)
+		if len(path) == 1 && c.op == deepdiff.DTInsert {
+			if i > len(d) {
+				i = len(d)
+			}
+			return append(d[:i:i], append([]interface{}{c.value}, d[i:]...)...)
+		}
+		if i < len(d) {
+			d[i] = applyMergeChange(d[i], path[1:], c)
+		}
+	}
+	return doc
+}
+
+// mergeValueAt returns the value at path within doc, nil if doc has no value
+// at path
+func mergeValueAt(doc interface{}, path []deepdiff.Addr) interface{} {
+	for _, addr := range path {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			key, _ := addr.Value().(string)
+			doc = d[key]
+		case []interface{}:
+			i, _ := addr.Value().(int)
+			if i >= len(d) {
+				return nil
+			}
+			doc = d[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+// mergePath formats a path as a slash-delimited string
+func mergePath(path []deepdiff.Addr) string {
+	var b strings.Builder
+	for _, addr := range path {
+		b.WriteString("/")
+		b.WriteString(addr.String())
+	}
+	return b.String()
+}
+
+// mergeComponent converts components to plain-old-data and merges them
+func mergeComponent(name string, base, ours, theirs interface{}) (interface{}, []MergeConflict, error) {
+	b, err := toPlainValue(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := toPlainValue(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := toPlainValue(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflicts := []MergeConflict{}
+	res := Merge3(name, b, o, t, &conflicts)
+	return res, conflicts, nil
+}
+
+func toPlainValue(v interface{}) (interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func remarshal(v interface{}, dst interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// mergeBodyValue reads the body of a dataset into plain-old-data, JSON-encoded
+// to normalize number types across body formats
+func mergeBodyValue(ctx context.Context, fs qfs.Filesystem, ds *dataset.Dataset) (interface{}, error) {
+	if ds.BodyPath == "" || ds.Structure == nil {
+		return nil, nil
+	}
+	f, err := dsfs.LoadBody(ctx, fs, ds)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rdr, err := dsio.NewEntryReader(ds.Structure, f)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ReadEntries(rdr)
+	if err != nil {
+		return nil, err
+	}
+	return toPlainValue(body)
+}
+
+func mergeBodyFile(st *dataset.Structure, body interface{}) (qfs.File, error) {
+	w, err := dsio.NewEntryBuffer(st)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b := body.(type) {
+	case []interface{}:
+		for i, v := range b {
+			if err := w.WriteEntry(dsio.Entry{Index: i, Value: v}); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(b))
+		for k := range b {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := w.WriteEntry(dsio.Entry{Key: k, Value: b[k]}); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cannot write merged body of type %T", body)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return qfs.NewMemfileBytes(fmt.Sprintf("body.%s", strings.ToLower(st.Format)), w.Bytes()), nil
+}
@@ -0,0 +1,109 @@
+package base
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func TestMerge3(t *testing.T) {
+	cases := []struct {
+		description         string
+		base, ours, theirs  string
+		expect              string
+		expectConflictPaths []string
+	}{
+		{"disjoint object edits", `{"a":1,"b":2}`, `{"a":1,"b":3}`, `{"a":5,"b":2,"c":1}`, `{"a":5,"b":3,"c":1}`, nil},
+		{"array edit & append", `[1,2]`, `[1,2,3]`, `[1,5]`, `[1,5,3]`, nil},
+		{"same change on both sides", `[1,2]`, `[1,2,3]`, `[1,2,3]`, `[1,2,3]`, nil},
+		{"conflicting appends", `[1,2]`, `[1,2,3]`, `[1,2,4]`, `[1,2,3]`, []string{"/2"}},
+		{"conflicting values", `{"a":1}`, `{"a":2}`, `{"a":3}`, `{"a":2}`, []string{"/a"}},
+		{"delete & modify", `{"a":1}`, `{}`, `{"a":2}`, `{}`, []string{"/a"}},
+		{"removed elements", `[1,2]`, `[1]`, `[1,2,4]`, `[1]`, []string{""}},
+		{"edits to different rows", `[["a",1],["b",2]]`, `[["a",9],["b",2]]`, `[["a",1],["b",7]]`, `[["a",9],["b",7]]`, nil},
+		{"edits to different cells of a row", `[["a",1],["b",2]]`, `[["a",9],["b",2]]`, `[["c",1],["b",2]]`, `[["c",9],["b",2]]`, nil},
+		{"edits to the same cell", `[["a",1],["b",2]]`, `[["a",9],["b",2]]`, `[["a",8],["b",2]]`, `[["a",9],["b",2]]`, []string{"/0/1"}},
+		{"inserted elements & edit", `[1,2]`, `[0,1,2]`, `[1,5]`, `[0,1,2]`, []string{""}},
+		{"nested edit & delete", `{"a":{"b":1,"c":2}}`, `{"a":{"b":1}}`, `{"a":{"b":3,"c":2}}`, `{"a":{"b":3}}`, nil},
+		{"added on both sides", `null`, `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`, nil},
+		{"added on one side", `null`, `null`, `{"b":2}`, `{"b":2}`, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			conflicts := []MergeConflict{}
+			got := Merge3("body", mustDecodeJSON(t, c.base), mustDecodeJSON(t, c.ours), mustDecodeJSON(t, c.theirs), &conflicts)
+
+			if diff := cmp.Diff(mustDecodeJSON(t, c.expect), got); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+
+			var paths []string
+			for _, conflict := range conflicts {
+				paths = append(paths, conflict.Path)
+			}
+			if diff := cmp.Diff(c.expectConflictPaths, paths); diff != "" {
+				t.Errorf("conflict paths mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeDatasets(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+	ctx := run.Context
+	fs := run.Repo.Filesystem()
+
+	save := func(title, body string) string {
+		ds := run.BuildDataset("merge_test", "json")
+		ds.Meta = &dataset.Meta{Title: title}
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+		ref, err := run.SaveDatasetReplace(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref.Path
+	}
+
+	basePath := save("original", `[1,2]`)
+	oursPath := save("original", `[1,2,3]`)
+	theirsPath := save("new title", `[1,2]`)
+
+	ds, conflicts, err := MergeDatasets(ctx, fs, basePath, oursPath, theirsPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s, conflicts: %v", err, conflicts)
+	}
+	if ds.Meta == nil || ds.Meta.Title != "new title" {
+		t.Errorf("expected merged meta title to be %q, got: %v", "new title", ds.Meta)
+	}
+	data, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(mustDecodeJSON(t, `[1,2,3]`), mustDecodeJSON(t, string(data))); diff != "" {
+		t.Errorf("merged body mismatch (-want +got):\n%s", diff)
+	}
+
+	conflictPath := save("conflicting title", `[1,2]`)
+	_, conflicts, err = MergeDatasets(ctx, fs, theirsPath, conflictPath, oursPath)
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected ErrMergeConflict, got: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].String() != "meta/title" {
+		t.Errorf("expected a single conflict at meta/title, got: %v", conflicts)
+	}
+}
+
+func mustDecodeJSON(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,491 @@
+// Package rowdiff compares tabular dataset bodies row-by-row, matching rows
+// on the primary key columns a structure declares. Unlike a tree diff, adding
+// a row to the top of a body doesn't shift every later row. Bodies are read as
+// streams, so memory use grows with the number of rows and changes, not with
+// the size of each row
+package rowdiff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
+)
+
+// PrimaryKeyKeyword is the structure schema keyword that declares the key
+// columns of a tabular body. Its value is a column name or a list of names
+const PrimaryKeyKeyword = "primaryKey"
+
+// ErrNoPrimaryKey indicates a structure doesn't declare a primary key
+var ErrNoPrimaryKey = errors.New("structure has no primary key")
+
+// PrimaryKey returns the key columns declared in a structure's schema
+func PrimaryKey(st *dataset.Structure) ([]string, error) {
+	if st == nil || st.Schema == nil {
+		return nil, ErrNoPrimaryKey
+	}
+	switch v := st.Schema[PrimaryKeyKeyword].(type) {
+	case nil:
+		return nil, ErrNoPrimaryKey
+	case string:
+		if v == "" {
+			return nil, fmt.Errorf("primary key column name is empty")
+		}
+		return []string{v}, nil
+	case []string:
+		if len(v) == 0 {
+			return nil, ErrNoPrimaryKey
+		}
+		return v, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, ErrNoPrimaryKey
+		}
+		keys := make([]string, len(v))
+		for i, k := range v {
+			name, ok := k.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("primary key column %d must be a column name", i)
+			}
+			keys[i] = name
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("primary key must be a column name or a list of column names")
+	}
+}
+
+// Body is a tabular dataset body that can be read more than once
+type Body struct {
+	Structure *dataset.Structure
+	// Open returns a new reader for the body file
+	Open func(ctx context.Context) (qfs.File, error)
+}
+
+// Delta is a change to a single row. Added & removed rows carry the row,
+// modified rows list the cells that changed
+type Delta struct {
+	// Type is one of deepdiff.DTInsert, deepdiff.DTDelete or deepdiff.DTUpdate
+	Type deepdiff.Operation `json:"type"`
+	// Key holds the primary key values of the row
+	Key []interface{} `json:"key"`
+	// Row is the added or removed row
+	Row interface{} `json:"row,omitempty"`
+	// Cells lists changes to a modified row
+	Cells []*CellDelta `json:"cells,omitempty"`
+}
+
+// CellDelta is a change to a single value of a row. From is nil for columns
+// only the right side has, To is nil for columns only the left side has
+type CellDelta struct {
+	Column string      `json:"column"`
+	From   interface{} `json:"from"`
+	To     interface{} `json:"to"`
+}
+
+// Stats counts the rows of both sides of a diff & the rows that changed
+type Stats struct {
+	LeftRows  int `json:"leftRows"`
+	RightRows int `json:"rightRows"`
+	Added     int `json:"added,omitempty"`
+	Removed   int `json:"removed,omitempty"`
+	Modified  int `json:"modified,omitempty"`
+}
+
+// states of a left row while matching right rows
+const (
+	unmatched = iota
+	matched
+	modified
+)
+
+type leftRow struct {
+	hash  [16]byte
+	state int
+}
+
+// Diff compares two tabular bodies that declare the same primary key, calling
+// emit with each row that was added, removed or modified. Added rows are
+// emitted in right side order, followed by removed & modified rows in left
+// side order. The left body is read twice, the right body once. Memory holds
+// a key & checksum for each row, plus the right side of modified rows
+func Diff(ctx context.Context, left, right Body, emit func(*Delta) error) (*Stats, error) {
+	keys, err := PrimaryKey(left.Structure)
+	if err != nil {
+		return nil, fmt.Errorf("left side: %w", err)
+	}
+	rightKeys, err := PrimaryKey(right.Structure)
+	if err != nil {
+		return nil, fmt.Errorf("right side: %w", err)
+	}
+	if !sameColumns(keys, rightKeys) {
+		return nil, fmt.Errorf("primary keys don't match. left: %v, right: %v", keys, rightKeys)
+	}
+
+	st := &Stats{}
+	index := map[string]*leftRow{}
+	err = eachRow(ctx, left, keys, func(r *row) error {
+		if _, ok := index[r.key]; ok {
+			return fmt.Errorf("left side: duplicate primary key %s at row %d", r.key, r.index)
+		}
+		index[r.key] = &leftRow{hash: r.hash}
+		st.LeftRows++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	pending := map[string]*row{}
+	err = eachRow(ctx, right, keys, func(r *row) error {
+		if _, ok := seen[r.key]; ok {
+			return fmt.Errorf("right side: duplicate primary key %s at row %d", r.key, r.index)
+		}
+		seen[r.key] = struct{}{}
+		st.RightRows++
+
+		l, ok := index[r.key]
+		if !ok {
+			st.Added++
+			return emit(&Delta{Type: deepdiff.DTInsert, Key: r.keyVals, Row: r.value})
+		}
+		if l.hash == r.hash {
+			l.state = matched
+			return nil
+		}
+		l.state = modified
+		pending[r.key] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen = nil
+
+	err = eachRow(ctx, left, keys, func(r *row) error {
+		switch index[r.key].state {
+		case unmatched:
+			st.Removed++
+			return emit(&Delta{Type: deepdiff.DTDelete, Key: r.keyVals, Row: r.value})
+		case modified:
+			st.Modified++
+			to := pending[r.key]
+			delete(pending, r.key)
+			return emit(&Delta{Type: deepdiff.DTUpdate, Key: r.keyVals, Cells: cellDeltas(r, to)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// row is a single entry of a tabular body
+type row struct {
+	index   int
+	key     string
+	keyVals []interface{}
+	value   interface{}
+	columns []string
+	hash    [16]byte
+}
+
+// cell returns the named value of the row
+func (r *row) cell(name string) (interface{}, bool) {
+	switch v := r.value.(type) {
+	case []interface{}:
+		for i, col := range r.columns {
+			if col == name && i < len(v) {
+				return v[i], true
+			}
+		}
+		// values past the last schema column are named by index
+		if i, err := strconv.Atoi(name); err == nil && i >= len(r.columns) && i < len(v) {
+			return v[i], true
+		}
+	case map[string]interface{}:
+		val, ok := v[name]
+		return val, ok
+	}
+	return nil, false
+}
+
+// names lists the columns of the row in order
+func (r *row) names() []string {
+	switch v := r.value.(type) {
+	case []interface{}:
+		names := make([]string, len(v))
+		copy(names, r.columns)
+		for i := len(r.columns); i < len(v); i++ {
+			names[i] = strconv.Itoa(i)
+		}
+		return names
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	return nil
+}
+
+// eachRow streams the rows of a body, calling fn with each row
+func eachRow(ctx context.Context, b Body, keys []string, fn func(r *row) error) error {
+	if b.Structure == nil {
+		return fmt.Errorf("body structure is required")
+	}
+	topLevel, err := dsio.GetTopLevelType(b.Structure)
+	if err != nil {
+		return err
+	}
+	if topLevel != "array" {
+		return fmt.Errorf("primary key diffs require a body that is an array of rows")
+	}
+
+	var columns []string
+	if cols, _, err := tabular.ColumnsFromJSONSchema(b.Structure.Schema); err == nil {
+		columns = cols.Titles()
+	}
+	keyIdx := make([]int, len(keys))
+	for i, k := range keys {
+		keyIdx[i] = -1
+		for j, col := range columns {
+			if col == k {
+				keyIdx[i] = j
+				break
+			}
+		}
+	}
+
+	f, err := b.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := dsio.NewEntryReader(b.Structure, f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err.Error() == io.EOF.Error() {
+				return nil
+			}
+			return err
+		}
+
+		rw := &row{index: i, value: ent.Value, columns: columns, keyVals: make([]interface{}, len(keys))}
+		switch v := ent.Value.(type) {
+		case []interface{}:
+			for j, idx := range keyIdx {
+				if idx == -1 {
+					return fmt.Errorf("primary key column %q isn't in the schema", keys[j])
+				}
+				if idx < len(v) {
+					rw.keyVals[j] = v[idx]
+				}
+			}
+		case map[string]interface{}:
+			for j, k := range keys {
+				rw.keyVals[j] = v[k]
+			}
+		default:
+			return fmt.Errorf("row %d isn't an array or object", i)
+		}
+
+		keyData, err := json.Marshal(rw.keyVals)
+		if err != nil {
+			return err
+		}
+		rw.key = string(keyData)
+		data, err := json.Marshal(rw.value)
+		if err != nil {
+			return err
+		}
+		h := fnv.New128a()
+		h.Write(data)
+		copy(rw.hash[:], h.Sum(nil))
+
+		if err := fn(rw); err != nil {
+			return err
+		}
+	}
+}
+
+// cellDeltas lists changed values of a row, left side columns first
+func cellDeltas(from, to *row) []*CellDelta {
+	names := from.names()
+	for _, name := range to.names() {
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var cells []*CellDelta
+	for _, name := range names {
+		a, _ := from.cell(name)
+		b, _ := to.cell(name)
+		if !equalValues(a, b) {
+			cells = append(cells, &CellDelta{Column: name, From: a, To: b})
+		}
+	}
+	return cells
+}
+
+// equalValues compares values by their JSON encoding, the same comparison
+// row checksums use
+func equalValues(a, b interface{}) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply writes the rows of body to w with row deltas applied. Removed rows are
+// dropped, modified rows are updated in place and added rows are written after
+// the last row of body. Apply fails if a delta doesn't match a row of body
+func Apply(ctx context.Context, body Body, deltas []*Delta, w dsio.EntryWriter) error {
+	keys, err := PrimaryKey(body.Structure)
+	if err != nil {
+		return err
+	}
+
+	byKey := map[string]*Delta{}
+	var inserts []*Delta
+	for _, d := range deltas {
+		if len(d.Key) != len(keys) {
+			return fmt.Errorf("delta key %v doesn't match primary key %v", d.Key, keys)
+		}
+		data, err := json.Marshal(d.Key)
+		if err != nil {
+			return err
+		}
+		key := string(data)
+		if _, ok := byKey[key]; ok {
+			return fmt.Errorf("more than one change to row %s", key)
+		}
+		switch d.Type {
+		case deepdiff.DTInsert:
+			inserts = append(inserts, d)
+		case deepdiff.DTDelete, deepdiff.DTUpdate:
+		default:
+			return fmt.Errorf("invalid change type %q for row %s", d.Type, key)
+		}
+		byKey[key] = d
+	}
+
+	applied := map[string]struct{}{}
+	i := 0
+	err = eachRow(ctx, body, keys, func(r *row) error {
+		d, ok := byKey[r.key]
+		if !ok {
+			i++
+			return w.WriteEntry(dsio.Entry{Index: i - 1, Value: r.value})
+		}
+		applied[r.key] = struct{}{}
+		switch d.Type {
+		case deepdiff.DTInsert:
+			return fmt.Errorf("can't add row %s, it already exists", r.key)
+		case deepdiff.DTDelete:
+			return nil
+		}
+		val, err := r.update(d.Cells)
+		if err != nil {
+			return fmt.Errorf("row %s: %w", r.key, err)
+		}
+		i++
+		return w.WriteEntry(dsio.Entry{Index: i - 1, Value: val})
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, d := range byKey {
+		if _, ok := applied[key]; !ok && d.Type != deepdiff.DTInsert {
+			return fmt.Errorf("row %s doesn't exist", key)
+		}
+	}
+	for _, d := range inserts {
+		if err := w.WriteEntry(dsio.Entry{Index: i, Value: d.Row}); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// update returns a copy of the row value with cell changes applied
+func (r *row) update(cells []*CellDelta) (interface{}, error) {
+	switch v := r.value.(type) {
+	case []interface{}:
+		updated := make([]interface{}, len(v))
+		copy(updated, v)
+		for _, c := range cells {
+			idx := -1
+			for i, col := range r.columns {
+				if col == c.Column {
+					idx = i
+					break
+				}
+			}
+			if i, err := strconv.Atoi(c.Column); idx == -1 && err == nil && i >= len(r.columns) {
+				idx = i
+			}
+			if idx == -1 || idx >= len(updated) {
+				return nil, fmt.Errorf("column %q doesn't exist", c.Column)
+			}
+			updated[idx] = c.To
+		}
+		return updated, nil
+	case map[string]interface{}:
+		updated := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			updated[k] = val
+		}
+		for _, c := range cells {
+			updated[c.Column] = c.To
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("row isn't an array or object")
+}
@@ -0,0 +1,231 @@
+package rowdiff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
+)
+
+var citiesSchema = map[string]interface{}{
+	"type":       "array",
+	"primaryKey": []interface{}{"city"},
+	"items": map[string]interface{}{
+		"type": "array",
+		"items": []interface{}{
+			map[string]interface{}{"title": "city", "type": "string"},
+			map[string]interface{}{"title": "pop", "type": "integer"},
+			map[string]interface{}{"title": "in_usa", "type": "boolean"},
+		},
+	},
+}
+
+func csvBody(schema map[string]interface{}, data string) Body {
+	return Body{
+		Structure: &dataset.Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Schema:       schema,
+		},
+		Open: func(ctx context.Context) (qfs.File, error) {
+			return qfs.NewMemfileBytes("body.csv", []byte(data)), nil
+		},
+	}
+}
+
+func collect(t *testing.T, left, right Body) ([]*Delta, *Stats) {
+	t.Helper()
+	var deltas []*Delta
+	st, err := Diff(context.Background(), left, right, func(d *Delta) error {
+		deltas = append(deltas, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deltas, st
+}
+
+func TestDiff(t *testing.T) {
+	left := csvBody(citiesSchema, "city,pop,in_usa\ntoronto,40000000,false\nnew york,8500000,true\nchicago,300000,true\n")
+	// a row inserted at the top, chicago removed & new york modified
+	right := csvBody(citiesSchema, "city,pop,in_usa\nchatham,35000,true\ntoronto,40000000,false\nnew york,8600000,true\n")
+
+	deltas, st := collect(t, left, right)
+	expect := []*Delta{
+		{Type: deepdiff.DTInsert, Key: []interface{}{"chatham"}, Row: []interface{}{"chatham", int64(35000), true}},
+		{Type: deepdiff.DTUpdate, Key: []interface{}{"new york"}, Cells: []*CellDelta{{Column: "pop", From: int64(8500000), To: int64(8600000)}}},
+		{Type: deepdiff.DTDelete, Key: []interface{}{"chicago"}, Row: []interface{}{"chicago", int64(300000), true}},
+	}
+	if diff := cmp.Diff(expect, deltas); diff != "" {
+		t.Errorf("deltas mismatch (-want +got):\n%s", diff)
+	}
+	expectStats := &Stats{LeftRows: 3, RightRows: 3, Added: 1, Removed: 1, Modified: 1}
+	if diff := cmp.Diff(expectStats, st); diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+
+	if deltas, _ := collect(t, left, left); len(deltas) != 0 {
+		t.Errorf("expected diffing a body with itself to have no changes. got: %d", len(deltas))
+	}
+}
+
+func TestDiffObjectRows(t *testing.T) {
+	body := func(data string) Body {
+		return Body{
+			Structure: &dataset.Structure{
+				Format: "json",
+				Schema: map[string]interface{}{"type": "array", "primaryKey": "id"},
+			},
+			Open: func(ctx context.Context) (qfs.File, error) {
+				return qfs.NewMemfileBytes("body.json", []byte(data)), nil
+			},
+		}
+	}
+	left := body(`[{"id":1,"name":"a"},{"id":2,"name":"b"}]`)
+	right := body(`[{"id":2,"name":"b","note":"new"},{"id":1,"name":"a"}]`)
+
+	deltas, st := collect(t, left, right)
+	expect := []*Delta{
+		{Type: deepdiff.DTUpdate, Key: []interface{}{int64(2)}, Cells: []*CellDelta{{Column: "note", To: "new"}}},
+	}
+	if diff := cmp.Diff(expect, deltas); diff != "" {
+		t.Errorf("deltas mismatch (-want +got):\n%s", diff)
+	}
+	if st.Modified != 1 || st.Added != 0 || st.Removed != 0 {
+		t.Errorf("unexpected stats: %v", st)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	noKey := map[string]interface{}{"type": "array", "items": citiesSchema["items"]}
+	otherKey := map[string]interface{}{"type": "array", "primaryKey": "pop", "items": citiesSchema["items"]}
+	missingKey := map[string]interface{}{"type": "array", "primaryKey": "country", "items": citiesSchema["items"]}
+	data := "city,pop,in_usa\ntoronto,40000000,false\n"
+
+	cases := []struct {
+		description string
+		left, right Body
+		err         string
+	}{
+		{"no primary key", csvBody(noKey, data), csvBody(citiesSchema, data), "left side: structure has no primary key"},
+		{"different keys", csvBody(citiesSchema, data), csvBody(otherKey, data), "primary keys don't match. left: [city], right: [pop]"},
+		{"key not in schema", csvBody(missingKey, data), csvBody(missingKey, data), `primary key column "country" isn't in the schema`},
+		{"duplicate key", csvBody(citiesSchema, data+"toronto,1,false\n"), csvBody(citiesSchema, data), `left side: duplicate primary key ["toronto"] at row 1`},
+	}
+	for _, c := range cases {
+		_, err := Diff(context.Background(), c.left, c.right, func(*Delta) error { return nil })
+		if err == nil {
+			t.Errorf("case %q: expected error, got nil", c.description)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("case %q: error mismatch. want: %q, got: %q", c.description, c.err, err)
+		}
+	}
+}
+
+func TestPrimaryKey(t *testing.T) {
+	cases := []struct {
+		key    interface{}
+		expect []string
+		err    string
+	}{
+		{nil, nil, ErrNoPrimaryKey.Error()},
+		{"id", []string{"id"}, ""},
+		{[]interface{}{"a", "b"}, []string{"a", "b"}, ""},
+		{[]interface{}{"a", 1}, nil, "primary key column 1 must be a column name"},
+		{5, nil, "primary key must be a column name or a list of column names"},
+	}
+	for i, c := range cases {
+		st := &dataset.Structure{Schema: map[string]interface{}{"type": "array"}}
+		if c.key != nil {
+			st.Schema[PrimaryKeyKeyword] = c.key
+		}
+		got, err := PrimaryKey(st)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("case %d: expected error %q, got: %v", i, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %s", i, err)
+			continue
+		}
+		if diff := cmp.Diff(c.expect, got); diff != "" {
+			t.Errorf("case %d: result mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	left := csvBody(citiesSchema, "city,pop,in_usa\ntoronto,40000000,false\nnew york,8500000,true\nchicago,300000,true\n")
+	right := csvBody(citiesSchema, "city,pop,in_usa\nchatham,35000,true\ntoronto,40000000,false\nnew york,8600000,true\n")
+	deltas, _ := collect(t, left, right)
+
+	buf, err := dsio.NewEntryBuffer(left.Structure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(ctx, left, deltas, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// added rows are written after existing rows
+	expect := "city,pop,in_usa\ntoronto,40000000,false\nnew york,8600000,true\nchatham,35000,true\n"
+	if diff := cmp.Diff(expect, string(buf.Bytes())); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	bad := []struct {
+		description string
+		deltas      []*Delta
+		err         string
+	}{
+		{"add existing row", []*Delta{{Type: deepdiff.DTInsert, Key: []interface{}{"toronto"}, Row: []interface{}{"toronto", 1, false}}}, `can't add row ["toronto"], it already exists`},
+		{"remove missing row", []*Delta{{Type: deepdiff.DTDelete, Key: []interface{}{"paris"}}}, `row ["paris"] doesn't exist`},
+		{"update missing column", []*Delta{{Type: deepdiff.DTUpdate, Key: []interface{}{"toronto"}, Cells: []*CellDelta{{Column: "country", To: "CA"}}}}, `row ["toronto"]: column "country" doesn't exist`},
+		{"wrong key length", []*Delta{{Type: deepdiff.DTDelete, Key: []interface{}{"toronto", "CA"}}}, `delta key [toronto CA] doesn't match primary key [city]`},
+	}
+	for _, c := range bad {
+		buf, err := dsio.NewEntryBuffer(left.Structure)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Apply(ctx, left, c.deltas, buf)
+		if err == nil {
+			t.Errorf("case %q: expected error, got nil", c.description)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("case %q: error mismatch. want: %q, got: %q", c.description, c.err, err)
+		}
+	}
+}
+
+func BenchmarkDiff(b *testing.B) {
+	left, right := &strings.Builder{}, &strings.Builder{}
+	left.WriteString("city,pop,in_usa\n")
+	right.WriteString("city,pop,in_usa\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(left, "city_%d,%d,true\n", i, i)
+		fmt.Fprintf(right, "city_%d,%d,true\n", i+1, i)
+	}
+	lb, rb := csvBody(citiesSchema, left.String()), csvBody(citiesSchema, right.String())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Diff(context.Background(), lb, rb, func(*Delta) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -104,6 +104,10 @@ func SaveDataset(
 	// let's make history, if it exists
 	changes.PreviousPath = prevPath
 
+	if sw.LoadCheckReference == nil {
+		sw.LoadCheckReference = CheckReferenceLoader(r)
+	}
+
 	// Write the dataset to storage and get back the new path
 	ds, err = CreateDataset(ctx, r, writeDest, changes, prev, sw)
 	if err != nil {
@@ -0,0 +1,12 @@
+//go:build cgo
+// +build cgo
+
+package sqlite
+
+import (
+	// register the "sqlite3" database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Supported is true when qri is built with a SQLite driver
+const Supported = true
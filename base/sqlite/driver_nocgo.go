@@ -0,0 +1,8 @@
+//go:build !cgo
+// +build !cgo
+
+package sqlite
+
+// Supported is true when qri is built with a SQLite driver. The driver
+// requires cgo, builds without cgo return ErrNoCgo when reading databases
+const Supported = false
@@ -0,0 +1,274 @@
+// Package sqlite reads tables & query results from SQLite database files as
+// tabular dataset bodies
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// ErrTableNotFound indicates a database doesn't have a requested table
+var ErrTableNotFound = fmt.Errorf("table not found")
+
+// ErrNoCgo indicates qri was built without cgo, which the SQLite driver
+// requires
+var ErrNoCgo = fmt.Errorf("reading sqlite databases requires a qri build with cgo enabled (CGO_ENABLED=1)")
+
+// IsDatabase returns true if a filename has the extension of a SQLite
+// database: .sqlite, .sqlite3 or .db
+func IsDatabase(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".sqlite", ".sqlite3", ".db":
+		return true
+	}
+	return false
+}
+
+// open connects to the database file at path in read-only mode
+func open(path string) (*sql.DB, error) {
+	if !Supported {
+		return nil, ErrNoCgo
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("opening sqlite database: %w", err)
+	}
+	dsn := (&url.URL{Scheme: "file", Opaque: path, RawQuery: "mode=ro"}).String()
+	return sql.Open("sqlite3", dsn)
+}
+
+// Tables lists the names of the tables in a database in sorted order,
+// excluding SQLite's internal tables
+func Tables(ctx context.Context, path string) ([]string, error) {
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return tables(ctx, db)
+}
+
+func tables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return nil, fmt.Errorf("listing sqlite tables: %w", err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, rows.Err()
+}
+
+// ReadTable reads all rows of a table as a JSON body file named after the
+// table, returning the body & a structure with a tabular schema
+func ReadTable(ctx context.Context, path, table string) (qfs.File, *dataset.Structure, error) {
+	db, err := open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	names, err := tables(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := false
+	for _, name := range names {
+		if name == table {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("%w: %q. database has tables: %s", ErrTableNotFound, table, strings.Join(names, ", "))
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM "%s"`, strings.Replace(table, `"`, `""`, -1))
+	return read(ctx, db, table, query)
+}
+
+// ReadQuery reads the result of a query as a JSON body file, returning the
+// body & a structure with a tabular schema. Query results are read in a
+// read-only connection
+func ReadQuery(ctx context.Context, path, query string) (qfs.File, *dataset.Structure, error) {
+	db, err := open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	return read(ctx, db, "query", query)
+}
+
+func read(ctx context.Context, db *sql.DB, name, query string) (qfs.File, *dataset.Structure, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying sqlite: %w", err)
+	}
+	defer rows.Close()
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	cols := make([]*column, len(colTypes))
+	for i, ct := range colTypes {
+		cols[i] = &column{name: ct.Name(), typ: ColumnType(ct.DatabaseTypeName())}
+	}
+
+	body := [][]interface{}{}
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		for i, col := range cols {
+			vals[i] = col.value(vals[i])
+		}
+		body = append(body, vals)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("reading sqlite rows: %w", err)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]interface{}, len(cols))
+	for i, col := range cols {
+		items[i] = map[string]interface{}{
+			"title": col.name,
+			"type":  col.schemaType(),
+		}
+	}
+	st := &dataset.Structure{
+		Format: dataset.JSONDataFormat.String(),
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": items,
+			},
+		},
+	}
+	return qfs.NewMemfileBytes(name+".json", data), st, nil
+}
+
+// ColumnType maps the declared type of a SQLite column to a JSON schema type
+// using SQLite's type affinity rules. Columns without a declared type, like
+// expressions in a query, return an empty string
+func ColumnType(declared string) string {
+	t := strings.ToUpper(declared)
+	switch {
+	case t == "":
+		return ""
+	case strings.Contains(t, "INT"):
+		return "integer"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "string"
+	case strings.Contains(t, "BLOB"):
+		return "string"
+	case strings.Contains(t, "BOOL"):
+		return "boolean"
+	case strings.Contains(t, "DATE"), strings.Contains(t, "TIME"):
+		return "string"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"),
+		strings.Contains(t, "NUMERIC"), strings.Contains(t, "DECIMAL"):
+		return "number"
+	}
+	return "string"
+}
+
+// column tracks the type of a result column. SQLite columns are dynamically
+// typed, so the kinds of values a column holds are recorded to fall back to a
+// wider type when values don't match the declared type
+type column struct {
+	name string
+	typ  string
+	// kinds of non-null values seen
+	ints, floats, bools, strs bool
+}
+
+// value converts a scanned value to a JSON-encodable value
+func (c *column) value(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case int64:
+		if c.typ == "boolean" && (x == 0 || x == 1) {
+			c.bools = true
+			return x == 1
+		}
+		c.ints = true
+		return x
+	case float64:
+		c.floats = true
+		return x
+	case bool:
+		c.bools = true
+		return x
+	case time.Time:
+		c.strs = true
+		return x.Format(time.RFC3339)
+	case []byte:
+		c.strs = true
+		if utf8.Valid(x) {
+			return string(x)
+		}
+		return base64.StdEncoding.EncodeToString(x)
+	case string:
+		c.strs = true
+		return x
+	default:
+		c.strs = true
+		return fmt.Sprintf("%v", x)
+	}
+}
+
+// schemaType gives the JSON schema type of the column: the declared type if
+// all values match it, otherwise the narrowest type that fits all values
+func (c *column) schemaType() string {
+	switch {
+	case c.strs:
+		return "string"
+	case c.bools && (c.ints || c.floats):
+		return "string"
+	case c.bools:
+		return "boolean"
+	case c.floats:
+		return "number"
+	case c.ints:
+		if c.typ == "number" {
+			return "number"
+		}
+		return "integer"
+	case c.typ != "":
+		return c.typ
+	}
+	return "string"
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func makeTestDB(t *testing.T) string {
+	if !Supported {
+		t.Skip("sqlite requires cgo")
+	}
+	dir, err := ioutil.TempDir("", "sqlite_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "reference.sqlite")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE TABLE cities (name TEXT, pop INTEGER, avg_age REAL, in_usa BOOLEAN, founded DATE, notes)`,
+		`INSERT INTO cities VALUES ('toronto', 40000000, 55.5, 0, '1793-08-27', 1)`,
+		`INSERT INTO cities VALUES ('new york', NULL, 44.4, 1, NULL, 'big apple')`,
+		`INSERT INTO cities VALUES ('chicago', 300000, NULL, 1, NULL, NULL)`,
+		`CREATE TABLE "odd ""name""" (id INTEGER PRIMARY KEY, score NUMERIC)`,
+		`INSERT INTO "odd ""name""" (score) VALUES (7)`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+	}
+	return path
+}
+
+func TestIsDatabase(t *testing.T) {
+	cases := map[string]bool{
+		"data.sqlite":       true,
+		"/a/b/DATA.SQLITE3": true,
+		"data.db":           true,
+		"data.csv":          false,
+		"sqlite":            false,
+	}
+	for filename, expect := range cases {
+		if got := IsDatabase(filename); got != expect {
+			t.Errorf("%s: expected %t, got %t", filename, expect, got)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if Supported {
+		t.Skip("sqlite is supported in cgo builds")
+	}
+	ctx := context.Background()
+	if _, err := Tables(ctx, "reference.sqlite"); !errors.Is(err, ErrNoCgo) {
+		t.Errorf("expected listing tables without cgo to return ErrNoCgo. got: %v", err)
+	}
+	if _, _, err := ReadQuery(ctx, "reference.sqlite", "SELECT 1"); !errors.Is(err, ErrNoCgo) {
+		t.Errorf("expected querying without cgo to return ErrNoCgo. got: %v", err)
+	}
+}
+
+func TestTables(t *testing.T) {
+	ctx := context.Background()
+	path := makeTestDB(t)
+	got, err := Tables(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"cities", `odd "name"`}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("tables mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := Tables(ctx, filepath.Join(filepath.Dir(path), "missing.sqlite")); err == nil {
+		t.Error("expected listing tables of a missing database to fail")
+	}
+}
+
+func TestReadTable(t *testing.T) {
+	ctx := context.Background()
+	path := makeTestDB(t)
+
+	f, st, err := ReadTable(ctx, path, "cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileName() != "cities.json" {
+		t.Errorf("expected body filename to be cities.json, got %q", f.FileName())
+	}
+	if st.Format != "json" {
+		t.Errorf("expected json format, got %q", st.Format)
+	}
+	expectSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "name", "type": "string"},
+				map[string]interface{}{"title": "pop", "type": "integer"},
+				map[string]interface{}{"title": "avg_age", "type": "number"},
+				map[string]interface{}{"title": "in_usa", "type": "boolean"},
+				map[string]interface{}{"title": "founded", "type": "string"},
+				// undeclared column holding mixed values widens to string
+				map[string]interface{}{"title": "notes", "type": "string"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectSchema, st.Schema); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []interface{}{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		[]interface{}{"toronto", 40000000.0, 55.5, false, "1793-08-27T00:00:00Z", 1.0},
+		[]interface{}{"new york", nil, 44.4, true, nil, "big apple"},
+		[]interface{}{"chicago", 300000.0, nil, true, nil, nil},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := ReadTable(ctx, path, `odd "name"`); err != nil {
+		t.Errorf("reading a table with a quoted name: %s", err)
+	}
+	if _, _, err := ReadTable(ctx, path, "towns"); !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("expected reading a missing table to return ErrTableNotFound. got: %v", err)
+	}
+}
+
+func TestReadQuery(t *testing.T) {
+	ctx := context.Background()
+	path := makeTestDB(t)
+
+	f, st, err := ReadQuery(ctx, path, `SELECT name, pop * 2 AS double_pop FROM cities WHERE pop IS NOT NULL ORDER BY pop`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "name", "type": "string"},
+				// expressions have no declared type, and infer one from values
+				map[string]interface{}{"title": "double_pop", "type": "integer"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectSchema, st.Schema); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[["chicago",600000],["toronto",80000000]]`; string(data) != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, string(data))
+	}
+
+	if _, _, err := ReadQuery(ctx, path, `DELETE FROM cities`); err == nil {
+		t.Error("expected a query that writes to a read-only database to fail")
+	}
+	if _, _, err := ReadQuery(ctx, path, `SELECT nope FROM cities`); err == nil {
+		t.Error("expected an invalid query to fail")
+	}
+}
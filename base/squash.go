@@ -0,0 +1,136 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// SquashVersions collapses the first n of a list of versions into a single
+// version, rewriting every version that follows on top of the squashed one.
+// versions must be ordered oldest-first & run up to the current head of the
+// dataset. The squashed version keeps the contents & timestamp of the newest
+// version it replaces. Replaced versions are left in the store, unreferenced
+// by the logbook, until they're garbage collected. SquashVersions returns the
+// new head of the dataset
+func SquashVersions(ctx context.Context, r repo.Repo, ref dsref.Ref, versions []dsref.VersionInfo, n int) (*dataset.Dataset, error) {
+	if n < 2 || n > len(versions) {
+		return nil, fmt.Errorf("squashing requires at least two versions")
+	}
+	if ref.InitID == "" {
+		return nil, fmt.Errorf("squashing requires an initID")
+	}
+	fs := r.Filesystem()
+
+	// versions are rewritten through the refstore, keep the link to a working
+	// directory if the dataset has one
+	fsiPath := ""
+	if existing, err := r.GetRef(reporef.DatasetRef{Peername: ref.Username, Name: ref.Name}); err == nil {
+		fsiPath = existing.FSIPath
+	}
+
+	first, err := dsfs.LoadDataset(ctx, fs, versions[0].Path)
+	if err != nil {
+		return nil, err
+	}
+	prevPath := first.PreviousPath
+	var prev *dataset.Dataset
+	if prevPath != "" {
+		if prev, err = loadVersionWithBody(ctx, fs, prevPath); err != nil {
+			return nil, err
+		}
+	}
+
+	squashed := make([]string, n)
+	titles := make([]string, n)
+	for i, vi := range versions[:n] {
+		squashed[i] = vi.Path
+		titles[i] = fmt.Sprintf("  %s", vi.CommitTitle)
+	}
+
+	written := make([]*dataset.Dataset, 0, len(versions)-n+1)
+	replaces := make([][]string, 0, len(versions)-n+1)
+	for i := n - 1; i < len(versions); i++ {
+		ds, err := loadVersionWithBody(ctx, fs, versions[i].Path)
+		if err != nil {
+			return nil, err
+		}
+
+		cm := &dataset.Commit{}
+		if ds.Commit != nil {
+			cm = ds.Commit
+		}
+		if i == n-1 {
+			cm = &dataset.Commit{
+				Qri:       dataset.KindCommit.String(),
+				Author:    cm.Author,
+				Timestamp: cm.Timestamp,
+				Title:     fmt.Sprintf("squashed %d versions", n),
+				Message:   strings.Join(titles, "\n"),
+			}
+			replaces = append(replaces, squashed)
+		} else {
+			cm.Path = ""
+			cm.Signature = ""
+			if cm.Message == "" {
+				cm.Message = cm.Title
+			}
+			replaces = append(replaces, []string{versions[i].Path})
+		}
+		ds.Commit = cm
+		ds.Path = ""
+		ds.PreviousPath = prevPath
+		ds.Peername = ref.Username
+		ds.ProfileID = ref.ProfileID
+		ds.Name = ref.Name
+
+		sw := SaveSwitches{Pin: true, ForceIfNoChanges: true}
+		res, err := CreateDataset(ctx, r, fs.DefaultWriteFS(), ds, prev, sw)
+		if err != nil {
+			return nil, fmt.Errorf("rewriting %s: %w", versions[i].Path, err)
+		}
+		written = append(written, res)
+
+		prevPath = res.Path
+		if prev, err = loadVersionWithBody(ctx, fs, prevPath); err != nil {
+			return nil, err
+		}
+	}
+
+	removed := len(versions)
+	if err := r.Logbook().WriteVersionSquash(ctx, ref.InitID, removed, written, replaces); err != nil {
+		return nil, err
+	}
+
+	head := written[len(written)-1]
+	if fsiPath != "" {
+		vi := dsref.ConvertDatasetToVersionInfo(head)
+		vi.FSIPath = fsiPath
+		if err := repo.PutVersionInfoShim(ctx, r, &vi); err != nil {
+			return nil, err
+		}
+	}
+	return head, nil
+}
+
+func loadVersionWithBody(ctx context.Context, fs qfs.Filesystem, path string) (*dataset.Dataset, error) {
+	ds, err := dsfs.LoadDataset(ctx, fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if ds.BodyPath != "" {
+		body, err := dsfs.LoadBody(ctx, fs, ds)
+		if err != nil {
+			return nil, err
+		}
+		ds.SetBodyFile(body)
+	}
+	return ds, nil
+}
@@ -0,0 +1,298 @@
+package changes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/fsi"
+)
+
+// maxBodyChanges caps the number of changes listed in a body change report.
+// Summary counts always cover all changes
+const maxBodyChanges = 1000
+
+// BodyChangeType enumerates the kinds of structural changes that can occur
+// between two dataset bodies
+type BodyChangeType string
+
+const (
+	// BCAdd indicates a key path exists only on the right side
+	BCAdd = BodyChangeType("add")
+	// BCRemove indicates a key path exists only on the left side
+	BCRemove = BodyChangeType("remove")
+	// BCChange indicates a scalar value changed
+	BCChange = BodyChangeType("change")
+	// BCTypeChange indicates the value at a key path changed type
+	BCTypeChange = BodyChangeType("typeChange")
+	// BCLengthChange indicates an array changed length
+	BCLengthChange = BodyChangeType("lengthChange")
+)
+
+// BodyChange describes a single structural change at a key path. Paths are
+// JSON pointers (RFC 6901), the empty string is the root of the body
+type BodyChange struct {
+	Type      BodyChangeType `json:"type"`
+	Path      string         `json:"path"`
+	LeftType  string         `json:"leftType,omitempty"`
+	RightType string         `json:"rightType,omitempty"`
+	// scalar values are included on both sides of a change. objects & arrays
+	// are only described by their type
+	Left  interface{} `json:"left,omitempty"`
+	Right interface{} `json:"right,omitempty"`
+	// number of elements added to (positive) or removed from (negative) an array
+	LengthDelta int `json:"lengthDelta,omitempty"`
+}
+
+// BodyChangeSummary counts body changes by type
+type BodyChangeSummary struct {
+	Added         int `json:"added"`
+	Removed       int `json:"removed"`
+	Changed       int `json:"changed"`
+	TypeChanged   int `json:"typeChanged"`
+	LengthChanged int `json:"lengthChanged"`
+}
+
+// Total returns the number of changes in the summary
+func (s BodyChangeSummary) Total() int {
+	return s.Added + s.Removed + s.Changed + s.TypeChanged + s.LengthChanged
+}
+
+// BodyChangeComponent is a structural summary of the differences between two
+// bodies of arbitrary JSON. Change reports include it in place of column
+// stats for datasets with non-tabular bodies
+type BodyChangeComponent struct {
+	Summary BodyChangeSummary      `json:"summary"`
+	Changes []*BodyChange          `json:"changes"`
+	About   map[string]interface{} `json:"about,omitempty"`
+	// true when there were more than the listed changes
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func (c *BodyChangeComponent) add(ch *BodyChange) {
+	switch ch.Type {
+	case BCAdd:
+		c.Summary.Added++
+	case BCRemove:
+		c.Summary.Removed++
+	case BCChange:
+		c.Summary.Changed++
+	case BCTypeChange:
+		c.Summary.TypeChanged++
+	case BCLengthChange:
+		c.Summary.LengthChanged++
+	}
+	if len(c.Changes) < maxBodyChanges {
+		c.Changes = append(c.Changes, ch)
+	} else {
+		c.Truncated = true
+	}
+}
+
+// isTabular returns true if a dataset has no structure, or a structure the
+// column-based stats change report supports
+func isTabular(ds *dataset.Dataset) bool {
+	return ds.Structure == nil || ds.Structure.Format == "csv"
+}
+
+// bodyDiff reads the body of both datasets & compares their structure
+func (svc *service) bodyDiff(leftDs, rightDs *dataset.Dataset) (*BodyChangeComponent, error) {
+	left, err := readBody(leftDs)
+	if err != nil {
+		return nil, fmt.Errorf("reading left body: %w", err)
+	}
+	right, err := readBody(rightDs)
+	if err != nil {
+		return nil, fmt.Errorf("reading right body: %w", err)
+	}
+
+	res := BodyStructureDiff(left, right)
+	res.About = EmptyObject{}
+	if left == nil && right == nil {
+		res.About["status"] = fsi.STMissing
+	} else if left == nil {
+		res.About["status"] = fsi.STAdd
+	} else if right == nil {
+		res.About["status"] = fsi.STRemoved
+	} else if res.Summary.Total() == 0 {
+		res.About["status"] = fsi.STUnmodified
+	} else {
+		res.About["status"] = fsi.STChange
+	}
+	return res, nil
+}
+
+// readBody reads an entire dataset body into memory, returning nil if the
+// dataset has no body
+func readBody(ds *dataset.Dataset) (interface{}, error) {
+	if ds.BodyFile() == nil || ds.Structure == nil {
+		return nil, nil
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	return dsio.ReadAll(r)
+}
+
+// BodyStructureDiff compares two JSON trees, listing added, removed and
+// changed key paths, type changes, and array length changes. Elements of
+// arrays are compared by position
+func BodyStructureDiff(left, right interface{}) *BodyChangeComponent {
+	res := &BodyChangeComponent{Changes: []*BodyChange{}}
+	diffValues(res, "", left, right)
+	return res
+}
+
+func diffValues(res *BodyChangeComponent, path string, left, right interface{}) {
+	lt, rt := jsonType(left), jsonType(right)
+	if lt != rt {
+		res.add(&BodyChange{
+			Type:      BCTypeChange,
+			Path:      path,
+			LeftType:  lt,
+			RightType: rt,
+			Left:      scalar(left),
+			Right:     scalar(right),
+		})
+		return
+	}
+
+	switch l := left.(type) {
+	case map[string]interface{}:
+		r := right.(map[string]interface{})
+		for _, key := range unionKeys(l, r) {
+			lv, lok := l[key]
+			rv, rok := r[key]
+			keyPath := path + "/" + escapePointerToken(key)
+			switch {
+			case lok && !rok:
+				res.add(&BodyChange{Type: BCRemove, Path: keyPath, LeftType: jsonType(lv), Left: scalar(lv)})
+			case !lok && rok:
+				res.add(&BodyChange{Type: BCAdd, Path: keyPath, RightType: jsonType(rv), Right: scalar(rv)})
+			default:
+				diffValues(res, keyPath, lv, rv)
+			}
+		}
+	case []interface{}:
+		r := right.([]interface{})
+		if len(l) != len(r) {
+			res.add(&BodyChange{
+				Type:        BCLengthChange,
+				Path:        path,
+				LeftType:    lt,
+				RightType:   rt,
+				LengthDelta: len(r) - len(l),
+			})
+		}
+		n := len(l)
+		if len(r) < n {
+			n = len(r)
+		}
+		for i := 0; i < n; i++ {
+			diffValues(res, fmt.Sprintf("%s/%d", path, i), l[i], r[i])
+		}
+	default:
+		if !scalarEqual(left, right) {
+			res.add(&BodyChange{
+				Type:      BCChange,
+				Path:      path,
+				LeftType:  lt,
+				RightType: rt,
+				Left:      left,
+				Right:     right,
+			})
+		}
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// scalar returns v if it's a scalar value, nil for objects & arrays
+func scalar(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil
+	default:
+		return v
+	}
+}
+
+// scalarEqual compares two scalars of the same JSON type. numbers are
+// compared by value, regardless of their go type
+func scalarEqual(a, b interface{}) bool {
+	if jsonType(a) == "number" {
+		return toFloat(a) == toFloat(b)
+	}
+	return a == b
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointerToken(s string) string {
+	return pointerEscaper.Replace(s)
+}
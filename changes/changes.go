@@ -49,6 +49,9 @@ type ChangeReportResponse struct {
 	Structure   *ChangeReportComponent `json:"structure,omitempty"`
 	Transform   *ChangeReportComponent `json:"transform,omitempty"`
 	Stats       *StatsChangeComponent  `json:"stats,omitempty"`
+	// Body is a structural summary of body changes, populated instead of Stats
+	// when either body isn't tabular
+	Body *BodyChangeComponent `json:"body,omitempty"`
 }
 
 // StatsChangeSummaryFields represents the stats summary
@@ -384,7 +387,9 @@ func (svc *service) columnStatsDelta(left, right interface{}, lCol, rCol *tabula
 }
 
 // Report computes the change report of two sources
-// This takes some assumptions - we work only with tabular data, with header rows and functional structure.json
+// Tabular data is compared by column stats, which assumes CSV bodies with header
+// rows and a functional structure.json. All other bodies get a structural
+// summary of changed key paths
 func (svc *service) Report(ctx context.Context, leftRef, rightRef dsref.Ref, loadSource string) (*ChangeReportResponse, error) {
 	leftDs, err := svc.loader.LoadDataset(ctx, leftRef, loadSource)
 	if err != nil {
@@ -507,17 +512,11 @@ func (svc *service) Report(ctx context.Context, leftRef, rightRef dsref.Ref, loa
 	if leftDs.Structure != nil || rightDs.Structure != nil {
 		res.Structure = &ChangeReportComponent{}
 		if leftDs.Structure != nil {
-			if leftDs.Structure.Format != "csv" {
-				return nil, errors.New("changes are only supported for CSV datasets")
-			}
 			res.Structure.Left = leftDs.Structure
 		} else {
 			res.Structure.Left = EmptyObject{}
 		}
 		if rightDs.Structure != nil {
-			if rightDs.Structure.Format != "csv" {
-				return nil, errors.New("changes are only supported for CSV datasets")
-			}
 			res.Structure.Right = rightDs.Structure
 		} else {
 			res.Structure.Right = EmptyObject{}
@@ -568,6 +567,16 @@ func (svc *service) Report(ctx context.Context, leftRef, rightRef dsref.Ref, loa
 		}
 	}
 
+	// column stats only make sense for tabular data, describe changes to the
+	// shape of other bodies instead
+	if !isTabular(leftDs) || !isTabular(rightDs) {
+		if res.Body, err = svc.bodyDiff(leftDs, rightDs); err != nil {
+			log.Debugf("failed to calculate body change report: %s", err.Error())
+			return nil, qerr.New(err, "failed to calculate body change report")
+		}
+		return res, nil
+	}
+
 	res.Stats, err = svc.statsDiff(ctx, leftDs, rightDs)
 	if err != nil {
 		return nil, err
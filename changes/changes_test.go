@@ -684,6 +684,69 @@ func TestReport(t *testing.T) {
 	}
 }
 
+func TestReportNonTabular(t *testing.T) {
+	ctx := context.Background()
+	run := newTestRunner(t)
+	svc := run.Service
+
+	left := run.saveJSONDataset(t, "config", nil, `{"name":"staging","replicas":2,"regions":["us","eu"],"limits":{"cpu":1}}`)
+	leftDs := run.MustLoadRef(t, left)
+	right := run.saveJSONDataset(t, "config", leftDs, `{"name":"production","replicas":"2","regions":["us","eu","ap"],"debug":false}`)
+
+	res, err := svc.Report(ctx, left, right, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats != nil {
+		t.Errorf("expected non-tabular report to omit column stats")
+	}
+	if res.Body == nil {
+		t.Fatal("expected non-tabular report to include a body structure summary")
+	}
+
+	expect := BodyChangeSummary{Added: 1, Removed: 1, Changed: 1, TypeChanged: 1, LengthChanged: 1}
+	if diff := cmp.Diff(expect, res.Body.Summary); diff != "" {
+		t.Errorf("body summary mismatch (-want +got):\n%s", diff)
+	}
+	if res.Body.About["status"] != fsi.STChange {
+		t.Errorf("expected body status %q, got %q", fsi.STChange, res.Body.About["status"])
+	}
+}
+
+func TestBodyStructureDiff(t *testing.T) {
+	cases := []struct {
+		description string
+		left, right interface{}
+		expect      []*BodyChange
+	}{
+		{"identical", map[string]interface{}{"a": int64(1)}, map[string]interface{}{"a": float64(1)}, []*BodyChange{}},
+		{"scalar change", map[string]interface{}{"a": "x"}, map[string]interface{}{"a": "y"}, []*BodyChange{
+			{Type: BCChange, Path: "/a", LeftType: "string", RightType: "string", Left: "x", Right: "y"},
+		}},
+		{"add & remove", map[string]interface{}{"a": true}, map[string]interface{}{"b/c": map[string]interface{}{}}, []*BodyChange{
+			{Type: BCRemove, Path: "/a", LeftType: "boolean", Left: true},
+			{Type: BCAdd, Path: "/b~1c", RightType: "object"},
+		}},
+		{"type change", map[string]interface{}{"a": []interface{}{}}, map[string]interface{}{"a": "x"}, []*BodyChange{
+			{Type: BCTypeChange, Path: "/a", LeftType: "array", RightType: "string", Right: "x"},
+		}},
+		{"array length & element change", []interface{}{"a", "b"}, []interface{}{"a", "c", "d"}, []*BodyChange{
+			{Type: BCLengthChange, Path: "", LeftType: "array", RightType: "array", LengthDelta: 1},
+			{Type: BCChange, Path: "/1", LeftType: "string", RightType: "string", Left: "b", Right: "c"},
+		}},
+		{"nested", map[string]interface{}{"a": map[string]interface{}{"b": nil}}, map[string]interface{}{"a": map[string]interface{}{"b": int64(1)}}, []*BodyChange{
+			{Type: BCTypeChange, Path: "/a/b", LeftType: "null", RightType: "number", Right: int64(1)},
+		}},
+	}
+
+	for _, c := range cases {
+		got := BodyStructureDiff(c.left, c.right)
+		if diff := cmp.Diff(c.expect, got.Changes); diff != "" {
+			t.Errorf("%s: changes mismatch (-want +got):\n%s", c.description, diff)
+		}
+	}
+}
+
 type testRunner struct {
 	Repo    repo.Repo
 	Service *service
@@ -779,3 +842,28 @@ raleigh,25000,5000.65,false`
 
 	return run.updateDataset(t, ds, alteredBodyData)
 }
+
+func (run *testRunner) saveJSONDataset(t *testing.T, name string, prev *dataset.Dataset, body string) dsref.Ref {
+	t.Helper()
+	r := run.Repo
+	ctx := context.Background()
+
+	ds := &dataset.Dataset{
+		Peername: "peer",
+		Name:     name,
+		Commit:   &dataset.Commit{Title: "update " + name},
+	}
+	if prev != nil {
+		ds.PreviousPath = prev.Path
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+	if err := base.InferStructure(ds); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := base.CreateDataset(ctx, r, r.Filesystem().DefaultWriteFS(), ds, prev, dsfs.SaveSwitches{Pin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dsref.ConvertDatasetToVersionInfo(res).SimpleRef()
+}
@@ -0,0 +1,249 @@
+// Package checks evaluates declarative data quality checks against dataset
+// bodies. Checks are declared in the "checks" field of the meta component:
+//
+//	"meta": {
+//	  "checks": [
+//	    { "type": "notNull", "column": "city", "minRatio": 0.95 },
+//	    { "type": "unique", "column": "id" },
+//	    { "type": "range", "column": "pop", "min": 0, "level": "warn" },
+//	    { "type": "pattern", "column": "zip", "pattern": "^[0-9]{5}$" },
+//	    { "type": "reference", "column": "country", "dataset": "me/countries", "datasetColumn": "code" }
+//	  ]
+//	}
+//
+// Checks run as entries stream through a Runner, so the body is only read
+// once, no matter how many checks a dataset declares
+package checks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	logger "github.com/ipfs/go-log"
+	"github.com/qri-io/dataset"
+)
+
+var log = logger.Logger("checks")
+
+// MetaKey is the field of the meta component checks are declared in
+const MetaKey = "checks"
+
+// ErrCheckFailed indicates at least one error-level check failed
+var ErrCheckFailed = errors.New("data quality check failed")
+
+// Type enumerates the kinds of checks
+type Type string
+
+const (
+	// TypeNotNull checks the ratio of values in a column that aren't null or
+	// the empty string
+	TypeNotNull = Type("notNull")
+	// TypeUnique checks the ratio of distinct values to all non-null values
+	// in a column
+	TypeUnique = Type("unique")
+	// TypeRange checks numeric values fall within a min and/or max
+	TypeRange = Type("range")
+	// TypePattern checks string values match a regular expression
+	TypePattern = Type("pattern")
+	// TypeReference checks values exist in a column of another dataset
+	TypeReference = Type("reference")
+)
+
+// Level is the severity of a failing check
+type Level string
+
+const (
+	// LevelError checks fail the save when they don't pass. Error is the
+	// default level
+	LevelError = Level("error")
+	// LevelWarn checks record a warning when they don't pass
+	LevelWarn = Level("warn")
+)
+
+// Check is a declarative data quality rule for a single column
+type Check struct {
+	// optional human-readable name, defaults to a description of the check
+	Name string `json:"name,omitempty"`
+	Type Type   `json:"type"`
+	// title of a tabular column, or key of an object entry
+	Column string `json:"column"`
+	Level  Level  `json:"level,omitempty"`
+	// minimum ratio of values that must pass, from 0-1. default is 1, every
+	// value must pass
+	MinRatio *float64 `json:"minRatio,omitempty"`
+
+	// range bounds, inclusive
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// regular expression string values must match
+	Pattern string `json:"pattern,omitempty"`
+	// reference to a dataset whose values the column must be drawn from
+	Dataset string `json:"dataset,omitempty"`
+	// column of the referenced dataset, defaults to Column
+	DatasetColumn string `json:"datasetColumn,omitempty"`
+}
+
+// Validate confirms a check is well-formed
+func (c *Check) Validate() error {
+	if c.Column == "" {
+		return fmt.Errorf("check column is required")
+	}
+	if c.MinRatio != nil && (*c.MinRatio < 0 || *c.MinRatio > 1) {
+		return fmt.Errorf("check %q: minRatio must be between 0 and 1", c.String())
+	}
+	switch c.Level {
+	case "", LevelError, LevelWarn:
+	default:
+		return fmt.Errorf("check %q: invalid level %q. must be one of [error,warn]", c.String(), c.Level)
+	}
+
+	switch c.Type {
+	case TypeNotNull, TypeUnique:
+	case TypeRange:
+		if c.Min == nil && c.Max == nil {
+			return fmt.Errorf("check %q: range checks require a min, max, or both", c.String())
+		}
+	case TypePattern:
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("check %q: invalid pattern: %w", c.String(), err)
+		}
+	case TypeReference:
+		if c.Dataset == "" {
+			return fmt.Errorf("check %q: reference checks require a dataset", c.String())
+		}
+	default:
+		return fmt.Errorf("invalid check type %q. must be one of [notNull,unique,range,pattern,reference]", c.Type)
+	}
+	return nil
+}
+
+// String describes a check, using the check name if one is set
+func (c *Check) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s(%s)", c.Type, c.Column)
+}
+
+// level returns the check level, applying the default
+func (c *Check) level() Level {
+	if c.Level == "" {
+		return LevelError
+	}
+	return c.Level
+}
+
+func (c *Check) minRatio() float64 {
+	if c.MinRatio == nil {
+		return 1
+	}
+	return *c.MinRatio
+}
+
+// FromMeta reads checks declared in a meta component. A nil meta or a meta
+// with no checks returns no checks & no error
+func FromMeta(md *dataset.Meta) ([]*Check, error) {
+	if md == nil {
+		return nil, nil
+	}
+	v, ok := md.Meta()[MetaKey]
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	// round trip through JSON to support checks decoded as maps
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("reading checks: %w", err)
+	}
+	checks := []*Check{}
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return nil, fmt.Errorf("reading checks: meta.%s must be a list of checks: %w", MetaKey, err)
+	}
+	for i, c := range checks {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("meta.%s.%d: %w", MetaKey, i, err)
+		}
+	}
+	return checks, nil
+}
+
+// Result is the outcome of evaluating a check against a body
+type Result struct {
+	Check  *Check `json:"check"`
+	Passed bool   `json:"passed"`
+	// number of values the check considered
+	Checked int `json:"checked"`
+	// number of values that didn't pass
+	Failed int `json:"failed"`
+	// ratio of passing values, from 0-1
+	Ratio float64 `json:"ratio"`
+	// human-readable description of the outcome
+	Message string `json:"message"`
+	// up to maxExamples values that didn't pass
+	Examples []interface{} `json:"examples,omitempty"`
+}
+
+// Results is a set of check results
+type Results []*Result
+
+// Failed returns error-level results that didn't pass
+func (rs Results) Failed() Results {
+	res := Results{}
+	for _, r := range rs {
+		if !r.Passed && r.Check.level() == LevelError {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Warnings returns warn-level results that didn't pass
+func (rs Results) Warnings() Results {
+	res := Results{}
+	for _, r := range rs {
+		if !r.Passed && r.Check.level() == LevelWarn {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Err returns an error wrapping ErrCheckFailed if any error-level checks
+// failed
+func (rs Results) Err() error {
+	failed := rs.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(failed))
+	for i, r := range failed {
+		msgs[i] = r.Message
+	}
+	return fmt.Errorf("%w: %s", ErrCheckFailed, strings.Join(msgs, "; "))
+}
+
+// Summary describes results as text, with a line for each check that didn't
+// pass. Summary is empty when there are no results
+func (rs Results) Summary() string {
+	if len(rs) == 0 {
+		return ""
+	}
+	passed := 0
+	for _, r := range rs {
+		if r.Passed {
+			passed++
+		}
+	}
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "checks: %d passed, %d warnings, %d failed", passed, len(rs.Warnings()), len(rs.Failed()))
+	for _, r := range rs {
+		if !r.Passed {
+			fmt.Fprintf(buf, "\n  %s: %s", r.Check.level(), r.Message)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,201 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qfs"
+)
+
+func TestFromMeta(t *testing.T) {
+	md := &dataset.Meta{}
+	if err := md.Set(MetaKey, []interface{}{
+		map[string]interface{}{"type": "notNull", "column": "city", "minRatio": 0.5},
+		map[string]interface{}{"type": "range", "column": "pop", "min": 0, "level": "warn"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := FromMeta(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(cs))
+	}
+	if cs[0].Type != TypeNotNull || cs[0].MinRatio == nil || *cs[0].MinRatio != 0.5 {
+		t.Errorf("unexpected first check: %#v", cs[0])
+	}
+	if cs[1].Level != LevelWarn || cs[1].Min == nil || *cs[1].Min != 0 {
+		t.Errorf("unexpected second check: %#v", cs[1])
+	}
+
+	if cs, err := FromMeta(nil); cs != nil || err != nil {
+		t.Errorf("expected nil meta to have no checks & no error. got: %v, %v", cs, err)
+	}
+
+	bad := []struct {
+		description string
+		checks      interface{}
+		err         string
+	}{
+		{"not a list", "nope", "reading checks: meta.checks must be a list of checks: json: cannot unmarshal string into Go value of type []*checks.Check"},
+		{"no column", []interface{}{map[string]interface{}{"type": "unique"}}, "meta.checks.0: check column is required"},
+		{"unknown type", []interface{}{map[string]interface{}{"type": "vibes", "column": "a"}}, `meta.checks.0: invalid check type "vibes". must be one of [notNull,unique,range,pattern,reference]`},
+		{"range without bounds", []interface{}{map[string]interface{}{"type": "range", "column": "a"}}, `meta.checks.0: check "range(a)": range checks require a min, max, or both`},
+		{"bad pattern", []interface{}{map[string]interface{}{"type": "pattern", "column": "a", "pattern": "("}}, "meta.checks.0: check \"pattern(a)\": invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{"bad level", []interface{}{map[string]interface{}{"type": "unique", "column": "a", "level": "panic"}}, `meta.checks.0: check "unique(a)": invalid level "panic". must be one of [error,warn]`},
+	}
+	for _, c := range bad {
+		t.Run(c.description, func(t *testing.T) {
+			md := &dataset.Meta{}
+			md.Set(MetaKey, c.checks)
+			_, err := FromMeta(md)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if err.Error() != c.err {
+				t.Errorf("error mismatch.\nwant: %s\ngot:  %s", c.err, err)
+			}
+		})
+	}
+}
+
+var citiesStructure = &dataset.Structure{
+	Format: "json",
+	Schema: map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "city", "type": "string"},
+				map[string]interface{}{"title": "pop", "type": "integer"},
+				map[string]interface{}{"title": "country", "type": "string"},
+			},
+		},
+	},
+}
+
+const citiesBody = `[
+	["toronto", 2731571, "CA"],
+	["new york", 8398748, "US"],
+	["", 1000, "CA"],
+	["chatham", -50, "UK"],
+	["toronto", 2731571, "CA"]
+]`
+
+const countriesBody = `[{"code":"CA"},{"code":"US"},{"code":"MX"}]`
+
+func loadCountries(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+	if refstr != "me/countries" {
+		return nil, fmt.Errorf("not found")
+	}
+	ds := &dataset.Dataset{Structure: &dataset.Structure{
+		Format: "json",
+		Schema: dataset.BaseSchemaArray,
+	}}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(countriesBody)))
+	return ds, nil
+}
+
+func float(f float64) *float64 { return &f }
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	cs := []*Check{
+		{Type: TypeNotNull, Column: "city"},
+		{Type: TypeNotNull, Column: "city", MinRatio: float(0.75), Name: "mostly named"},
+		{Type: TypeUnique, Column: "city", Level: LevelWarn},
+		{Type: TypeRange, Column: "pop", Min: float(0)},
+		{Type: TypePattern, Column: "country", Pattern: "^[A-Z]{2}$"},
+		{Type: TypeReference, Column: "country", Dataset: "me/countries", DatasetColumn: "code"},
+	}
+	expect := []struct {
+		passed   bool
+		failed   int
+		examples []interface{}
+	}{
+		{false, 1, []interface{}{""}},
+		{true, 1, []interface{}{""}},
+		{false, 1, []interface{}{"toronto"}},
+		{false, 1, []interface{}{float64(-50)}},
+		{true, 0, nil},
+		{false, 1, []interface{}{"UK"}},
+	}
+
+	rdr, err := dsio.NewEntryReader(citiesStructure, qfs.NewMemfileBytes("body.json", []byte(citiesBody)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(ctx, cs, citiesStructure, rdr, loadCountries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(expect) {
+		t.Fatalf("expected %d results, got %d", len(expect), len(res))
+	}
+	for i, e := range expect {
+		r := res[i]
+		if r.Passed != e.passed || r.Failed != e.failed || fmt.Sprint(r.Examples) != fmt.Sprint(e.examples) {
+			t.Errorf("result %d (%s) mismatch. expected passed: %t failed: %d examples: %v, got: %t %d %v", i, r.Check, e.passed, e.failed, e.examples, r.Passed, r.Failed, r.Examples)
+		}
+	}
+
+	if len(res.Failed()) != 3 {
+		t.Errorf("expected 3 failed checks, got %d", len(res.Failed()))
+	}
+	if len(res.Warnings()) != 1 {
+		t.Errorf("expected 1 warning, got %d", len(res.Warnings()))
+	}
+	if err := res.Err(); !errors.Is(err, ErrCheckFailed) {
+		t.Errorf("expected error to wrap ErrCheckFailed, got %v", err)
+	}
+
+	summary := res.Summary()
+	if !strings.HasPrefix(summary, "checks: 2 passed, 1 warnings, 3 failed") {
+		t.Errorf("unexpected summary: %q", summary)
+	}
+	if !strings.Contains(summary, "warn: unique(city): 1 of 4 values duplicate") {
+		t.Errorf("expected summary to list warnings, got: %q", summary)
+	}
+	if Results(nil).Summary() != "" {
+		t.Error("expected empty results to have an empty summary")
+	}
+}
+
+func TestRunObjectEntries(t *testing.T) {
+	ctx := context.Background()
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	body := `[{"id":1,"email":"a@qri.io"},{"id":2,"email":"b@qri.io"},{"id":2}]`
+	cs := []*Check{
+		{Type: TypeUnique, Column: "id"},
+		{Type: TypeNotNull, Column: "email", MinRatio: float(0.5)},
+	}
+
+	rdr, err := dsio.NewEntryReader(st, qfs.NewMemfileBytes("body.json", []byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(ctx, cs, st, rdr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Passed || res[0].Failed != 1 {
+		t.Errorf("expected unique check to fail once, got: %s", res[0].Message)
+	}
+	if !res[1].Passed || res[1].Failed != 1 {
+		t.Errorf("expected not null check to pass with one failure, got: %s", res[1].Message)
+	}
+
+	if _, err := NewRunner(ctx, []*Check{{Type: TypeReference, Column: "id", Dataset: "me/ids"}}, st, nil); err == nil {
+		t.Error("expected reference check without a loader to error")
+	}
+	if _, err := NewRunner(ctx, []*Check{{Type: TypeUnique, Column: "nope"}}, citiesStructure, nil); err == nil {
+		t.Error("expected check of a missing tabular column to error")
+	}
+}
@@ -0,0 +1,297 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// maxExamples caps the number of failing values a result lists
+const maxExamples = 5
+
+// ReferenceLoader loads a dataset named by a reference check. The returned
+// dataset must have a structure and an open body file
+type ReferenceLoader func(ctx context.Context, refstr string) (*dataset.Dataset, error)
+
+// Runner evaluates checks against a stream of body entries
+type Runner struct {
+	states []*checkState
+}
+
+// checkState accumulates the outcome of a single check
+type checkState struct {
+	check *Check
+	// position of the column in tabular entries, -1 for object entries
+	index int
+
+	checked  int
+	failed   int
+	examples []interface{}
+
+	seen    map[string]struct{}
+	pattern *regexp.Regexp
+	refs    map[string]struct{}
+}
+
+// NewRunner prepares checks for a body with the given structure. Values for
+// reference checks are read with load, which may be nil if there are no
+// reference checks
+func NewRunner(ctx context.Context, checks []*Check, st *dataset.Structure, load ReferenceLoader) (*Runner, error) {
+	r := &Runner{}
+	for _, c := range checks {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		index, err := columnIndex(st, c.Column)
+		if err != nil {
+			return nil, fmt.Errorf("check %q: %w", c.String(), err)
+		}
+
+		s := &checkState{check: c, index: index}
+		switch c.Type {
+		case TypeUnique:
+			s.seen = map[string]struct{}{}
+		case TypePattern:
+			s.pattern = regexp.MustCompile(c.Pattern)
+		case TypeReference:
+			if load == nil {
+				return nil, fmt.Errorf("check %q: can't load referenced dataset %q", c.String(), c.Dataset)
+			}
+			if s.refs, err = referenceValues(ctx, c, load); err != nil {
+				return nil, fmt.Errorf("check %q: %w", c.String(), err)
+			}
+		}
+		r.states = append(r.states, s)
+	}
+	return r, nil
+}
+
+// Run evaluates checks against an entire body
+func Run(ctx context.Context, checks []*Check, st *dataset.Structure, body dsio.EntryReader, load ReferenceLoader) (Results, error) {
+	r, err := NewRunner(ctx, checks, st, load)
+	if err != nil {
+		return nil, err
+	}
+	err = dsio.EachEntry(body, func(i int, ent dsio.Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		return r.WriteEntry(ent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Results(), nil
+}
+
+// WriteEntry evaluates checks against a single body entry
+func (r *Runner) WriteEntry(ent dsio.Entry) error {
+	for _, s := range r.states {
+		s.write(columnValue(ent.Value, s.index, s.check.Column))
+	}
+	return nil
+}
+
+// Results returns the outcome of all checks. Results should be read after all
+// entries are written
+func (r *Runner) Results() Results {
+	res := make(Results, len(r.states))
+	for i, s := range r.states {
+		res[i] = s.result()
+	}
+	return res
+}
+
+func (s *checkState) write(v interface{}) {
+	if s.check.Type == TypeNotNull {
+		s.checked++
+		if isNull(v) {
+			s.fail(v)
+		}
+		return
+	}
+
+	// all other checks ignore nulls, which are the domain of notNull checks
+	if isNull(v) {
+		return
+	}
+	s.checked++
+
+	switch s.check.Type {
+	case TypeUnique:
+		key := valueKey(v)
+		if _, ok := s.seen[key]; ok {
+			s.fail(v)
+			return
+		}
+		s.seen[key] = struct{}{}
+	case TypeRange:
+		f, ok := toFloat(v)
+		if !ok || (s.check.Min != nil && f < *s.check.Min) || (s.check.Max != nil && f > *s.check.Max) {
+			s.fail(v)
+		}
+	case TypePattern:
+		if !s.pattern.MatchString(valueKey(v)) {
+			s.fail(v)
+		}
+	case TypeReference:
+		if _, ok := s.refs[valueKey(v)]; !ok {
+			s.fail(v)
+		}
+	}
+}
+
+func (s *checkState) fail(v interface{}) {
+	s.failed++
+	if len(s.examples) < maxExamples {
+		s.examples = append(s.examples, v)
+	}
+}
+
+func (s *checkState) result() *Result {
+	c := s.check
+	res := &Result{
+		Check:    c,
+		Checked:  s.checked,
+		Failed:   s.failed,
+		Ratio:    1,
+		Examples: s.examples,
+	}
+	if s.checked > 0 {
+		res.Ratio = float64(s.checked-s.failed) / float64(s.checked)
+	}
+	res.Passed = res.Ratio >= c.minRatio()
+
+	verb := map[Type]string{
+		TypeNotNull:   "null",
+		TypeUnique:    "duplicate",
+		TypeRange:     "out of range",
+		TypePattern:   "not matching the pattern",
+		TypeReference: fmt.Sprintf("not found in %s", c.Dataset),
+	}[c.Type]
+	res.Message = fmt.Sprintf("%s: %d of %d values %s", c.String(), s.failed, s.checked, verb)
+	if !res.Passed && c.MinRatio != nil {
+		res.Message += fmt.Sprintf(". %.3f passing is below the minimum of %.3f", res.Ratio, *c.MinRatio)
+	}
+	return res
+}
+
+// referenceValues reads the set of values in a column of a referenced dataset
+func referenceValues(ctx context.Context, c *Check, load ReferenceLoader) (map[string]struct{}, error) {
+	ds, err := load(ctx, c.Dataset)
+	if err != nil {
+		return nil, fmt.Errorf("loading referenced dataset %q: %w", c.Dataset, err)
+	}
+	if ds.Structure == nil || ds.BodyFile() == nil {
+		return nil, fmt.Errorf("referenced dataset %q has no body", c.Dataset)
+	}
+	defer ds.BodyFile().Close()
+
+	col := c.DatasetColumn
+	if col == "" {
+		col = c.Column
+	}
+	index, err := columnIndex(ds.Structure, col)
+	if err != nil {
+		return nil, fmt.Errorf("referenced dataset %q: %w", c.Dataset, err)
+	}
+
+	rdr, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	vals := map[string]struct{}{}
+	err = dsio.EachEntry(rdr, func(i int, ent dsio.Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		if v := columnValue(ent.Value, index, col); !isNull(v) {
+			vals[valueKey(v)] = struct{}{}
+		}
+		return nil
+	})
+	return vals, err
+}
+
+// columnIndex finds the position of a column in tabular entries. Bodies
+// without a tabular schema have object entries, which are indexed by key
+// and return -1
+func columnIndex(st *dataset.Structure, column string) (int, error) {
+	if st == nil || st.Schema == nil {
+		return -1, nil
+	}
+	if t, _ := st.Schema["type"].(string); t != "array" {
+		return -1, nil
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		// array entries without column titles can be addressed by position
+		if i, convErr := strconv.Atoi(column); convErr == nil {
+			return i, nil
+		}
+		return -1, nil
+	}
+	for i, title := range cols.Titles() {
+		if title == column {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(column); err == nil && i >= 0 && i < len(cols) {
+		return i, nil
+	}
+	return 0, fmt.Errorf("column %q not found", column)
+}
+
+// columnValue extracts the value of a column from an entry
+func columnValue(entry interface{}, index int, column string) interface{} {
+	switch e := entry.(type) {
+	case []interface{}:
+		if index >= 0 && index < len(e) {
+			return e[index]
+		}
+	case map[string]interface{}:
+		return e[column]
+	}
+	return nil
+}
+
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && s == ""
+}
+
+// valueKey converts a value to a string for comparison. numbers of different
+// go types compare equal when their values are equal
+func valueKey(v interface{}) string {
+	if f, ok := toFloat(v); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
+	"github.com/spf13/cobra"
+)
+
+// NewAccessCommand creates a `qri access` subcommand for managing who can
+// read, write & push a dataset
+func NewAccessCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &AccessOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "manage access control lists for datasets",
+		Long: `Access control lists grant other profiles rights to a dataset you own.
+Rights are recorded in the dataset's logbook and travel with the dataset when
+it's pushed. Remotes use them to decide who can pull, push, and remove it.
+
+Available rights:
+  read    fetch the dataset, eg: pull from a remote
+  write   modify the dataset, eg: remove it from a remote
+  push    send new versions of the dataset to a remote
+
+Once any access has been granted, remotes deny actions to profiles that
+haven't been granted the matching right. Owners always have all rights.
+Access control lists are enforced alongside the access policy of a remote,
+a grant never allows an action the remote's policy denies.`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	grant := &cobra.Command{
+		Use:   "grant DATASET PROFILE_ID",
+		Short: "give a profile rights to a dataset",
+		Example: `  # Allow a profile to pull and push a dataset:
+  $ qri access grant me/annual_pop QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt --rights read,push`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Grant()
+		},
+	}
+	grant.Flags().StringSliceVar(&o.Rights, "rights", []string{logbook.ACLRead}, "comma separated rights to grant")
+
+	revoke := &cobra.Command{
+		Use:   "revoke DATASET PROFILE_ID",
+		Short: "remove rights to a dataset from a profile",
+		Long: `Revoke removes rights from a profile. Without the --rights flag all access
+is removed.`,
+		Example: `  # Stop a profile from pushing a dataset:
+  $ qri access revoke me/annual_pop QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt --rights push`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Revoke()
+		},
+	}
+	revoke.Flags().StringSliceVar(&o.Rights, "rights", nil, "comma separated rights to revoke")
+
+	list := &cobra.Command{
+		Use:     "list DATASET",
+		Aliases: []string{"ls"},
+		Short:   "show the access control list for a dataset",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	cmd.AddCommand(grant, revoke, list)
+	return cmd
+}
+
+// AccessOptions encapsulates state for the access command & subcommands
+type AccessOptions struct {
+	ioes.IOStreams
+
+	Refstr    string
+	ProfileID string
+	Rights    []string
+
+	AccessMethods *lib.AccessMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *AccessOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Refstr = args[0]
+	}
+	if len(args) > 1 {
+		o.ProfileID = args[1]
+	}
+	o.AccessMethods, err = f.AccessMethods()
+	return err
+}
+
+// Grant executes the access grant command
+func (o *AccessOptions) Grant() error {
+	ctx := context.TODO()
+	p := &lib.AccessParams{Ref: o.Refstr, ProfileID: o.ProfileID, Rights: o.Rights}
+	res, err := o.AccessMethods.Grant(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "granted %s access to %s", strings.Join(o.Rights, ","), o.ProfileID)
+	o.printEntries(res)
+	return nil
+}
+
+// Revoke executes the access revoke command
+func (o *AccessOptions) Revoke() error {
+	ctx := context.TODO()
+	p := &lib.AccessParams{Ref: o.Refstr, ProfileID: o.ProfileID, Rights: o.Rights}
+	res, err := o.AccessMethods.Revoke(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "revoked access from %s", o.ProfileID)
+	o.printEntries(res)
+	return nil
+}
+
+// List executes the access list command
+func (o *AccessOptions) List() error {
+	ctx := context.TODO()
+	res, err := o.AccessMethods.List(ctx, &lib.AccessListParams{Ref: o.Refstr})
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no access has been granted for %s", o.Refstr)
+		return nil
+	}
+	o.printEntries(res)
+	return nil
+}
+
+func (o *AccessOptions) printEntries(entries []logbook.ACLEntry) {
+	for _, e := range entries {
+		fmt.Fprintf(o.Out, "%s\t%s\n", e.ProfileID, strings.Join(e.Rights, ","))
+	}
+}
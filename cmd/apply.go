@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/transform/startf"
 	"github.com/spf13/cobra"
 )
 
@@ -24,12 +27,56 @@ func NewApplyCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 the command completes.
 
 The apply command itself does not commit results to the repository. Use
-the --apply flag on the save command to commit results from transforms.`,
+the --apply flag on the save command to commit results from transforms.
+
+Transform steps that haven't changed since they last ran are skipped, reusing
+their cached results. Use --no-cache to run every step.
+
+Each transform step runs within execution limits set in the transform section
+of config. --max-steps, --step-timeout, and --max-body-size override those
+limits for a single run. Requests to the API from other machines can only
+lower them.
+
+Transforms can only make HTTP requests to the hosts listed in the
+allowedHosts key of transform config, or any host if the list isn't set.
+The list is part of the transform, so apply, save --apply and scheduled runs
+all honor it. --replay answers HTTP requests with the responses recorded by a
+previous run of the dataset (see qri save --record-http), reproducing the run
+without network access.
+
+With --test, apply runs a script's tests instead of the transform. Tests are
+functions named test_* in the script, or in a companion file that ends in
+_test.star (tests for transform.star go in transform_test.star). Tests take
+no arguments, and can call the script's functions along with these builtins:
+
+  new_dataset(ref=None)                 a dataset to pass to transform
+  new_context(config={}, secrets={})    a context to pass to transform
+  assert_eq(actual, expect, msg="")     fail unless actual equals expect
+  assert_true(cond, msg="")             fail unless cond is true
+  fail(msg)                             fail the test
+
+Tests run within the execution limits set in config, and can't make network
+requests. Tests never read from the repo. load_dataset & new_dataset load
+fixtures from local files: dataset documents (.json, .yaml) or body files. Map
+a reference to a fixture with --fixture, or pass a file path relative to the
+script as the reference.`,
 		Example: ` # Apply a transform and display the output:
  $ qri apply --file transform.star
 
  # Apply a transform using an existing dataset version:
- $ qri apply --file transform.star me/my_dataset`,
+ $ qri apply --file transform.star me/my_dataset
+
+ # Apply a transform, failing any step that runs longer than 30 seconds:
+ $ qri apply --file transform.star --step-timeout 30s
+
+ # Reproduce a recorded run offline:
+ $ qri apply --file transform.star --replay RUN_ID me/my_dataset
+
+ # Run a transform's tests, loading me/cities from a local file:
+ $ qri apply --test --file transform.star --fixture me/cities=testdata/cities.csv
+
+ # Run tests matching a pattern, writing results as JSON:
+ $ qri apply --test --file transform.star --run population --format json`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -44,6 +91,16 @@ the --apply flag on the save command to commit results from transforms.`,
 	cmd.Flags().StringVar(&o.FilePath, "file", "", "path of transform script file")
 	cmd.MarkFlagRequired("file")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
+	cmd.Flags().BoolVar(&o.NoCache, "no-cache", false, "run every transform step, ignoring cached step results")
+	cmd.Flags().Uint64Var(&o.MaxSteps, "max-steps", 0, "maximum starlark execution steps per transform step")
+	cmd.Flags().DurationVar(&o.StepTimeout, "step-timeout", 0, "maximum duration of each transform step")
+	cmd.Flags().Int64Var(&o.MaxBodySize, "max-body-size", 0, "maximum size in bytes of a body written by the transform")
+	cmd.Flags().StringVar(&o.ReplayRun, "replay", "", "answer HTTP requests with the responses recorded by a run of the dataset")
+	cmd.Flags().BoolVar(&o.Test, "test", false, "run transform tests instead of the transform")
+	cmd.Flags().StringSliceVar(&o.Fixtures, "fixture", nil, "with --test, load a dataset reference from a local file, as REF=PATH")
+	cmd.Flags().StringVar(&o.TestRun, "run", "", "with --test, only run tests matching a regular expression")
+	cmd.Flags().StringVar(&o.Format, "format", "pretty", "with --test, output format. one of [json,pretty]")
+	cmd.Flags().BoolVarP(&o.Verbose, "verbose", "v", false, "with --test, list every test & its output")
 
 	return cmd
 }
@@ -55,6 +112,19 @@ type ApplyOptions struct {
 	Refs     *RefSelect
 	FilePath string
 	Secrets  []string
+	NoCache  bool
+
+	MaxSteps    uint64
+	StepTimeout time.Duration
+	MaxBodySize int64
+
+	ReplayRun string
+
+	Test     bool
+	Fixtures []string
+	TestRun  string
+	Format   string
+	Verbose  bool
 
 	TransformMethods *lib.TransformMethods
 }
@@ -80,13 +150,16 @@ func (o *ApplyOptions) Complete(f Factory, args []string) (err error) {
 
 // Run executes the apply command
 func (o *ApplyOptions) Run() error {
-	printRefSelect(o.ErrOut, o.Refs)
-
-	var err error
-
 	if !strings.HasSuffix(o.FilePath, ".star") {
 		return errors.New("only transform scripts are supported by --file")
 	}
+	if o.Test {
+		return o.RunTests()
+	}
+
+	printRefSelect(o.ErrOut, o.Refs)
+
+	var err error
 
 	tf := dataset.Transform{
 		ScriptPath: o.FilePath,
@@ -105,6 +178,11 @@ func (o *ApplyOptions) Run() error {
 		Transform:    &tf,
 		ScriptOutput: o.Out,
 		Wait:         true,
+		NoCache:      o.NoCache,
+		MaxSteps:     o.MaxSteps,
+		StepTimeout:  o.StepTimeout,
+		MaxBodySize:  o.MaxBodySize,
+		ReplayRun:    o.ReplayRun,
 	}
 	res, err := o.TransformMethods.Apply(ctx, &params)
 	if err != nil {
@@ -116,5 +194,89 @@ func (o *ApplyOptions) Run() error {
 		return err
 	}
 	printSuccess(o.Out, string(data))
+	printInfo(o.ErrOut, "run ID: %s", res.RunID)
 	return nil
 }
+
+// RunTests executes the apply command in test mode
+func (o *ApplyOptions) RunTests() error {
+	if o.Format != "pretty" && o.Format != "json" {
+		return fmt.Errorf("invalid format %q, must be one of [json,pretty]", o.Format)
+	}
+
+	p := &lib.TransformTestParams{
+		ScriptPath: o.FilePath,
+		Fixtures:   map[string]string{},
+		Run:        o.TestRun,
+	}
+	for _, fx := range o.Fixtures {
+		parts := strings.SplitN(fx, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid fixture %q, must be REF=PATH", fx)
+		}
+		path, err := filepath.Abs(parts[1])
+		if err != nil {
+			return err
+		}
+		p.Fixtures[parts[0]] = path
+	}
+	if len(o.Secrets) > 0 {
+		secrets, err := parseSecrets(o.Secrets...)
+		if err != nil {
+			return err
+		}
+		p.Secrets = secrets
+	}
+
+	report, err := o.TransformMethods.Test(context.TODO(), p)
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+	} else {
+		o.printTestReport(report)
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("%d of %d tests failed", report.Failed(), len(report.Tests))
+	}
+	return nil
+}
+
+// printTestReport writes test results in the style of `go test`
+func (o *ApplyOptions) printTestReport(report *startf.TestReport) {
+	for _, t := range report.Tests {
+		// passing tests are only listed in verbose mode
+		if t.Passed && !o.Verbose {
+			continue
+		}
+		if o.Verbose {
+			fmt.Fprintf(o.Out, "=== RUN   %s\n", t.Name)
+		}
+		for _, line := range t.Output {
+			fmt.Fprintf(o.Out, "    %s\n", line)
+		}
+		if t.Passed {
+			fmt.Fprintf(o.Out, "--- PASS: %s (%.2fs)\n", t.Name, t.Elapsed)
+		} else {
+			fmt.Fprintf(o.Out, "--- FAIL: %s (%.2fs)\n", t.Name, t.Elapsed)
+			fmt.Fprintf(o.Out, "    %s\n", t.Error)
+		}
+	}
+
+	if len(report.Tests) == 0 {
+		fmt.Fprintf(o.Out, "ok  \t%s\t%.3fs [no tests to run]\n", report.Script, report.Elapsed)
+	} else if report.Passed {
+		fmt.Fprintln(o.Out, "PASS")
+		fmt.Fprintf(o.Out, "ok  \t%s\t%.3fs\n", report.Script, report.Elapsed)
+	} else {
+		fmt.Fprintln(o.Out, "FAIL")
+		fmt.Fprintf(o.Out, "FAIL\t%s\t%.3fs\n", report.Script, report.Elapsed)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -82,3 +83,74 @@ func TestApplyModifyBody(t *testing.T) {
 		t.Errorf("contents mismatch, want: %s, got: %s", expectContains, output)
 	}
 }
+
+func TestApplyTest(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_apply_test", "qri_test_apply_test")
+	defer run.Delete()
+
+	err := run.ExecCommand("qri apply --test --file testdata/movies/tf_long_movies.star --fixture me/movies=testdata/movies/body_ten.csv")
+	if err == nil {
+		t.Fatal("expected a failing test to return an error")
+	}
+	if expect := "1 of 4 tests failed"; err.Error() != expect {
+		t.Errorf("error mismatch. want %q, got %q", expect, err)
+	}
+	output := run.GetCommandOutput()
+	for _, expect := range []string{
+		"--- FAIL: test_broken (",
+		"    tf_long_movies_test.star:13: body length: expected 0, got 7\n",
+		"FAIL\ttf_long_movies.star\t",
+	} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected output to contain %q, got:\n%s", expect, output)
+		}
+	}
+	if strings.Contains(output, "test_long_movies") {
+		t.Errorf("expected passing tests to be omitted without --verbose, got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri apply --test -v --run long --file testdata/movies/tf_long_movies.star --fixture me/movies=testdata/movies/body_ten.csv")
+	expect := "=== RUN   test_long_movies\n    found 3 long movies\n--- PASS: test_long_movies ("
+	if !strings.Contains(output, expect) {
+		t.Errorf("expected verbose output to contain %q, got:\n%s", expect, output)
+	}
+	if !strings.Contains(output, "PASS\nok  \ttf_long_movies.star\t") {
+		t.Errorf("expected a passing summary, got:\n%s", output)
+	}
+
+	// running the test file directly picks up the script it tests
+	output = run.MustExec(t, "qri apply --test --format json --run inline --file testdata/movies/tf_long_movies_test.star")
+	res := struct {
+		Passed bool
+		Tests  []struct{ Name string }
+	}{}
+	if err := json.Unmarshal([]byte(output), &res); err != nil {
+		t.Fatalf("unmarshaling json output: %s\n%s", err, output)
+	}
+	if !res.Passed || len(res.Tests) != 1 || res.Tests[0].Name != "test_inline_fixture" {
+		t.Errorf("unexpected json report: %s", output)
+	}
+}
+
+func TestApplyLimits(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_apply_limits", "qri_test_apply_limits")
+	defer run.Delete()
+
+	err := run.ExecCommand("qri apply --file testdata/movies/tf_one_movie.star --max-body-size 4")
+	if err == nil {
+		t.Fatal("expected exceeding the body size limit to error")
+	}
+	if expect := "transform body exceeded the limit of 4 bytes"; err.Error() != expect {
+		t.Errorf("error mismatch. want %q, got %q", expect, err)
+	}
+
+	err = run.ExecCommand("qri apply --file testdata/movies/tf_one_movie.star --max-steps 2")
+	if err == nil {
+		t.Fatal("expected exceeding the step limit to error")
+	}
+	if expect := "transform step exceeded the limit of 2 execution steps"; err.Error() != expect {
+		t.Errorf("error mismatch. want %q, got %q", expect, err)
+	}
+
+	run.MustExec(t, "qri apply --file testdata/movies/tf_one_movie.star --max-body-size 1024 --max-steps 1000 --step-timeout 1m")
+}
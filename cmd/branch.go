@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewBranchCommand creates a `qri branch` subcommand for working with named
+// lines of dataset history
+func NewBranchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &BranchOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "create, list & switch between lines of dataset history",
+		Long: `Branches are named lines of history within a dataset. Every dataset starts
+with a single branch named "main". Creating a branch starts a new line of
+history from the latest version of the active branch.
+
+Saves are always written to the active branch, and the dataset reference
+resolves to the latest version of the active branch. The active branch is
+local to your repo, switching branches doesn't change the history others pull.
+Use ` + "`qri merge`" + ` to combine the changes made on one branch into the active
+branch.`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create DATASET BRANCH",
+		Short: "start a new branch from the active branch",
+		Example: `  # Create a branch for experimental changes:
+  $ qri branch create me/annual_pop experiment`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list DATASET",
+		Aliases: []string{"ls"},
+		Short:   "show all branches of a dataset",
+		Example: `  # List branches, the active branch is marked with an asterisk:
+  $ qri branch list me/annual_pop`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	switchCmd := &cobra.Command{
+		Use:   "switch DATASET BRANCH",
+		Short: "change the active branch of a dataset",
+		Example: `  # Save new versions to the experiment branch:
+  $ qri branch switch me/annual_pop experiment`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Switch()
+		},
+	}
+
+	cmd.AddCommand(create, list, switchCmd)
+	return cmd
+}
+
+// BranchOptions encapsulates state for the branch command & subcommands
+type BranchOptions struct {
+	ioes.IOStreams
+
+	Refstr string
+	Branch string
+
+	BranchMethods *lib.BranchMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *BranchOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Refstr = args[0]
+	}
+	if len(args) > 1 {
+		o.Branch = args[1]
+	}
+	o.BranchMethods, err = f.BranchMethods()
+	return err
+}
+
+// Create executes the branch create command
+func (o *BranchOptions) Create() error {
+	ctx := context.TODO()
+	p := &lib.BranchParams{Ref: o.Refstr, Branch: o.Branch}
+	res, err := o.BranchMethods.Create(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "created branch %s", res.Name)
+	return nil
+}
+
+// List executes the branch list command
+func (o *BranchOptions) List() error {
+	ctx := context.TODO()
+	p := &lib.BranchListParams{Ref: o.Refstr}
+	res, err := o.BranchMethods.List(ctx, p)
+	if err != nil {
+		return err
+	}
+	for _, b := range res {
+		marker := " "
+		if b.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(o.Out, "%s %s\t%d versions\t%s\n", marker, b.Name, b.Versions, b.HeadPath)
+	}
+	return nil
+}
+
+// Switch executes the branch switch command
+func (o *BranchOptions) Switch() error {
+	ctx := context.TODO()
+	p := &lib.BranchParams{Ref: o.Refstr, Branch: o.Branch}
+	res, err := o.BranchMethods.Switch(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "switched to branch %s", res.Name)
+	return nil
+}
+
+// NewMergeCommand creates a `qri merge` cobra command for combining branches
+func NewMergeCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &MergeOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "merge DATASET BRANCH",
+		Short: "combine changes from a branch into the active branch",
+		Long: `Merge combines the changes made to a branch into the active branch of a
+dataset, saving the result as a new version of the active branch.
+
+Merge compares the latest versions of both branches with the most recent
+version they have in common. Changes made to the meta, structure, and body
+components on only one branch are combined. If both branches changed the
+same value in different ways merge reports the conflicting locations and
+saves nothing.`,
+		Example: `  # Merge the experiment branch into the active branch:
+  $ qri merge me/annual_pop experiment`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Title, "title", "t", "", "title of commit message for the merge version")
+	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for the merge version")
+
+	return cmd
+}
+
+// MergeOptions encapsulates state for the merge command
+type MergeOptions struct {
+	ioes.IOStreams
+
+	Refstr  string
+	Branch  string
+	Title   string
+	Message string
+
+	BranchMethods *lib.BranchMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *MergeOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) == 2 {
+		o.Refstr = args[0]
+		o.Branch = args[1]
+	}
+	o.BranchMethods, err = f.BranchMethods()
+	return err
+}
+
+// Run executes the merge command
+func (o *MergeOptions) Run() error {
+	ctx := context.TODO()
+	p := &lib.MergeParams{
+		Ref:     o.Refstr,
+		Branch:  o.Branch,
+		Title:   o.Title,
+		Message: o.Message,
+	}
+	res, err := o.BranchMethods.Merge(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if res.UpToDate {
+		printInfo(o.Out, "already up to date")
+		return nil
+	}
+	if len(res.Conflicts) > 0 {
+		for _, c := range res.Conflicts {
+			printWarning(o.ErrOut, "conflict: %s", c)
+		}
+		return errors.New(fmt.Errorf("merge conflict"), fmt.Sprintf("merge failed with %d conflicts, nothing was saved", len(res.Conflicts)))
+	}
+
+	printSuccess(o.Out, "merged branch %s\nnew version: %s", o.Branch, res.Dataset.Path)
+	if res.Stat != nil {
+		return printDiff(o.Out, &lib.DiffResponse{Stat: res.Stat, Diff: res.Diff}, true)
+	}
+	return nil
+}
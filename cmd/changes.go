@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/changes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewChangesCommand creates a `qri changes` cobra command for summarizing
+// changes between dataset versions
+func NewChangesCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ChangesOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "changes LEFT RIGHT",
+		Short: "summarize changes between two dataset versions",
+		Long: `Changes reports which components changed between two versions of a dataset,
+and summarizes how the body changed. Tabular (CSV) bodies are compared column by
+column using stats. All other bodies are compared by structure: added, removed
+and changed key paths, type changes, and changes in array length.`,
+		Example: `  # Compare two versions:
+  $ qri changes me/annual_pop@/ipfs/QmFirst me/annual_pop@/ipfs/QmSecond`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "pretty", "output format. one of [json,pretty]")
+
+	return cmd
+}
+
+// ChangesOptions encapsulates state for the changes command
+type ChangesOptions struct {
+	ioes.IOStreams
+
+	Left   string
+	Right  string
+	Format string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ChangesOptions) Complete(f Factory, args []string) (err error) {
+	o.Left, o.Right = args[0], args[1]
+	o.DatasetMethods, err = f.DatasetMethods()
+	return err
+}
+
+// Run executes the changes command
+func (o *ChangesOptions) Run() error {
+	res := &lib.ChangeReport{}
+	if err := o.DatasetMethods.ChangeReport(&lib.ChangeReportParams{LeftRefstr: o.Left, RightRefstr: o.Right}, res); err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		return json.NewEncoder(o.Out).Encode(res)
+	}
+	printChangeReport(o.Out, res)
+	return nil
+}
+
+func printChangeReport(w io.Writer, res *lib.ChangeReport) {
+	components := []struct {
+		name string
+		c    *changes.ChangeReportComponent
+	}{
+		{"commit", res.Commit},
+		{"meta", res.Meta},
+		{"readme", res.Readme},
+		{"structure", res.Structure},
+		{"transform", res.Transform},
+	}
+	for _, comp := range components {
+		if comp.c != nil {
+			fmt.Fprintf(w, "%-10s %v\n", comp.name, comp.c.About["status"])
+		}
+	}
+
+	if res.Body != nil {
+		b := res.Body
+		fmt.Fprintf(w, "%-10s %v\n\n", "body", b.About["status"])
+		fmt.Fprintf(w, "%d added, %d removed, %d changed, %d type changes, %d length changes\n",
+			b.Summary.Added, b.Summary.Removed, b.Summary.Changed, b.Summary.TypeChanged, b.Summary.LengthChanged)
+		for _, ch := range b.Changes {
+			path := ch.Path
+			if path == "" {
+				path = "/"
+			}
+			switch ch.Type {
+			case changes.BCAdd:
+				fmt.Fprintf(w, "  + %s (%s)\n", path, ch.RightType)
+			case changes.BCRemove:
+				fmt.Fprintf(w, "  - %s (%s)\n", path, ch.LeftType)
+			case changes.BCChange:
+				fmt.Fprintf(w, "  ~ %s: %v -> %v\n", path, ch.Left, ch.Right)
+			case changes.BCTypeChange:
+				fmt.Fprintf(w, "  ~ %s: %s -> %s\n", path, ch.LeftType, ch.RightType)
+			case changes.BCLengthChange:
+				fmt.Fprintf(w, "  # %s: length %+d\n", path, ch.LengthDelta)
+			}
+		}
+		if b.Truncated {
+			fmt.Fprintf(w, "  ... and %d more\n", b.Summary.Total()-len(b.Changes))
+		}
+		return
+	}
+
+	if res.Stats != nil {
+		fmt.Fprintln(w, "")
+		cols := res.Stats.Columns
+		sort.Slice(cols, func(i, j int) bool { return cols[i].Title < cols[j].Title })
+		for _, col := range cols {
+			fmt.Fprintf(w, "  %-20s %v\n", col.Title, col.About["status"])
+		}
+	}
+}
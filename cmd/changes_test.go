@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangesNestedBody(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_changes", "qri_test_changes")
+	defer run.Delete()
+
+	tmpDir := run.MakeTmpDir(t, "changes")
+	bodyOne := filepath.Join(tmpDir, "config_1.json")
+	run.MustWriteFile(t, bodyOne, `{"name":"api","limits":{"rate":10},"hosts":["a","b"]}`)
+	bodyTwo := filepath.Join(tmpDir, "config_2.json")
+	run.MustWriteFile(t, bodyTwo, `{"name":"api","limits":{"rate":"10/s","burst":5},"hosts":["a","b","c"]}`)
+
+	run.MustExec(t, fmt.Sprintf("qri save --body %s me/config", bodyOne))
+	left := run.GetPathForDataset(t, 0)
+	run.MustExec(t, fmt.Sprintf("qri save --body %s me/config", bodyTwo))
+	right := run.GetPathForDataset(t, 0)
+
+	output := run.MustExec(t, fmt.Sprintf("qri changes me/config@%s me/config@%s", left, right))
+	expect := `commit     modified
+structure  modified
+body       modified
+
+1 added, 0 removed, 0 changed, 1 type changes, 1 length changes
+  # /hosts: length +1
+  + /limits/burst (number)
+  ~ /limits/rate: number -> string
+`
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := run.ExecCommand("qri changes me/config"); err == nil {
+		t.Error("expected changes with a single dataset to fail")
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"path/filepath"
 	"strings"
 
@@ -30,7 +31,16 @@ qri diff works on structured data. qri diffs are measured in elements
 (think cells in a spreadsheet), each change is either an insert (added 
 elements), delete (removed elements), or update (changed values).
 
-Each change has a path that locates it within the document`,
+Each change has a path that locates it within the document
+
+Tabular bodies can be diffed row-by-row by declaring key columns with a
+"primaryKey" field in the structure schema. When both sides of a body diff
+declare the same key, rows are matched by key & the diff lists added, removed
+and modified rows, with the cells that changed in each modified row
+
+Diffs of the body, meta, or structure component of a dataset can be written
+to a patch file with --output. Patches record the version they were created
+from, and can be applied with 'qri patch'`,
 		Example: `  # Diff between a latest version & the next one back:
   $ qri diff me/annual_pop
 
@@ -47,7 +57,10 @@ Each change has a path that locates it within the document`,
   $ qri diff a.json b.json
 
   # Diff a json & csv file:
-  $ qri diff some_table.csv b.json`,
+  $ qri diff some_table.csv b.json
+
+  # Write the changes between two bodies to a patch file:
+  $ qri diff body me/annual_pop me/annual_pop_fixes --output patch.json`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -61,6 +74,7 @@ Each change has a path that locates it within the document`,
 
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "pretty", "output format. one of [json,pretty]")
 	cmd.Flags().BoolVar(&o.Summary, "summary", false, "just output the summary")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "path to write a patch file to")
 
 	return cmd
 }
@@ -73,6 +87,7 @@ type DiffOptions struct {
 	Selector string
 	Format   string
 	Summary  bool
+	Output   string
 
 	DatasetMethods *lib.DatasetMethods
 }
@@ -142,6 +157,10 @@ func (o *DiffOptions) Run() (err error) {
 		return err
 	}
 
+	if o.Output != "" {
+		return o.writePatch(res)
+	}
+
 	if o.Format == "json" {
 		json.NewEncoder(o.Out).Encode(res)
 		return
@@ -149,3 +168,20 @@ func (o *DiffOptions) Run() (err error) {
 
 	return printDiff(o.Out, res, o.Summary)
 }
+
+// writePatch writes a diff result to the output path as a patch file
+func (o *DiffOptions) writePatch(res *lib.DiffResponse) error {
+	pt, err := lib.NewPatch(o.Selector, res)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pt, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(o.Output, data, 0644); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "wrote patch to %s", o.Output)
+	return nil
+}
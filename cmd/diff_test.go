@@ -3,6 +3,9 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -92,7 +95,7 @@ func TestDiffRun(t *testing.T) {
 				Selector: "meta",
 				Format:   "json",
 			},
-			`{"stat":{"leftNodes":4,"rightNodes":4,"leftWeight":147,"rightWeight":145,"inserts":2,"deletes":2},"diff":[["-","path","/mem/QmZQNhYYVRx8LyMmPV9mqzVZVEeZKpso4Ywu7nwyWvT4X4"],["+","path","/mem/QmWX9MV7ms5QXVGt26gXAbp5z8TdfamUgVBdzxSqhWhPzV"],[" ","qri","md:0"],["-","title","example movie data"],["+","title","example city data"]]}
+			`{"base":"/mem/QmQPS7Nf6dG8zosyAA8zYd64gaLBTAzYsVhMkaMCgCXJST","stat":{"leftNodes":4,"rightNodes":4,"leftWeight":147,"rightWeight":145,"inserts":2,"deletes":2},"diff":[["-","path","/mem/QmZQNhYYVRx8LyMmPV9mqzVZVEeZKpso4Ywu7nwyWvT4X4"],["+","path","/mem/QmWX9MV7ms5QXVGt26gXAbp5z8TdfamUgVBdzxSqhWhPzV"],[" ","qri","md:0"],["-","title","example movie data"],["+","title","example city data"]]}
 `,
 		},
 	}
@@ -256,3 +259,48 @@ func TestDiffKnownFilenameComponent(t *testing.T) {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
 }
+
+// Test that bodies with a primary key are diffed row-by-row
+func TestDiffPrimaryKey(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_diff_primary_key", "qri_test_diff_primary_key")
+	defer run.Delete()
+
+	tmpDir := run.MakeTmpDir(t, "diff_primary_key")
+	structurePath := filepath.Join(tmpDir, "structure.json")
+	run.MustWriteFile(t, structurePath, `{
+  "format": "csv",
+  "formatConfig": {"headerRow": true},
+  "schema": {
+    "type": "array",
+    "primaryKey": ["city"],
+    "items": {
+      "type": "array",
+      "items": [{"title": "city", "type": "string"}, {"title": "pop", "type": "integer"}]
+    }
+  }
+}`)
+	bodyOne := filepath.Join(tmpDir, "cities_1.csv")
+	run.MustWriteFile(t, bodyOne, "city,pop\ntoronto,50000000\nnew york,8500000\nchicago,300000\n")
+	bodyTwo := filepath.Join(tmpDir, "cities_2.csv")
+	run.MustWriteFile(t, bodyTwo, "city,pop\nchatham,35000\ntoronto,50000000\nnew york,8600000\n")
+
+	run.MustExec(t, fmt.Sprintf("qri save --body %s --file %s me/cities", bodyOne, structurePath))
+	run.MustExec(t, fmt.Sprintf("qri save --body %s me/cities", bodyTwo))
+	output := run.MustExec(t, "qri diff body me/cities")
+
+	expect := `3 rows -> 3 rows. 1 added. 1 removed. 1 modified.
+
++ ["chatham"]: ["chatham",35000]
+~ ["new york"]
+  pop: 8500000 -> 8600000
+- ["chicago"]: ["chicago",300000]
+`
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+	// diffing the whole dataset diffs the body by key too
+	output = run.MustExec(t, "qri diff me/cities")
+	if !strings.HasSuffix(output, "\n"+expect) {
+		t.Errorf("expected dataset diff to end with the row diff. got:\n%s", output)
+	}
+}
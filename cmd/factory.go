@@ -38,6 +38,14 @@ type Factory interface {
 	FSIMethods() (*lib.FSIMethods, error)
 	RenderMethods() (*lib.RenderMethods, error)
 	TransformMethods() (*lib.TransformMethods, error)
+	BranchMethods() (*lib.BranchMethods, error)
+	AccessMethods() (*lib.AccessMethods, error)
+	JobMethods() (*lib.JobMethods, error)
+	RunMethods() (*lib.RunMethods, error)
+	ScheduleMethods() (*lib.ScheduleMethods, error)
+	SecretMethods() (*lib.SecretMethods, error)
+	StatsMethods() (*lib.StatsMethods, error)
+	RepoMethods() (*lib.RepoMethods, error)
 }
 
 // StandardRepoPath returns qri paths based on the QRI_PATH environment
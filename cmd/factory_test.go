@@ -190,3 +190,43 @@ func (t TestFactory) RenderMethods() (*lib.RenderMethods, error) {
 func (t TestFactory) TransformMethods() (*lib.TransformMethods, error) {
 	return lib.NewTransformMethods(t.inst), nil
 }
+
+// BranchMethods generates a lib.BranchMethods from internal state
+func (t TestFactory) BranchMethods() (*lib.BranchMethods, error) {
+	return lib.NewBranchMethods(t.inst), nil
+}
+
+// AccessMethods generates a lib.AccessMethods from internal state
+func (t TestFactory) AccessMethods() (*lib.AccessMethods, error) {
+	return lib.NewAccessMethods(t.inst), nil
+}
+
+// JobMethods generates a lib.JobMethods from internal state
+func (t TestFactory) JobMethods() (*lib.JobMethods, error) {
+	return lib.NewJobMethods(t.inst), nil
+}
+
+// RunMethods generates a lib.RunMethods from internal state
+func (t TestFactory) RunMethods() (*lib.RunMethods, error) {
+	return lib.NewRunMethods(t.inst), nil
+}
+
+// ScheduleMethods generates a lib.ScheduleMethods from internal state
+func (t TestFactory) ScheduleMethods() (*lib.ScheduleMethods, error) {
+	return lib.NewScheduleMethods(t.inst), nil
+}
+
+// SecretMethods generates a lib.SecretMethods from internal state
+func (t TestFactory) SecretMethods() (*lib.SecretMethods, error) {
+	return lib.NewSecretMethods(t.inst), nil
+}
+
+// StatsMethods generates a lib.StatsMethods from internal state
+func (t TestFactory) StatsMethods() (*lib.StatsMethods, error) {
+	return lib.NewStatsMethods(t.inst), nil
+}
+
+// RepoMethods generates a lib.RepoMethods from internal state
+func (t TestFactory) RepoMethods() (*lib.RepoMethods, error) {
+	return lib.NewRepoMethods(t.inst), nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewFsckCommand creates a new `qri fsck` cobra command for checking the repo
+// for inconsistencies
+func NewFsckCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &FsckOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "check the repo for inconsistencies",
+		Long: `Fsck checks the repo for inconsistencies, treating the logbook as the source of
+truth. It checks that:
+
+  * signed logs in the logbook match the key of their author
+  * the head of every dataset in the reference store is stored in full on
+    the repo filesystem
+  * the dataset cache & reference store list the same datasets and versions
+    as the logbook. Datasets with a latest version that isn't stored locally,
+    like datasets with logs fetched from a remote, are skipped unless the
+    dataset cache or reference store lists them, in which case the latest
+    version is reported as missing blocks
+
+Each problem is printed on its own line as the kind of problem, the dataset or
+log it concerns, and a description. fsck exits with an error if it finds any
+problems.
+
+Use --repair to rebuild the dataset cache & reference store from the logbook.
+Links to working directories are kept. Signature mismatches and missing blocks
+can't be repaired.`,
+		Example: `  # Check the repo:
+  $ qri fsck
+
+  # Check the repo, rebuilding references from the logbook:
+  $ qri fsck --repair`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Repair, "repair", false, "rebuild the dataset cache & reference store from the logbook")
+
+	return cmd
+}
+
+// FsckOptions encapsulates state for the fsck command
+type FsckOptions struct {
+	ioes.IOStreams
+
+	Repair bool
+
+	RepoMethods *lib.RepoMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *FsckOptions) Complete(f Factory) (err error) {
+	o.RepoMethods, err = f.RepoMethods()
+	return err
+}
+
+// Run executes the fsck command
+func (o *FsckOptions) Run() error {
+	ctx := context.TODO()
+	res, err := o.RepoMethods.Fsck(ctx, &lib.FsckParams{Repair: o.Repair})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range res.Problems {
+		fmt.Fprintln(o.Out, p.String())
+	}
+
+	if !res.Repaired {
+		if len(res.Problems) > 0 {
+			return fmt.Errorf("found %d problems", len(res.Problems))
+		}
+		printSuccess(o.ErrOut, "no problems found")
+		return nil
+	}
+
+	if len(res.Remaining) > 0 {
+		printInfo(o.ErrOut, "rebuilt references from the logbook, problems that remain:")
+		for _, p := range res.Remaining {
+			fmt.Fprintln(o.ErrOut, p.String())
+		}
+		return fmt.Errorf("found %d problems, %d couldn't be repaired", len(res.Problems), len(res.Remaining))
+	}
+	printSuccess(o.ErrOut, "repaired %d problems", len(res.Problems))
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestFsck(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_fsck", "qri_test_fsck")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	first := run.GetPathForDataset(t, 0)
+	run.MustExec(t, "qri save --body=testdata/movies/body_twenty.csv me/movies")
+	head := run.GetPathForDataset(t, 0)
+
+	if output := run.MustExec(t, "qri fsck"); output != "" {
+		t.Errorf("expected a consistent repo to have no problems. got:\n%s", output)
+	}
+
+	// point the refstore at an old version & add a dataset the logbook has
+	// never seen
+	corruptRefstore(t, run, func(r repo.Repo) {
+		ref, err := r.GetRef(reporef.DatasetRef{Peername: "test_peer_fsck", Name: "movies"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref.Path = first
+		if err := r.PutRef(ref); err != nil {
+			t.Fatal(err)
+		}
+		ghost := reporef.DatasetRef{Peername: "test_peer_fsck", ProfileID: ref.ProfileID, Name: "ghost", Path: head}
+		if err := r.PutRef(ghost); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	err := run.ExecCommand("qri fsck")
+	if err == nil || err.Error() != "found 2 problems" {
+		t.Errorf("expected fsck to report 2 problems. got: %v", err)
+	}
+	output := run.GetCommandOutput()
+	expect := []string{
+		"refstore\ttest_peer_fsck/movies\thead is " + first + ", logbook head is " + head,
+		"refstore\ttest_peer_fsck/ghost\tdataset isn't in the logbook",
+	}
+	for _, line := range expect {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected output to contain %q. got:\n%s", line, output)
+		}
+	}
+
+	run.MustExec(t, "qri fsck --repair")
+	if errOut := run.GetCommandErrOutput(); !strings.Contains(errOut, "repaired 2 problems") {
+		t.Errorf("expected repair to fix both problems. got: %q", errOut)
+	}
+	if output := run.MustExec(t, "qri fsck"); output != "" {
+		t.Errorf("expected no problems after repair. got:\n%s", output)
+	}
+	if got := run.GetPathForDataset(t, 0); got != head {
+		t.Errorf("expected repair to restore the head of me/movies. want: %s, got: %s", head, got)
+	}
+}
+
+// corruptRefstore edits the test runner's refstore without updating the
+// logbook
+func corruptRefstore(t *testing.T, run *TestRunner, edit func(r repo.Repo)) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := run.RepoRoot.Repo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(r)
+	cancel()
+	<-r.Done()
+}
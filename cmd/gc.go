@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewGCCommand creates a new `qri gc` cobra command for collecting
+// unreferenced data from the repo
+func NewGCCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &GCOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "remove data the repo no longer needs",
+		Long: `GC (garbage collection) frees space used by data qri no longer references.
+Removing versions of a dataset, failed saves & abandoned pulls can leave
+dataset versions pinned to the repo filesystem that aren't part of any dataset
+history. gc unpins these versions, removes filesystem blocks that aren't
+reachable from a pin, and drops cached stats for versions that no longer exist.
+Transform run records are dropped once a dataset has more runs than the
+keepruns limit in the transform section of config, oldest runs first.
+
+Data pinned to the filesystem outside of qri, and HTTP recordings of transform
+runs that are still in a dataset history are never removed.
+
+Use --dry-run to see what gc would remove without removing anything.`,
+		Example: `  # See how much space garbage collection would reclaim:
+  $ qri gc --dry-run
+
+  # Collect garbage:
+  $ qri gc`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "list what would be removed without removing anything")
+
+	return cmd
+}
+
+// GCOptions encapsulates state for the gc command
+type GCOptions struct {
+	ioes.IOStreams
+
+	DryRun bool
+
+	RepoMethods *lib.RepoMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *GCOptions) Complete(f Factory) (err error) {
+	o.RepoMethods, err = f.RepoMethods()
+	return err
+}
+
+// Run executes the gc command
+func (o *GCOptions) Run() error {
+	ctx := context.TODO()
+	res, err := o.RepoMethods.GC(ctx, &lib.GCParams{DryRun: o.DryRun})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range res.Unpinned {
+		fmt.Fprintf(o.Out, "version\t%s\n", path)
+	}
+	for _, ent := range res.StatsRemoved {
+		fmt.Fprintf(o.Out, "stats\t%s\n", ent.Key)
+	}
+	for _, id := range res.RunsRemoved {
+		fmt.Fprintf(o.Out, "run\t%s\n", id)
+	}
+
+	summary := fmt.Sprintf("%d unreferenced versions, %d blocks (%s), %d cached stats (%s), %d run records",
+		len(res.Unpinned),
+		res.Blocks, humanize.Bytes(res.BlocksReclaimed),
+		len(res.StatsRemoved), humanize.Bytes(uint64(res.StatsReclaimed)),
+		len(res.RunsRemoved))
+	if o.DryRun {
+		printInfo(o.ErrOut, "gc would remove %s, reclaiming %s", summary, humanize.Bytes(res.Reclaimed()))
+		return nil
+	}
+	printSuccess(o.ErrOut, "removed %s, reclaimed %s", summary, humanize.Bytes(res.Reclaimed()))
+	return nil
+}
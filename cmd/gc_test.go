@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/event"
+)
+
+func TestGC(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_gc", "qri_test_gc")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	run.MustExec(t, "qri save --body=testdata/movies/body_twenty.csv me/movies")
+
+	if output := run.MustExec(t, "qri gc --dry-run"); output != "" {
+		t.Errorf("expected a clean repo to have nothing to collect. got:\n%s", output)
+	}
+
+	// a version that's pinned but never made it into the logbook, like the
+	// result of a failed save
+	orphan := writeUnreferencedVersion(t, run)
+	// removing a version leaves its blocks behind
+	run.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/movies")
+	run.MustExec(t, "qri remove me/movies --revisions 1")
+
+	output := run.MustExec(t, "qri gc --dry-run")
+	if expect := "version\t" + orphan + "\n"; output != expect {
+		t.Errorf("dry run output mismatch. expected: %q, got: %q", expect, output)
+	}
+	if !strings.Contains(run.GetCommandErrOutput(), "gc would remove 1 unreferenced versions") {
+		t.Errorf("expected dry run to report what would be removed. got: %q", run.GetCommandErrOutput())
+	}
+	// dry runs don't remove anything
+	if output := run.MustExec(t, "qri gc --dry-run"); !strings.Contains(output, orphan) {
+		t.Errorf("expected dry run to leave the unreferenced version in place. got:\n%s", output)
+	}
+
+	if output := run.MustExec(t, "qri gc"); !strings.Contains(output, orphan) {
+		t.Errorf("expected gc to unpin the unreferenced version. got:\n%s", output)
+	}
+	if errOut := run.GetCommandErrOutput(); strings.Contains(errOut, "versions, 0 blocks") {
+		t.Errorf("expected gc to collect blocks. got: %q", errOut)
+	}
+	if output := run.MustExec(t, "qri gc --dry-run"); output != "" {
+		t.Errorf("expected nothing left to collect. got:\n%s", output)
+	}
+
+	// referenced versions are untouched
+	run.MustExec(t, "qri get body me/movies")
+	if output := run.MustExec(t, "qri log me/movies"); strings.Count(output, "Commit:") != 2 {
+		t.Errorf("expected two versions of me/movies to remain. got:\n%s", output)
+	}
+}
+
+// writeUnreferencedVersion writes a dataset version directly to the test
+// runner's filesystem, without recording it in the logbook
+func writeUnreferencedVersion(t *testing.T, run *TestRunner) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := run.RepoRoot.Repo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "never recorded"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1,2,3]`)))
+	fs := r.Filesystem()
+	path, err := dsfs.CreateDataset(ctx, fs, fs.DefaultWriteFS(), event.NilBus, ds, nil, r.Profiles().Owner().PrivKey, dsfs.SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	<-r.Done()
+	return path
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/ioes"
 	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/base/columnar"
 	"github.com/qri-io/qri/base/component"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
@@ -33,7 +34,10 @@ dataset and its fields.`,
   $ qri get meta me/annual_pop
 
   # Print the dataset body size to the console:
-  $ qri get structure.length me/annual_pop`,
+  $ qri get structure.length me/annual_pop
+
+  # Write the dataset body to a parquet file:
+  $ qri get body --format parquet -o annual_pop.parquet me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -50,7 +54,7 @@ dataset and its fields.`,
 		},
 	}
 
-	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json, yaml]")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json, yaml]. body also accepts [csv, parquet, arrow]")
 	cmd.Flags().BoolVar(&o.Pretty, "pretty", false, "whether to print output with indentation, only for json format")
 	cmd.Flags().IntVar(&o.PageSize, "page-size", -1, "for body, limit how many entries to get per page")
 	cmd.Flags().IntVar(&o.Page, "page", -1, "for body, page at which to get entries")
@@ -101,6 +105,10 @@ func (o *GetOptions) Complete(f Factory, args []string) (err error) {
 		return
 	}
 
+	if columnar.IsFormat(o.Format) && o.Selector != "body" {
+		return fmt.Errorf("%s format is only supported when getting body", o.Format)
+	}
+
 	if o.Selector == "body" {
 		// if we have a PageSize, but not Page, assume an Page of 1
 		if o.PageSize != -1 && o.Page == -1 {
@@ -169,11 +177,21 @@ func (o *GetOptions) Run() (err error) {
 		GenFilename: o.Outfile == "" && stdoutIsTerminal() && o.Format == "zip",
 		Remote:      o.Remote,
 	}
+	binary := columnar.IsFormat(o.Format)
+	if binary && o.Outfile == "" && stdoutIsTerminal() {
+		return fmt.Errorf("%s is a binary format. use --outfile to write a file, or redirect output", o.Format)
+	}
+
 	ctx := context.TODO()
 	res, err := o.DatasetMethods.Get(ctx, &p)
 	if err != nil {
 		return err
 	}
+	if binary && res.Message == "" {
+		// binary output is written as-is, without a pager or trailing newline
+		_, err = o.Out.Write(res.Bytes)
+		return err
+	}
 	if res.Message != "" {
 		o.Out.Write([]byte(res.Message))
 		o.Out.Write([]byte{'\n'})
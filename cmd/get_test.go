@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -557,3 +559,34 @@ structure:
 		t.Errorf("repsonse mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestGetBodyColumnar(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_get", "get_body_columnar")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	expect := run.MustExec(t, "qri get body --format json me/movies")
+	// empty csv values in typed columns are written as nulls
+	expect = strings.Replace(expect, `""`, "null", -1)
+
+	tmpDir := run.MakeTmpDir(t, "get_body_columnar")
+	for _, format := range []string{"parquet", "arrow"} {
+		bodyPath := filepath.Join(tmpDir, "movies."+format)
+		run.MustExec(t, fmt.Sprintf("qri get body --format %s --outfile %s me/movies", format, bodyPath))
+
+		// columnar bodies are converted to json on save
+		name := "movies_from_" + format
+		run.MustExec(t, fmt.Sprintf("qri save --body %s me/%s", bodyPath, name))
+		got := run.MustExec(t, fmt.Sprintf("qri get body --format json me/%s", name))
+		if diff := cmp.Diff(expect, got); diff != "" {
+			t.Errorf("%s: body mismatch after round trip (-want +got):\n%s", format, diff)
+		}
+		if got := run.MustExec(t, fmt.Sprintf("qri get structure.format me/%s", name)); got != "json\n\n" {
+			t.Errorf("%s: expected saved body format to be json. got: %q", format, got)
+		}
+	}
+
+	if err := run.ExecCommand("qri get meta --format parquet me/movies"); err == nil {
+		t.Error("expected getting meta as parquet to fail")
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates a `qri import` subcommand for creating datasets
+// from other data stores
+func NewImportCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ImportOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "create datasets from other data stores",
+		Long: `Import creates datasets from the contents of other data stores, saving a version
+of each dataset. Importing into a dataset that already exists adds a version if
+the data has changed.`,
+		Example: `  # import every table of a SQLite database, one dataset per table
+  $ qri import sqlite --all reference.sqlite
+
+  # import the result of a query as dataset me/big_cities
+  $ qri import sqlite reference.sqlite --query "SELECT * FROM cities WHERE pop > 1000000" me/big_cities
+
+  # import each resource of a data package as a dataset
+  $ qri import datapackage path/to/datapackage.json`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	sqlite := &cobra.Command{
+		Use:   "sqlite DATABASE [DATASET]",
+		Short: "create datasets from the tables of a SQLite database",
+		Long: `Import sqlite saves tables of a SQLite database file as datasets named after
+each table. Column types are mapped to a JSON schema in the structure of each
+dataset. Choose tables with --table, or import all of them with --all. A
+database with a single table can be imported without either flag. Use --query
+to save the result of a SQL query to the dataset given as the second argument.
+
+Reading SQLite databases requires qri to be built with cgo enabled, builds
+made with CGO_ENABLED=0 return an error.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.RunSQLite()
+		},
+	}
+	sqlite.Flags().StringSliceVar(&o.Tables, "table", nil, "tables to import, comma separated or repeated")
+	sqlite.Flags().BoolVar(&o.All, "all", false, "import every table in the database")
+	sqlite.Flags().StringVar(&o.Query, "query", "", "SQL query to import as a single dataset")
+
+	datapackage := &cobra.Command{
+		Use:   "datapackage PATH",
+		Short: "create datasets from the resources of a data package",
+		Long: `Import datapackage saves each resource of a Frictionless Data Package as a
+dataset named after the resource. PATH is a datapackage.json file, or a zipped
+data package on the local filesystem or at a URL. Package metadata is mapped
+to the meta component of each dataset, and table schemas to the structure.
+CSV & JSON resources are supported.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.RunDataPackage()
+		},
+	}
+
+	cmd.AddCommand(datapackage, sqlite)
+	return cmd
+}
+
+// ImportOptions encapsulates state for the import command & subcommands
+type ImportOptions struct {
+	ioes.IOStreams
+
+	Path   string
+	Ref    string
+	Tables []string
+	All    bool
+	Query  string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ImportOptions) Complete(f Factory, args []string) (err error) {
+	o.Path = args[0]
+	if len(args) > 1 {
+		o.Ref = args[1]
+	}
+	// use an absolute path, a connected instance may run in a different directory
+	if qfs.PathKind(o.Path) == "local" {
+		if err = qfs.AbsPath(&o.Path); err != nil {
+			return err
+		}
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return err
+}
+
+// RunSQLite executes the import sqlite command
+func (o *ImportOptions) RunSQLite() error {
+	if o.Ref != "" && o.Query == "" {
+		return fmt.Errorf("a dataset reference can only be given with --query")
+	}
+
+	ctx := context.TODO()
+	res, err := o.DatasetMethods.ImportSQLite(ctx, &lib.ImportSQLiteParams{
+		Path:   o.Path,
+		Tables: o.Tables,
+		All:    o.All,
+		Query:  o.Query,
+		Ref:    o.Ref,
+	})
+	if err != nil {
+		return err
+	}
+	o.printResult(res)
+	return nil
+}
+
+// RunDataPackage executes the import datapackage command
+func (o *ImportOptions) RunDataPackage() error {
+	ctx := context.TODO()
+	res, err := o.DatasetMethods.ImportDataPackage(ctx, &lib.ImportDataPackageParams{Path: o.Path})
+	if err != nil {
+		return err
+	}
+	o.printResult(res)
+	return nil
+}
+
+func (o *ImportOptions) printResult(res *lib.ImportResult) {
+	for _, vi := range res.Saved {
+		printSuccess(o.ErrOut, "dataset saved: %s", vi.SimpleRef().Alias())
+	}
+	for _, ref := range res.Unchanged {
+		printInfo(o.ErrOut, "no changes to save: %s", ref)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/base/sqlite"
+)
+
+func TestImportSQLite(t *testing.T) {
+	if !sqlite.Supported {
+		t.Skip("sqlite requires cgo")
+	}
+	run := NewTestRunner(t, "test_peer_import_sqlite", "import_sqlite")
+	defer run.Delete()
+
+	dbPath := filepath.Join(run.MakeTmpDir(t, "import_sqlite"), "reference.sqlite")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`CREATE TABLE cities (city TEXT, pop INTEGER, avg_age REAL, in_usa BOOLEAN)`,
+		`INSERT INTO cities VALUES ('toronto', 40000000, 55.5, 0), ('new york', 8500000, 44.4, 1), ('chicago', 300000, NULL, 1)`,
+		`CREATE TABLE "Country Codes" (code TEXT PRIMARY KEY, name TEXT)`,
+		`INSERT INTO "Country Codes" VALUES ('CA', 'Canada'), ('US', 'United States')`,
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	// saving a database with more than one table requires a table name
+	if err := run.ExecCommand(fmt.Sprintf("qri save --body %s me/cities", dbPath)); err == nil {
+		t.Error("expected saving a database with many tables and no table name to fail")
+	}
+
+	run.MustExec(t, fmt.Sprintf("qri save --body %s --table cities me/cities", dbPath))
+	got := run.MustExec(t, "qri get body --format json me/cities")
+	expect := `[["toronto",40000000,55.5,false],["new york",8500000,44.4,true],["chicago",300000,null,true]]` + "\n"
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+	expectSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "city", "type": "string"},
+				map[string]interface{}{"title": "pop", "type": "integer"},
+				map[string]interface{}{"title": "avg_age", "type": "number"},
+				map[string]interface{}{"title": "in_usa", "type": "boolean"},
+			},
+		},
+	}
+	ds := run.MustLoadDataset(t, run.LookupVersionInfo(t, "me/cities").Path)
+	if diff := cmp.Diff(expectSchema, ds.Structure.Schema); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+
+	// import all tables, datasets are named after tables. unchanged datasets
+	// are skipped
+	out := run.MustExecCombinedOutErr(t, fmt.Sprintf("qri import sqlite --all %s", dbPath))
+	expectOut := `dataset saved: test_peer_import_sqlite/country_codes
+no changes to save: me/cities
+`
+	if diff := cmp.Diff(expectOut, out); diff != "" {
+		t.Errorf("import output mismatch (-want +got):\n%s", diff)
+	}
+	got = run.MustExec(t, "qri get body --format json me/country_codes")
+	if expect := `[["CA","Canada"],["US","United States"]]` + "\n"; got != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, got)
+	}
+
+	run.MustExecuteQuotedCommand(t, fmt.Sprintf(`qri "import" "sqlite" %q "--query" "SELECT city FROM cities WHERE in_usa ORDER BY city" "me/us_cities"`, dbPath))
+	got = run.MustExec(t, "qri get body --format json me/us_cities")
+	if expect := `[["chicago"],["new york"]]` + "\n"; got != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, got)
+	}
+
+	if err := run.ExecCommand(fmt.Sprintf("qri import sqlite --all --table cities %s", dbPath)); err == nil {
+		t.Error("expected importing all tables and a list of tables to fail")
+	}
+}
+
+func TestImportDataPackage(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_import_datapackage", "import_datapackage")
+	defer run.Delete()
+
+	dir := run.MakeTmpDir(t, "import_datapackage")
+	run.MustWriteFile(t, filepath.Join(dir, "movies.csv"), "movie,year\nup,2009\n")
+	descriptor := filepath.Join(dir, "datapackage.json")
+	run.MustWriteFile(t, descriptor, `{
+  "name": "movies",
+  "title": "pixar movies",
+  "resources": [{"name": "movies", "path": "movies.csv"}]
+}`)
+
+	// a data package with a single resource can be saved as dataset files
+	run.MustExec(t, fmt.Sprintf("qri save --file %s me/pixar", descriptor))
+	if got := run.MustExec(t, "qri get meta.title me/pixar"); got != "pixar movies\n\n" {
+		t.Errorf("expected meta title from data package. got: %q", got)
+	}
+
+	out := run.MustExecCombinedOutErr(t, fmt.Sprintf("qri import datapackage %s", descriptor))
+	if expect := "dataset saved: test_peer_import_datapackage/movies\n"; out != expect {
+		t.Errorf("import output mismatch.\nwant: %q\ngot:  %q", expect, out)
+	}
+}
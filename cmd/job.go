@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewJobCommand creates a `qri job` subcommand for checking on and canceling
+// background jobs
+func NewJobCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &JobOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "inspect & cancel background jobs",
+		Long: `Jobs are long-running operations like ` + "`qri save --async`" + ` that run in the
+background of a ` + "`qri connect`" + ` process. Job commands show the progress of
+running jobs, the results of recently finished jobs, and can cancel a job
+that's still running.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "show running & recently finished jobs",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+	list.Flags().BoolVar(&o.Running, "running", false, "only show jobs that are still running")
+
+	status := &cobra.Command{
+		Use:   "status JOB_ID",
+		Short: "show the progress of a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Status()
+		},
+	}
+
+	cancel := &cobra.Command{
+		Use:   "cancel JOB_ID",
+		Short: "stop a running job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Cancel()
+		},
+	}
+
+	cmd.AddCommand(list, status, cancel)
+	return cmd
+}
+
+// JobOptions encapsulates state for the job command & subcommands
+type JobOptions struct {
+	ioes.IOStreams
+
+	ID      string
+	Running bool
+
+	JobMethods *lib.JobMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *JobOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.ID = args[0]
+	}
+	o.JobMethods, err = f.JobMethods()
+	return err
+}
+
+// List executes the job list command
+func (o *JobOptions) List() error {
+	ctx := context.TODO()
+	res, err := o.JobMethods.List(ctx, &lib.JobListParams{Running: o.Running})
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no jobs")
+		return nil
+	}
+	for _, j := range res {
+		fmt.Fprintf(o.Out, "%s\t%s\t%s\t%s\t%s\n", j.ID, j.Type, j.Ref, j.Status, j.Started.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Status executes the job status command
+func (o *JobOptions) Status() error {
+	ctx := context.TODO()
+	j, err := o.JobMethods.Get(ctx, &lib.JobParams{ID: o.ID})
+	if err != nil {
+		return err
+	}
+	o.printJob(j)
+	return nil
+}
+
+// Cancel executes the job cancel command
+func (o *JobOptions) Cancel() error {
+	ctx := context.TODO()
+	j, err := o.JobMethods.Cancel(ctx, &lib.JobParams{ID: o.ID})
+	if err != nil {
+		return err
+	}
+	if j.Status == lib.JSCanceled {
+		printSuccess(o.ErrOut, "canceled job %s", j.ID)
+	}
+	o.printJob(j)
+	return nil
+}
+
+func (o *JobOptions) printJob(j *lib.Job) {
+	fmt.Fprintf(o.Out, "job:      %s\n", j.ID)
+	fmt.Fprintf(o.Out, "type:     %s\n", j.Type)
+	fmt.Fprintf(o.Out, "ref:      %s\n", j.Ref)
+	fmt.Fprintf(o.Out, "status:   %s\n", j.Status)
+	fmt.Fprintf(o.Out, "started:  %s\n", j.Started.Format(time.RFC3339))
+	if j.Status == lib.JSRunning {
+		fmt.Fprintf(o.Out, "progress: %d%% %s\n", int(j.Completion*100), j.Message)
+		return
+	}
+	if j.Finished != nil {
+		fmt.Fprintf(o.Out, "finished: %s\n", j.Finished.Format(time.RFC3339))
+	}
+	if j.Path != "" {
+		fmt.Fprintf(o.Out, "path:     %s\n", j.Path)
+	}
+	if j.Error != "" {
+		fmt.Fprintf(o.Out, "error:    %s\n", j.Error)
+	}
+}
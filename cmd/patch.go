@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewPatchCommand creates a new `qri patch` cobra command for applying patch
+// files to datasets
+func NewPatchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &PatchOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "patch DATASET PATCH_FILE",
+		Short: "apply a patch file to a dataset",
+		Long: `Patch applies a patch file created with 'qri diff --output' to the latest
+version of a dataset, saving the result as a new version.
+
+Patches record the version they were created from. If the dataset has changed
+since the patch was created, patch refuses to apply it. Create a new patch
+from the latest version instead.`,
+		Example: `  # Write changes from a proposed copy of a dataset to a patch file:
+  $ qri diff body me/annual_pop me/annual_pop_fixes --output patch.json
+
+  # Apply the patch:
+  $ qri patch me/annual_pop patch.json --title "fix population counts"`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Title, "title", "t", "", "title of commit message for the new version")
+	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for the new version")
+
+	return cmd
+}
+
+// PatchOptions encapsulates state for the patch command
+type PatchOptions struct {
+	ioes.IOStreams
+
+	Ref       string
+	PatchPath string
+	Title     string
+	Message   string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *PatchOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) == 2 {
+		o.Ref = args[0]
+		o.PatchPath = args[1]
+		if err = qfs.AbsPath(&o.PatchPath); err != nil {
+			return err
+		}
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *PatchOptions) Validate() error {
+	if o.Ref == "" || o.PatchPath == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a dataset name & a patch file, for example:\n    $ qri patch me/dataset_name patch.json\nsee `qri patch --help` for more details")
+	}
+	return nil
+}
+
+// Run executes the patch command
+func (o *PatchOptions) Run() error {
+	pt, err := lib.ReadPatchFile(o.PatchPath)
+	if err != nil {
+		return err
+	}
+	p := &lib.ApplyPatchParams{
+		Ref:     o.Ref,
+		Patch:   pt,
+		Title:   o.Title,
+		Message: o.Message,
+	}
+	ctx := context.TODO()
+	ds, err := o.DatasetMethods.ApplyPatch(ctx, p)
+	if err != nil {
+		return err
+	}
+	ref := dsref.ConvertDatasetToVersionInfo(ds).SimpleRef()
+	ref.ProfileID = ""
+	printSuccess(o.ErrOut, "dataset patched: %s", ref.String())
+	return nil
+}
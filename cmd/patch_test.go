@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPatch(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_patch", "qri_test_patch")
+	defer run.Delete()
+
+	tmpDir := run.MakeTmpDir(t, "patch")
+	bodyOne := filepath.Join(tmpDir, "movies_1.json")
+	run.MustWriteFile(t, bodyOne, `[["up",96],["cars",117]]`)
+	bodyTwo := filepath.Join(tmpDir, "movies_2.json")
+	run.MustWriteFile(t, bodyTwo, `[["up",96],["coco",105],["cars",117]]`)
+	patchPath := filepath.Join(tmpDir, "patch.json")
+
+	run.MustExec(t, fmt.Sprintf("qri save --body %s me/movies", bodyOne))
+	run.MustExec(t, fmt.Sprintf("qri save --body %s me/movies_proposal", bodyTwo))
+	run.MustExec(t, fmt.Sprintf("qri diff body me/movies me/movies_proposal --output %s", patchPath))
+	run.MustExec(t, fmt.Sprintf("qri patch me/movies %s", patchPath))
+
+	output := run.MustExec(t, "qri get body me/movies")
+	expect := `[["up",96],["coco",105],["cars",117]]` + "\n"
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("patched body mismatch (-want +got):\n%s", diff)
+	}
+
+	// the patch was created from a version that's no longer the latest
+	err := run.ExecCommand(fmt.Sprintf("qri patch me/movies %s", patchPath))
+	if err == nil || !strings.Contains(err.Error(), "patch doesn't apply to the latest version") {
+		t.Errorf("expected applying a patch twice to fail, got: %v", err)
+	}
+
+	// whole datasets can't be written as patches
+	err = run.ExecCommand(fmt.Sprintf("qri diff me/movies me/movies_proposal --output %s", patchPath))
+	if err == nil {
+		t.Error("expected writing a patch of a whole dataset to fail")
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -197,12 +198,24 @@ func doesCommandExist(cmdName string) bool {
 
 func printDiff(w io.Writer, res *lib.DiffResponse, summaryOnly bool) (err error) {
 	buf := &bytes.Buffer{}
-	// TODO (b5): this reading from a package variable is pretty hacky :/
-	// should use the IsATTY package from mattn
-	deepdiff.FormatPrettyStats(buf, res.Stat, !color.NoColor)
-	if !summaryOnly {
-		buf.WriteByte('\n')
-		if err = deepdiff.FormatPretty(buf, res.Diff, !color.NoColor); err != nil {
+	// keyed body diffs only have row changes, whole dataset diffs with a keyed
+	// body have both
+	if res.RowStat == nil || res.Stat != nil {
+		// TODO (b5): this reading from a package variable is pretty hacky :/
+		// should use the IsATTY package from mattn
+		deepdiff.FormatPrettyStats(buf, res.Stat, !color.NoColor)
+		if !summaryOnly {
+			buf.WriteByte('\n')
+			if err = deepdiff.FormatPretty(buf, res.Diff, !color.NoColor); err != nil {
+				return err
+			}
+		}
+	}
+	if res.RowStat != nil {
+		if res.Stat != nil {
+			buf.WriteByte('\n')
+		}
+		if err = formatRowDiff(buf, res, summaryOnly); err != nil {
 			return err
 		}
 	}
@@ -211,6 +224,56 @@ func printDiff(w io.Writer, res *lib.DiffResponse, summaryOnly bool) (err error)
 	return nil
 }
 
+// formatRowDiff writes a primary key diff, one line for each changed row
+func formatRowDiff(w io.Writer, res *lib.DiffResponse, summaryOnly bool) error {
+	st := res.RowStat
+	fmt.Fprintf(w, "%d rows -> %d rows. %s %s %s\n",
+		st.LeftRows, st.RightRows,
+		color.GreenString("%d added.", st.Added),
+		color.RedString("%d removed.", st.Removed),
+		color.BlueString("%d modified.", st.Modified),
+	)
+	if summaryOnly {
+		return nil
+	}
+
+	w.Write([]byte{'\n'})
+	for _, d := range res.Rows {
+		key, err := json.Marshal(d.Key)
+		if err != nil {
+			return err
+		}
+		switch d.Type {
+		case deepdiff.DTInsert, deepdiff.DTDelete:
+			row, err := json.Marshal(d.Row)
+			if err != nil {
+				return err
+			}
+			line := fmt.Sprintf("%s %s: %s", d.Type, key, row)
+			if d.Type == deepdiff.DTInsert {
+				line = color.GreenString("%s", line)
+			} else {
+				line = color.RedString("%s", line)
+			}
+			fmt.Fprintln(w, line)
+		default:
+			fmt.Fprintln(w, color.BlueString("%s %s", d.Type, key))
+			for _, c := range d.Cells {
+				from, err := json.Marshal(c.From)
+				if err != nil {
+					return err
+				}
+				to, err := json.Marshal(c.To)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "  %s: %s -> %s\n", c.Column, color.RedString("%s", from), color.GreenString("%s", to))
+			}
+		}
+	}
+	return nil
+}
+
 func printRefSelect(w io.Writer, refset *RefSelect) {
 	if refset.IsExplicit() {
 		return
@@ -43,19 +43,28 @@ https://github.com/qri-io/qri/issues`,
 	cmd.PersistentFlags().BoolVarP(&opt.LogAll, "log-all", "", false, "log all activity")
 
 	cmd.AddCommand(
+		NewAccessCommand(opt, ioStreams),
 		NewApplyCommand(opt, ioStreams),
 		NewAutocompleteCommand(opt, ioStreams),
+		NewBranchCommand(opt, ioStreams),
+		NewChangesCommand(opt, ioStreams),
 		NewCheckoutCommand(opt, ioStreams),
 		NewConfigCommand(opt, ioStreams),
 		NewConnectCommand(opt, ioStreams),
 		NewDAGCommand(opt, ioStreams),
 		NewDiffCommand(opt, ioStreams),
 		NewFSICommand(opt, ioStreams),
+		NewFsckCommand(opt, ioStreams),
+		NewGCCommand(opt, ioStreams),
 		NewGetCommand(opt, ioStreams),
+		NewImportCommand(opt, ioStreams),
 		NewInitCommand(opt, ioStreams),
+		NewJobCommand(opt, ioStreams),
 		NewListCommand(opt, ioStreams),
 		NewLogCommand(opt, ioStreams),
 		NewLogbookCommand(opt, ioStreams),
+		NewMergeCommand(opt, ioStreams),
+		NewPatchCommand(opt, ioStreams),
 		NewPushCommand(opt, ioStreams),
 		NewPullCommand(opt, ioStreams),
 		NewPeersCommand(opt, ioStreams),
@@ -65,12 +74,16 @@ https://github.com/qri-io/qri/issues`,
 		NewRenameCommand(opt, ioStreams),
 		NewRenderCommand(opt, ioStreams),
 		NewRestoreCommand(opt, ioStreams),
+		NewRunCommand(opt, ioStreams),
 		NewSaveCommand(opt, ioStreams),
+		NewScheduleCommand(opt, ioStreams),
 		NewSearchCommand(opt, ioStreams),
+		NewSecretsCommand(opt, ioStreams),
 		NewSetupCommand(opt, ioStreams),
 		NewStatsCommand(opt, ioStreams),
 		NewStatusCommand(opt, ioStreams),
 		NewSQLCommand(opt, ioStreams),
+		NewSquashCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
@@ -247,6 +260,70 @@ func (o *QriOptions) TransformMethods() (*lib.TransformMethods, error) {
 	return lib.NewTransformMethods(o.inst), nil
 }
 
+// BranchMethods generates a lib.BranchMethods from internal state
+func (o *QriOptions) BranchMethods() (*lib.BranchMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewBranchMethods(o.inst), nil
+}
+
+// AccessMethods generates a lib.AccessMethods from internal state
+func (o *QriOptions) AccessMethods() (*lib.AccessMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewAccessMethods(o.inst), nil
+}
+
+// JobMethods generates a lib.JobMethods from internal state
+func (o *QriOptions) JobMethods() (*lib.JobMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewJobMethods(o.inst), nil
+}
+
+// RunMethods generates a lib.RunMethods from internal state
+func (o *QriOptions) RunMethods() (*lib.RunMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewRunMethods(o.inst), nil
+}
+
+// ScheduleMethods generates a lib.ScheduleMethods from internal state
+func (o *QriOptions) ScheduleMethods() (*lib.ScheduleMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewScheduleMethods(o.inst), nil
+}
+
+// SecretMethods generates a lib.SecretMethods from internal state
+func (o *QriOptions) SecretMethods() (*lib.SecretMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewSecretMethods(o.inst), nil
+}
+
+// StatsMethods generates a lib.StatsMethods from internal state
+func (o *QriOptions) StatsMethods() (*lib.StatsMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewStatsMethods(o.inst), nil
+}
+
+// RepoMethods generates a lib.RepoMethods from internal state
+func (o *QriOptions) RepoMethods() (*lib.RepoMethods, error) {
+	if err := o.Init(); err != nil {
+		return nil, err
+	}
+	return lib.NewRepoMethods(o.inst), nil
+}
+
 // RemoteMethods generates a lib.RemoteMethods from internal state
 func (o *QriOptions) RemoteMethods() (*lib.RemoteMethods, error) {
 	if err := o.Init(); err != nil {
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qri-io/ioes"
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewRunCommand creates a `qri run` subcommand for inspecting transform runs
+func NewRunCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &RunOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "inspect dataset transform runs",
+		Long: `Run commands show the record of transform runs. A run is recorded each time a
+transform is applied, by 'qri save --apply', 'qri apply', scheduled saves or
+'qri apply --test', including runs that fail. Records keep the timing, printed
+output & errors of each step, and the path of the version the run saved.
+
+Runs of 'qri apply' without a dataset & test runs aren't listed. Show them by
+the run ID 'qri apply' prints, or the runID of a test report written with
+--format json. 'qri gc' drops the oldest records of datasets with more runs
+than the keepruns limit in the transform section of config.`,
+		Example: `  # list the transform runs of a dataset, newest first
+  $ qri run ls me/dataset
+
+  # show the steps & output of a run
+  $ qri run show 5f3e0ec8-2cf7-4b5e-a0ac-1d0cd2a44e8b`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list DATASET",
+		Aliases: []string{"ls"},
+		Short:   "list the transform runs of a dataset",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+	list.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
+	list.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+
+	show := &cobra.Command{
+		Use:   "show RUN-ID",
+		Short: "show the steps & output of a transform run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Show()
+		},
+	}
+
+	cmd.AddCommand(list, show)
+	return cmd
+}
+
+// RunOptions encapsulates state for the run command & subcommands
+type RunOptions struct {
+	ioes.IOStreams
+
+	Arg      string
+	PageSize int
+	Page     int
+
+	RunMethods *lib.RunMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *RunOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Arg = args[0]
+	}
+	o.RunMethods, err = f.RunMethods()
+	return err
+}
+
+// List executes the run list command
+func (o *RunOptions) List() error {
+	ctx := context.TODO()
+	page := apiutil.NewPage(o.Page, o.PageSize)
+	res, err := o.RunMethods.List(ctx, &lib.RunListParams{
+		Ref:    o.Arg,
+		Offset: page.Offset(),
+		Limit:  page.Limit(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no transform runs")
+		return nil
+	}
+	for _, rs := range res {
+		result := rs.Path
+		if result == "" {
+			result = lastLine(rs.Message)
+		}
+		fmt.Fprintf(o.Out, "%s\t%s\t%s\t%s\t%s\n", rs.ID, rs.Status, formatRunTime(rs.StartTime), time.Duration(rs.Duration), result)
+	}
+	return nil
+}
+
+// Show executes the run show command
+func (o *RunOptions) Show() error {
+	ctx := context.TODO()
+	rs, err := o.RunMethods.Get(ctx, &lib.RunParams{ID: o.Arg})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "run:      %s\n", rs.ID)
+	fmt.Fprintf(o.Out, "status:   %s\n", rs.Status)
+	fmt.Fprintf(o.Out, "started:  %s\n", formatRunTime(rs.StartTime))
+	fmt.Fprintf(o.Out, "duration: %s\n", time.Duration(rs.Duration))
+	if rs.Path != "" {
+		fmt.Fprintf(o.Out, "version:  %s\n", rs.Path)
+	}
+	if rs.Message != "" {
+		fmt.Fprintf(o.Out, "message:  %s\n", rs.Message)
+	}
+	if rs.HTTPRecording != "" {
+		fmt.Fprintf(o.Out, "recorded http requests: %s\n", rs.HTTPRecording)
+	}
+
+	printRunOutput(o.Out, rs.Output)
+	for _, step := range rs.Steps {
+		fmt.Fprintf(o.Out, "\nstep %s (%s", step.Name, step.Status)
+		if step.Duration > 0 {
+			fmt.Fprintf(o.Out, ", %s", time.Duration(step.Duration))
+		}
+		fmt.Fprintln(o.Out, ")")
+		printRunOutput(o.Out, step.Output)
+	}
+	return nil
+}
+
+func printRunOutput(w io.Writer, output []event.Event) {
+	for _, e := range output {
+		msg, ok := e.Payload.(event.TransformMessage)
+		if !ok {
+			continue
+		}
+		if e.Type == event.ETTransformError {
+			fmt.Fprintf(w, "  error: %s\n", msg.Msg)
+		} else {
+			fmt.Fprintf(w, "  %s\n", msg.Msg)
+		}
+	}
+}
+
+// lastLine returns the last non-empty line of a message, which for transform
+// errors with a backtrace is the error itself
+func lastLine(msg string) string {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func formatRunTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
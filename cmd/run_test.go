@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_run", "qri_test_run")
+	defer run.Delete()
+
+	tmpDir := run.MakeTmpDir(t, "run_test")
+	failScript := filepath.Join(tmpDir, "fail.star")
+	if err := ioutil.WriteFile(failScript, []byte("def transform(ds, ctx):\n  print(\"fetching\")\n  error(\"upstream is down\")\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run.MustExec(t, "qri save --file testdata/movies/tf_one_movie.star --apply me/one_movie")
+	if err := run.ExecCommand("qri save --file " + failScript + " --apply me/one_movie"); err == nil {
+		t.Fatal("expected failing transform to error")
+	}
+
+	output := run.MustExec(t, "qri run ls me/one_movie")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 runs, got: %q", output)
+	}
+	if !strings.Contains(lines[0], "\tfailed\t") || !strings.Contains(lines[1], "\tsucceeded\t") {
+		t.Errorf("expected runs newest first, got: %q", output)
+	}
+
+	failedID := strings.Split(lines[0], "\t")[0]
+	output = run.MustExec(t, "qri run show "+failedID)
+	for _, expect := range []string{"status:   failed", "  fetching", "  error: ", "upstream is down"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected run output to contain %q, got: %s", expect, output)
+		}
+	}
+
+	if err := run.ExecCommand("qri run show not-a-run"); err == nil {
+		t.Error("expected showing an unknown run to fail")
+	}
+}
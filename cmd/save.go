@@ -30,7 +30,11 @@ Dataset changes can be automated with a transform component adn the --apply flag
 For more on transforms see https://qri.io/docs/transforms/overview
 If the dataset you're changing has a transform, running ` + "`qri save --apply`" +
 			`
-will re-execute it to produce a new version
+will re-execute it to produce a new version. Transform steps that haven't
+changed since they last ran are skipped, reusing their cached results. Use
+` + "`--no-cache`" + ` to run every step. ` + "`--record-http`" + ` stores the HTTP requests a
+transform makes with the run, which ` + "`--replay RUN_ID`" + ` serves back to reproduce
+the run without network access
 
 Every time you save, you can provide a message about what you changed and why. 
 If you don’t provide a message Qri will automatically generate one for you.
@@ -44,11 +48,24 @@ different peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" +
 		Example: `  # Save updated data to dataset annual_pop:
   $ qri save --body /path/to/data.csv me/annual_pop
 
+  # Save a table of a SQLite database as the body of dataset cities:
+  $ qri save --body /path/to/reference.sqlite --table cities me/cities
+
   # Save updated dataset (no data) to annual_pop:
   $ qri save --file /path/to/dataset.yaml me/annual_pop
   
   # Re-execute the latest transform from history:
-  $ qri save --apply me/tf_dataset`,
+  $ qri save --apply me/tf_dataset
+
+  # Re-execute every transform step, ignoring cached step results:
+  $ qri save --apply --no-cache me/tf_dataset
+
+  # Re-execute a transform, recording the HTTP requests it makes:
+  $ qri save --apply --record-http me/tf_dataset
+
+  # Save in the background of a running ` + "`qri connect`" + ` process:
+  $ qri save --async --body /path/to/large_data.csv me/annual_pop
+  $ qri job status JOB_ID`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -64,15 +81,20 @@ different peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" +
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&o.FilePaths, "file", "f", nil, "dataset or component file (yaml or json)")
+	cmd.Flags().StringSliceVarP(&o.FilePaths, "file", "f", nil, "dataset, component or datapackage.json file (yaml or json)")
 	cmd.MarkFlagFilename("file", "yaml", "yml", "json")
 	cmd.Flags().StringVarP(&o.Title, "title", "t", "", "title of commit message for save")
 	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for save")
 	cmd.Flags().StringVarP(&o.BodyPath, "body", "", "", "path to file or url of data to add as dataset contents")
 	cmd.MarkFlagFilename("body")
+	cmd.Flags().StringVar(&o.Table, "table", "", "table to read when the body is a SQLite database")
+	cmd.Flags().StringVar(&o.Query, "query", "", "SQL query to read when the body is a SQLite database")
 	// cmd.Flags().BoolVarP(&o.ShowValidation, "show-validation", "s", false, "display a list of validation errors upon adding")
 	cmd.Flags().BoolVar(&o.Apply, "apply", false, "apply a transformation and save the result")
 	cmd.Flags().BoolVar(&o.NoApply, "no-apply", false, "don't apply any transforms that are added")
+	cmd.Flags().BoolVar(&o.NoCache, "no-cache", false, "run every transform step, ignoring cached step results")
+	cmd.Flags().BoolVar(&o.RecordHTTP, "record-http", false, "record HTTP requests the transform makes, storing them with the run")
+	cmd.Flags().StringVar(&o.ReplayRun, "replay", "", "answer transform HTTP requests with the responses recorded by a previous run")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
 	cmd.Flags().BoolVar(&o.DeprecatedDryRun, "dry-run", false, "deprecated: use `qri apply` instead")
 	cmd.Flags().BoolVar(&o.Force, "force", false, "force a new commit, even if no changes are detected")
@@ -82,6 +104,7 @@ different peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" +
 	cmd.Flags().BoolVarP(&o.NewName, "new", "n", false, "save a new dataset only, using an available name")
 	cmd.Flags().BoolVarP(&o.UseDscache, "use-dscache", "", false, "experimental: build and use dscache if none exists")
 	cmd.Flags().StringVar(&o.Drop, "drop", "", "comma-separated list of components to remove")
+	cmd.Flags().BoolVar(&o.Async, "async", false, "save in the background of a running `qri connect` process, printing a job ID")
 
 	return cmd
 }
@@ -93,6 +116,8 @@ type SaveOptions struct {
 	Refs      *RefSelect
 	FilePaths []string
 	BodyPath  string
+	Table     string
+	Query     string
 	Drop      string
 
 	Title   string
@@ -100,6 +125,9 @@ type SaveOptions struct {
 
 	Apply            bool
 	NoApply          bool
+	NoCache          bool
+	RecordHTTP       bool
+	ReplayRun        string
 	DeprecatedDryRun bool
 	Secrets          []string
 
@@ -110,6 +138,7 @@ type SaveOptions struct {
 	NoRender       bool
 	NewName        bool
 	UseDscache     bool
+	Async          bool
 
 	DatasetMethods *lib.DatasetMethods
 	FSIMethods     *lib.FSIMethods
@@ -125,6 +154,12 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 		return
 	}
 
+	// background saves only outlive this command when handed to a connected
+	// instance
+	if o.Async && f.Instance().HTTPClient() == nil {
+		return fmt.Errorf("--async requires a running `qri connect` process")
+	}
+
 	if o.Refs, err = GetCurrentRefSelect(f, args, BadUpperCaseOkayWhenSavingExistingDataset, nil); err != nil {
 		// Not an error to use an empty reference, it will be inferred later on.
 		if err != repo.ErrEmptyRef {
@@ -150,6 +185,12 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that all user input is valid
 func (o *SaveOptions) Validate() error {
+	if o.Table != "" && o.Query != "" {
+		return fmt.Errorf("--table and --query can't be used together")
+	}
+	if (o.Table != "" || o.Query != "") && o.BodyPath == "" {
+		return fmt.Errorf("--table and --query require a SQLite database --body")
+	}
 	return nil
 }
 
@@ -160,6 +201,8 @@ func (o *SaveOptions) Run() (err error) {
 	p := &lib.SaveParams{
 		Ref:      o.Refs.Ref(),
 		BodyPath: o.BodyPath,
+		Table:    o.Table,
+		Query:    o.Query,
 		Title:    o.Title,
 		Message:  o.Message,
 
@@ -167,6 +210,9 @@ func (o *SaveOptions) Run() (err error) {
 		FilePaths:    o.FilePaths,
 		Private:      false,
 		Apply:        o.Apply,
+		NoCache:      o.NoCache,
+		RecordHTTP:   o.RecordHTTP,
+		ReplayRun:    o.ReplayRun,
 		Drop:         o.Drop,
 
 		ConvertFormatToPrev: o.KeepFormat,
@@ -206,6 +252,16 @@ continue?`, true) {
 	}
 
 	ctx := context.TODO()
+	if o.Async {
+		job, err := o.DatasetMethods.SaveAsync(ctx, p)
+		if err != nil {
+			return err
+		}
+		printSuccess(o.ErrOut, "save started, job ID: %s", job.ID)
+		printInfo(o.ErrOut, "check progress with `qri job status %s`", job.ID)
+		return nil
+	}
+
 	res, err := o.DatasetMethods.Save(ctx, p)
 	if err != nil {
 		return err
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/scheduler"
+	"github.com/spf13/cobra"
+)
+
+// NewScheduleCommand creates a `qri schedule` subcommand for running dataset
+// transforms periodically
+func NewScheduleCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ScheduleOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "run dataset transforms on a schedule",
+		Long: `Schedule commands set datasets with a transform to re-run periodically. While
+` + "`qri connect`" + ` is running, each scheduled dataset is saved with its transform
+applied, the same as running ` + "`qri save --apply`" + `.
+
+A schedule's periodicity is either a five-field cron expression, or an ISO-8601
+repeating interval. Intervals without a start time start now:
+
+  "0 */6 * * *"                   every six hours, on the hour
+  "30 9 * * 1-5"                  weekdays at 9:30
+  "R/P1D"                         once a day, starting now
+  "R/2021-01-01T00:00:00Z/PT1H"   every hour from the start of 2021
+  "R10/PT15M"                     every fifteen minutes, ten more times
+
+Datasets with a failing transform back off, waiting longer before each retry.
+Schedules are stored in the "scheduler" section of qri config.`,
+		Example: `  # run a dataset transform every morning at 8:00
+  $ qri schedule add me/dataset "0 8 * * *"
+
+  # show scheduled datasets
+  $ qri schedule ls
+
+  # run a scheduled dataset transform right now
+  $ qri schedule run-now me/dataset
+
+  # stop running a dataset on a schedule
+  $ qri schedule rm me/dataset`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add DATASET PERIODICITY",
+		Short: "schedule a dataset transform",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Add()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "show scheduled datasets",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:     "remove DATASET",
+		Aliases: []string{"rm"},
+		Short:   "stop running a dataset transform on a schedule",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	runNow := &cobra.Command{
+		Use:   "run-now DATASET",
+		Short: "run a scheduled dataset transform immediately",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.RunNow()
+		},
+	}
+
+	cmd.AddCommand(add, list, remove, runNow)
+	return cmd
+}
+
+// ScheduleOptions encapsulates state for the schedule command & subcommands
+type ScheduleOptions struct {
+	ioes.IOStreams
+
+	Ref         string
+	Periodicity string
+
+	ScheduleMethods *lib.ScheduleMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ScheduleOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	if len(args) > 1 {
+		o.Periodicity = args[1]
+	}
+	o.ScheduleMethods, err = f.ScheduleMethods()
+	return err
+}
+
+// Add executes the schedule add command
+func (o *ScheduleOptions) Add() error {
+	ctx := context.TODO()
+	st, err := o.ScheduleMethods.Add(ctx, &lib.ScheduleParams{Ref: o.Ref, Periodicity: o.Periodicity})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "scheduled %s", st.Ref)
+	o.printStatus(st)
+	return nil
+}
+
+// List executes the schedule list command
+func (o *ScheduleOptions) List() error {
+	ctx := context.TODO()
+	res, err := o.ScheduleMethods.List(ctx, &lib.ScheduleListParams{})
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no scheduled datasets")
+		return nil
+	}
+	for _, st := range res {
+		next := "never"
+		if st.NextRun != nil {
+			next = st.NextRun.Format(time.RFC3339)
+		}
+		fmt.Fprintf(o.Out, "%s\t%s\t%s", st.Ref, st.Periodicity, next)
+		if st.Failures > 0 {
+			fmt.Fprintf(o.Out, "\tfailing (%d): %s", st.Failures, st.LastError)
+		}
+		fmt.Fprintln(o.Out)
+	}
+	return nil
+}
+
+// Remove executes the schedule remove command
+func (o *ScheduleOptions) Remove() error {
+	ctx := context.TODO()
+	st, err := o.ScheduleMethods.Remove(ctx, &lib.ScheduleParams{Ref: o.Ref})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "removed schedule for %s", st.Ref)
+	return nil
+}
+
+// RunNow executes the schedule run-now command
+func (o *ScheduleOptions) RunNow() error {
+	ctx := context.TODO()
+	j, err := o.ScheduleMethods.RunNow(ctx, &lib.ScheduleParams{Ref: o.Ref})
+	if err != nil {
+		return err
+	}
+	if j.Status != lib.JSSucceeded {
+		return fmt.Errorf("running %s: %s", j.Ref, j.Error)
+	}
+	if j.Path == "" {
+		printInfo(o.ErrOut, "ran %s, no changes to save", j.Ref)
+		return nil
+	}
+	printSuccess(o.ErrOut, "ran %s, saved new version %s", j.Ref, j.Path)
+	return nil
+}
+
+func (o *ScheduleOptions) printStatus(st *scheduler.Status) {
+	fmt.Fprintf(o.Out, "dataset:     %s\n", st.Ref)
+	fmt.Fprintf(o.Out, "periodicity: %s\n", st.Periodicity)
+	if st.NextRun != nil {
+		fmt.Fprintf(o.Out, "next run:    %s\n", st.NextRun.Format(time.RFC3339))
+	}
+}
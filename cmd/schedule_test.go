@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduleCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_schedule", "qri_test_schedule")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --file testdata/movies/tf_one_movie.star --apply me/one_movie")
+
+	output := run.MustExecuteQuotedCommand(t, `qri schedule add "me/one_movie" "0 8 * * *"`)
+	if !strings.Contains(output, "periodicity: 0 8 * * *") {
+		t.Errorf("expected add to show the schedule, got: %s", output)
+	}
+
+	output = run.MustExec(t, "qri schedule ls")
+	if !strings.Contains(output, "test_peer_schedule/one_movie\t0 8 * * *") {
+		t.Errorf("expected list to show the scheduled dataset, got: %s", output)
+	}
+
+	run.MustExec(t, "qri schedule run-now me/one_movie")
+
+	run.MustExec(t, "qri schedule rm me/one_movie")
+	output = run.MustExec(t, "qri schedule ls")
+	if !strings.Contains(output, "no scheduled datasets") {
+		t.Errorf("expected no scheduled datasets after removal, got: %s", output)
+	}
+
+	if err := run.ExecCommand("qri schedule run-now me/one_movie"); err == nil {
+		t.Error("expected running an unscheduled dataset to fail")
+	}
+}
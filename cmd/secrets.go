@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewSecretsCommand creates a `qri secrets` subcommand for managing the
+// secrets transforms can read
+func NewSecretsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &SecretsOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "manage secrets for dataset transforms",
+		Long: `Secrets commands store values like API keys that dataset transforms need, so
+they don't have to be passed with ` + "`--secrets`" + ` each time a transform runs. Secrets
+are encrypted with your profile's private key.
+
+A transform can only read the stored secrets granted to its dataset. Grants
+are kept in your repo, so a transform script can't give itself access:
+
+  $ qri secrets grant API_KEY me/dataset_name
+
+Granted secrets are passed to the transform each time it's applied, and read
+with ` + "`ctx.get_secret(\"API_KEY\")`" + `. Secret values are redacted from transform
+output & run logs. Secrets passed with ` + "`--secrets`" + ` take precedence over stored
+values.`,
+		Example: `  # store a secret, reading the value from a prompt
+  $ qri secrets set API_KEY
+
+  # show the names of stored secrets
+  $ qri secrets ls
+
+  # let transforms of a dataset read a secret
+  $ qri secrets grant API_KEY me/dataset_name
+
+  # stop transforms of a dataset from reading a secret
+  $ qri secrets revoke API_KEY me/dataset_name
+
+  # remove a stored secret
+  $ qri secrets rm API_KEY`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	set := &cobra.Command{
+		Use:   "set NAME [VALUE]",
+		Short: "store a secret",
+		Long: `Set stores a secret, replacing any existing value. Without a value argument the
+value is read from standard input, keeping it out of your shell history.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Set()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "show the names of stored secrets",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "remove a stored secret",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	grant := &cobra.Command{
+		Use:   "grant NAME DATASET",
+		Short: "let transforms of a dataset read a secret",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Grant()
+		},
+	}
+
+	revoke := &cobra.Command{
+		Use:   "revoke NAME DATASET",
+		Short: "stop transforms of a dataset from reading a secret",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Revoke()
+		},
+	}
+
+	cmd.AddCommand(set, list, remove, grant, revoke)
+	return cmd
+}
+
+// SecretsOptions encapsulates state for the secrets command & subcommands
+type SecretsOptions struct {
+	ioes.IOStreams
+
+	Name string
+	// Value is the second argument: a secret value when setting, a dataset
+	// reference when granting or revoking
+	Value    string
+	HasValue bool
+
+	SecretMethods *lib.SecretMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *SecretsOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if len(args) > 1 {
+		o.Value = args[1]
+		o.HasValue = true
+	}
+	o.SecretMethods, err = f.SecretMethods()
+	return err
+}
+
+// Set executes the secrets set command
+func (o *SecretsOptions) Set() error {
+	ctx := context.TODO()
+	if !o.HasValue {
+		printInfoNoEndline(o.ErrOut, "value for %s: ", o.Name)
+		line, err := bufio.NewReader(o.In).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading secret value: %w", err)
+		}
+		o.Value = strings.TrimRight(line, "\r\n")
+	}
+
+	name, err := o.SecretMethods.Set(ctx, &lib.SecretParams{Name: o.Name, Value: o.Value})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "stored secret %s", name)
+	return nil
+}
+
+// List executes the secrets list command
+func (o *SecretsOptions) List() error {
+	ctx := context.TODO()
+	names, err := o.SecretMethods.List(ctx, &lib.SecretListParams{})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		printInfo(o.Out, "no stored secrets")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Fprintln(o.Out, name)
+	}
+	return nil
+}
+
+// Grant executes the secrets grant command
+func (o *SecretsOptions) Grant() error {
+	ctx := context.TODO()
+	ref, err := o.SecretMethods.Grant(ctx, &lib.SecretGrantParams{Name: o.Name, Dataset: o.Value})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "granted secret %s to %s", o.Name, ref)
+	return nil
+}
+
+// Revoke executes the secrets revoke command
+func (o *SecretsOptions) Revoke() error {
+	ctx := context.TODO()
+	ref, err := o.SecretMethods.Revoke(ctx, &lib.SecretGrantParams{Name: o.Name, Dataset: o.Value})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "revoked secret %s from %s", o.Name, ref)
+	return nil
+}
+
+// Remove executes the secrets remove command
+func (o *SecretsOptions) Remove() error {
+	ctx := context.TODO()
+	name, err := o.SecretMethods.Remove(ctx, &lib.SecretParams{Name: o.Name})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "removed secret %s", name)
+	return nil
+}
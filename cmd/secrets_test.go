@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSecretsCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_secrets", "qri_test_secrets")
+	defer run.Delete()
+
+	run.MustExec(t, "qri secrets set API_KEY hunter2")
+	if err := run.ExecCommandWithStdin(context.Background(), "qri secrets set TOKEN", "from stdin\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	output := run.MustExec(t, "qri secrets ls")
+	if output != "API_KEY\nTOKEN\n" {
+		t.Errorf("unexpected secrets list: %q", output)
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Error("expected list to never show secret values")
+	}
+
+	run.MustExec(t, "qri secrets grant TOKEN me/ds")
+	run.MustExec(t, "qri secrets revoke TOKEN me/ds")
+	if err := run.ExecCommand("qri secrets revoke TOKEN me/ds"); err == nil {
+		t.Error("expected revoking a secret that isn't granted to fail")
+	}
+	if err := run.ExecCommand("qri secrets grant MISSING me/ds"); err == nil {
+		t.Error("expected granting a missing secret to fail")
+	}
+
+	run.MustExec(t, "qri secrets rm TOKEN")
+	output = run.MustExec(t, "qri secrets ls")
+	if output != "API_KEY\n" {
+		t.Errorf("expected removed secret to be dropped from list, got: %q", output)
+	}
+
+	if err := run.ExecCommand("qri secrets rm TOKEN"); err == nil {
+		t.Error("expected removing a missing secret to fail")
+	}
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
 )
@@ -12,7 +16,7 @@ import (
 func NewSQLCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	o := &SQLOptions{IOStreams: ioStreams}
 	cmd := &cobra.Command{
-		Use:   "sql QUERY",
+		Use:   "sql [QUERY]",
 		Short: "experimental: run a SQL query on local dataset(s)",
 		Long: `sql runs Structured Query Language (SQL) commands, using local datasets 
 as tables.
@@ -23,12 +27,22 @@ The qri sql command differs from classic relational databases like MySQL or
 PostgreSQL in a few ways:
   * sql queries datasets as if they were tables, Any valid dataset reference
     can be used as a table name
-  * to query a dataset, it must be in your local qri repo
+  * datasets that aren't in your local qri repo are fetched from the registry,
+    or the remote set with --remote. Prefix a table name with a source to
+    fetch a single table from somewhere else, eg: "p2p:peer/dataset" or
+    "my_remote:peer/dataset". Sources can be "local", "network", "registry",
+    "p2p", or the name of a configured remote. Fetched versions are kept in
+    your repo, and aren't fetched again
   * Tables must always be aliased. eg: select a.col from user/dataset as a
   * For a dataset to be queryable it's schema must be properly configured to
     describe a tabular structure, with valid column names & types
   * Referencing columns that do not exist will return null values instead of
-    throwing an error`,
+    throwing an error
+
+Use --repl to start an interactive shell that runs queries as they're
+entered. Statements end with a semicolon and can span multiple lines. The
+shell completes dataset references and column names when tab is pressed,
+keeps a history of statements, and can describe datasets with \d DATASET`,
 		Example: `  # first, fetch the dataset b5/world_bank_population:
   $ qri add b5/world_bank_population
   $ qri sql "SELECT 
@@ -42,21 +56,46 @@ PostgreSQL in a few ways:
     cc.official_name_en, wbp.year_2010, wbp.year_2011 
     FROM b5/world_bank_population as wbp
     LEFT JOIN b5/country_codes as cc 
-    ON cc.iso_3166_1_alpha_3 = wbp.country_code"`,
+    ON cc.iso_3166_1_alpha_3 = wbp.country_code"
+
+  # join a local dataset with a collaborator's published version
+  $ qri sql "
+    SELECT 
+    mine.name, theirs.population
+    FROM me/cities as mine
+    JOIN registry:b5/city_populations as theirs
+    ON mine.name = theirs.name"
+
+  # save query results as a new version of me/big_cities. the query is saved
+  # as the dataset's transform, and re-runs with "qri save --apply"
+  $ qri sql --save me/big_cities "
+    SELECT * FROM me/cities as c WHERE c.population > 1000000"
+
+  # start an interactive shell
+  $ qri sql --repl
+  qri> \d me/cities
+  qri> SELECT c.name
+    -> FROM me/cities as c;`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
 			}
+			if o.REPL {
+				return o.RunREPL()
+			}
 			return o.Run()
 		},
 	}
 
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "table", "set output format [table]")
 	cmd.Flags().BoolVar(&o.Offline, "offline", false, "prevent network access")
+	cmd.Flags().StringVar(&o.Remote, "remote", "", "name of remote to fetch tables from by default")
+	cmd.Flags().StringVar(&o.SaveTo, "save", "", "save results as a new version of a dataset")
+	cmd.Flags().BoolVar(&o.REPL, "repl", false, "start an interactive SQL shell")
 
 	return cmd
 }
@@ -68,14 +107,37 @@ type SQLOptions struct {
 	Query   string
 	Format  string
 	Offline bool
+	Remote  string
+	SaveTo  string
+	REPL    bool
 
-	SQLMethods *lib.SQLMethods
+	RepoPath       string
+	SQLMethods     *lib.SQLMethods
+	DatasetMethods *lib.DatasetMethods
 }
 
 // Complete adds any missing configuration that can only be added just before
 // calling Run
 func (o *SQLOptions) Complete(f Factory, args []string) (err error) {
-	o.Query = args[0]
+	if o.Offline && o.Remote != "" {
+		return fmt.Errorf("cannot use --offline and --remote together")
+	}
+	if o.REPL {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot provide a query with --repl")
+		}
+		if o.SaveTo != "" {
+			return fmt.Errorf("cannot use --save with --repl")
+		}
+		o.RepoPath = f.RepoPath()
+		if o.DatasetMethods, err = f.DatasetMethods(); err != nil {
+			return err
+		}
+	} else if len(args) == 0 {
+		return fmt.Errorf("please provide a query, or use --repl to start an interactive shell")
+	} else {
+		o.Query = args[0]
+	}
 	o.SQLMethods, err = f.SQLMethods()
 	return
 }
@@ -84,15 +146,11 @@ func (o *SQLOptions) Complete(f Factory, args []string) (err error) {
 func (o *SQLOptions) Run() (err error) {
 	o.StartSpinner()
 
-	var mode string
-	if o.Offline {
-		mode = "local"
-	}
-
 	p := &lib.SQLQueryParams{
 		Query:        o.Query,
 		OutputFormat: o.Format,
-		ResolverMode: mode,
+		ResolverMode: o.resolverMode(),
+		SaveTo:       o.SaveTo,
 	}
 
 	res := []byte{}
@@ -102,6 +160,23 @@ func (o *SQLOptions) Run() (err error) {
 	}
 
 	o.StopSpinner()
+	if o.SaveTo != "" {
+		ds := &dataset.Dataset{}
+		if err := json.Unmarshal(res, ds); err != nil {
+			return err
+		}
+		ref := dsref.ConvertDatasetToVersionInfo(ds).SimpleRef()
+		printSuccess(o.ErrOut, "dataset saved: %s", ref.String())
+		return nil
+	}
 	printToPager(o.Out, bytes.NewBuffer(res))
 	return nil
 }
+
+// resolverMode converts the --offline & --remote flags to a lib resolver mode
+func (o *SQLOptions) resolverMode() string {
+	if o.Offline {
+		return "local"
+	}
+	return o.Remote
+}
@@ -0,0 +1,437 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/sql"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	sqlPrompt         = "qri> "
+	sqlContinuePrompt = "  -> "
+	// number of statements kept in the history file
+	sqlHistorySize = 500
+)
+
+const sqlREPLHelp = `Enter SQL statements ending with a semicolon. Statements can span multiple lines.
+Commands:
+  \d          list datasets
+  \d DATASET  describe a dataset's columns
+  \?          show this help
+  \q          quit
+Press tab to complete dataset references & column names. Use the up & down
+arrows to move through history`
+
+// lineReader reads lines of input. *terminal.Terminal is a lineReader
+type lineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// scanLineReader reads lines from input that isn't a terminal, like a pipe
+type scanLineReader struct {
+	s *bufio.Scanner
+}
+
+func (r *scanLineReader) ReadLine() (string, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.s.Text(), nil
+}
+
+func (r *scanLineReader) SetPrompt(string) {}
+
+// swapReadWriter is the connection a terminal reads from & writes to. it can
+// be swapped out, which is used to load history before reading input
+type swapReadWriter struct {
+	io.Reader
+	io.Writer
+}
+
+// sqlREPL is an interactive shell for running SQL queries
+type sqlREPL struct {
+	o       *SQLOptions
+	in      lineReader
+	out     io.Writer
+	history string
+
+	completer *sqlCompleter
+}
+
+// RunREPL starts an interactive SQL shell, running until input ends or the
+// user quits
+func (o *SQLOptions) RunREPL() error {
+	repl := &sqlREPL{
+		o:         o,
+		out:       o.Out,
+		completer: newSQLCompleter(o.listRefs, o.listColumns),
+	}
+	if o.RepoPath != "" {
+		repl.history = filepath.Join(o.RepoPath, "sql_history")
+	}
+
+	if f, ok := o.In.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		state, err := terminal.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return err
+		}
+		defer terminal.Restore(int(f.Fd()), state)
+
+		conn := &swapReadWriter{}
+		term := terminal.NewTerminal(conn, sqlPrompt)
+		if w, h, err := terminal.GetSize(int(f.Fd())); err == nil {
+			term.SetSize(w, h)
+		}
+		repl.loadHistory(term, conn)
+		conn.Reader, conn.Writer = f, o.Out
+		term.AutoCompleteCallback = repl.completer.Complete
+		repl.in = term
+		// the terminal translates newlines for raw mode
+		repl.out = term
+		fmt.Fprintln(repl.out, `qri sql shell. type \? for help`)
+	} else {
+		repl.in = &scanLineReader{s: bufio.NewScanner(o.In)}
+	}
+
+	return repl.run()
+}
+
+func (r *sqlREPL) run() error {
+	var stmt []string
+	for {
+		if len(stmt) == 0 {
+			r.in.SetPrompt(sqlPrompt)
+		} else {
+			r.in.SetPrompt(sqlContinuePrompt)
+		}
+
+		line, err := r.in.ReadLine()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if len(stmt) == 0 {
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, `\`) {
+				if quit := r.command(trimmed); quit {
+					return nil
+				}
+				continue
+			}
+		}
+
+		stmt = append(stmt, line)
+		if !strings.HasSuffix(trimmed, ";") {
+			r.completer.SetStatement(strings.Join(stmt, "\n"))
+			continue
+		}
+
+		query := strings.TrimSuffix(strings.TrimSpace(strings.Join(stmt, "\n")), ";")
+		stmt = nil
+		r.appendHistory(query)
+		r.completer.SetStatement("")
+		if err := r.exec(query); err != nil {
+			printErr(r.out, err)
+		}
+	}
+}
+
+// command runs a backslash command, returning true if the shell should exit
+func (r *sqlREPL) command(line string) (quit bool) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case `\q`, `\quit`:
+		return true
+	case `\?`, `\h`, `\help`:
+		fmt.Fprintln(r.out, sqlREPLHelp)
+	case `\d`:
+		if len(fields) == 1 {
+			for _, ref := range r.o.listRefs() {
+				fmt.Fprintln(r.out, ref)
+			}
+			return false
+		}
+		if err := r.describe(fields[1]); err != nil {
+			printErr(r.out, err)
+		}
+	default:
+		printErr(r.out, fmt.Errorf(`unknown command %q. type \? for help`, fields[0]))
+	}
+	return false
+}
+
+func (r *sqlREPL) exec(query string) error {
+	p := &lib.SQLQueryParams{
+		Query:        query,
+		OutputFormat: r.o.Format,
+		ResolverMode: r.o.resolverMode(),
+	}
+	res := []byte{}
+	if err := r.o.SQLMethods.Exec(p, &res); err != nil {
+		return err
+	}
+	_, err := r.out.Write(res)
+	return err
+}
+
+// describe prints the columns of a dataset
+func (r *sqlREPL) describe(table string) error {
+	source, refstr := sql.SplitTableSource(table)
+	if source == "" {
+		source = r.o.resolverMode()
+	}
+	ds, err := r.o.loadStructure(refstr, source)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.out, "%s/%s@%s\n", ds.Peername, ds.Name, ds.Path)
+	if ds.Meta != nil && ds.Meta.Title != "" {
+		fmt.Fprintln(r.out, ds.Meta.Title)
+	}
+	if ds.Structure == nil {
+		fmt.Fprintln(r.out, "no structure")
+		return nil
+	}
+	fmt.Fprintf(r.out, "format: %s, entries: %d, length: %d bytes\n", ds.Structure.Format, ds.Structure.Entries, ds.Structure.Length)
+
+	cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema)
+	if err != nil {
+		fmt.Fprintf(r.out, "not tabular: %s\n", err)
+		return nil
+	}
+	data := make([][]string, len(cols))
+	for i, col := range cols {
+		types := ""
+		if col.Type != nil {
+			types = strings.Join([]string(*col.Type), ",")
+		}
+		data[i] = []string{col.Title, types, col.Description}
+	}
+	renderTable(r.out, []string{"column", "type", "description"}, data)
+	return nil
+}
+
+// loadHistory reads statements from the history file into a terminal. The
+// terminal only records history from lines it reads, so history is replayed
+// as input with output discarded
+func (r *sqlREPL) loadHistory(term *terminal.Terminal, conn *swapReadWriter) {
+	if r.history == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(r.history)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	conn.Reader = strings.NewReader(strings.Join(lines, "\r") + "\r")
+	conn.Writer = ioutil.Discard
+	for range lines {
+		if _, err := term.ReadLine(); err != nil {
+			break
+		}
+	}
+}
+
+// sqlHistoryNewlines replaces the line breaks of multi-line statements
+var sqlHistoryNewlines = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// appendHistory records a statement in the history file as a single line,
+// keeping the most recent sqlHistorySize statements. Line breaks become
+// spaces, other whitespace is kept as written so string literals don't change
+func (r *sqlREPL) appendHistory(query string) {
+	if r.history == "" {
+		return
+	}
+	var lines []string
+	if data, err := ioutil.ReadFile(r.history); err == nil && len(data) > 0 {
+		lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+	lines = append(lines, sqlHistoryNewlines.Replace(query)+";")
+	if len(lines) > sqlHistorySize {
+		lines = lines[len(lines)-sqlHistorySize:]
+	}
+	if err := ioutil.WriteFile(r.history, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		log.Debugf("writing sql history: %s", err)
+	}
+}
+
+// listRefs returns references for local datasets, read from dscache
+func (o *SQLOptions) listRefs() []string {
+	infos, err := o.DatasetMethods.List(context.Background(), &lib.ListParams{
+		Limit:      10000,
+		UseDscache: true,
+	})
+	if err != nil {
+		log.Debugf("listing datasets for completion: %s", err)
+		return nil
+	}
+	refs := make([]string, 0, len(infos))
+	for _, vi := range infos {
+		refs = append(refs, vi.SimpleRef().Alias())
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// listColumns returns the column titles of a dataset. Completion runs on each
+// keypress, so columns only come from datasets in the local repo
+func (o *SQLOptions) listColumns(table string) []string {
+	_, refstr := sql.SplitTableSource(table)
+	ds, err := o.loadStructure(refstr, "local")
+	if err != nil || ds.Structure == nil {
+		return nil
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema)
+	if err != nil {
+		return nil
+	}
+	return cols.Titles()
+}
+
+// loadStructure gets a dataset without it's body, resolving the reference
+// through a source
+func (o *SQLOptions) loadStructure(refstr, source string) (*dataset.Dataset, error) {
+	res, err := o.DatasetMethods.Get(context.Background(), &lib.GetParams{
+		Refstr: refstr,
+		Remote: source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Dataset, nil
+}
+
+// sqlCompleter completes dataset references & column names
+type sqlCompleter struct {
+	refs    func() []string
+	columns func(ref string) []string
+
+	lock sync.Mutex
+	// statement lines entered before the current line
+	statement string
+	refCache  []string
+	colCache  map[string][]string
+}
+
+func newSQLCompleter(refs func() []string, columns func(ref string) []string) *sqlCompleter {
+	return &sqlCompleter{
+		refs:     refs,
+		columns:  columns,
+		colCache: map[string][]string{},
+	}
+}
+
+// SetStatement sets the lines of the statement entered so far, which are used
+// to find table aliases for completing columns
+func (c *sqlCompleter) SetStatement(stmt string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.statement = stmt
+}
+
+// tableAlias matches a table reference & its alias, eg: "FROM me/cities AS c"
+var tableAlias = regexp.MustCompile(`(?i)\b(?:from|join)\s+([^\s,()]+)(?:\s+as)?\s+([A-Za-z_][\w]*)`)
+
+// Complete implements terminal.Terminal's AutoCompleteCallback, completing
+// the word before the cursor when tab is pressed. A word with a single match
+// is completed, multiple matches complete to their longest shared prefix
+func (c *sqlCompleter) Complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	start := strings.LastIndexAny(line[:pos], " \t,()") + 1
+	word := line[start:pos]
+	if word == "" {
+		return "", 0, false
+	}
+
+	match := longestCommonPrefix(c.candidates(line, word))
+	if len(match) <= len(word) {
+		return "", 0, false
+	}
+	return line[:start] + match + line[pos:], start + len(match), true
+}
+
+// candidates returns completions that begin with word
+func (c *sqlCompleter) candidates(line, word string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var options []string
+	if i := strings.Index(word, "."); i > 0 {
+		// alias.column
+		alias := word[:i]
+		for _, m := range tableAlias.FindAllStringSubmatch(c.statement+"\n"+line, -1) {
+			if m[2] == alias {
+				for _, col := range c.tableColumns(m[1]) {
+					options = append(options, alias+"."+col)
+				}
+			}
+		}
+	} else {
+		if c.refCache == nil {
+			c.refCache = c.refs()
+		}
+		options = append(options, c.refCache...)
+		for _, m := range tableAlias.FindAllStringSubmatch(c.statement+"\n"+line, -1) {
+			options = append(options, c.tableColumns(m[1])...)
+		}
+	}
+
+	matches := []string{}
+	seen := map[string]bool{}
+	for _, opt := range options {
+		if strings.HasPrefix(opt, word) && !seen[opt] {
+			seen[opt] = true
+			matches = append(matches, opt)
+		}
+	}
+	return matches
+}
+
+func (c *sqlCompleter) tableColumns(ref string) []string {
+	if cols, ok := c.colCache[ref]; ok {
+		return cols
+	}
+	cols := c.columns(ref)
+	c.colCache[ref] = cols
+	return cols
+}
+
+func longestCommonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
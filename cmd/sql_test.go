@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
-	// "github.com/google/go-cmp/cmp"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestSQLRun(t *testing.T) {
@@ -20,3 +26,167 @@ func TestSQLRun(t *testing.T) {
 	// 	t.Errorf("result mismatch. (-want +got): %s\n", diff)
 	// }
 }
+
+func TestSQLTableSources(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_sql_sources", "qri_test_sql_sources")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save me/one_ds --body testdata/movies/body_ten.csv")
+
+	// tables can name the source they resolve through
+	run.MustExecuteQuotedCommand(t, `qri sql "SELECT a.movie_title FROM local:me/one_ds as a JOIN me/one_ds as b ON a.movie_title = b.movie_title LIMIT 1" "--format" "csv"`)
+
+	o := &SQLOptions{Offline: true, Remote: "registry"}
+	if err := o.Complete(nil, []string{"SELECT * FROM me/one_ds as one"}); err == nil {
+		t.Error("expected combining --offline and --remote to error")
+	}
+}
+
+func TestSQLSave(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_sql_save", "qri_test_sql_save")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save me/one_ds --body testdata/movies/body_ten.csv")
+	run.MustExecuteQuotedCommand(t, `qri sql "--save" "me/movie_sample" "SELECT m.movie_title, m.duration FROM me/one_ds as m LIMIT 3"`)
+
+	vi := run.LookupVersionInfo(t, "test_peer_sql_save/movie_sample")
+	if vi == nil {
+		t.Fatal("expected query results to be saved")
+	}
+	ds := run.MustLoadDataset(t, vi.Path)
+	if ds.Transform == nil || len(ds.Transform.Steps) != 1 {
+		t.Fatalf("expected saved dataset to have a single transform step")
+	}
+	step := ds.Transform.Steps[0]
+	if step.Syntax != "sql" || step.Script != "SELECT m.movie_title, m.duration FROM test_peer_sql_save/one_ds as m LIMIT 3" {
+		t.Errorf("unexpected transform step: %#v", step)
+	}
+	if ds.Structure == nil || ds.Structure.Format != "csv" {
+		t.Errorf("expected saved dataset to have a csv structure")
+	}
+}
+
+func TestSQLREPL(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_sql_repl", "qri_test_sql_repl")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save me/one_ds --body testdata/movies/body_ten.csv")
+
+	input := `\d me/one_ds
+SELECT m.movie_title
+FROM me/one_ds as m LIMIT 1;
+\q
+SELECT "never runs";
+`
+	if err := run.ExecCommandWithStdin(run.Context, "qri sql --repl --format csv", input); err != nil {
+		t.Fatal(err)
+	}
+
+	got := run.GetCommandOutput()
+	for _, expect := range []string{
+		"test_peer_sql_repl/one_ds@",
+		"movie_title",
+		"duration",
+		"Avatar",
+	} {
+		if !strings.Contains(got, expect) {
+			t.Errorf("expected output to contain %q. got:\n%s", expect, got)
+		}
+	}
+	if strings.Contains(got, "never runs") {
+		t.Errorf("expected \\q to exit the shell")
+	}
+}
+
+func TestSQLREPLHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_test_sql_history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &sqlREPL{history: filepath.Join(dir, "sql_history")}
+	r.appendHistory("SELECT 1")
+	r.appendHistory("SELECT m.movie_title\nFROM me/movies as m\nWHERE m.movie_title = 'Pirates  of the  Caribbean'")
+
+	data, err := ioutil.ReadFile(r.history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "SELECT 1;\nSELECT m.movie_title FROM me/movies as m WHERE m.movie_title = 'Pirates  of the  Caribbean';\n"
+	if diff := cmp.Diff(expect, string(data)); diff != "" {
+		t.Errorf("history mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSQLCompleter(t *testing.T) {
+	refs := func() []string {
+		return []string{"peer/movies", "peer/movie_ratings", "peer/cities"}
+	}
+	columns := func(ref string) []string {
+		switch ref {
+		case "peer/movies":
+			return []string{"movie_title", "duration"}
+		case "peer/cities":
+			return []string{"city", "population"}
+		}
+		return nil
+	}
+	c := newSQLCompleter(refs, columns)
+
+	cases := []struct {
+		statement, line string
+		pos             int
+		expect          string
+		expectPos       int
+		ok              bool
+	}{
+		{"", "SELECT * FROM peer/ci", 21, "SELECT * FROM peer/cities", 25, true},
+		// multiple matches complete to the longest shared prefix
+		{"", "SELECT * FROM peer/mo", 21, "SELECT * FROM peer/movie", 24, true},
+		{"", "SELECT m.mo FROM peer/movies m", 11, "SELECT m.movie_title FROM peer/movies m", 20, true},
+		// aliases can be declared on earlier lines of the statement
+		{"SELECT *\nFROM peer/cities AS c", "WHERE c.po", 10, "WHERE c.population", 18, true},
+		{"", "SELECT dur FROM peer/movies AS m", 10, "SELECT duration FROM peer/movies AS m", 15, true},
+		{"", "SELECT * FROM peer/zz", 21, "", 0, false},
+		{"", "SELECT ", 7, "", 0, false},
+	}
+	for i, tc := range cases {
+		c.SetStatement(tc.statement)
+		line, pos, ok := c.Complete(tc.line, tc.pos, '\t')
+		if line != tc.expect || pos != tc.expectPos || ok != tc.ok {
+			t.Errorf("case %d: expected (%q, %d, %t), got (%q, %d, %t)", i, tc.expect, tc.expectPos, tc.ok, line, pos, ok)
+		}
+	}
+
+	if _, _, ok := c.Complete("SELECT * FROM peer/ci", 21, 'x'); ok {
+		t.Error("expected keys other than tab not to complete")
+	}
+}
+
+func TestSQLListColumns(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	f, err := NewTestFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsm, err := f.DatasetMethods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// completion ignores the default source, resolving tables locally
+	o := &SQLOptions{DatasetMethods: dsm, Remote: "registry"}
+
+	expect := []string{"title", "duration"}
+	if diff := cmp.Diff(expect, o.listColumns("peer/movies")); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expect, o.listColumns("registry:peer/movies")); diff != "" {
+		t.Errorf("columns of a table with a source mismatch (-want +got):\n%s", diff)
+	}
+	if cols := o.listColumns("registry:peer/not_local"); cols != nil {
+		t.Errorf("expected a table that isn't local to have no columns, got: %v", cols)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewSquashCommand creates a new `qri squash` cobra command for collapsing
+// runs of versions in a dataset history
+func NewSquashCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &SquashOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "squash DATASET",
+		Short: "collapse runs of versions into a single version",
+		Long: `Squash rewrites the history of a dataset, collapsing a run of versions into a
+single version. The squashed version has the contents & timestamp of the newest
+version in the run, and lists the commit titles of every version it replaces.
+
+Use --before to squash every version committed before a date, or --range to
+pick versions by their position in 'qri log', where the latest version is 1.
+Versions newer than the squashed run are rewritten on top of it, which gives
+them new paths.
+
+Replaced versions are no longer part of the dataset history. Run 'qri gc' to
+remove them from the repo. If the dataset has been pushed to remotes, squash
+sends each remote the rewritten history.`,
+		Example: `  # Collapse every version of a dataset saved before 2026:
+  $ qri squash me/annual_pop --before 2026-01-01
+
+  # Collapse the 3rd through 10th most recent versions:
+  $ qri squash me/annual_pop --range 3-10`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Before, "before", "", "squash versions committed before a date, as YYYY-MM-DD")
+	cmd.Flags().StringVar(&o.Range, "range", "", "squash versions by log position, as FROM-TO")
+
+	return cmd
+}
+
+// SquashOptions encapsulates state for the squash command
+type SquashOptions struct {
+	ioes.IOStreams
+
+	Ref    string
+	Before string
+	Range  string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *SquashOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *SquashOptions) Validate() error {
+	if o.Ref == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a dataset name, for example:\n    $ qri squash me/dataset_name --before 2026-01-01\nsee `qri squash --help` for more details")
+	}
+	if (o.Before == "") == (o.Range == "") {
+		return errors.New(lib.ErrBadArgs, "please provide one of --before or --range")
+	}
+	return nil
+}
+
+// Run executes the squash command
+func (o *SquashOptions) Run() error {
+	p := &lib.SquashParams{
+		Ref:    o.Ref,
+		Before: o.Before,
+		Range:  o.Range,
+	}
+	ctx := context.TODO()
+	res, err := o.DatasetMethods.Squash(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range res.Squashed {
+		fmt.Fprintf(o.Out, "squashed\t%s\n", path)
+	}
+	for _, path := range res.Rewritten {
+		fmt.Fprintf(o.Out, "rewritten\t%s\n", path)
+	}
+	for _, addr := range res.Remotes {
+		printInfo(o.ErrOut, "sent rewritten history to %s", addr)
+	}
+	for _, msg := range res.RemoteErrors {
+		printWarning(o.ErrOut, "couldn't send rewritten history to %s", msg)
+	}
+	printSuccess(o.ErrOut, "squashed %d versions of %s", len(res.Squashed), res.Ref)
+	return nil
+}
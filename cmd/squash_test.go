@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSquash(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_squash", "qri_test_squash")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	v1 := run.GetPathForDataset(t, 0)
+	run.MustExec(t, "qri save --body=testdata/movies/body_twenty.csv me/movies")
+	v2 := run.GetPathForDataset(t, 0)
+	run.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/movies")
+	v3 := run.GetPathForDataset(t, 0)
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/movies")
+	v4 := run.GetPathForDataset(t, 0)
+	body := run.MustExec(t, "qri get body me/movies")
+
+	if err := run.ExecCommand("qri squash me/movies"); err == nil {
+		t.Error("expected squash without --before or --range to fail")
+	}
+	if err := run.ExecCommand("qri squash me/movies --range 1-1"); err == nil {
+		t.Error("expected squashing a single version to fail")
+	}
+
+	// collapse the 2nd & 3rd most recent versions
+	output := run.MustExec(t, "qri squash me/movies --range 2-3")
+	expect := "squashed\t" + v2 + "\nsquashed\t" + v3 + "\nrewritten\t" + v4 + "\n"
+	if output != expect {
+		t.Errorf("squash output mismatch. expected: %q, got: %q", expect, output)
+	}
+
+	history := run.MustExec(t, "qri log me/movies")
+	if count := strings.Count(history, "Commit:"); count != 3 {
+		t.Errorf("expected 3 versions after squashing. got %d:\n%s", count, history)
+	}
+	if !strings.Contains(history, "squashed 2 versions") {
+		t.Errorf("expected log to contain the squashed version. got:\n%s", history)
+	}
+	if !strings.Contains(history, v1) {
+		t.Errorf("expected the oldest version to be untouched. got:\n%s", history)
+	}
+	for _, replaced := range []string{v2, v3, v4} {
+		if strings.Contains(history, replaced) {
+			t.Errorf("expected replaced version %s to be gone from the log. got:\n%s", replaced, history)
+		}
+	}
+	if got := run.MustExec(t, "qri get body me/movies"); got != body {
+		t.Errorf("expected the latest body to be unchanged. want:\n%s\ngot:\n%s", body, got)
+	}
+
+	// references agree with the rewritten logbook
+	if output := run.MustExec(t, "qri fsck"); output != "" {
+		t.Errorf("expected no problems after squashing. got:\n%s", output)
+	}
+
+	// replaced versions are garbage
+	gc := run.MustExec(t, "qri gc --dry-run")
+	for _, replaced := range []string{v2, v3, v4} {
+		if !strings.Contains(gc, replaced) {
+			t.Errorf("expected replaced version %s to be collectable. got:\n%s", replaced, gc)
+		}
+	}
+
+	run.MustExec(t, "qri squash me/movies --before 2100-01-01")
+	history = run.MustExec(t, "qri log me/movies")
+	if count := strings.Count(history, "Commit:"); count != 1 {
+		t.Errorf("expected 1 version after squashing everything. got %d:\n%s", count, history)
+	}
+	if got := run.MustExec(t, "qri get body me/movies"); got != body {
+		t.Errorf("expected the latest body to be unchanged. want:\n%s\ngot:\n%s", body, got)
+	}
+}
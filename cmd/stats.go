@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/stats"
 	"github.com/spf13/cobra"
 )
 
@@ -18,7 +20,10 @@ func NewStatsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		Short: "get aggregated stats for a dataset",
 		Long:  `Run the ` + "`stats`" + ` to generate and view stats for a dataset using a dataset reference.`,
 		Example: `  # Get stats for me/dataset_name:
-  $ qri stats me/dataset_name`,
+  $ qri stats me/dataset_name
+
+  # Estimate stats for a very large dataset, using a fixed amount of memory:
+  $ qri stats me/big_dataset --mode estimate`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -35,16 +40,138 @@ func NewStatsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&o.Pretty, "pretty", "p", false, "whether to print output with indentation")
+	cmd.Flags().StringVar(&o.Mode, "mode", "exact", "how to calculate stats. one of [exact,estimate]. estimates use less memory on large bodies")
 
+	cmd.AddCommand(NewStatsCacheCommand(f, ioStreams))
 	return cmd
 }
 
+// NewStatsCacheCommand creates a `qri stats cache` subcommand for inspecting
+// and cleaning up cached stats
+func NewStatsCacheCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &StatsCacheOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "inspect & clean up cached stats",
+		Long: `Calculating stats requires reading an entire dataset body, so qri caches stats
+once they're calculated. The kind of cache, it's location, and it's maximum size
+are set by the stats.cache section of qri configuration. Cache commands list
+cached stats along with cache hits, misses and evictions, and drop cached
+stats that are no longer needed.`,
+		Example: `  # List cached stats:
+  $ qri stats cache ls
+
+  # Drop stats for versions that are no longer in the logbook, and for local
+  # files that have changed:
+  $ qri stats cache prune`,
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list cached stats & cache performance",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	clear := &cobra.Command{
+		Use:   "clear",
+		Short: "drop all cached stats",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Clear()
+		},
+	}
+
+	prune := &cobra.Command{
+		Use:   "prune",
+		Short: "drop cached stats that are no longer needed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Prune()
+		},
+	}
+
+	cmd.AddCommand(list, clear, prune)
+	return cmd
+}
+
+// StatsCacheOptions encapsulates state for the stats cache subcommands
+type StatsCacheOptions struct {
+	ioes.IOStreams
+
+	StatsMethods *lib.StatsMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *StatsCacheOptions) Complete(f Factory) (err error) {
+	o.StatsMethods, err = f.StatsMethods()
+	return err
+}
+
+// List executes the stats cache list command
+func (o *StatsCacheOptions) List() error {
+	ctx := context.TODO()
+	res, err := o.StatsMethods.CacheList(ctx, &lib.StatsCacheParams{})
+	if err != nil {
+		return err
+	}
+
+	for _, ent := range res.Entries {
+		stale := ""
+		if ent.Stale {
+			stale = "\tstale"
+		}
+		fmt.Fprintf(o.Out, "%s\t%s%s\n", ent.Key, humanize.Bytes(uint64(ent.Size)), stale)
+	}
+	fmt.Fprintf(o.Out, "\n%d entries, %s\n", len(res.Entries), humanize.Bytes(uint64(res.Size)))
+	c := res.Counters
+	fmt.Fprintf(o.Out, "hits: %d, misses: %d, evictions: %d\n", c.Hits, c.Misses, c.Evictions)
+	return nil
+}
+
+// Clear executes the stats cache clear command
+func (o *StatsCacheOptions) Clear() error {
+	ctx := context.TODO()
+	if err := o.StatsMethods.CacheClear(ctx, &lib.StatsCacheParams{}); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "cleared stats cache")
+	return nil
+}
+
+// Prune executes the stats cache prune command
+func (o *StatsCacheOptions) Prune() error {
+	ctx := context.TODO()
+	res, err := o.StatsMethods.CachePrune(ctx, &lib.StatsCacheParams{})
+	if err != nil {
+		return err
+	}
+	for _, ent := range res.Removed {
+		fmt.Fprintln(o.Out, ent.Key)
+	}
+	printSuccess(o.ErrOut, "removed %d cached stats, reclaimed %s", len(res.Removed), humanize.Bytes(uint64(res.Reclaimed)))
+	return nil
+}
+
 // StatsOptions encapsulates state for the stats command
 type StatsOptions struct {
 	ioes.IOStreams
 
 	Refs   *RefSelect
 	Pretty bool
+	Mode   string
 
 	DatasetMethods *lib.DatasetMethods
 }
@@ -64,7 +191,8 @@ func (o *StatsOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that any user input is valid
 func (o *StatsOptions) Validate() error {
-	return nil
+	_, err := stats.ParseMode(o.Mode)
+	return err
 }
 
 // Run executes the stats command
@@ -74,6 +202,7 @@ func (o *StatsOptions) Run() (err error) {
 	ctx := context.TODO()
 	p := &lib.StatsParams{
 		Refstr: o.Refs.Ref(),
+		Mode:   o.Mode,
 	}
 	sa, err := o.DatasetMethods.Stats(ctx, p)
 	if err != nil {
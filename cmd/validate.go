@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/checks"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
@@ -56,6 +59,13 @@ will affect a dataset before saving changes to a dataset.
 You can get the current schema of a dataset by running the ` + "`qri get structure.schema`" + `
 command.
 
+Validate also runs any data quality checks declared in the "checks" field of
+the dataset's meta component, the same checks that run when saving. Check
+results are listed after schema errors. JSON output of a dataset with checks
+is an object with "errors" and "checks" fields. Validating a saved version
+without --body or --schema flags shows the check results recorded when that
+version was saved.
+
 Note: --body and --schema or --structure flags will override the dataset
 if these flags are provided.`,
 		Example: `  # Show errors in an existing dataset:
@@ -145,25 +155,50 @@ func (o *ValidateOptions) Run() (err error) {
 
 	switch o.Format {
 	case "table":
-		if len(res.Errors) == 0 {
+		if len(res.Errors) == 0 && len(res.Checks.Failed()) == 0 && len(res.Checks.Warnings()) == 0 {
+			printCheckResults(o.Out, res.Checks)
 			printSuccess(o.Out, "✔ All good!")
 			return nil
 		}
-		header, data := tabularValidationData(res.Structure, res.Errors)
 		buf := &bytes.Buffer{}
-		renderTable(buf, header, data)
+		if len(res.Errors) > 0 {
+			header, data := tabularValidationData(res.Structure, res.Errors)
+			renderTable(buf, header, data)
+		}
+		printCheckResults(buf, res.Checks)
 		printToPager(o.Out, buf)
 	case "csv":
 		header, data := tabularValidationData(res.Structure, res.Errors)
 		csv.NewWriter(o.Out).WriteAll(append([][]string{header}, data...))
 	case "json":
-		if err := json.NewEncoder(o.Out).Encode(res.Errors); err != nil {
+		var v interface{} = res.Errors
+		if len(res.Checks) > 0 {
+			v = map[string]interface{}{
+				"errors": res.Errors,
+				"checks": res.Checks,
+			}
+		}
+		if err := json.NewEncoder(o.Out).Encode(v); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// printCheckResults writes a line per data quality check
+func printCheckResults(w io.Writer, rs checks.Results) {
+	for _, r := range rs {
+		switch {
+		case r.Passed:
+			printSuccess(w, "✔ %s", r.Message)
+		case r.Check.Level == checks.LevelWarn:
+			printWarning(w, "! %s", r.Message)
+		default:
+			fmt.Fprintln(w, color.New(color.FgRed).Sprintf("✖ %s", r.Message))
+		}
+	}
+}
+
 func tabularValidationData(st *dataset.Structure, errs []jsonschema.KeyError) ([]string, [][]string) {
 	var (
 		header []string
@@ -39,6 +39,9 @@ type Config struct {
 	API     *API
 	RPC     *RPC
 	Logging *Logging
+
+	Scheduler *Scheduler
+	Transform *Transform
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -73,6 +76,9 @@ func DefaultConfig() *Config {
 		API:     DefaultAPI(),
 		RPC:     DefaultRPC(),
 		Logging: DefaultLogging(),
+
+		Scheduler: DefaultScheduler(),
+		Transform: DefaultTransform(),
 	}
 }
 
@@ -224,6 +230,8 @@ func (cfg Config) Validate() error {
 		cfg.API,
 		cfg.RPC,
 		cfg.Logging,
+		cfg.Scheduler,
+		cfg.Transform,
 	}
 	for _, val := range validators {
 		// we need to check here because we're potentially calling methods on nil
@@ -282,6 +290,12 @@ func (cfg *Config) Copy() *Config {
 	if cfg.Stats != nil {
 		res.Stats = cfg.Stats.Copy()
 	}
+	if cfg.Scheduler != nil {
+		res.Scheduler = cfg.Scheduler.Copy()
+	}
+	if cfg.Transform != nil {
+		res.Transform = cfg.Transform.Copy()
+	}
 	if cfg.Filesystems != nil {
 		for _, fs := range cfg.Filesystems {
 			res.Filesystems = append(res.Filesystems, fs)
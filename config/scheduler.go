@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/scheduler"
+)
+
+// Scheduler configures periodic runs of dataset transforms while qri is
+// connected
+type Scheduler struct {
+	// Enabled toggles running scheduled transforms in `qri connect`
+	Enabled bool `json:"enabled"`
+	// BackoffBase is the delay after a first failed run, doubling with each
+	// consecutive failure up to BackoffMax. Both are go duration strings
+	BackoffBase string `json:"backoffbase"`
+	BackoffMax  string `json:"backoffmax"`
+	// Schedules lists datasets to run
+	Schedules []*Schedule `json:"schedules"`
+}
+
+// Schedule pairs a dataset with when it should run
+type Schedule struct {
+	// Ref is a dataset reference in the form peername/name
+	Ref string `json:"ref"`
+	// Periodicity is a cron expression or an ISO-8601 repeating interval
+	Periodicity string `json:"periodicity"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Scheduler) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// DefaultScheduler creates & returns a new default scheduler configuration
+func DefaultScheduler() *Scheduler {
+	return &Scheduler{
+		Enabled:     true,
+		BackoffBase: "1m",
+		BackoffMax:  "24h",
+	}
+}
+
+// Validate validates all the fields of scheduler returning all errors found.
+func (cfg Scheduler) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Scheduler",
+    "description": "Config for running dataset transforms on a schedule",
+    "type": "object",
+    "required": ["enabled"],
+    "properties": {
+      "enabled": {
+        "description": "When true, qri connect runs scheduled transforms",
+        "type": "boolean"
+      },
+      "backoffbase": {
+        "description": "Delay after a first failed run, doubling with each consecutive failure",
+        "type": "string"
+      },
+      "backoffmax": {
+        "description": "Longest delay between runs of a failing dataset",
+        "type": "string"
+      },
+      "schedules": {
+        "description": "Datasets to run on a schedule",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "required": ["ref", "periodicity"],
+          "properties": {
+            "ref": {
+              "description": "Reference to the scheduled dataset",
+              "type": "string"
+            },
+            "periodicity": {
+              "description": "A cron expression or ISO-8601 repeating interval",
+              "type": "string"
+            }
+          }
+        }
+      }
+    }
+  }`)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+
+	if _, err := cfg.Backoff(); err != nil {
+		return err
+	}
+	for _, s := range cfg.Schedules {
+		if _, err := scheduler.ParsePeriodicity(s.Periodicity); err != nil {
+			return fmt.Errorf("scheduler: %q: %w", s.Ref, err)
+		}
+	}
+	return nil
+}
+
+// Backoff reads the backoff durations, using defaults for empty values
+func (cfg *Scheduler) Backoff() (scheduler.Backoff, error) {
+	b := scheduler.DefaultBackoff
+	var err error
+	if cfg.BackoffBase != "" {
+		if b.Base, err = time.ParseDuration(cfg.BackoffBase); err != nil {
+			return b, fmt.Errorf("scheduler: invalid backoffbase: %w", err)
+		}
+	}
+	if cfg.BackoffMax != "" {
+		if b.Max, err = time.ParseDuration(cfg.BackoffMax); err != nil {
+			return b, fmt.Errorf("scheduler: invalid backoffmax: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// Copy returns a deep copy of the Scheduler struct
+func (cfg *Scheduler) Copy() *Scheduler {
+	res := &Scheduler{
+		Enabled:     cfg.Enabled,
+		BackoffBase: cfg.BackoffBase,
+		BackoffMax:  cfg.BackoffMax,
+	}
+	if cfg.Schedules != nil {
+		res.Schedules = make([]*Schedule, len(cfg.Schedules))
+		for i, s := range cfg.Schedules {
+			cpy := *s
+			res.Schedules[i] = &cpy
+		}
+	}
+	return res
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchedulerValidate(t *testing.T) {
+	if err := DefaultScheduler().Validate(); err != nil {
+		t.Errorf("error validating default scheduler: %s", err)
+	}
+
+	cfg := DefaultScheduler()
+	cfg.Schedules = []*Schedule{{Ref: "me/foo", Periodicity: "nope"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected invalid periodicity to error")
+	}
+
+	cfg = DefaultScheduler()
+	cfg.BackoffMax = "forever"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected invalid backoff duration to error")
+	}
+}
+
+func TestSchedulerCopy(t *testing.T) {
+	s := DefaultScheduler()
+	s.Schedules = []*Schedule{{Ref: "me/foo", Periodicity: "0 * * * *"}}
+
+	cpy := s.Copy()
+	if !reflect.DeepEqual(cpy, s) {
+		t.Errorf("scheduler structs are not equal: \ncopy: %v, \noriginal: %v", cpy, s)
+	}
+	cpy.Schedules[0].Periodicity = "*/5 * * * *"
+	if s.Schedules[0].Periodicity != "0 * * * *" {
+		t.Error("expected modifying a copy not to change the original")
+	}
+}
@@ -60,15 +60,17 @@ func (cfg Stats) Validate() error {
             "type": "number"
           },
           "path": {
-            "description": "The path to the cache. Default is empty. If empty, Qri will save the cache in the Qri Path",
+            "description": "The path to the cache. Default is empty. If empty, Qri will save the cache in the Qri Path. Point the path of content caches in multiple repos at the same directory to share stats",
             "type": "string"
           },
           "type": {
-            "description": "Type of cache",
+            "description": "Type of cache. fs stores stats by dataset version in the qri path, mem & lru keep stats in memory, and content stores stats by dataset body, and can be shared by multiple repos",
             "type": "string",
             "enum": [
               "fs",
               "mem",
+              "lru",
+              "content",
               "postgres"
             ]
           }
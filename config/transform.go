@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// Transform configures the execution of transform scripts
+type Transform struct {
+	// MaxSteps caps the number of starlark execution steps each transform
+	// step can take. 0 means no limit
+	MaxSteps uint64 `json:"maxsteps"`
+	// StepTimeout caps the wall-clock time of each transform step, written as
+	// a go duration string. empty means no limit
+	StepTimeout string `json:"steptimeout"`
+	// MaxBodySize caps the size in bytes of a body a transform writes. 0
+	// means no limit
+	MaxBodySize int64 `json:"maxbodysize"`
+	// KeepRuns caps the number of run records `qri gc` keeps for each
+	// dataset. 0 means no limit
+	KeepRuns int `json:"keepruns"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Transform) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// DefaultKeepRuns is the default number of run records kept for each dataset
+const DefaultKeepRuns = 100
+
+// DefaultTransform creates & returns a new default transform configuration
+func DefaultTransform() *Transform {
+	return &Transform{
+		StepTimeout: "10m",
+		KeepRuns:    DefaultKeepRuns,
+	}
+}
+
+// Validate validates all the fields of transform returning all errors found.
+func (cfg Transform) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Transform",
+    "description": "Config for executing transform scripts",
+    "type": "object",
+    "properties": {
+      "maxsteps": {
+        "description": "Maximum starlark execution steps per transform step, 0 for no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "steptimeout": {
+        "description": "Maximum duration of a transform step, empty for no limit",
+        "type": "string"
+      },
+      "maxbodysize": {
+        "description": "Maximum size in bytes of a body written by a transform, 0 for no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "keepruns": {
+        "description": "Maximum number of run records garbage collection keeps per dataset, 0 for no limit",
+        "type": "integer",
+        "minimum": 0
+      }
+    }
+  }`)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+
+	if _, err := cfg.StepTimeoutDuration(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// StepTimeoutDuration parses the step timeout, returning 0 if none is set
+func (cfg *Transform) StepTimeoutDuration() (time.Duration, error) {
+	if cfg.StepTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(cfg.StepTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("transform: invalid steptimeout: %w", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("transform: steptimeout must not be negative")
+	}
+	return d, nil
+}
+
+// Copy returns a deep copy of the Transform struct
+func (cfg *Transform) Copy() *Transform {
+	return &Transform{
+		MaxSteps:    cfg.MaxSteps,
+		StepTimeout: cfg.StepTimeout,
+		MaxBodySize: cfg.MaxBodySize,
+		KeepRuns:    cfg.KeepRuns,
+	}
+}
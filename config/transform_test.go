@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransformValidate(t *testing.T) {
+	if err := DefaultTransform().Validate(); err != nil {
+		t.Errorf("error validating default transform: %s", err)
+	}
+
+	cfg := DefaultTransform()
+	cfg.StepTimeout = "forever"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected invalid step timeout to error")
+	}
+
+	cfg = DefaultTransform()
+	cfg.StepTimeout = ""
+	d, err := cfg.StepTimeoutDuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 0 {
+		t.Errorf("expected empty step timeout to mean no limit. got: %s", d)
+	}
+
+	cfg.StepTimeout = "90s"
+	if d, _ = cfg.StepTimeoutDuration(); d != 90*time.Second {
+		t.Errorf("step timeout mismatch. expected: 90s, got: %s", d)
+	}
+}
+
+func TestTransformCopy(t *testing.T) {
+	cfg := &Transform{MaxSteps: 1000, StepTimeout: "1m", MaxBodySize: 2048, KeepRuns: 10}
+	cpy := cfg.Copy()
+	if !reflect.DeepEqual(cpy, cfg) {
+		t.Errorf("transform structs are not equal: \ncopy: %v, \noriginal: %v", cpy, cfg)
+	}
+}
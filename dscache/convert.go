@@ -165,7 +165,7 @@ func convertLogbookAndRefs(ctx context.Context, book *logbook.Book, dsrefs []rep
 		// TODO(dlong): Test for username changes
 		profileID := userLog.Ops[0].AuthorID
 		// Get the info for each dataset in this user's collection.
-		infoList := convertLogbookUserToDsInfoList(profileID, userLog.Logs)
+		infoList := convertLogbookUserToDsInfoList(book, profileID, userLog.Logs)
 		allInfoList = append(allInfoList, infoList...)
 	}
 
@@ -193,10 +193,10 @@ func convertLogbookAndRefs(ctx context.Context, book *logbook.Book, dsrefs []rep
 	return allInfoList, nil
 }
 
-func convertLogbookUserToDsInfoList(profileID string, dsLogs []*oplog.Log) []*entryInfo {
+func convertLogbookUserToDsInfoList(book *logbook.Book, profileID string, dsLogs []*oplog.Log) []*entryInfo {
 	infoList := make([]*entryInfo, 0, len(dsLogs))
 	for _, dsLog := range dsLogs {
-		info := convertDatasetHistoryToDsInfo(*dsLog)
+		info := convertDatasetHistoryToDsInfo(book, *dsLog)
 		if info == nil {
 			continue
 		}
@@ -206,10 +206,14 @@ func convertLogbookUserToDsInfoList(profileID string, dsLogs []*oplog.Log) []*en
 	return infoList
 }
 
-func convertDatasetHistoryToDsInfo(dsLog oplog.Log) *entryInfo {
+func convertDatasetHistoryToDsInfo(book *logbook.Book, dsLog oplog.Log) *entryInfo {
 	// Get the final pretty name, most recently ammended.
 	prettyName := ""
 	for _, op := range dsLog.Ops {
+		if op.Model == logbook.ACLModel {
+			// acl operations don't affect dataset info
+			continue
+		}
 		if op.Model != logbook.DatasetModel {
 			log.Errorf("expected to be at the dataset level, got model number %d", op.Model)
 			return nil
@@ -223,12 +227,11 @@ func convertDatasetHistoryToDsInfo(dsLog oplog.Log) *entryInfo {
 
 	// Get the init-id here, because this the log for the dataset model.
 	initID := dsLog.ID()
-	if len(dsLog.Logs) != 1 {
-		log.Errorf("expected only 1 branch, got %d\n", len(dsLog.Logs))
+	historyLog := book.ActiveBranchLog(&dsLog)
+	if historyLog == nil {
+		log.Errorf("active branch of dataset %q not found", initID)
 		return nil
 	}
-
-	historyLog := dsLog.Logs[0]
 	topIndex, headRef := convertHistoryToIndexAndRef(*historyLog)
 	cursorIndex := topIndex
 	return &entryInfo{
@@ -246,6 +249,15 @@ func convertHistoryToIndexAndRef(historyLog oplog.Log) (int, string) {
 	refs := make([]string, 0, len(historyLog.Ops))
 	// Collect references added and removed to get those that remain.
 	for _, op := range historyLog.Ops {
+		if op.Model == logbook.BranchModel && op.Type == oplog.OpTypeAmend {
+			// branch merge records don't add versions
+			continue
+		}
+		if op.Model == logbook.RunModel || op.Model == logbook.PushModel {
+			// transform runs & pushes don't add versions, and versions removed
+			// from history are counted in commits
+			continue
+		}
 		if op.Type == oplog.OpTypeRemove {
 			refs = refs[0 : len(refs)-int(op.Size)]
 		} else {
@@ -13,6 +13,7 @@ import (
 	testPeers "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
 	"github.com/qri-io/qri/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
@@ -560,6 +561,27 @@ func TestBuildDscacheFromLogbookAndProfilesAndDsrefFillInfo(t *testing.T) {
 	}
 }
 
+func TestConvertHistoryToIndexAndRefRunsAndPushes(t *testing.T) {
+	historyLog := oplog.Log{
+		Ops: []oplog.Op{
+			{Type: oplog.OpTypeInit, Model: logbook.BranchModel, Name: "main"},
+			{Type: oplog.OpTypeInit, Model: logbook.CommitModel, Ref: "QmHashOfVersion1"},
+			{Type: oplog.OpTypeInit, Model: logbook.RunModel, Ref: "run-id-1", Note: "succeeded"},
+			{Type: oplog.OpTypeInit, Model: logbook.CommitModel, Ref: "QmHashOfVersion2"},
+			{Type: oplog.OpTypeInit, Model: logbook.PushModel, Size: 2, Relations: []string{"registry.qri.cloud"}},
+			{Type: oplog.OpTypeInit, Model: logbook.RunModel, Ref: "run-id-2", Note: "unchanged"},
+			{Type: oplog.OpTypeRemove, Model: logbook.PushModel, Size: 2, Relations: []string{"registry.qri.cloud"}},
+		},
+	}
+
+	// runs & pushes don't add versions, and removing a dataset from a remote
+	// doesn't remove versions
+	index, ref := convertHistoryToIndexAndRef(historyLog)
+	if index != 2 || ref != "QmHashOfVersion2" {
+		t.Errorf("expected index 2 & ref QmHashOfVersion2. got: %d, %q", index, ref)
+	}
+}
+
 // TODO(dlong): Test convertHistoryToIndexAndRef edge-cases, like big deletes, len(logs) > 0, len=0
 // TODO(dlong): Test a logbook where a username is changed after datasets already existed
 // TODO(dlong): Test a logbook with logs from other peers
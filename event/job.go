@@ -0,0 +1,13 @@
+package event
+
+const (
+	// ETJobStarted fires when a background job is created. Job progress is
+	// reported by the events of the underlying operation, eg: saving a dataset
+	// publishes ETDatasetSaveProgress events
+	// payload will be a lib.Job
+	ETJobStarted = Type("job:Started")
+	// ETJobCompleted fires when a background job stops, either by succeeding,
+	// failing, or being canceled
+	// payload will be a lib.Job
+	ETJobCompleted = Type("job:Completed")
+)
@@ -67,4 +67,15 @@ const (
 type TransformMessage struct {
 	Lvl TransformMsgLvl `json:"lvl"`
 	Msg string          `json:"msg"`
+	// Limit is set on error events caused by a transform exceeding an
+	// execution limit
+	Limit *TransformLimit `json:"limit,omitempty"`
+}
+
+// TransformLimit describes an execution limit a transform exceeded
+type TransformLimit struct {
+	// Name of the limit, eg: "maxSteps", "stepTimeout", "maxBodySize"
+	Name string `json:"name"`
+	// Max is the configured limit: a number of steps, nanoseconds, or bytes
+	Max int64 `json:"max"`
 }
@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/profile"
+)
+
+// AccessMethods encapsulates business logic for dataset access control lists
+type AccessMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (AccessMethods) CoreRequestsName() string { return "access" }
+
+// NewAccessMethods creates an AccessMethods pointer from a qri instance
+func NewAccessMethods(inst *Instance) *AccessMethods {
+	return &AccessMethods{
+		inst: inst,
+	}
+}
+
+// AccessParams defines parameters for changing the rights a profile has to a
+// dataset
+type AccessParams struct {
+	Ref string
+	// base58-encoded ID of the profile to grant rights to or revoke rights from
+	ProfileID string
+	// Rights to change, any of "read", "write" & "push". Revoking without any
+	// rights removes all access
+	Rights []string
+}
+
+// Valid returns an error if AccessParams fields are in an invalid state
+func (p *AccessParams) Valid() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if _, err := profile.IDB58Decode(p.ProfileID); err != nil {
+		return fmt.Errorf("invalid profile ID %q: %w", p.ProfileID, err)
+	}
+	return logbook.ValidACLRights(p.Rights)
+}
+
+// Grant adds rights for a profile to a dataset's access control list,
+// returning the updated list
+func (m *AccessMethods) Grant(ctx context.Context, p *AccessParams) ([]logbook.ACLEntry, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+	if len(p.Rights) == 0 {
+		return nil, fmt.Errorf("at least one right to grant is required")
+	}
+
+	if m.inst.http != nil {
+		res := []logbook.ACLEntry{}
+		if err := m.inst.http.Call(ctx, AEAccessGrant, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	initID, err := m.initID(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.inst.logbook.WriteACLGrant(ctx, initID, p.ProfileID, p.Rights...); err != nil {
+		return nil, err
+	}
+	return m.inst.logbook.ACL(ctx, initID)
+}
+
+// Revoke removes rights for a profile from a dataset's access control list,
+// returning the updated list
+func (m *AccessMethods) Revoke(ctx context.Context, p *AccessParams) ([]logbook.ACLEntry, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	if m.inst.http != nil {
+		res := []logbook.ACLEntry{}
+		if err := m.inst.http.Call(ctx, AEAccessRevoke, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	initID, err := m.initID(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.inst.logbook.WriteACLRevoke(ctx, initID, p.ProfileID, p.Rights...); err != nil {
+		return nil, err
+	}
+	return m.inst.logbook.ACL(ctx, initID)
+}
+
+// AccessListParams defines parameters for listing a dataset's access control
+// list
+type AccessListParams struct {
+	Ref string
+}
+
+// List shows the access control list for a dataset
+func (m *AccessMethods) List(ctx context.Context, p *AccessListParams) ([]logbook.ACLEntry, error) {
+	if p.Ref == "" {
+		return nil, fmt.Errorf("dataset reference is required")
+	}
+
+	if m.inst.http != nil {
+		res := []logbook.ACLEntry{}
+		if err := m.inst.http.Call(ctx, AEAccessList, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	initID, err := m.initID(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return m.inst.logbook.ACL(ctx, initID)
+}
+
+func (m *AccessMethods) initID(ctx context.Context, refstr string) (string, error) {
+	ref, _, err := m.inst.ParseAndResolveRef(ctx, refstr, "local")
+	if err != nil {
+		return "", err
+	}
+	return ref.InitID, nil
+}
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestAccessGrantRevoke(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	ctx := tr.Ctx
+	m := NewAccessMethods(tr.Instance)
+	tr.MustSaveFromBody(t, "access_test", "testdata/cities_2/body.csv")
+
+	granteeID := "QmSyDX5LYTiwQi861F5NAwdHrrnd1iRGsoEvCyzQMUyZ4W"
+	if _, err := m.Grant(ctx, &AccessParams{Ref: "peer/access_test", ProfileID: "not_an_id", Rights: []string{"read"}}); err == nil {
+		t.Error("expected granting to an invalid profile ID to fail")
+	}
+
+	acl, err := m.Grant(ctx, &AccessParams{Ref: "peer/access_test", ProfileID: granteeID, Rights: []string{"push", "read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []logbook.ACLEntry{{ProfileID: granteeID, Rights: []string{"read", "push"}}}
+	if diff := cmp.Diff(expect, acl); diff != "" {
+		t.Errorf("grant result mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err = m.Revoke(ctx, &AccessParams{Ref: "peer/access_test", ProfileID: granteeID}); err != nil {
+		t.Fatal(err)
+	}
+	acl, err = m.List(ctx, &AccessListParams{Ref: "peer/access_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acl) != 0 {
+		t.Errorf("expected empty access control list after revoking all rights, got: %v", acl)
+	}
+}
@@ -80,10 +80,90 @@ const (
 	AERename = APIEndpoint("/rename")
 	// AEDiff is an endpoint for generating dataset diffs
 	AEDiff = APIEndpoint("/diff")
+	// AEApplyPatch is an endpoint for applying patches created from diffs
+	AEApplyPatch = APIEndpoint("/patch")
+	// AESquash is an endpoint for collapsing runs of dataset versions
+	AESquash = APIEndpoint("/squash")
 	// AEChanges is an endpoint for generating dataset change reports
 	AEChanges = APIEndpoint("/changes")
 	// AEUnpack unpacks a zip file and sends it back
 	AEUnpack = APIEndpoint("/unpack/{path:.*}")
+	// AEImportSQLite creates datasets from the tables of a SQLite database
+	AEImportSQLite = APIEndpoint("/import/sqlite")
+	// AEImportDataPackage creates datasets from the resources of a data package
+	AEImportDataPackage = APIEndpoint("/import/datapackage")
+
+	// branch endpoints
+
+	// AEBranches lists the branches of a dataset history
+	AEBranches = APIEndpoint("/branches")
+	// AEBranchCreate creates a new branch of a dataset history
+	AEBranchCreate = APIEndpoint("/branch/create")
+	// AEBranchSwitch changes the active branch of a dataset
+	AEBranchSwitch = APIEndpoint("/branch/switch")
+	// AEMerge merges a branch into the active branch of a dataset
+	AEMerge = APIEndpoint("/merge")
+
+	// access control endpoints
+
+	// AEAccessList lists the access control list of a dataset
+	AEAccessList = APIEndpoint("/access/list")
+	// AEAccessGrant grants rights to a dataset to a profile
+	AEAccessGrant = APIEndpoint("/access/grant")
+	// AEAccessRevoke revokes rights to a dataset from a profile
+	AEAccessRevoke = APIEndpoint("/access/revoke")
+
+	// job endpoints
+
+	// AEJobs lists background jobs
+	AEJobs = APIEndpoint("/jobs")
+	// AEJob fetches the status of a background job
+	AEJob = APIEndpoint("/job")
+	// AEJobCancel cancels a running background job
+	AEJobCancel = APIEndpoint("/job/cancel")
+
+	// AESchedules lists datasets scheduled to run
+	AESchedules = APIEndpoint("/schedules")
+	// AEScheduleAdd schedules a dataset transform
+	AEScheduleAdd = APIEndpoint("/schedule/add")
+	// AEScheduleRemove removes a dataset schedule
+	AEScheduleRemove = APIEndpoint("/schedule/remove")
+	// AEScheduleRun runs a scheduled dataset transform immediately
+	AEScheduleRun = APIEndpoint("/schedule/run")
+
+	// AERuns lists the transform runs of a dataset
+	AERuns = APIEndpoint("/runs")
+	// AERun fetches the full record of a transform run
+	AERun = APIEndpoint("/run")
+
+	// secret store endpoints
+
+	// AESecrets lists the names of stored secrets
+	AESecrets = APIEndpoint("/secrets")
+	// AESecretSet stores a secret
+	AESecretSet = APIEndpoint("/secret/set")
+	// AESecretRemove removes a stored secret
+	AESecretRemove = APIEndpoint("/secret/remove")
+	// AESecretGrant allows a dataset's transforms to read a stored secret
+	AESecretGrant = APIEndpoint("/secret/grant")
+	// AESecretRevoke stops a dataset's transforms from reading a stored secret
+	AESecretRevoke = APIEndpoint("/secret/revoke")
+
+	// stats cache endpoints
+
+	// AEStatsCache lists the contents of the stats cache
+	AEStatsCache = APIEndpoint("/stats/cache")
+	// AEStatsCacheClear drops all cached stats
+	AEStatsCacheClear = APIEndpoint("/stats/cache/clear")
+	// AEStatsCachePrune drops unreferenced & stale cached stats
+	AEStatsCachePrune = APIEndpoint("/stats/cache/prune")
+
+	// repo maintenance endpoints
+
+	// AEGC collects unreferenced data from the repo
+	AEGC = APIEndpoint("/gc")
+	// AEFsck checks the repo for inconsistencies
+	AEFsck = APIEndpoint("/fsck")
 
 	// remote client endpoints
 
@@ -130,6 +210,8 @@ const (
 	AESQL = APIEndpoint("/sql")
 	// AEApply invokes a transform apply
 	AEApply = APIEndpoint("/apply")
+	// AEApplyTest runs the tests of a transform script
+	AEApplyTest = APIEndpoint("/apply/test")
 	// AEWebUI serves the remote WebUI
 	AEWebUI = APIEndpoint("/webui")
 )
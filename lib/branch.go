@@ -0,0 +1,300 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+)
+
+// BranchMethods encapsulates business logic for working with named lines of
+// dataset history
+type BranchMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (BranchMethods) CoreRequestsName() string { return "branch" }
+
+// NewBranchMethods creates a BranchMethods pointer from a qri instance
+func NewBranchMethods(inst *Instance) *BranchMethods {
+	return &BranchMethods{
+		inst: inst,
+	}
+}
+
+// BranchParams identifies a branch of a dataset history
+type BranchParams struct {
+	Ref    string
+	Branch string
+}
+
+// Valid returns an error if BranchParams fields are in an invalid state
+func (p *BranchParams) Valid() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if p.Branch == "" {
+		return fmt.Errorf("branch name is required")
+	}
+	return nil
+}
+
+// Create adds a new branch to a dataset history, starting at the head of the
+// active branch
+func (m *BranchMethods) Create(ctx context.Context, p *BranchParams) (*logbook.BranchInfo, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	if m.inst.http != nil {
+		res := &logbook.BranchInfo{}
+		if err := m.inst.http.Call(ctx, AEBranchCreate, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, err := m.resolveHead(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.inst.logbook.WriteBranchInit(ctx, ref.InitID, p.Branch); err != nil {
+		return nil, err
+	}
+	return m.branchInfo(ctx, ref.InitID, p.Branch)
+}
+
+// BranchListParams defines parameters for listing branches
+type BranchListParams struct {
+	Ref string
+}
+
+// List shows all branches of a dataset history
+func (m *BranchMethods) List(ctx context.Context, p *BranchListParams) ([]logbook.BranchInfo, error) {
+	if p.Ref == "" {
+		return nil, fmt.Errorf("dataset reference is required")
+	}
+
+	if m.inst.http != nil {
+		res := []logbook.BranchInfo{}
+		if err := m.inst.http.Call(ctx, AEBranches, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, err := m.resolveHead(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return m.inst.logbook.Branches(ctx, ref.InitID)
+}
+
+// Switch changes the active branch of a dataset. Subsequent saves are written
+// to the active branch, and the dataset reference resolves to its head
+func (m *BranchMethods) Switch(ctx context.Context, p *BranchParams) (*logbook.BranchInfo, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	if m.inst.http != nil {
+		res := &logbook.BranchInfo{}
+		if err := m.inst.http.Call(ctx, AEBranchSwitch, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, err := m.resolveHead(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.inst.logbook.SwitchBranch(ctx, ref.InitID, p.Branch); err != nil {
+		return nil, err
+	}
+
+	info, err := m.branchInfo(ctx, ref.InitID, p.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// point the repo reference at the head of the newly active branch
+	if info.HeadPath != ref.Path {
+		next := ref.Copy()
+		next.Path = info.HeadPath
+		if _, err := base.RewindDatasetRef(ctx, m.inst.repo, ref, next); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// MergeParams defines parameters for merging one branch of a dataset history
+// into the active branch
+type MergeParams struct {
+	Ref string
+	// Branch to merge into the active branch
+	Branch string
+	// commit title for the merge version, defaults to a generated description
+	Title   string
+	Message string
+}
+
+// Valid returns an error if MergeParams fields are in an invalid state
+func (p *MergeParams) Valid() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if p.Branch == "" {
+		return fmt.Errorf("branch to merge is required")
+	}
+	return nil
+}
+
+// MergeResult is the result of a merge
+type MergeResult struct {
+	// UpToDate is true when the merged branch has no versions the active branch
+	// doesn't already contain. no version is saved
+	UpToDate bool `json:"upToDate,omitempty"`
+	// Conflicts lists incompatible changes. When conflicts are present no
+	// version is saved
+	Conflicts []base.MergeConflict `json:"conflicts,omitempty"`
+	// Dataset is the newly saved merge version
+	Dataset *dataset.Dataset `json:"dataset,omitempty"`
+	// Stat & Diff describe changes the merge made to the active branch
+	Stat *DiffStat `json:"stat,omitempty"`
+	Diff []*Delta  `json:"diff,omitempty"`
+}
+
+// Merge performs a three-way merge of the structure, meta, and body of the
+// head of a branch into the head of the active branch, saving the result as a
+// new version on the active branch. If the branches made conflicting changes
+// Merge returns a result listing conflicts instead of saving
+func (m *BranchMethods) Merge(ctx context.Context, p *MergeParams) (*MergeResult, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	res := &MergeResult{}
+	if m.inst.http != nil {
+		if err := m.inst.http.Call(ctx, AEMerge, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, err := m.resolveHead(ctx, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := m.inst.logbook.Branches(ctx, ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	var active, theirs *logbook.BranchInfo
+	for i, b := range branches {
+		if b.Active {
+			active = &branches[i]
+		}
+		if b.Name == p.Branch {
+			theirs = &branches[i]
+		}
+	}
+	if theirs == nil {
+		return nil, fmt.Errorf("%w: %q", logbook.ErrBranchNotFound, p.Branch)
+	}
+	if active == nil || active.Name == theirs.Name {
+		return nil, fmt.Errorf("cannot merge branch %q into itself", p.Branch)
+	}
+
+	basePath, err := m.inst.logbook.MergeBase(ctx, ref.InitID, active.Name, theirs.Name)
+	if err != nil {
+		return nil, err
+	}
+	if theirs.HeadPath == "" || theirs.HeadPath == basePath {
+		res.UpToDate = true
+		return res, nil
+	}
+
+	fs := m.inst.repo.Filesystem()
+	ds, conflicts, err := base.MergeDatasets(ctx, fs, basePath, ref.Path, theirs.HeadPath)
+	if errors.Is(err, base.ErrMergeConflict) {
+		res.Conflicts = conflicts
+		return res, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	title := p.Title
+	if title == "" {
+		title = fmt.Sprintf("merge branch %q into %q", theirs.Name, active.Name)
+	}
+	ds.Name = ref.Name
+	ds.Peername = ref.Username
+	ds.Commit = &dataset.Commit{
+		Title:   title,
+		Message: p.Message,
+	}
+
+	switches := base.SaveSwitches{
+		Replace:          true,
+		Pin:              true,
+		ForceIfNoChanges: true,
+	}
+	saved, err := base.SaveDataset(ctx, m.inst.repo, m.inst.qfs.DefaultWriteFS(), ref.InitID, ref.Path, ds, nil, switches)
+	if err != nil {
+		return nil, err
+	}
+	res.Dataset = saved
+	if err = m.inst.logbook.WriteBranchMerge(ctx, ref.InitID, theirs.Name); err != nil {
+		return nil, err
+	}
+
+	// report what changed in the active branch
+	diff := &DiffResponse{}
+	diffParams := &DiffParams{
+		LeftSide:  fmt.Sprintf("%s@%s", ref.Human(), ref.Path),
+		RightSide: fmt.Sprintf("%s@%s", ref.Human(), saved.Path),
+	}
+	if err := NewDatasetMethods(m.inst).Diff(diffParams, diff); err != nil {
+		return nil, err
+	}
+	res.Stat = diff.Stat
+	res.Diff = diff.Diff
+	return res, nil
+}
+
+// resolveHead resolves a local dataset reference to the head of the active
+// branch
+func (m *BranchMethods) resolveHead(ctx context.Context, refstr string) (dsref.Ref, error) {
+	ref, err := dsref.Parse(refstr)
+	if err != nil {
+		return ref, fmt.Errorf("%q is not a valid dataset reference: %w", refstr, err)
+	}
+	if ref.Path != "" {
+		return ref, fmt.Errorf("branch operations require a dataset reference without a version path")
+	}
+	if _, err = m.inst.ResolveReference(ctx, &ref, "local"); err != nil {
+		return ref, err
+	}
+	return ref, nil
+}
+
+func (m *BranchMethods) branchInfo(ctx context.Context, initID, name string) (*logbook.BranchInfo, error) {
+	branches, err := m.inst.logbook.Branches(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		if b.Name == name {
+			return &b, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", logbook.ErrBranchNotFound, name)
+}
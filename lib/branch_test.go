@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestBranchSwitchAndMerge(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	ctx := tr.Ctx
+	m := NewBranchMethods(tr.Instance)
+
+	first := tr.MustSaveFromBody(t, "branch_test", tr.MustWriteTmpFile(t, "body.json", `[1,2]`))
+
+	if _, err := m.Create(ctx, &BranchParams{Ref: "peer/branch_test", Branch: "feature"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Switch(ctx, &BranchParams{Ref: "peer/branch_test", Branch: "feature"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:     "peer/branch_test",
+		Dataset: &dataset.Dataset{Meta: &dataset.Meta{Title: "feature title"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := m.Switch(ctx, &BranchParams{Ref: "peer/branch_test", Branch: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.HeadPath != first.Path {
+		t.Errorf("expected main branch head to be %q, got %q", first.Path, info.HeadPath)
+	}
+	if got := tr.MustGet(t, "peer/branch_test"); got.Path != first.Path {
+		t.Errorf("expected dataset to resolve to main branch head %q, got %q", first.Path, got.Path)
+	}
+
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:      "peer/branch_test",
+		BodyPath: tr.MustWriteTmpFile(t, "body_2.json", `[1,2,3]`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := m.Merge(ctx, &MergeParams{Ref: "peer/branch_test", Branch: "feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) > 0 {
+		t.Fatalf("unexpected merge conflicts: %v", res.Conflicts)
+	}
+
+	got := tr.MustGet(t, "peer/branch_test")
+	if got.Path != res.Dataset.Path {
+		t.Errorf("expected dataset head to be merge version %q, got %q", res.Dataset.Path, got.Path)
+	}
+	if got.Meta == nil || got.Meta.Title != "feature title" {
+		t.Errorf("expected merged meta title %q, got: %v", "feature title", got.Meta)
+	}
+	if got.Structure.Entries != 3 {
+		t.Errorf("expected merged body to have 3 entries, got %d", got.Structure.Entries)
+	}
+
+	res, err = m.Merge(ctx, &MergeParams{Ref: "peer/branch_test", Branch: "feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.UpToDate {
+		t.Errorf("expected merging the same branch twice to be up to date")
+	}
+}
@@ -20,13 +20,17 @@ import (
 	"github.com/qri-io/dag"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
+	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/localfs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/archive"
+	"github.com/qri-io/qri/base/columnar"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/base/fill"
+	"github.com/qri-io/qri/base/sqlite"
+	"github.com/qri-io/qri/checks"
 	"github.com/qri-io/qri/dscache/build"
 	"github.com/qri-io/qri/dsref"
 	qrierr "github.com/qri-io/qri/errors"
@@ -36,8 +40,10 @@ import (
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/stats"
 	"github.com/qri-io/qri/transform"
 	"github.com/qri-io/qri/transform/run"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 // DatasetMethods encapsulates business logic for working with Datasets on Qri
@@ -217,6 +223,9 @@ type GetParams struct {
 	// whether to generate a filename from the dataset name instead
 	GenFilename bool   `json:"genfilename"`
 	Remote      string `json:"remote"`
+	// how to calculate stats when the selector is "stats", one of
+	// [exact,estimate]. default is exact
+	StatsMode string `json:"statsmode"`
 }
 
 // SetNonZeroDefaults assigns default values
@@ -244,6 +253,12 @@ func parseSelector(selector string) (string, string, error) {
 	if strings.HasSuffix(selector, ".zip") {
 		format = "zip"
 	}
+	if strings.HasSuffix(selector, ".parquet") {
+		format = columnar.ParquetFormat
+	}
+	if strings.HasSuffix(selector, ".arrow") {
+		format = columnar.ArrowFormat
+	}
 
 	if format != "" {
 		selector = selector[:len(selector)-len(format)-1]
@@ -308,13 +323,16 @@ func (p *GetParams) UnmarshalFromRequest(r *http.Request) error {
 		params.Selector = "body"
 	}
 
-	if params.Format != "" && params.Format != "json" && params.Format != "csv" && params.Format != "zip" {
+	if params.Format != "" && params.Format != "json" && params.Format != "csv" && params.Format != "zip" && !columnar.IsFormat(params.Format) {
 		return fmt.Errorf("invalid extension format")
 	}
 
 	if params.Remote == "" {
 		params.Remote = r.FormValue("remote")
 	}
+	if params.StatsMode == "" {
+		params.StatsMode = r.FormValue("statsmode")
+	}
 
 	// TODO(arqu): we default to true but should implement a guard and/or respect the page params
 	params.All = true
@@ -455,6 +473,19 @@ func (m *DatasetMethods) Get(ctx context.Context, p *GetParams) (*GetResult, err
 		if !p.All && (p.Limit < 0 || p.Offset < 0) {
 			return nil, fmt.Errorf("invalid limit / offset settings")
 		}
+		if columnar.IsFormat(p.Format) {
+			// column-oriented formats aren't body formats, convert from the
+			// stored body using the structure's tabular schema
+			res.Bytes, err = base.ReadColumnarBody(ds, p.Format, p.Limit, p.Offset, p.All)
+			if err != nil {
+				log.Debugf("Get dataset, base.ReadColumnarBody %q failed, error: %s", ds, err)
+				return nil, err
+			}
+			if err = m.maybeWriteOutfile(p, res); err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
 		df, err := dataset.ParseDataFormatString(p.Format)
 		if err != nil {
 			log.Debugf("Get dataset, ParseDataFormatString %q failed, error: %s", p.Format, err)
@@ -500,6 +531,7 @@ func (m *DatasetMethods) Get(ctx context.Context, p *GetParams) (*GetResult, err
 	} else if p.Selector == "stats" {
 		statsParams := &StatsParams{
 			Dataset: res.Dataset,
+			Mode:    p.StatsMode,
 		}
 		sa, err := m.Stats(ctx, statsParams)
 		if err != nil {
@@ -608,6 +640,11 @@ type SaveParams struct {
 	Message string
 	// path to body data
 	BodyPath string
+	// Table names the table to read when the body path is a SQLite database
+	Table string
+	// Query reads the result of a SQL query when the body path is a SQLite
+	// database
+	Query string
 	// absolute path or URL to the list of dataset files or components to load
 	FilePaths []string
 	// secrets for transform execution
@@ -616,11 +653,23 @@ type SaveParams struct {
 	// note: this won't work over RPC, only on local calls
 	ScriptOutput io.Writer `json:"-"`
 
-	// TODO(dustmop): add `Wait bool`, if false, run the save asynchronously
-	// and return events on the bus that provide the progress of the save operation
+	// Async runs the save in the background. Async saves must be started with
+	// DatasetMethods.SaveAsync, which returns a job that tracks the save
+	Async bool
 
 	// Apply runs a transform script to create the next version to save
 	Apply bool
+	// NoCache runs every transform step when applying, instead of skipping
+	// steps that are unchanged since they last ran
+	NoCache bool
+	// RecordHTTP saves the HTTP requests & responses a transform makes while
+	// applying, storing them with the run in the logbook. Recording runs every
+	// transform step
+	RecordHTTP bool
+	// ReplayRun answers HTTP requests a transform makes while applying with
+	// the responses recorded by a previous run of the dataset, instead of the
+	// network
+	ReplayRun string
 	// Replace writes the entire given dataset as a new snapshot instead of
 	// applying save params as augmentations to the existing history
 	Replace bool
@@ -705,9 +754,18 @@ func (p *SaveParams) UnmarshalFromRequest(r *http.Request) error {
 	if v := r.FormValue("bodypath"); v != "" {
 		p.BodyPath = v
 	}
+	if v := r.FormValue("table"); v != "" {
+		p.Table = v
+	}
+	if v := r.FormValue("query"); v != "" {
+		p.Query = v
+	}
 	if v := r.FormValue("drop"); v != "" {
 		p.Drop = v
 	}
+	if v := r.FormValue("async"); v != "" {
+		p.Async = v == "true"
+	}
 
 	if r.FormValue("secrets") != "" {
 		p.Secrets = map[string]string{}
@@ -727,11 +785,53 @@ func (p *SaveParams) SetNonZeroDefaults() {
 	p.ConvertFormatToPrev = true
 }
 
-// Save adds a history entry, updating a dataset
+// SaveAsync starts saving a dataset in the background, returning a job that
+// tracks the save. Progress is reported with ETDatasetSaveProgress events, and
+// an ETJobCompleted event fires when the save stops. Use JobMethods to check on
+// or cancel the save. Transform script output isn't captured by background
+// saves, subscribe to ETTransformPrint events instead
+func (m *DatasetMethods) SaveAsync(ctx context.Context, p *SaveParams) (*Job, error) {
+	log.Debugw("DatasetMethods.SaveAsync", "ref", p.Ref, "apply", p.Apply)
+
+	if m.inst.http != nil {
+		p.ScriptOutput = nil
+		p.Async = true
+		res := &Job{}
+		if err := m.inst.http.Call(ctx, AESave, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if m.inst.jobs == nil {
+		return nil, ErrNoJobRegistry
+	}
+
+	params := *p
+	params.Async = false
+	params.ScriptOutput = nil
+	// the save runs on a context owned by the job registry, so the save can
+	// outlive the caller's context
+	job := m.inst.jobs.start(JobTypeSave, p.Ref, func(ctx context.Context) (string, error) {
+		ds, err := m.Save(ctx, &params)
+		if err != nil {
+			return "", err
+		}
+		return ds.Path, nil
+	})
+	return &job, nil
+}
+
+// Save adds a history entry, updating a dataset. Save blocks until the new
+// version is written, see SaveAsync for saving in the background
 func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Dataset, error) {
 	log.Debugw("DatasetMethods.Save", "ref", p.Ref, "apply", p.Apply)
 	res := &dataset.Dataset{}
 
+	if p.Async {
+		return nil, fmt.Errorf("asynchronous saves must be started with SaveAsync")
+	}
+
 	if m.inst.http != nil {
 		p.ScriptOutput = nil
 		err := m.inst.http.Call(ctx, AESave, p, &res)
@@ -789,7 +889,18 @@ func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Data
 		return nil, err
 	}
 
-	ref, isNew, err := base.PrepareSaveRef(ctx, pro, m.inst.logbook, resolver, p.Ref, ds.BodyPath, p.NewName)
+	// read bodies from SQLite databases before preparing a reference, new
+	// datasets are named after the table they're read from
+	nameHint := ds.BodyPath
+	if p.Table != "" || p.Query != "" || sqlite.IsDatabase(ds.BodyPath) {
+		if err = base.ReadSQLiteBody(ctx, ds, p.Table, p.Query); err != nil {
+			log.Debugw("save ReadSQLiteBody", "bodyPath", ds.BodyPath, "err", err)
+			return nil, err
+		}
+		nameHint = ds.BodyFile().FileName()
+	}
+
+	ref, isNew, err := base.PrepareSaveRef(ctx, pro, m.inst.logbook, resolver, p.Ref, nameHint, p.NewName)
 	if err != nil {
 		log.Debugw("save PrepareSaveRef", "refParam", p.Ref, "wantNewName", p.NewName, "err", err)
 		return nil, err
@@ -844,40 +955,63 @@ func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Data
 		log.Debugw("save OpenDataset", "err", err.Error())
 		return nil, err
 	}
+	if err = base.ConvertColumnarBody(ds); err != nil {
+		log.Debugw("save ConvertColumnarBody", "err", err.Error())
+		return nil, err
+	}
 
 	// runState holds the results of transform application. will be non-nil if a
 	// transform is applied while saving
 	var runState *run.State
+	// redact removes the values of secrets passed to a transform from run
+	// messages
+	var redact startf.Redactor
 
 	// If applying a transform, execute its script before saving
 	if p.Apply {
+		secrets := m.inst.transformSecrets(ref, p.Secrets)
+		redact = startf.NewRedactor(secrets)
+		// allocate an ID for the transform, subscribe to print output & build up
+		// runState. runs that fail before the transform starts are recorded too
+		runID := transform.NewRunID()
+		runState = run.NewState(runID)
+		runState.InitID = ref.InitID
+		failRun := func(err error) error {
+			m.inst.putFailedRun(ctx, runState, redact.Redact(err.Error()))
+			return err
+		}
+
 		if ds.Transform == nil {
 			// if no transform component exists, load the latest transform component
 			// from history
 			if isNew {
-				return nil, fmt.Errorf("cannot apply while saving without a transform")
+				return nil, failRun(fmt.Errorf("cannot apply while saving without a transform"))
 			}
 
 			prevTransformDataset, err := base.LoadRevs(ctx, m.inst.qfs, ref, []*dsref.Rev{{Field: "tf", Gen: 1}})
 			if err != nil {
-				return nil, fmt.Errorf("loading transform component from history: %w", err)
+				return nil, failRun(fmt.Errorf("loading transform component from history: %w", err))
 			}
 			ds.Transform = prevTransformDataset.Transform
+			if ds.Transform != nil {
+				if err := ds.Transform.OpenScriptFile(ctx, m.inst.qfs); err != nil {
+					return nil, failRun(fmt.Errorf("opening transform script from history: %w", err))
+				}
+			}
 		}
 
 		str := m.inst.node.LocalStreams
 		scriptOut := p.ScriptOutput
-		secrets := p.Secrets
-		// allocate an ID for the transform, subscribe to print output & build up
-		// runState
-		runID := transform.NewRunID()
-		runState = run.NewState(runID)
-		// create a loader so transforms can call `load_dataset`
-		// TODO(b5) - add a ResolverMode save parameter and call m.inst.resolverForMode
-		// on the passed in mode string instead of just using the default resolver
+		// create a loader so transforms can call `load_dataset` & SQL steps can
+		// read tables. references can be prefixed with a source to resolve
+		// through, eg: "registry:b5/world_bank_population"
+		// TODO(b5) - add a ResolverMode save parameter to set the default source
 		// cmd can then define "remote" and "offline" flags, that set the ResolverMode
 		// string and control how transform functions
-		loader := NewParseResolveLoadFunc("", m.inst.defaultResolver(), m.inst)
+		loader, err := m.inst.newSourceLoader("", "")
+		if err != nil {
+			return nil, failRun(err)
+		}
 
 		m.inst.bus.SubscribeID(func(ctx context.Context, e event.Event) error {
 			runState.AddTransformEvent(e)
@@ -892,12 +1026,44 @@ func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Data
 			return nil
 		}, runID)
 
+		limits, err := m.inst.transformLimits(ctx, startf.Limits{})
+		if err != nil {
+			return nil, failRun(err)
+		}
+		opts := []func(*startf.ExecOpts){startf.SetLimits(limits)}
+
+		noCache := p.NoCache
+		var recording *startf.HTTPRecording
+		if p.RecordHTTP {
+			// cached steps don't make requests, run every step to record them all
+			noCache = true
+			recording = startf.NewHTTPRecording()
+			opts = append(opts, startf.RecordHTTP(recording))
+		}
+		if p.ReplayRun != "" {
+			if p.RecordHTTP {
+				return nil, failRun(fmt.Errorf("cannot both record & replay HTTP requests"))
+			}
+			replay, err := m.inst.loadHTTPRecording(ctx, ref.InitID, p.ReplayRun)
+			if err != nil {
+				return nil, failRun(err)
+			}
+			opts = append(opts, startf.ReplayHTTP(replay))
+		}
+
 		// apply the transform
 		shouldWait := true
-		err := m.inst.transform.Apply(ctx, ds, loader, runID, m.inst.bus, shouldWait, str, scriptOut, secrets)
+		err = m.inst.transform.Apply(ctx, ds, loader, runID, m.inst.bus, shouldWait, str, scriptOut, secrets, noCache, opts...)
+		if recording != nil {
+			path, recErr := m.inst.saveHTTPRecording(ctx, recording)
+			if recErr != nil {
+				return nil, failRun(recErr)
+			}
+			runState.HTTPRecording = path
+		}
 		if err != nil {
-			log.Errorw("transform run error", "err", err.Error())
-			runState.Message = err.Error()
+			m.inst.putFailedRun(ctx, runState, redact.Redact(err.Error()))
+			log.Errorw("transform run error", "err", runState.Message)
 			if err := m.inst.logbook.WriteTransformRun(ctx, ref.InitID, runState); err != nil {
 				log.Debugw("writing errored transform run to logbook:", "err", err.Error())
 				return nil, err
@@ -934,11 +1100,15 @@ func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Data
 		// to logbook
 		if errors.Is(err, dsfs.ErrNoChanges) && runState != nil {
 			runState.Status = run.RSUnchanged
-			runState.Message = err.Error()
+			runState.Message = redact.Redact(err.Error())
+			m.inst.putRun(ctx, runState)
 			if err := m.inst.logbook.WriteTransformRun(ctx, ref.InitID, runState); err != nil {
 				log.Debugw("writing unchanged transform run to logbook:", "err", err.Error())
 				return nil, err
 			}
+		} else if runState != nil {
+			// keep a record of runs that couldn't be saved for debugging
+			m.inst.putFailedRun(ctx, runState, redact.Redact(err.Error()))
 		}
 
 		log.Debugw("save base.SaveDataset", "err", err)
@@ -948,6 +1118,11 @@ func (m *DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Data
 	success = true
 	*res = *savedDs
 
+	if runState != nil {
+		runState.Path = savedDs.Path
+		m.inst.putRun(ctx, runState)
+	}
+
 	// TODO (b5) - this should be integrated into base.SaveDataset
 	if fsiPath != "" {
 		vi := dsref.ConvertDatasetToVersionInfo(savedDs)
@@ -1331,6 +1506,8 @@ type ValidateResponse struct {
 	Structure *dataset.Structure
 	// Validation Errors
 	Errors []jsonschema.KeyError
+	// Results of data quality checks declared in the dataset's meta component
+	Checks checks.Results
 }
 
 // Validate gives a dataset of errors and issues for a given dataset
@@ -1458,14 +1635,55 @@ func (m *DatasetMethods) Validate(p *ValidateParams, res *ValidateResponse) erro
 		}
 	}
 
+	var (
+		declared []*checks.Check
+		results  checks.Results
+		stored   bool
+	)
+	if fsiPath == "" && p.Ref != "" && p.BodyFilename == "" && schemaFlagType == "" {
+		// an unchanged version has the results of checks run when it was saved.
+		// versions saved without checks have no results, run any declared checks
+		if results, err = dsfs.LoadCheckResults(ctx, m.inst.repo.Filesystem(), ref.Path); err == nil {
+			stored = true
+		}
+	}
+	if ds != nil && !stored {
+		if declared, err = checks.FromMeta(ds.Meta); err != nil {
+			return err
+		}
+	}
+
+	var checkBody qfs.File
+	if len(declared) > 0 && body != nil {
+		// checks read the body a second time, buffer it in memory
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		body.Close()
+		body = qfs.NewMemfileBytes(body.FullPath(), data)
+		checkBody = qfs.NewMemfileBytes(body.FullPath(), data)
+	}
+
 	valerrs, err := base.Validate(ctx, m.inst.repo, body, st)
 	if err != nil {
 		return err
 	}
 
+	if checkBody != nil {
+		rdr, err := dsio.NewEntryReader(st, checkBody)
+		if err != nil {
+			return err
+		}
+		if results, err = checks.Run(ctx, declared, st, rdr, base.CheckReferenceLoader(m.inst.repo)); err != nil {
+			return err
+		}
+	}
+
 	*res = ValidateResponse{
 		Structure: st,
 		Errors:    valerrs,
+		Checks:    results,
 	}
 	return nil
 }
@@ -1540,6 +1758,11 @@ type StatsParams struct {
 	// if we get a Dataset from the params, then we do not have to
 	// attempt to open a dataset from the reference
 	Dataset *dataset.Dataset
+	// Mode selects how stats are calculated, one of [exact,estimate]. exact
+	// stats read every entry in the body. estimates stream the body through
+	// fixed-size sketches, and are marked with "estimate": true. default is
+	// exact
+	Mode string
 }
 
 // Stats generates stats for a dataset
@@ -1547,8 +1770,9 @@ func (m *DatasetMethods) Stats(ctx context.Context, p *StatsParams) (*dataset.St
 	if m.inst.http != nil {
 		res := &dataset.Stats{}
 		params := &GetParams{
-			Refstr:   p.Refstr,
-			Selector: "stats",
+			Refstr:    p.Refstr,
+			Selector:  "stats",
+			StatsMode: p.Mode,
 		}
 		err := m.inst.http.Call(ctx, AEGet, params, res)
 		if err != nil {
@@ -1560,6 +1784,10 @@ func (m *DatasetMethods) Stats(ctx context.Context, p *StatsParams) (*dataset.St
 	if p.Refstr == "" && p.Dataset == nil {
 		return nil, fmt.Errorf("either a reference or dataset is required")
 	}
+	mode, err := stats.ParseMode(p.Mode)
+	if err != nil {
+		return nil, err
+	}
 
 	ds := p.Dataset
 	if ds == nil {
@@ -1573,7 +1801,7 @@ func (m *DatasetMethods) Stats(ctx context.Context, p *StatsParams) (*dataset.St
 		}
 	}
 
-	return m.inst.stats.Stats(ctx, ds)
+	return m.inst.stats.StatsMode(ctx, ds, mode)
 }
 
 // formFileDataset extracts a dataset document from a http Request
@@ -1675,7 +1903,8 @@ func formFileDataset(r *http.Request, ds *dataset.Dataset) (err error) {
 		ds.BodyPath = bodyHeader.Filename
 		ds.BodyBytes = bodyData
 
-		if ds.Structure == nil {
+		// column-oriented bodies are converted to JSON when saving
+		if ds.Structure == nil && columnar.FilenameFormat(bodyHeader.Filename) == "" {
 			// TODO - this is silly and should move into base.PrepareDataset funcs
 			ds.Structure = &dataset.Structure{}
 			format, err := detect.ExtensionDataFormat(bodyHeader.Filename)
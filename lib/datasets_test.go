@@ -27,6 +27,7 @@ import (
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/checks"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
@@ -34,6 +35,7 @@ import (
 	p2ptest "github.com/qri-io/qri/p2p/test"
 	reporef "github.com/qri-io/qri/repo/ref"
 	testrepo "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 func TestDatasetRequestsSave(t *testing.T) {
@@ -281,6 +283,39 @@ func TestDatasetRequestsSaveApply(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsSaveApplyAllowedHosts(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[["a",1]]`))
+	}))
+	defer s.Close()
+
+	tf := func(allowed ...interface{}) *dataset.Transform {
+		return &dataset.Transform{
+			Config: map[string]interface{}{"allowedHosts": allowed},
+			Steps: []*dataset.TransformStep{
+				{Syntax: "starlark", Category: "setup", Script: "load(\"http.star\", \"http\")"},
+				{Syntax: "starlark", Category: "download", Script: fmt.Sprintf("def download(ctx):\n\treturn http.get(%q).json()", s.URL)},
+				{Syntax: "starlark", Category: "transform", Script: "def transform(ds, ctx):\n\tds.set_body(ctx.download)"},
+			},
+		}
+	}
+
+	_, err := run.SaveWithParams(&SaveParams{Ref: "me/fetched", Dataset: &dataset.Dataset{Transform: tf("example.com")}, Apply: true})
+	if err == nil {
+		t.Fatal("expected saving a transform that requests a host it doesn't allow to fail")
+	}
+	if !strings.Contains(err.Error(), startf.ErrHostNotAllowed.Error()) {
+		t.Errorf("expected error to contain %q, got: %s", startf.ErrHostNotAllowed, err)
+	}
+
+	if _, err := run.SaveWithParams(&SaveParams{Ref: "me/fetched", Dataset: &dataset.Dataset{Transform: tf("127.0.0.1")}, Apply: true}); err != nil {
+		t.Errorf("expected saving a transform that requests an allowed host to succeed, got: %s", err)
+	}
+}
+
 func TestDatasetRequestsList(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -1130,6 +1165,51 @@ Pirates of the Caribbean: At World's End ,foo
 	}
 }
 
+func TestDatasetRequestsValidateStoredChecks(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	idsFilename := tr.MakeTmpFilename("ids.json")
+	tr.MustWriteFile(t, idsFilename, `[{"id":1},{"id":2}]`)
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "me/ids", BodyPath: idsFilename}); err != nil {
+		t.Fatal(err)
+	}
+
+	md := &dataset.Meta{Title: "people"}
+	md.Set(checks.MetaKey, []interface{}{
+		map[string]interface{}{"type": "reference", "column": "id", "dataset": "me/ids", "datasetColumn": "id", "level": "warn"},
+	})
+	peopleFilename := tr.MakeTmpFilename("people.json")
+	tr.MustWriteFile(t, peopleFilename, `[{"id":1},{"id":2}]`)
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "me/people", BodyPath: peopleFilename, Dataset: &dataset.Dataset{Meta: md}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new version of the referenced dataset fails the check if it's run again
+	tr.MustWriteFile(t, idsFilename, `[{"id":3}]`)
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "me/ids", BodyPath: idsFilename}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDatasetMethods(tr.Instance)
+	res := &ValidateResponse{}
+	if err := m.Validate(&ValidateParams{Ref: "me/people"}, res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Checks) != 1 || !res.Checks[0].Passed {
+		t.Errorf("expected validating an unchanged version to report the check results stored when it was saved, got: %v", res.Checks)
+	}
+
+	// a body file that isn't part of the version runs the declared checks
+	res = &ValidateResponse{}
+	if err := m.Validate(&ValidateParams{Ref: "me/people", BodyFilename: peopleFilename}, res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Checks) != 1 || res.Checks[0].Passed {
+		t.Errorf("expected validating a new body to run checks against the latest referenced dataset, got: %v", res.Checks)
+	}
+}
+
 func TestDatasetRequestsValidateFSI(t *testing.T) {
 	ctx := context.Background()
 	tr := newTestRunner(t)
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/component"
 	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/base/rowdiff"
 	"github.com/qri-io/qri/dsref"
 	qerr "github.com/qri-io/qri/errors"
 )
@@ -21,6 +23,13 @@ type Delta = deepdiff.Delta
 // away from packages that depend on lib
 type DiffStat = deepdiff.Stats
 
+// RowDelta is an alias for rowdiff.Delta, a change to a single row of a body
+// diffed by primary key
+type RowDelta = rowdiff.Delta
+
+// RowDiffStat is an alias for rowdiff.Stats
+type RowDiffStat = rowdiff.Stats
+
 // DiffParams defines parameters for diffing two sources. There are three valid ways to use these
 // parameters: 1) both LeftSide and RightSide set, 2) only LeftSide set with a WorkingDir, 3) only
 // LeftSide set with the UseLeftPrevVersion flag.
@@ -81,10 +90,17 @@ func (p *DiffParams) diffMode() (DiffMode, error) {
 
 // DiffResponse is the result of a call to diff
 type DiffResponse struct {
+	// Base is the path of the dataset version on the left side of the diff,
+	// patches created from the diff apply to this version
+	Base       string    `json:"base,omitempty"`
 	Stat       *DiffStat `json:"stat,omitempty"`
 	SchemaStat *DiffStat `json:"schemaStat,omitempty"`
 	Schema     []*Delta  `json:"schema,omitempty"`
 	Diff       []*Delta  `json:"diff,omitempty"`
+	// RowStat & Rows describe body changes when bodies are diffed by the
+	// primary key both structures declare. Stat & Diff then leave the body out
+	RowStat *RowDiffStat `json:"rowStat,omitempty"`
+	Rows    []*RowDelta  `json:"rows,omitempty"`
 }
 
 // DiffMode is one of the methods that diff can perform
@@ -163,6 +179,7 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 		}
 		return err
 	}
+	res.Base = ds.Path
 	// TODO (b5) - setting name & peername to zero values makes tests pass, but
 	// calling ds.DropDerivedValues is overzealous. investigate the right solution
 	ds.Name = ""
@@ -200,6 +217,7 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 		if err != nil {
 			return err
 		}
+		res.Base = ds.Path
 		leftComp = component.ConvertDatasetToComponents(ds, m.inst.repo.Filesystem())
 	case DatasetRefDiffMode:
 		ds, err = parseResolveLoad(ctx, p.RightSide)
@@ -213,6 +231,28 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 		rightComp = component.ConvertDatasetToComponents(ds, m.inst.repo.Filesystem())
 	}
 
+	selector := p.Selector
+	if selector == "" {
+		selector = "dataset"
+	}
+
+	// bodies with the same primary key are diffed row-by-row, whether the body
+	// is selected or the whole dataset is diffed. keyed bodies are left out of
+	// the tree diff, so they're never read into memory
+	keyedBody := false
+	if selector == "body" || selector == "dataset" {
+		leftBody := leftComp.Base().GetSubcomponent("body")
+		rightBody := rightComp.Base().GetSubcomponent("body")
+		if leftBody != nil && rightBody != nil {
+			if keyedBody, err = keyedBodyDiff(ctx, m.inst.repo.Filesystem(), leftBody, rightBody, res); err != nil {
+				return err
+			}
+		}
+		if keyedBody && selector == "body" {
+			return nil
+		}
+	}
+
 	// If in an FSI linked working directory, drop derived values, since the user is not
 	// expected to have those transient values on their checked out files.
 	if diffMode == WorkingDirectoryDiffMode {
@@ -237,7 +277,7 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 				ds.Peername = ""
 				ds.PreviousPath = ""
 				bodyComp := leftComp.Base().GetSubcomponent("body")
-				if bodyComp != nil {
+				if bodyComp != nil && !keyedBody {
 					bodyComp.LoadAndFill(ds)
 					ds.Body, err = bodyComp.StructuredData()
 					if err != nil {
@@ -258,7 +298,7 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 				ds.Peername = ""
 				ds.PreviousPath = ""
 				bodyComp := rightComp.Base().GetSubcomponent("body")
-				if bodyComp != nil {
+				if bodyComp != nil && !keyedBody {
 					bodyComp.LoadAndFill(ds)
 					ds.Body, err = bodyComp.StructuredData()
 					if err != nil {
@@ -270,10 +310,6 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 		}
 	}
 
-	selector := p.Selector
-	if selector == "" {
-		selector = "dataset"
-	}
 	leftComp = leftComp.Base().GetSubcomponent(selector)
 	rightComp = rightComp.Base().GetSubcomponent(selector)
 	if leftComp == nil || rightComp == nil {
@@ -288,12 +324,65 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) (err error) {
 	if err != nil {
 		return err
 	}
+	if keyedBody {
+		dropBody(leftData)
+		dropBody(rightData)
+	}
 
 	dd := deepdiff.New()
 	res.Diff, res.Stat, err = dd.StatDiff(ctx, leftData, rightData)
 	return err
 }
 
+// keyedBodyDiff diffs bodies row-by-row when both structures declare the same
+// primary key, reading bodies from their source files. It returns false if
+// bodies can't be diffed by key
+func keyedBodyDiff(ctx context.Context, fs qfs.Filesystem, left, right component.Component, res *DiffResponse) (bool, error) {
+	lb, ok := left.(*component.BodyComponent)
+	if !ok || lb.SourceFile == "" {
+		return false, nil
+	}
+	rb, ok := right.(*component.BodyComponent)
+	if !ok || rb.SourceFile == "" {
+		return false, nil
+	}
+	lKey, err := rowdiff.PrimaryKey(lb.Structure)
+	if err != nil {
+		return false, nil
+	}
+	rKey, err := rowdiff.PrimaryKey(rb.Structure)
+	if err != nil || strings.Join(lKey, ",") != strings.Join(rKey, ",") {
+		// bodies with different keys fall back to a tree diff
+		return false, nil
+	}
+
+	open := func(path string) func(ctx context.Context) (qfs.File, error) {
+		return func(ctx context.Context) (qfs.File, error) {
+			return fs.Get(ctx, path)
+		}
+	}
+	rows := []*RowDelta{}
+	res.RowStat, err = rowdiff.Diff(ctx,
+		rowdiff.Body{Structure: lb.Structure, Open: open(lb.SourceFile)},
+		rowdiff.Body{Structure: rb.Structure, Open: open(rb.SourceFile)},
+		func(d *RowDelta) error {
+			rows = append(rows, d)
+			return nil
+		})
+	if err != nil {
+		return false, err
+	}
+	res.Rows = rows
+	return true, nil
+}
+
+// dropBody removes an inlined body from the structured data of a dataset
+func dropBody(data interface{}) {
+	if ds, ok := data.(map[string]interface{}); ok {
+		delete(ds, "body")
+	}
+}
+
 func schemaDiff(ctx context.Context, left, right *component.BodyComponent) ([]*Delta, *DiffStat, error) {
 	dd := deepdiff.New()
 	if left.Format == ".csv" && right.Format == ".csv" {
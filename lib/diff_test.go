@@ -1,9 +1,11 @@
 package lib
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/dsref"
 )
 
@@ -194,7 +196,7 @@ func TestDiffPrevRevision(t *testing.T) {
 
 	// TODO(dustmop): Come up with a better way to represent this diff, that still looks nice when
 	// compared with cmp.Diff.
-	expect := `{"stat":{"leftNodes":36,"rightNodes":46,"leftWeight":510,"rightWeight":637,"inserts":4,"deletes":2},"diff":[[" ",0,["toronto",50000000,55.5,false]],[" ",1,["new york",8500000,44.4,true]],[" ",2,["los angeles",3990000,42.7,true]],["-",3,["chicago",300000,44.4,true]],["+",3,["dallas",1340000,30,true]],[" ",4,["chatham",35000,65.25,true]],[" ",5,null,[[" ",0,"mexico city"],["-",1,70000000],["+",1,80000000],[" ",2,28.6],[" ",3,false]]],[" ",6,["raleigh",250000,50.65,true]],["+",7,["paris",2100000,41.1,false]],["+",8,["london",8900000,36.5,false]]]}`
+	expect := `{"base":"/mem/QmeakdvLuqr8mtt6z16VxSPcTV2RhF6qukco49nX4hsM6B","stat":{"leftNodes":36,"rightNodes":46,"leftWeight":510,"rightWeight":637,"inserts":4,"deletes":2},"diff":[[" ",0,["toronto",50000000,55.5,false]],[" ",1,["new york",8500000,44.4,true]],[" ",2,["los angeles",3990000,42.7,true]],["-",3,["chicago",300000,44.4,true]],["+",3,["dallas",1340000,30,true]],[" ",4,["chatham",35000,65.25,true]],[" ",5,null,[[" ",0,"mexico city"],["-",1,70000000],["+",1,80000000],[" ",2,28.6],[" ",3,false]]],[" ",6,["raleigh",250000,50.65,true]],["+",7,["paris",2100000,41.1,false]],["+",8,["london",8900000,36.5,false]]]}`
 
 	if diff := cmp.Diff(expect, output); diff != "" {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
@@ -221,7 +223,7 @@ func TestDiff(t *testing.T) {
 	// compared with cmp.Diff.
 	// TODO(dustmop): Would be better if Diff only returned the changes, instead of things that
 	// stay the same, since the delta in this case is pretty small.
-	expect := `{"stat":{"leftNodes":102,"rightNodes":112,"leftWeight":3814,"rightWeight":4170,"inserts":51,"deletes":25},"diff":[["-","bodyPath","/mem/Qmc7AoCfFVW5xe8qhyjNYewSgBHFubp6yLM3mfBzQp7iTr"],["+","bodyPath","/mem/QmYuVj1JvALB9Au5DNcVxGLMcWCDBUfbKCN3QbpvissSC4"],[" ","commit",null,[[" ","author",{"id":"QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt"}],["-","message","created dataset from body.csv"],["+","message","created dataset from body_more.csv"],["-","path","/mem/QmPuJT13PENgG6yQQsC5SxRickT8CfBGdP27Q1DFzYbR1U"],["+","path","/mem/QmQm7BGbSbjXSXJopZG23j1ruAYHNPMGk5rsbsvaJ2AKH3"],[" ","qri","cm:0"],["-","signature","aO6TIie3VfmC8fvzZL4BulTUYd98nEMzvXrsw8Ght3Tt4vDEaZ58v1iogI1BHVX4lVLlVIJn2F5JK1vZEGB/Du8Y9qvZi1VPvZIT4kRBG4KcGezNHQmiQidk9GgnuqgVVW+wPgDyAwrP+FD2B/3U+UXTeqUKiTeaYEiS+8odqnBWkA0lNjkIROVz3Q7bOmKUZePzvCDONv4zugpLgKSPNuEbAv8qf2rVZLzrOiA7C1z1dh1kgrfv+RNgrNwWL6PmdfebE2ND5kr9X6qesZamUXyrK97Zb/KDZwEOsDPUQlQYdXlzvi0yx+o2V7vnwbkUN6PO9ytmOsh3egL7TTJH1A=="],["+","signature","M1RDiczU7mze3siJoi5BCi73oV7c0bXisPXYlZD11fvXeCCiu+NYvUMcDIOB5u/k/CNn4zi51NX503mxy05wS6I7D5FDl6GME9qyiky8KY5vDobgFBq93ht+p+/arTo2G0a0FdWgaQf9c0YWid1xTbBlZ2ED558AZFBnH7QHhtZRc5YDRPVAEMP/0R9pgDsM4lfo52cOfIxmg7cRkcjfvJ6pkTRPMDNu/M1ZvveF0fq5sMMJ+8joA0Kh9IkLU2CiwxfkFKcQi2fDcixjpFoikyRqQbLYATMRDrB+s18eSMMO5AKMp41WonBGBaAv+j0RGEONtJBaN7pmQU5od3Y9Tw=="],["-","timestamp","2001-01-01T01:01:01.000000001Z"],["+","timestamp","2001-01-01T01:02:01.000000001Z"],["-","title","created dataset from body.csv"],["+","title","created dataset from body_more.csv"]]],["-","path","/mem/QmQqnpYhA7UfcyCLkupkd35VLY8AL9XSQEtY5QhhLvV5jj"],["+","path","/mem/QmS6G5QpWtdHGeQMT3Nn2pqmdvQYJPSN8L7DyxVcmqu342"],[" ","qri","ds:0"],[" ","stats",null,[["-","path","/mem/QmVvv9vBHLsYbDYzG1G931Pi58gTPdVE4hcUsxb6rAU8S9"],["+","path","/mem/QmcLRdnni9jpvhACHN9LmUsDuT2iiqpEhQ1ZqxdLK5ELr6"],[" ","qri","sa:0"],[" ","stats",null,[[" ",0,null,[["-","count",5],["+","count",7],[" ","frequencies",null,[[" ","chatham",1],[" ","chicago",1],["+","los angeles",1],["+","mexico city",1],[" ","new york",1],[" ","raleigh",1],[" ","toronto",1]]],["-","maxLength",8],["+","maxLength",11],[" ","minLength",7],[" ","type","string"],["-","unique",5],["+","unique",7]]],[" ",1,null,[["-","count",5],["+","count",7],[" ","histogram",null,[[" ","bins",null,[["+",0,35000],[" ",1,250000],["+",2,300000],["+",3,3990000],[" ",4,8500000],["+",5,50000000],["+",6,70000000],["+",7,70000001]]],[" ","frequencies",null,[["+",0,1],["+",1,1],["+",2,1],["+",3,1],["+",4,1],["+",5,1],["+",6,1]]]]],["-","max",50000000],["+","max",70000000],["-","mean",11817000],["+","mean",19010714.285714287],["-","median",300000],["+","median",3990000],[" ","min",35000],[" ","type","numeric"]]],[" ",2,null,[["-","count",5],["+","count",7],[" ","histogram",null,[[" ","bins",null,[["+",0,28.6],["+",1,42.7],["+",2,44.4],["+",3,50.65],[" ",4,55.5],["+",5,65.25],[" ",6,66.25]]],[" ","frequencies",null,[["+",0,1],["+",1,1],["+",2,2],["+",3,1],["+",4,1],["+",5,1]]]]],[" ","max",65.25],["-","mean",52.04],["+","mean",47.357142857142854],[" ","median",50.65],["-","min",44.4],["+","min",28.6],[" ","type","numeric"]]],[" ",3,null,[["-","count",5],["+","count",7],["-","falseCount",1],["+","falseCount",2],["-","trueCount",4],["+","trueCount",5],[" ","type","boolean"]]]]]]],[" ","structure",null,[["-","checksum","/mem/Qmc7AoCfFVW5xe8qhyjNYewSgBHFubp6yLM3mfBzQp7iTr"],["+","checksum","/mem/QmYuVj1JvALB9Au5DNcVxGLMcWCDBUfbKCN3QbpvissSC4"],[" ","depth",2],["-","entries",5],["+","entries",7],[" ","format","csv"],[" ","formatConfig",{"headerRow":true,"lazyQuotes":true}],["-","length",155],["+","length",217],["-","path","/mem/QmX3HjmvFGYXavQiPqpJvAZZ14J1DNPjCCGEzEy9NgZq2J"],["+","path","/mem/QmNpWHSFo8xwNQyamsaYAqUKu7Nzd3J1a4MukyNVf4J1xt"],[" ","qri","st:0"],[" ","schema",{"items":{"items":[{"title":"city","type":"string"},{"title":"pop","type":"integer"},{"title":"avg_age","type":"number"},{"title":"in_usa","type":"boolean"}],"type":"array"},"type":"array"}]]]]}`
+	expect := `{"base":"/mem/QmQqnpYhA7UfcyCLkupkd35VLY8AL9XSQEtY5QhhLvV5jj","stat":{"leftNodes":102,"rightNodes":112,"leftWeight":3814,"rightWeight":4170,"inserts":51,"deletes":25},"diff":[["-","bodyPath","/mem/Qmc7AoCfFVW5xe8qhyjNYewSgBHFubp6yLM3mfBzQp7iTr"],["+","bodyPath","/mem/QmYuVj1JvALB9Au5DNcVxGLMcWCDBUfbKCN3QbpvissSC4"],[" ","commit",null,[[" ","author",{"id":"QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt"}],["-","message","created dataset from body.csv"],["+","message","created dataset from body_more.csv"],["-","path","/mem/QmPuJT13PENgG6yQQsC5SxRickT8CfBGdP27Q1DFzYbR1U"],["+","path","/mem/QmQm7BGbSbjXSXJopZG23j1ruAYHNPMGk5rsbsvaJ2AKH3"],[" ","qri","cm:0"],["-","signature","aO6TIie3VfmC8fvzZL4BulTUYd98nEMzvXrsw8Ght3Tt4vDEaZ58v1iogI1BHVX4lVLlVIJn2F5JK1vZEGB/Du8Y9qvZi1VPvZIT4kRBG4KcGezNHQmiQidk9GgnuqgVVW+wPgDyAwrP+FD2B/3U+UXTeqUKiTeaYEiS+8odqnBWkA0lNjkIROVz3Q7bOmKUZePzvCDONv4zugpLgKSPNuEbAv8qf2rVZLzrOiA7C1z1dh1kgrfv+RNgrNwWL6PmdfebE2ND5kr9X6qesZamUXyrK97Zb/KDZwEOsDPUQlQYdXlzvi0yx+o2V7vnwbkUN6PO9ytmOsh3egL7TTJH1A=="],["+","signature","M1RDiczU7mze3siJoi5BCi73oV7c0bXisPXYlZD11fvXeCCiu+NYvUMcDIOB5u/k/CNn4zi51NX503mxy05wS6I7D5FDl6GME9qyiky8KY5vDobgFBq93ht+p+/arTo2G0a0FdWgaQf9c0YWid1xTbBlZ2ED558AZFBnH7QHhtZRc5YDRPVAEMP/0R9pgDsM4lfo52cOfIxmg7cRkcjfvJ6pkTRPMDNu/M1ZvveF0fq5sMMJ+8joA0Kh9IkLU2CiwxfkFKcQi2fDcixjpFoikyRqQbLYATMRDrB+s18eSMMO5AKMp41WonBGBaAv+j0RGEONtJBaN7pmQU5od3Y9Tw=="],["-","timestamp","2001-01-01T01:01:01.000000001Z"],["+","timestamp","2001-01-01T01:02:01.000000001Z"],["-","title","created dataset from body.csv"],["+","title","created dataset from body_more.csv"]]],["-","path","/mem/QmQqnpYhA7UfcyCLkupkd35VLY8AL9XSQEtY5QhhLvV5jj"],["+","path","/mem/QmS6G5QpWtdHGeQMT3Nn2pqmdvQYJPSN8L7DyxVcmqu342"],[" ","qri","ds:0"],[" ","stats",null,[["-","path","/mem/QmVvv9vBHLsYbDYzG1G931Pi58gTPdVE4hcUsxb6rAU8S9"],["+","path","/mem/QmcLRdnni9jpvhACHN9LmUsDuT2iiqpEhQ1ZqxdLK5ELr6"],[" ","qri","sa:0"],[" ","stats",null,[[" ",0,null,[["-","count",5],["+","count",7],[" ","frequencies",null,[[" ","chatham",1],[" ","chicago",1],["+","los angeles",1],["+","mexico city",1],[" ","new york",1],[" ","raleigh",1],[" ","toronto",1]]],["-","maxLength",8],["+","maxLength",11],[" ","minLength",7],[" ","type","string"],["-","unique",5],["+","unique",7]]],[" ",1,null,[["-","count",5],["+","count",7],[" ","histogram",null,[[" ","bins",null,[["+",0,35000],[" ",1,250000],["+",2,300000],["+",3,3990000],[" ",4,8500000],["+",5,50000000],["+",6,70000000],["+",7,70000001]]],[" ","frequencies",null,[["+",0,1],["+",1,1],["+",2,1],["+",3,1],["+",4,1],["+",5,1],["+",6,1]]]]],["-","max",50000000],["+","max",70000000],["-","mean",11817000],["+","mean",19010714.285714287],["-","median",300000],["+","median",3990000],[" ","min",35000],[" ","type","numeric"]]],[" ",2,null,[["-","count",5],["+","count",7],[" ","histogram",null,[[" ","bins",null,[["+",0,28.6],["+",1,42.7],["+",2,44.4],["+",3,50.65],[" ",4,55.5],["+",5,65.25],[" ",6,66.25]]],[" ","frequencies",null,[["+",0,1],["+",1,1],["+",2,2],["+",3,1],["+",4,1],["+",5,1]]]]],[" ","max",65.25],["-","mean",52.04],["+","mean",47.357142857142854],[" ","median",50.65],["-","min",44.4],["+","min",28.6],[" ","type","numeric"]]],[" ",3,null,[["-","count",5],["+","count",7],["-","falseCount",1],["+","falseCount",2],["-","trueCount",4],["+","trueCount",5],[" ","type","boolean"]]]]]]],[" ","structure",null,[["-","checksum","/mem/Qmc7AoCfFVW5xe8qhyjNYewSgBHFubp6yLM3mfBzQp7iTr"],["+","checksum","/mem/QmYuVj1JvALB9Au5DNcVxGLMcWCDBUfbKCN3QbpvissSC4"],[" ","depth",2],["-","entries",5],["+","entries",7],[" ","format","csv"],[" ","formatConfig",{"headerRow":true,"lazyQuotes":true}],["-","length",155],["+","length",217],["-","path","/mem/QmX3HjmvFGYXavQiPqpJvAZZ14J1DNPjCCGEzEy9NgZq2J"],["+","path","/mem/QmNpWHSFo8xwNQyamsaYAqUKu7Nzd3J1a4MukyNVf4J1xt"],[" ","qri","st:0"],[" ","schema",{"items":{"items":[{"title":"city","type":"string"},{"title":"pop","type":"integer"},{"title":"avg_age","type":"number"},{"title":"in_usa","type":"boolean"}],"type":"array"},"type":"array"}]]]]}`
 	if diff := cmp.Diff(expect, output); diff != "" {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
@@ -232,12 +234,72 @@ func TestDiff(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expect = `{"stat":{"leftNodes":26,"rightNodes":36,"leftWeight":344,"rightWeight":510,"inserts":2},"diff":[[" ",0,["toronto",50000000,55.5,false]],[" ",1,["new york",8500000,44.4,true]],["+",2,["los angeles",3990000,42.7,true]],[" ",3,["chicago",300000,44.4,true]],[" ",4,["chatham",35000,65.25,true]],["+",5,["mexico city",70000000,28.6,false]],[" ",6,["raleigh",250000,50.65,true]]]}`
+	expect = `{"base":"/mem/QmQqnpYhA7UfcyCLkupkd35VLY8AL9XSQEtY5QhhLvV5jj","stat":{"leftNodes":26,"rightNodes":36,"leftWeight":344,"rightWeight":510,"inserts":2},"diff":[[" ",0,["toronto",50000000,55.5,false]],[" ",1,["new york",8500000,44.4,true]],["+",2,["los angeles",3990000,42.7,true]],[" ",3,["chicago",300000,44.4,true]],[" ",4,["chatham",35000,65.25,true]],["+",5,["mexico city",70000000,28.6,false]],[" ",6,["raleigh",250000,50.65,true]]]}`
 	if diff := cmp.Diff(expect, output); diff != "" {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
 }
 
+// Test that bodies with a primary key are diffed row-by-row
+func TestDiffPrimaryKey(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type":       "array",
+			"primaryKey": []interface{}{"city"},
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "city", "type": "string"},
+					map[string]interface{}{"title": "pop", "type": "integer"},
+				},
+			},
+		},
+	}
+	bodyOne := run.MustWriteTmpFile(t, "cities_1.csv", "city,pop\ntoronto,50000000\nnew york,8500000\nchicago,300000\n")
+	bodyTwo := run.MustWriteTmpFile(t, "cities_2.csv", "city,pop\nchatham,35000\ntoronto,50000000\nnew york,8600000\n")
+	for _, path := range []string{bodyOne, bodyTwo} {
+		if _, err := run.SaveWithParams(&SaveParams{Ref: "me/keyed_cities", BodyPath: path, Dataset: &dataset.Dataset{Structure: st}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := run.Diff("me/keyed_cities", "", "body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"base":"/mem/QmczNHZ7jRrgKu6BSufg9LtRiQdqSxcZuWraizKt8gE9m3","rowStat":{"leftRows":3,"rightRows":3,"added":1,"removed":1,"modified":1},"rows":[{"type":"+","key":["chatham"],"row":["chatham",35000]},{"type":"~","key":["new york"],"cells":[{"column":"pop","from":8500000,"to":8600000}]},{"type":"-","key":["chicago"],"row":["chicago",300000]}]}`
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	// diffing the whole dataset diffs the body by key, leaving the body out of
+	// the tree diff
+	res := &DiffResponse{}
+	if err := NewDatasetMethods(run.Instance).Diff(&DiffParams{LeftSide: "me/keyed_cities", UseLeftPrevVersion: true}, res); err != nil {
+		t.Fatal(err)
+	}
+	if res.RowStat == nil || res.RowStat.Added != 1 || res.RowStat.Removed != 1 || res.RowStat.Modified != 1 {
+		t.Errorf("expected dataset diff to diff rows by key. got: %v", res.RowStat)
+	}
+	if res.Stat == nil || len(res.Diff) == 0 {
+		t.Errorf("expected dataset diff to diff other components as trees")
+	}
+
+	// other components are diffed as trees
+	output, err = run.Diff("me/keyed_cities", "", "structure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, `"rows"`) {
+		t.Errorf("expected structure diff to not include rows. got: %s", output)
+	}
+}
+
 // Test that diffing a dataset with only one version produces an error
 func TestDiffOnlyOneRevision(t *testing.T) {
 	run := newTestRunner(t)
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -66,6 +67,9 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			return nil, "", err
 		}
 		resp.Body.Close()
+		if dss, err := archive.UnzipDataPackageBytes(data); !errors.Is(err, archive.ErrNotDataPackage) {
+			return singleResourceDataset(dss, err)
+		}
 		err = archive.UnzipDatasetBytes(data, &ds)
 		return &ds, "zip", nil
 
@@ -95,6 +99,10 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			return &ds, kind, err
 
 		case ".json":
+			if filepath.Base(path) == archive.DataPackageFilename {
+				f.Close()
+				return singleResourceDataset(archive.ReadDataPackageFile(path))
+			}
 			fields := make(map[string]interface{})
 			if err = json.NewDecoder(f).Decode(&fields); err != nil {
 				if strings.HasPrefix(err.Error(), "json: cannot unmarshal array") {
@@ -110,6 +118,9 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			if err != nil {
 				return nil, "", err
 			}
+			if dss, err := archive.UnzipDataPackageBytes(data); !errors.Is(err, archive.ErrNotDataPackage) {
+				return singleResourceDataset(dss, err)
+			}
 			err = archive.UnzipDatasetBytes(data, &ds)
 			return &ds, "zip", err
 
@@ -144,6 +155,47 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 	}
 }
 
+// singleResourceDataset returns the dataset of a data package with a single
+// resource as a full dataset
+func singleResourceDataset(dss []*dataset.Dataset, err error) (*dataset.Dataset, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+	if len(dss) != 1 {
+		return nil, "", fmt.Errorf("data package has %d resources, import data packages with more than one resource to create a dataset for each", len(dss))
+	}
+	return dss[0], "ds", nil
+}
+
+// ReadDataPackage reads a Frictionless Data Package, returning a dataset for
+// each resource in the package. path may be a local datapackage.json file, or
+// a zipped data package on the local filesystem or at an http(s) URL
+func ReadDataPackage(path string) ([]*dataset.Dataset, error) {
+	var data []byte
+	switch qfs.PathKind(path) {
+	case "http":
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if data, err = ioutil.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	case "local":
+		if filepath.Base(path) == archive.DataPackageFilename {
+			return archive.ReadDataPackageFile(path)
+		}
+		var err error
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("error, unknown path kind: \"%s\"", qfs.PathKind(path))
+	}
+	return archive.UnzipDataPackageBytes(data)
+}
+
 func fillDatasetOrComponent(fields map[string]interface{}, path string, ds *dataset.Dataset) (string, error) {
 	var target interface{}
 	target = ds
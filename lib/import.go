@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/base/sqlite"
+	"github.com/qri-io/qri/dsref"
+)
+
+// ImportSQLiteParams defines parameters for importing tables of a SQLite
+// database as datasets
+type ImportSQLiteParams struct {
+	// Path is the location of a SQLite database file on the local filesystem
+	Path string
+	// Tables to import, each table is saved as a dataset named after the table
+	Tables []string
+	// All imports every table in the database
+	All bool
+	// Query imports the result of a SQL query as a single dataset. Importing a
+	// query requires a dataset reference
+	Query string
+	// Ref is the dataset to save a query result to
+	Ref string
+}
+
+// ImportDataPackageParams defines parameters for importing a Frictionless
+// Data Package
+type ImportDataPackageParams struct {
+	// Path is the location of a datapackage.json file or a zipped data package.
+	// Zipped data packages can be read from http(s) URLs
+	Path string
+}
+
+// ImportResult lists the datasets an import saved
+type ImportResult struct {
+	// Saved holds a new version for each dataset that changed
+	Saved []dsref.VersionInfo
+	// Unchanged lists references to datasets that had no changes to save
+	Unchanged []string
+}
+
+// ImportSQLite saves tables or a query result from a SQLite database as
+// datasets, one dataset per table. Importing into an existing dataset adds a
+// version, datasets with no changes are skipped. If no tables are given, the
+// database must have exactly one table
+func (m *DatasetMethods) ImportSQLite(ctx context.Context, p *ImportSQLiteParams) (*ImportResult, error) {
+	if m.inst.http != nil {
+		res := &ImportResult{}
+		err := m.inst.http.Call(ctx, AEImportSQLite, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if p.Path == "" {
+		return nil, fmt.Errorf("path to a sqlite database is required")
+	}
+
+	if p.Query != "" {
+		if len(p.Tables) > 0 || p.All {
+			return nil, fmt.Errorf("can't import both tables and a query, use one or the other")
+		}
+		if p.Ref == "" {
+			return nil, fmt.Errorf("importing a query requires a dataset reference to save to")
+		}
+		res := &ImportResult{}
+		if err := m.importSave(ctx, res, &SaveParams{Ref: p.Ref, BodyPath: p.Path, Query: p.Query}); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if p.Ref != "" {
+		return nil, fmt.Errorf("a dataset reference can only be used when importing a query, tables are saved to datasets named after each table")
+	}
+	if len(p.Tables) > 0 && p.All {
+		return nil, fmt.Errorf("can't import all tables and a list of tables, use one or the other")
+	}
+
+	tables := p.Tables
+	if len(tables) == 0 {
+		all, err := sqlite.Tables(ctx, p.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !p.All && len(all) != 1 {
+			return nil, fmt.Errorf("database has %d tables, specify tables to import or import all of them. tables: %s", len(all), strings.Join(all, ", "))
+		}
+		tables = all
+	}
+
+	res := &ImportResult{}
+	for _, table := range tables {
+		sp := &SaveParams{
+			Ref:                 fmt.Sprintf("me/%s", dsref.GenerateName(table, "dataset_")),
+			BodyPath:            p.Path,
+			Table:               table,
+			ConvertFormatToPrev: true,
+		}
+		if err := m.importSave(ctx, res, sp); err != nil {
+			return nil, fmt.Errorf("importing table %q: %w", table, err)
+		}
+	}
+	return res, nil
+}
+
+// ImportDataPackage saves each resource of a Frictionless Data Package as a
+// dataset named after the resource. Importing into an existing dataset adds a
+// version, datasets with no changes are skipped
+func (m *DatasetMethods) ImportDataPackage(ctx context.Context, p *ImportDataPackageParams) (*ImportResult, error) {
+	if m.inst.http != nil {
+		res := &ImportResult{}
+		err := m.inst.http.Call(ctx, AEImportDataPackage, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if p.Path == "" {
+		return nil, fmt.Errorf("path to a data package is required")
+	}
+	dss, err := ReadDataPackage(p.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ImportResult{}
+	for _, ds := range dss {
+		sp := &SaveParams{
+			Ref:                 fmt.Sprintf("me/%s", ds.Name),
+			Dataset:             ds,
+			ConvertFormatToPrev: true,
+		}
+		if err := m.importSave(ctx, res, sp); err != nil {
+			return nil, fmt.Errorf("importing resource %q: %w", ds.Name, err)
+		}
+	}
+	return res, nil
+}
+
+// importSave saves a dataset, recording the result. Saves with no changes
+// aren't an error
+func (m *DatasetMethods) importSave(ctx context.Context, res *ImportResult, p *SaveParams) error {
+	ds, err := m.Save(ctx, p)
+	if errors.Is(err, dsfs.ErrNoChanges) {
+		res.Unchanged = append(res.Unchanged, p.Ref)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	res.Saved = append(res.Saved, dsref.ConvertDatasetToVersionInfo(ds))
+	return nil
+}
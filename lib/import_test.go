@@ -0,0 +1,130 @@
+package lib
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/base/sqlite"
+)
+
+func TestImportSQLite(t *testing.T) {
+	if !sqlite.Supported {
+		t.Skip("sqlite requires cgo")
+	}
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	dbPath := tr.MakeTmpFilename("reference.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`CREATE TABLE cities (city TEXT, pop INTEGER)`,
+		`INSERT INTO cities VALUES ('toronto', 40000000), ('chicago', 300000)`,
+		`CREATE TABLE countries (code TEXT, name TEXT)`,
+		`INSERT INTO countries VALUES ('CA', 'Canada')`,
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	m := NewDatasetMethods(tr.Instance)
+	bad := []struct {
+		description string
+		params      ImportSQLiteParams
+	}{
+		{"no path", ImportSQLiteParams{All: true}},
+		{"many tables without a table list", ImportSQLiteParams{Path: dbPath}},
+		{"tables and all", ImportSQLiteParams{Path: dbPath, All: true, Tables: []string{"cities"}}},
+		{"tables and a query", ImportSQLiteParams{Path: dbPath, Tables: []string{"cities"}, Query: "SELECT 1", Ref: "me/one"}},
+		{"query without a reference", ImportSQLiteParams{Path: dbPath, Query: "SELECT 1"}},
+		{"tables with a reference", ImportSQLiteParams{Path: dbPath, Tables: []string{"cities"}, Ref: "me/cities"}},
+		{"missing table", ImportSQLiteParams{Path: dbPath, Tables: []string{"towns"}}},
+	}
+	for _, c := range bad {
+		if _, err := m.ImportSQLite(tr.Ctx, &c.params); err == nil {
+			t.Errorf("case %q: expected error, got nil", c.description)
+		}
+	}
+
+	res, err := m.ImportSQLite(tr.Ctx, &ImportSQLiteParams{Path: dbPath, All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, vi := range res.Saved {
+		names = append(names, vi.Name)
+	}
+	if diff := cmp.Diff([]string{"cities", "countries"}, names); diff != "" {
+		t.Errorf("saved datasets mismatch (-want +got):\n%s", diff)
+	}
+	if len(res.Unchanged) != 0 {
+		t.Errorf("expected no unchanged datasets. got: %v", res.Unchanged)
+	}
+
+	// importing the same tables again saves nothing
+	res, err = m.ImportSQLite(tr.Ctx, &ImportSQLiteParams{Path: dbPath, Tables: []string{"cities"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Saved) != 0 || len(res.Unchanged) != 1 {
+		t.Errorf("expected re-importing an unchanged table to save nothing. got: %v", res)
+	}
+
+	ds := tr.MustGet(t, "me/cities")
+	if ds.Structure.Format != "json" || ds.Structure.Entries != 2 {
+		t.Errorf("unexpected structure. format: %q, entries: %d", ds.Structure.Format, ds.Structure.Entries)
+	}
+}
+
+func TestImportDataPackage(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	tr.MustWriteTmpFile(t, "cities.csv", "city,pop\ntoronto,40000000\nchicago,300000\n")
+	descriptor := tr.MustWriteTmpFile(t, "datapackage.json", `{
+  "name": "reference",
+  "title": "reference data",
+  "resources": [
+    {
+      "name": "cities",
+      "path": "cities.csv",
+      "schema": {"fields": [{"name": "city", "type": "string"}, {"name": "pop", "type": "integer"}]}
+    },
+    {
+      "name": "countries",
+      "data": [["code", "name"], ["CA", "Canada"]],
+      "schema": {"fields": [{"name": "code"}, {"name": "name"}]}
+    }
+  ]
+}`)
+
+	// reading a data package with many resources as dataset files fails
+	if _, err := ReadDatasetFiles(descriptor); err == nil {
+		t.Error("expected reading a data package with many resources as a single dataset to fail")
+	}
+
+	m := NewDatasetMethods(tr.Instance)
+	res, err := m.ImportDataPackage(tr.Ctx, &ImportDataPackageParams{Path: descriptor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Saved) != 2 {
+		t.Fatalf("expected 2 saved datasets, got %d", len(res.Saved))
+	}
+
+	ds := tr.MustGet(t, "me/countries")
+	if ds.Meta == nil || ds.Meta.Title != "reference data" {
+		t.Errorf("expected package title to be dataset title. got meta: %v", ds.Meta)
+	}
+	if ds.Structure.Entries != 1 {
+		t.Errorf("expected inline header row to be dropped. got %d entries", ds.Structure.Entries)
+	}
+	if ds = tr.MustGet(t, "me/cities"); ds.Structure.Format != "csv" || ds.Structure.Entries != 2 {
+		t.Errorf("unexpected structure. format: %q, entries: %d", ds.Structure.Format, ds.Structure.Entries)
+	}
+}
@@ -0,0 +1,357 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qri-io/qri/event"
+)
+
+// JobStatus enumerates the states a background job can be in
+type JobStatus string
+
+const (
+	// JSRunning indicates a job is in progress
+	JSRunning = JobStatus("running")
+	// JSSucceeded indicates a job finished without error
+	JSSucceeded = JobStatus("succeeded")
+	// JSFailed indicates a job stopped with an error
+	JSFailed = JobStatus("failed")
+	// JSCanceled indicates a job was canceled before it could finish
+	JSCanceled = JobStatus("canceled")
+)
+
+const (
+	// JobTypeSave is the type of jobs created by DatasetMethods.SaveAsync
+	JobTypeSave = "save"
+
+	// maxFinishedJobs is the number of stopped jobs the registry keeps around
+	// for status queries. The oldest finished jobs are dropped first
+	maxFinishedJobs = 100
+)
+
+var (
+	// ErrJobNotFound indicates a job ID isn't in the registry
+	ErrJobNotFound = fmt.Errorf("job not found")
+	// ErrNoJobRegistry indicates an instance can't run background jobs
+	ErrNoJobRegistry = fmt.Errorf("background jobs are not available")
+)
+
+// Job is a long-running operation that executes in the background. Jobs are
+// snapshots, re-fetch a job to see its latest state
+type Job struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// reference the job is operating on, as given by the caller
+	Ref    string    `json:"ref"`
+	Status JobStatus `json:"status"`
+	// human-centric description of the most recent progress
+	Message string `json:"message,omitempty"`
+	// completion pct from 0-1
+	Completion float64 `json:"completion"`
+	// path of the created dataset version, set when a save job succeeds
+	Path string `json:"path,omitempty"`
+	// error message, set when a job fails or is canceled
+	Error    string     `json:"error,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// Done returns true if the job has stopped
+func (j Job) Done() bool {
+	return j.Status != JSRunning
+}
+
+// job is the registry's record of a Job
+type job struct {
+	Job
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// jobIDCtxKey is the context key for the ID of the job an operation belongs
+// to. Events published from within a job carry the job ID on their context
+type jobIDCtxKey struct{}
+
+// jobRegistry tracks jobs running on an instance. Jobs are tied to the
+// context the registry was created with, and are canceled when the instance
+// shuts down
+type jobRegistry struct {
+	ctx context.Context
+	bus event.Bus
+
+	lk   sync.Mutex
+	jobs map[string]*job
+}
+
+func newJobRegistry(ctx context.Context, bus event.Bus) *jobRegistry {
+	if bus == nil {
+		bus = event.NilBus
+	}
+	r := &jobRegistry{
+		ctx:  ctx,
+		bus:  bus,
+		jobs: map[string]*job{},
+	}
+	bus.SubscribeTypes(r.handleSaveEvent, event.ETDatasetSaveStarted, event.ETDatasetSaveProgress)
+	return r
+}
+
+// start creates a job and runs fn in the background. fn returns the path
+// the job produced
+func (r *jobRegistry) start(typ, ref string, fn func(ctx context.Context) (string, error)) Job {
+	id := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.WithValue(r.ctx, jobIDCtxKey{}, id))
+	j := &job{
+		Job: Job{
+			ID:      id,
+			Type:    typ,
+			Ref:     ref,
+			Status:  JSRunning,
+			Started: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	r.lk.Lock()
+	r.jobs[id] = j
+	started := j.Job
+	r.lk.Unlock()
+
+	log.Debugw("starting job", "id", id, "type", typ, "ref", ref)
+	if err := r.bus.Publish(ctx, event.ETJobStarted, started); err != nil {
+		log.Debugw("publishing job start", "id", id, "err", err)
+	}
+
+	go func() {
+		path, err := fn(ctx)
+		finished := r.finish(j, path, err, ctx.Err())
+		cancel()
+		if err := r.bus.Publish(r.ctx, event.ETJobCompleted, finished); err != nil {
+			log.Debugw("publishing job completion", "id", id, "err", err)
+		}
+		close(j.done)
+	}()
+
+	return started
+}
+
+func (r *jobRegistry) finish(j *job, path string, err, ctxErr error) Job {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	now := time.Now()
+	j.Finished = &now
+	switch {
+	case err == nil:
+		j.Status = JSSucceeded
+		j.Completion = 1
+		j.Path = path
+	case errors.Is(ctxErr, context.Canceled):
+		j.Status = JSCanceled
+		j.Error = err.Error()
+	default:
+		j.Status = JSFailed
+		j.Error = err.Error()
+	}
+	r.prune()
+	return j.Job
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs. the registry
+// lock must be held
+func (r *jobRegistry) prune() {
+	finished := []*job{}
+	for _, j := range r.jobs {
+		if j.Done() {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i].Finished.Before(*finished[k].Finished) })
+	for _, j := range finished[:len(finished)-maxFinishedJobs] {
+		delete(r.jobs, j.ID)
+	}
+}
+
+// get returns a snapshot of a job
+func (r *jobRegistry) get(id string) (Job, error) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	j, ok := r.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrJobNotFound, id)
+	}
+	return j.Job, nil
+}
+
+// list returns snapshots of all jobs, oldest first
+func (r *jobRegistry) list() []Job {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	res := make([]Job, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		res = append(res, j.Job)
+	}
+	sort.Slice(res, func(i, k int) bool { return res[i].Started.Before(res[k].Started) })
+	return res
+}
+
+// cancel stops a running job, waiting for the job to stop or the given context
+// to be done. canceling a job that has already stopped is a no-op
+func (r *jobRegistry) cancel(ctx context.Context, id string) (Job, error) {
+	r.lk.Lock()
+	j, ok := r.jobs[id]
+	r.lk.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrJobNotFound, id)
+	}
+	j.cancel()
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+	}
+	return r.get(id)
+}
+
+// wait blocks until a job stops or the given context is done
+func (r *jobRegistry) wait(ctx context.Context, id string) (Job, error) {
+	r.lk.Lock()
+	j, ok := r.jobs[id]
+	r.lk.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrJobNotFound, id)
+	}
+	select {
+	case <-j.done:
+		return r.get(id)
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+// handleSaveEvent records save progress for events published from within a
+// job
+func (r *jobRegistry) handleSaveEvent(ctx context.Context, e event.Event) error {
+	id, ok := ctx.Value(jobIDCtxKey{}).(string)
+	if !ok {
+		return nil
+	}
+	evt, ok := e.Payload.(event.DsSaveEvent)
+	if !ok {
+		return nil
+	}
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if j, ok := r.jobs[id]; ok && !j.Done() {
+		j.Message = evt.Message
+		j.Completion = evt.Completion
+	}
+	return nil
+}
+
+// JobMethods encapsulates business logic for inspecting and controlling
+// background jobs
+type JobMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (JobMethods) CoreRequestsName() string { return "job" }
+
+// NewJobMethods creates a JobMethods pointer from a qri instance
+func NewJobMethods(inst *Instance) *JobMethods {
+	return &JobMethods{
+		inst: inst,
+	}
+}
+
+// JobParams identifies a single job
+type JobParams struct {
+	ID string
+}
+
+// JobListParams defines parameters for listing jobs
+type JobListParams struct {
+	// only show jobs that haven't stopped
+	Running bool
+}
+
+// List shows jobs the instance knows about, oldest first. Only recently
+// finished jobs are kept
+func (m *JobMethods) List(ctx context.Context, p *JobListParams) ([]Job, error) {
+	if m.inst.http != nil {
+		res := []Job{}
+		if err := m.inst.http.Call(ctx, AEJobs, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.jobs == nil {
+		return nil, ErrNoJobRegistry
+	}
+
+	res := []Job{}
+	for _, j := range m.inst.jobs.list() {
+		if p.Running && j.Done() {
+			continue
+		}
+		res = append(res, j)
+	}
+	return res, nil
+}
+
+// Get fetches the current state of a job
+func (m *JobMethods) Get(ctx context.Context, p *JobParams) (*Job, error) {
+	if p.ID == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+	if m.inst.http != nil {
+		res := &Job{}
+		if err := m.inst.http.Call(ctx, AEJob, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.jobs == nil {
+		return nil, ErrNoJobRegistry
+	}
+
+	j, err := m.inst.jobs.get(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// Cancel stops a running job, returning its state once it has stopped
+func (m *JobMethods) Cancel(ctx context.Context, p *JobParams) (*Job, error) {
+	if p.ID == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+	if m.inst.http != nil {
+		res := &Job{}
+		if err := m.inst.http.Call(ctx, AEJobCancel, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.jobs == nil {
+		return nil, ErrNoJobRegistry
+	}
+
+	j, err := m.inst.jobs.cancel(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+)
+
+func TestSaveAsync(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	ctx, cancel := context.WithTimeout(tr.Ctx, time.Second*10)
+	defer cancel()
+
+	completed := make(chan Job, 2)
+	tr.Instance.Bus().SubscribeTypes(func(_ context.Context, e event.Event) error {
+		completed <- e.Payload.(Job)
+		return nil
+	}, event.ETJobCompleted)
+
+	m := NewDatasetMethods(tr.Instance)
+	job, err := m.SaveAsync(ctx, &SaveParams{Ref: "me/async_save", BodyPath: "testdata/cities_2/body.csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == "" || job.Type != JobTypeSave || job.Status != JSRunning {
+		t.Errorf("unexpected started job: %#v", job)
+	}
+
+	got, err := tr.Instance.jobs.wait(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != JSSucceeded {
+		t.Fatalf("expected job to succeed, got status %q. error: %s", got.Status, got.Error)
+	}
+	if got.Path == "" || got.Completion != 1 {
+		t.Errorf("expected succeeded job to have a path and be complete, got: %#v", got)
+	}
+	if evt := <-completed; evt.ID != job.ID {
+		t.Errorf("completed event job ID mismatch. want %q, got %q", job.ID, evt.ID)
+	}
+
+	jm := NewJobMethods(tr.Instance)
+	failing, err := m.SaveAsync(ctx, &SaveParams{Ref: "me/async_save"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err = tr.Instance.jobs.wait(ctx, failing.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != JSFailed || got.Error != "no changes to save" {
+		t.Errorf("expected saving without changes to fail, got: %#v", got)
+	}
+
+	jobs, err := jm.List(ctx, &JobListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Errorf("expected 2 jobs, got %d", len(jobs))
+	}
+	if jobs, err = jm.List(ctx, &JobListParams{Running: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("expected no running jobs, got %d", len(jobs))
+	}
+
+	if _, err = jm.Get(ctx, &JobParams{ID: "unknown"}); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected getting an unknown job to return ErrJobNotFound, got: %v", err)
+	}
+
+	if _, err = m.Save(ctx, &SaveParams{Ref: "me/async_save", Async: true}); err == nil {
+		t.Error("expected Save with async params to fail")
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	reg := newJobRegistry(ctx, event.NewBus(ctx))
+	m := NewJobMethods(&Instance{jobs: reg})
+
+	job := reg.start(JobTypeSave, "me/slow", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	running, err := m.Get(ctx, &JobParams{ID: job.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running.Status != JSRunning {
+		t.Errorf("expected job to be running, got %q", running.Status)
+	}
+
+	canceled, err := m.Cancel(ctx, &JobParams{ID: job.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled.Status != JSCanceled {
+		t.Errorf("expected job to be canceled, got %q", canceled.Status)
+	}
+	if canceled.Finished == nil {
+		t.Error("expected canceled job to have a finished time")
+	}
+}
@@ -39,8 +39,11 @@ import (
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/buildrepo"
+	"github.com/qri-io/qri/scheduler"
+	"github.com/qri-io/qri/secrets"
 	"github.com/qri-io/qri/stats"
 	"github.com/qri-io/qri/transform"
+	"github.com/qri-io/qri/transform/run"
 )
 
 var (
@@ -91,6 +94,22 @@ type InstanceOptions struct {
 // InstanceContextKey is used by context to set keys for constucting a lib.Instance
 type InstanceContextKey string
 
+// remoteCallerCtxKey marks contexts of calls made by a remote client
+type remoteCallerCtxKey struct{}
+
+// WithRemoteCaller marks ctx as belonging to a call made by a remote client,
+// like a request to the API from another machine. Remote callers can't raise
+// limits set in config
+func WithRemoteCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, remoteCallerCtxKey{}, true)
+}
+
+// isRemoteCaller reports whether ctx was marked with WithRemoteCaller
+func isRemoteCaller(ctx context.Context) bool {
+	remote, _ := ctx.Value(remoteCallerCtxKey{}).(bool)
+	return remote
+}
+
 // Option is a function that manipulates config details when fed to New(). Fields on
 // the o parameter may be null, functions cannot assume the Config is non-null.
 type Option func(o *InstanceOptions) error
@@ -370,16 +389,17 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		repoPath: repoPath,
 		cfg:      cfg,
 
-		qfs:       o.qfs,
-		repo:      o.repo,
-		node:      o.node,
-		streams:   o.Streams,
-		registry:  o.regclient,
-		logbook:   o.logbook,
-		profiles:  o.profiles,
-		bus:       event.NewBus(ctx),
-		transform: transform.NewService(ctx),
-	}
+		qfs:      o.qfs,
+		repo:     o.repo,
+		node:     o.node,
+		streams:  o.Streams,
+		registry: o.regclient,
+		logbook:  o.logbook,
+		profiles: o.profiles,
+		bus:      event.NewBus(ctx),
+	}
+	inst.jobs = newJobRegistry(ctx, inst.bus)
+	inst.scheduler = newScheduler(inst, cfg)
 	qri = inst
 
 	// configure logging straight away
@@ -456,6 +476,16 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		}
 	}
 
+	if inst.secrets == nil {
+		if inst.secrets, err = newSecretStore(pro, inst.repoPath); err != nil {
+			return nil, fmt.Errorf("initializing secret store: %w", err)
+		}
+	}
+
+	if inst.runs == nil {
+		inst.runs = newRunStore(inst.repoPath)
+	}
+
 	if inst.registry == nil {
 		inst.registry = newRegClient(ctx, cfg)
 	}
@@ -485,6 +515,10 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		}
 	}
 
+	// the transform service reads from the repo, so it's created once the repo
+	// is built
+	inst.transform = transform.NewService(ctx, inst.repo, newTransformStepCache(inst.repoPath))
+
 	if inst.dscache == nil {
 		inst.dscache, err = newDscache(ctx, inst.qfs, inst.bus, pro.Peername, inst.repoPath)
 		if err != nil {
@@ -596,6 +630,17 @@ func newLogbook(fs qfs.Filesystem, cfg *config.Config, bus event.Bus, pro *profi
 	return logbook.NewJournal(pro.PrivKey, pro.Peername, bus, fs, logbookPath)
 }
 
+// newSecretStore opens the secret store at repoPath/secrets.enc, encrypted
+// with the profile's private key. Stores for instances without a repo path are
+// kept in memory
+func newSecretStore(pro *profile.Profile, repoPath string) (*secrets.Store, error) {
+	path := ""
+	if repoPath != "" {
+		path = filepath.Join(repoPath, "secrets.enc")
+	}
+	return secrets.NewStore(pro.PrivKey, path)
+}
+
 func newDscache(ctx context.Context, fs qfs.Filesystem, bus event.Bus, username, repoPath string) (*dscache.Dscache, error) {
 	dscachePath := filepath.Join(repoPath, "dscache.qfb")
 	return dscache.NewDscache(ctx, fs, bus, username, dscachePath), nil
@@ -622,11 +667,37 @@ func newStats(cfg *config.Config, repoPath string) (*stats.Service, error) {
 			return nil, err
 		}
 		return stats.New(cache), nil
+	case "mem", "lru":
+		return stats.New(stats.NewMemCache(int64(cfg.Stats.Cache.MaxSize))), nil
+	case "content":
+		cache, err := stats.NewContentCache(path, int64(cfg.Stats.Cache.MaxSize))
+		if err != nil {
+			return nil, err
+		}
+		return stats.New(cache), nil
 	default:
 		return stats.New(nil), nil
 	}
 }
 
+// transformStepCacheSize bounds the size of the transform step cache
+const transformStepCacheSize = 512 * 1024 * 1024
+
+// newTransformStepCache creates a cache of transform step results at
+// repoPath/transform_cache. Step caching is disabled if the cache can't be
+// created
+func newTransformStepCache(repoPath string) transform.StepCache {
+	if repoPath == "" {
+		return nil
+	}
+	cache, err := transform.NewLocalStepCache(filepath.Join(repoPath, "transform_cache"), transformStepCacheSize)
+	if err != nil {
+		log.Debugw("creating transform step cache", "err", err)
+		return nil
+	}
+	return cache
+}
+
 // NewInstanceFromConfigAndNode is a temporary solution to create an instance from an
 // already-allocated QriNode & configuration
 // don't write new code that relies on this, instead create a configuration
@@ -661,7 +732,7 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		node:      node,
 		dscache:   dc,
 		logbook:   r.Logbook(),
-		transform: transform.NewService(ctx),
+		transform: transform.NewService(ctx, r, nil),
 	}
 
 	inst.stats = stats.New(nil)
@@ -672,8 +743,14 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		inst.fsi = fsint
 		inst.qfs = r.Filesystem()
 	}
+	inst.jobs = newJobRegistry(ctx, inst.bus)
+	inst.scheduler = newScheduler(inst, cfg)
 
 	var err error
+	if inst.secrets, err = newSecretStore(pro, ""); err != nil {
+		log.Debugw("creating secret store", "err", err)
+	}
+	inst.runs = run.NewMemStore()
 	inst.remoteClient, err = remote.NewClient(ctx, node, inst.bus)
 	if err != nil {
 		cancel()
@@ -713,6 +790,10 @@ type Instance struct {
 	bus             event.Bus
 	watcher         *watchfs.FilesysWatcher
 	profiles        profile.Store
+	jobs            *jobRegistry
+	scheduler       *scheduler.Scheduler
+	secrets         *secrets.Store
+	runs            run.Store
 	remoteOptsFuncs []remote.OptionsFunc
 
 	rpc  *rpc.Client
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/base"
@@ -11,6 +12,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	qerr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/sql"
 )
 
 // LoadDataset fetches, dereferences and opens a dataset from a reference
@@ -37,6 +39,13 @@ func (inst *Instance) LoadDataset(ctx context.Context, ref dsref.Ref, source str
 		source = inst.cfg.Registry.Location
 	}
 
+	// versions that have already been fetched are loaded from the local repo
+	if ref.Path != "" {
+		if exists, err := inst.repo.Filesystem().Has(ctx, ref.Path); err == nil && exists {
+			return inst.loadLocalDataset(ctx, ref)
+		}
+	}
+
 	msg := fmt.Sprintf("pulling %s from %s ...\n", ref.Human(), source)
 	inst.streams.Out.Write([]byte(msg))
 
@@ -125,3 +134,44 @@ Replace "me" with your username for the reference:
 		return loader.LoadDataset(ctx, ref, source)
 	}
 }
+
+// newSourceLoader creates a loader that resolves each reference through the
+// source named by it's prefix, falling back to defaultMode. eg:
+// "registry:b5/world_bank_population" resolves through the registry. Versions
+// fetched from other peers are stored in the local repo, where later loads
+// will find them. username works the same as in NewParseResolveLoadFunc
+func (inst *Instance) newSourceLoader(username, defaultMode string) (dsref.ParseResolveLoad, error) {
+	var lock sync.Mutex
+	loaders := map[string]dsref.ParseResolveLoad{}
+	loaderForMode := func(mode string) (dsref.ParseResolveLoad, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if load, ok := loaders[mode]; ok {
+			return load, nil
+		}
+		resolver, err := inst.resolverForMode(mode)
+		if err != nil {
+			return nil, err
+		}
+		load := NewParseResolveLoadFunc(username, resolver, inst)
+		loaders[mode] = load
+		return load, nil
+	}
+
+	// check the default mode is valid before loading anything
+	if _, err := loaderForMode(defaultMode); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, refStr string) (*dataset.Dataset, error) {
+		mode, ref := sql.SplitTableSource(refStr)
+		if mode == "" {
+			mode = defaultMode
+		}
+		load, err := loaderForMode(mode)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", refStr, err)
+		}
+		return load(ctx, ref)
+	}, nil
+}
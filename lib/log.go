@@ -84,8 +84,10 @@ func (m *LogMethods) Log(params *LogParams, res *[]dsref.VersionInfo) error {
 	// with .Parent() fields loaded & connected
 	if len(logs.Logs) > 0 {
 		logs = logs.Logs[0]
-		if len(logs.Logs) > 0 {
-			logs = logs.Logs[0]
+		// the active branch is local to each peer, remote histories are read
+		// from the default branch
+		if branchLog := logbook.NamedBranchLog(logs, logbook.DefaultBranchName); branchLog != nil {
+			logs = branchLog
 		}
 	}
 
@@ -0,0 +1,374 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/base/rowdiff"
+	"github.com/qri-io/qri/dsref"
+)
+
+// PatchKind is the kind & version of patch documents. Patches with any other
+// kind can't be applied
+const PatchKind = "pt:0"
+
+// ErrPatchBaseMismatch indicates a patch was created from a version that
+// isn't the latest version of the dataset it's applied to
+var ErrPatchBaseMismatch = errors.New("patch doesn't apply to the latest version")
+
+// patchComponents lists the components a patch can change
+var patchComponents = []string{"body", "meta", "structure"}
+
+// Patch is a machine-applicable set of changes to one component of a dataset,
+// created from a diff. Patches only apply to the version they were diffed
+// against
+type Patch struct {
+	Qri string `json:"qri"`
+	// Base is the path of the version the patch applies to
+	Base string `json:"base"`
+	// Selector names the component the patch changes
+	Selector string `json:"selector"`
+	// Diff holds changes to the component as a tree
+	Diff []*Delta `json:"diff,omitempty"`
+	// Rows holds changes to a body diffed by primary key
+	Rows []*RowDelta `json:"rows,omitempty"`
+}
+
+// NewPatch creates a patch from the result of diffing a component against a
+// dataset version
+func NewPatch(selector string, res *DiffResponse) (*Patch, error) {
+	if res.Base == "" {
+		return nil, fmt.Errorf("patches can only be created from a diff against a dataset version")
+	}
+	if !isPatchComponent(selector) {
+		return nil, fmt.Errorf("patches can only change one of these components: %v", patchComponents)
+	}
+	return &Patch{
+		Qri:      PatchKind,
+		Base:     res.Base,
+		Selector: selector,
+		Diff:     changedDeltas(res.Diff),
+		Rows:     res.Rows,
+	}, nil
+}
+
+// ReadPatchFile reads a patch document from a JSON file
+func ReadPatchFile(path string) (*Patch, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pt := &Patch{}
+	if err := json.Unmarshal(data, pt); err != nil {
+		return nil, fmt.Errorf("reading patch: %w", err)
+	}
+	return pt, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding tree
+// changes from the compact form deltas are written in
+func (pt *Patch) UnmarshalJSON(data []byte) error {
+	type patch Patch
+	doc := struct {
+		*patch
+		Diff []json.RawMessage `json:"diff"`
+	}{patch: (*patch)(pt)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	deltas, err := decodeDeltas(doc.Diff)
+	if err != nil {
+		return err
+	}
+	pt.Diff = deltas
+	return nil
+}
+
+// decodeDeltas reads deltas written as [type, path, value] or
+// [type, path, null, deltas]
+func decodeDeltas(raw []json.RawMessage) ([]*Delta, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	deltas := make([]*Delta, len(raw))
+	for i, r := range raw {
+		var fields []json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return nil, err
+		}
+		if len(fields) != 3 && len(fields) != 4 {
+			return nil, fmt.Errorf("invalid change: %s", r)
+		}
+
+		d := &Delta{}
+		if err := json.Unmarshal(fields[0], &d.Type); err != nil {
+			return nil, err
+		}
+		var addr interface{}
+		if err := json.Unmarshal(fields[1], &addr); err != nil {
+			return nil, err
+		}
+		switch a := addr.(type) {
+		case nil:
+			d.Path = deepdiff.RootAddr{}
+		case string:
+			d.Path = deepdiff.StringAddr(a)
+		case float64:
+			d.Path = deepdiff.IndexAddr(int(a))
+		default:
+			return nil, fmt.Errorf("invalid change path: %s", fields[1])
+		}
+		if err := json.Unmarshal(fields[2], &d.Value); err != nil {
+			return nil, err
+		}
+		if len(fields) == 4 {
+			var rawChildren []json.RawMessage
+			if err := json.Unmarshal(fields[3], &rawChildren); err != nil {
+				return nil, err
+			}
+			children, err := decodeDeltas(rawChildren)
+			if err != nil {
+				return nil, err
+			}
+			d.Deltas = children
+		}
+		deltas[i] = d
+	}
+	return deltas, nil
+}
+
+// changedDeltas drops unchanged context from a list of deltas, keeping
+// context deltas that have changed children
+func changedDeltas(deltas []*Delta) []*Delta {
+	var changed []*Delta
+	for _, d := range deltas {
+		if d.Type != deepdiff.DTContext {
+			changed = append(changed, d)
+			continue
+		}
+		if children := changedDeltas(d.Deltas); len(children) > 0 {
+			changed = append(changed, &Delta{Type: d.Type, Path: d.Path, Deltas: children})
+		}
+	}
+	return changed
+}
+
+func isPatchComponent(selector string) bool {
+	for _, c := range patchComponents {
+		if selector == c {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPatchParams defines parameters for applying a patch
+type ApplyPatchParams struct {
+	// Ref is the dataset to patch. Patches always apply to the latest version,
+	// so Ref can't include a version path
+	Ref string
+	// Patch is the patch document to apply
+	Patch *Patch
+	// Title & Message of the commit that applies the patch
+	Title   string
+	Message string
+}
+
+// ApplyPatch applies a patch to the latest version of a dataset, saving the
+// result as a new version. Patches created from any other version are
+// refused
+func (m *DatasetMethods) ApplyPatch(ctx context.Context, p *ApplyPatchParams) (*dataset.Dataset, error) {
+	if m.inst.http != nil {
+		res := &dataset.Dataset{}
+		err := m.inst.http.Call(ctx, AEApplyPatch, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if p.Ref == "" {
+		return nil, fmt.Errorf("dataset reference is required")
+	}
+	if p.Patch == nil {
+		return nil, fmt.Errorf("patch is required")
+	}
+	pt := p.Patch
+	if pt.Qri != PatchKind {
+		return nil, fmt.Errorf("unsupported patch version %q, expected %q", pt.Qri, PatchKind)
+	}
+	if !isPatchComponent(pt.Selector) {
+		return nil, fmt.Errorf("patches can only change one of these components: %v", patchComponents)
+	}
+
+	// the base of the patch is compared with the latest version, a version
+	// path would load a different version than the one the patch is saved on
+	parsed, err := dsref.Parse(p.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid dataset reference: %w", p.Ref, err)
+	}
+	if parsed.Path != "" {
+		return nil, fmt.Errorf("patches apply to the latest version, remove the version path from %q", p.Ref)
+	}
+
+	ref, source, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	ds, err := m.inst.LoadDataset(ctx, ref, source)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Path != pt.Base {
+		return nil, fmt.Errorf("%w: patch was created from version %s, the latest version of %s is %s", ErrPatchBaseMismatch, pt.Base, ref.Human(), ds.Path)
+	}
+
+	next := &dataset.Dataset{}
+	switch pt.Selector {
+	case "body":
+		err = m.patchBody(ctx, ds, pt, next)
+	case "meta":
+		next.Meta = &dataset.Meta{}
+		err = patchComponent(ds.Meta, pt.Diff, next.Meta)
+		next.Meta.DropDerivedValues()
+	case "structure":
+		next.Structure = &dataset.Structure{}
+		err = patchComponent(ds.Structure, pt.Diff, next.Structure)
+		next.Structure.DropDerivedValues()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("applying patch: %w", err)
+	}
+
+	return m.Save(ctx, &SaveParams{
+		Ref:     ref.Alias(),
+		Dataset: next,
+		Title:   p.Title,
+		Message: p.Message,
+	})
+}
+
+// patchBody writes the body of ds with the patch applied to the body file of
+// next, keeping the structure of ds
+func (m *DatasetMethods) patchBody(ctx context.Context, ds *dataset.Dataset, pt *Patch, next *dataset.Dataset) error {
+	fs := m.inst.repo.Filesystem()
+	if ds.Structure == nil {
+		return fmt.Errorf("dataset has no structure")
+	}
+	st := &dataset.Structure{}
+	st.Assign(ds.Structure)
+	st.DropDerivedValues()
+
+	buf, err := dsio.NewEntryBuffer(st)
+	if err != nil {
+		return err
+	}
+
+	if len(pt.Rows) > 0 {
+		body := rowdiff.Body{
+			Structure: ds.Structure,
+			Open: func(ctx context.Context) (qfs.File, error) {
+				return dsfs.LoadBody(ctx, fs, ds)
+			},
+		}
+		if err := rowdiff.Apply(ctx, body, pt.Rows, buf); err != nil {
+			return err
+		}
+	} else {
+		f, err := dsfs.LoadBody(ctx, fs, ds)
+		if err != nil {
+			return err
+		}
+		r, err := dsio.NewEntryReader(ds.Structure, f)
+		if err != nil {
+			return err
+		}
+		data, err := base.ReadEntries(r)
+		if err != nil {
+			return err
+		}
+		if err := patchValue(pt.Diff, &data); err != nil {
+			return err
+		}
+		if err := writeEntries(buf, data); err != nil {
+			return err
+		}
+	}
+	if err := buf.Close(); err != nil {
+		return err
+	}
+
+	next.Structure = st
+	next.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", st.Format), buf.Bytes()))
+	return nil
+}
+
+// patchComponent applies tree changes to a JSON encoding of component,
+// decoding the result into next
+func patchComponent(component interface{}, deltas []*Delta, next interface{}) error {
+	var data interface{} = map[string]interface{}{}
+	if component != nil {
+		encoded, err := json.Marshal(component)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(encoded, &data); err != nil {
+			return err
+		}
+	}
+	if err := patchValue(deltas, &data); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, next)
+}
+
+// patchValue applies tree changes to a value. Changes that don't match the
+// shape of the value are an error
+func patchValue(deltas []*Delta, v *interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("patch doesn't match the data it's applied to: %v", r)
+		}
+	}()
+	return deepdiff.Patch(deltas, v)
+}
+
+// writeEntries writes a body value as entries
+func writeEntries(w dsio.EntryWriter, data interface{}) error {
+	switch v := data.(type) {
+	case []interface{}:
+		for i, val := range v {
+			if err := w.WriteEntry(dsio.Entry{Index: i, Value: val}); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := w.WriteEntry(dsio.Entry{Key: k, Value: v[k]}); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("body must be an array or object")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base/dsfs"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	m := NewDatasetMethods(tr.Instance)
+	keyed := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type":       "array",
+			"primaryKey": "city",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "city", "type": "string"},
+					map[string]interface{}{"title": "pop", "type": "integer"},
+				},
+			},
+		},
+	}
+	save := func(ref, body string, st *dataset.Structure) {
+		t.Helper()
+		p := &SaveParams{Ref: ref, BodyPath: tr.MustWriteTmpFile(t, "body.csv", body)}
+		if st != nil {
+			p.Dataset = &dataset.Dataset{Structure: st}
+		}
+		if _, err := m.Save(tr.Ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// patches are sent as JSON, round trip them through their encoding
+	makePatch := func(left, right, selector string) *Patch {
+		t.Helper()
+		res := &DiffResponse{}
+		if err := m.Diff(&DiffParams{LeftSide: left, RightSide: right, Selector: selector}, res); err != nil {
+			t.Fatal(err)
+		}
+		pt, err := NewPatch(selector, res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &Patch{}
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+	body := func(ref string) string {
+		t.Helper()
+		ds := tr.MustGet(t, ref)
+		f, err := dsfs.LoadBody(tr.Ctx, tr.Instance.repo.Filesystem(), ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// a keyed body is patched row-by-row
+	save("me/cities", "city,pop\ntoronto,50000000\nnew york,8500000\nchicago,300000\n", keyed)
+	save("me/cities_proposal", "city,pop\nchatham,35000\ntoronto,50000000\nnew york,8600000\n", keyed)
+	pt := makePatch("me/cities", "me/cities_proposal", "body")
+
+	if _, err := m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/cities", Patch: pt}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "city,pop\ntoronto,50000000\nnew york,8600000\nchatham,35000\n"
+	if diff := cmp.Diff(expect, body("me/cities")); diff != "" {
+		t.Errorf("patched body mismatch (-want +got):\n%s", diff)
+	}
+
+	// the patch no longer applies to the latest version
+	_, err := m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/cities", Patch: pt})
+	if !errors.Is(err, ErrPatchBaseMismatch) {
+		t.Errorf("expected applying a patch twice to fail with ErrPatchBaseMismatch. got: %v", err)
+	}
+
+	// naming the base version in the reference doesn't get around the check
+	pt = makePatch("me/cities", "me/cities_proposal", "body")
+	base := pt.Base
+	save("me/cities", "city,pop\ntoronto,50000000\n", keyed)
+	head := tr.MustGet(t, "me/cities").Path
+	if _, err := m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/cities@" + base, Patch: pt}); err == nil {
+		t.Error("expected applying a patch to a reference with a version path to fail")
+	}
+	if got := tr.MustGet(t, "me/cities").Path; got != head {
+		t.Errorf("expected a refused patch to leave the head unchanged. want: %s, got: %s", head, got)
+	}
+
+	// bodies without a key are patched as trees
+	save("me/movies", "title,duration\nup,96\ncars,117\n", nil)
+	save("me/movies_proposal", "title,duration\nup,96\ncoco,105\ncars,117\n", nil)
+	pt = makePatch("me/movies", "me/movies_proposal", "body")
+	if _, err := m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/movies", Patch: pt}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("title,duration\nup,96\ncoco,105\ncars,117\n", body("me/movies")); diff != "" {
+		t.Errorf("patched body mismatch (-want +got):\n%s", diff)
+	}
+
+	// components other than the body
+	if _, err := m.Save(tr.Ctx, &SaveParams{Ref: "me/movies", Dataset: &dataset.Dataset{Meta: &dataset.Meta{Title: "movies"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Save(tr.Ctx, &SaveParams{Ref: "me/movies_proposal", Dataset: &dataset.Dataset{Meta: &dataset.Meta{Title: "pixar movies"}}}); err != nil {
+		t.Fatal(err)
+	}
+	pt = makePatch("me/movies", "me/movies_proposal", "meta")
+	ds, err := m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/movies", Patch: pt, Title: "set title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta == nil || ds.Meta.Title != "pixar movies" {
+		t.Errorf("expected patched meta title. got: %v", ds.Meta)
+	}
+	if ds.Commit.Title != "set title" {
+		t.Errorf("expected commit title %q, got %q", "set title", ds.Commit.Title)
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	m := NewDatasetMethods(tr.Instance)
+	tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+
+	bad := []struct {
+		description string
+		patch       string
+		err         string
+	}{
+		{"unknown version", `{"qri":"pt:9","base":"/mem/Qm","selector":"body"}`, `unsupported patch version "pt:9", expected "pt:0"`},
+		{"unknown component", `{"qri":"pt:0","base":"/mem/Qm","selector":"commit"}`, `patches can only change one of these components: [body meta structure]`},
+		{"invalid change", `{"qri":"pt:0","base":"/mem/Qm","selector":"body","diff":[["+"]]}`, `reading patch: invalid change: ["+"]`},
+	}
+	for _, c := range bad {
+		pt, err := ReadPatchFile(tr.MustWriteTmpFile(t, "patch.json", c.patch))
+		if err == nil {
+			_, err = m.ApplyPatch(tr.Ctx, &ApplyPatchParams{Ref: "me/cities", Patch: pt})
+		}
+		if err == nil {
+			t.Errorf("case %q: expected error, got nil", c.description)
+			continue
+		}
+		if err.Error() != c.err {
+			t.Errorf("case %q: error mismatch. want: %q, got: %q", c.description, c.err, err)
+		}
+	}
+
+	if _, err := NewPatch("body", &DiffResponse{}); err == nil {
+		t.Error("expected creating a patch from a diff without a base version to fail")
+	}
+}
@@ -0,0 +1,163 @@
+package lib
+
+import (
+	"context"
+	"errors"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/stats"
+)
+
+// RepoMethods encapsulates business logic for maintaining a qri repo
+type RepoMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (RepoMethods) CoreRequestsName() string { return "repo" }
+
+// NewRepoMethods creates a RepoMethods pointer from a qri instance
+func NewRepoMethods(inst *Instance) *RepoMethods {
+	return &RepoMethods{
+		inst: inst,
+	}
+}
+
+// GCParams defines parameters for collecting garbage
+type GCParams struct {
+	// DryRun reports what would be collected without removing anything
+	DryRun bool
+}
+
+// GCResult describes the outcome of garbage collection
+type GCResult struct {
+	DryRun bool `json:"dryRun,omitempty"`
+	// Unpinned lists dataset versions the logbook doesn't reference
+	Unpinned []string `json:"unpinned"`
+	// Blocks is the number of collected filesystem blocks
+	Blocks int `json:"blocks"`
+	// BlocksReclaimed is the size of collected blocks, in bytes
+	BlocksReclaimed uint64 `json:"blocksReclaimed"`
+	// StatsRemoved lists dropped stats cache entries
+	StatsRemoved []stats.CacheEntry `json:"statsRemoved"`
+	// StatsReclaimed is the size of dropped stats cache entries, in bytes
+	StatsReclaimed int64 `json:"statsReclaimed"`
+	// RunsRemoved lists the IDs of dropped transform run records
+	RunsRemoved []string `json:"runsRemoved"`
+}
+
+// Reclaimed is the total space freed by garbage collection, in bytes
+func (r *GCResult) Reclaimed() uint64 {
+	return r.BlocksReclaimed + uint64(r.StatsReclaimed)
+}
+
+// GC removes data the repo no longer references: dataset versions that aren't
+// in the logbook are unpinned, unreachable blocks are removed from the
+// filesystem & cached stats for unreferenced versions are dropped. Run
+// records beyond the number transform config keeps for each dataset are
+// dropped. HTTP recordings of transform runs are kept as long as the logbook
+// references them
+func (m *RepoMethods) GC(ctx context.Context, p *GCParams) (*GCResult, error) {
+	if m.inst.http != nil {
+		res := &GCResult{}
+		if err := m.inst.http.Call(ctx, AEGC, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	res := &GCResult{
+		DryRun:       p.DryRun,
+		Unpinned:     []string{},
+		StatsRemoved: []stats.CacheEntry{},
+		RunsRemoved:  []string{},
+	}
+
+	blocks, err := base.GarbageCollect(ctx, m.inst.repo, p.DryRun)
+	if errors.Is(err, repo.ErrNotPinner) {
+		log.Debugw("repo filesystem doesn't support pinning, skipping block collection")
+	} else if err != nil {
+		return nil, err
+	} else {
+		res.Unpinned = blocks.Unpinned
+		res.Blocks = blocks.Blocks
+		res.BlocksReclaimed = blocks.Reclaimed
+	}
+
+	if m.inst.stats != nil {
+		removed, err := m.inst.pruneStatsCache(ctx, p.DryRun)
+		if err != nil && !errors.Is(err, stats.ErrNoCache) {
+			return nil, err
+		}
+		for _, ent := range removed {
+			res.StatsRemoved = append(res.StatsRemoved, ent)
+			res.StatsReclaimed += ent.Size
+		}
+	}
+
+	if res.RunsRemoved, err = m.inst.pruneRuns(ctx, p.DryRun); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FsckParams defines parameters for checking a repo for inconsistencies
+type FsckParams struct {
+	// Repair rebuilds the dscache & refstore from the logbook
+	Repair bool
+}
+
+// FsckResult describes the outcome of checking a repo
+type FsckResult struct {
+	// Problems lists inconsistencies found in the repo
+	Problems []base.FsckProblem `json:"problems"`
+	// Repaired is true if the dscache & refstore were rebuilt
+	Repaired bool `json:"repaired,omitempty"`
+	// Remaining lists problems left after a repair. Repairs can't fix
+	// signature mismatches or missing blocks
+	Remaining []base.FsckProblem `json:"remaining,omitempty"`
+}
+
+// Fsck checks the repo for inconsistencies: logbook signatures must match
+// their authors, every head in the refstore must be stored in full, and the
+// dscache & refstore must agree with the logbook about datasets stored
+// locally. Repairing rebuilds the dscache & refstore from the logbook
+func (m *RepoMethods) Fsck(ctx context.Context, p *FsckParams) (*FsckResult, error) {
+	if m.inst.http != nil {
+		res := &FsckResult{}
+		if err := m.inst.http.Call(ctx, AEFsck, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	problems, err := base.Fsck(ctx, m.inst.repo)
+	if err != nil {
+		return nil, err
+	}
+	res := &FsckResult{Problems: problems}
+	if !p.Repair || !repairable(problems) {
+		return res, nil
+	}
+
+	if err := base.RepairRefs(ctx, m.inst.repo); err != nil {
+		return nil, err
+	}
+	res.Repaired = true
+	if res.Remaining, err = base.Fsck(ctx, m.inst.repo); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// repairable is true if any problem can be fixed by rebuilding references
+func repairable(problems []base.FsckProblem) bool {
+	for _, p := range problems {
+		if p.Kind == base.FsckDscache || p.Kind == base.FsckRefstore {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/stats"
+)
+
+func TestGC(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	cache := stats.NewMemCache(1 << 20)
+	tr.Instance.stats = stats.New(cache)
+
+	ds := tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+	sa := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{}}
+	if err := cache.PutStats(tr.Ctx, ds.Path, sa); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.PutStats(tr.Ctx, "/mem/QmUnreferencedVersion", sa); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewRepoMethods(tr.Instance)
+	res, err := m.GC(tr.Ctx, &GCParams{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.DryRun {
+		t.Error("expected result to be marked as a dry run")
+	}
+	if len(res.StatsRemoved) != 1 || res.StatsRemoved[0].Key != "/mem/QmUnreferencedVersion" {
+		t.Errorf("expected dry run to list the unreferenced stats entry. got: %v", res.StatsRemoved)
+	}
+	if res.Reclaimed() == 0 {
+		t.Error("expected dry run to report reclaimable space")
+	}
+
+	entries, err := cache.Entries(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected dry run to keep all cache entries. got: %d", len(entries))
+	}
+
+	dryRun := res
+	if res, err = m.GC(tr.Ctx, &GCParams{}); err != nil {
+		t.Fatal(err)
+	}
+	dryRun.DryRun = false
+	if diff := cmp.Diff(dryRun, res); diff != "" {
+		t.Errorf("expected gc to remove what the dry run reported (-want +got):\n%s", diff)
+	}
+
+	entries, err = cache.Entries(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Key != ds.Path {
+		t.Errorf("expected only the referenced version's stats to remain. got: %v", entries)
+	}
+}
+
+func TestFsck(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	ds := tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+
+	m := NewRepoMethods(tr.Instance)
+	res, err := m.Fsck(tr.Ctx, &FsckParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) != 0 {
+		t.Errorf("expected a consistent repo to have no problems. got: %v", res.Problems)
+	}
+
+	ref := reporef.DatasetRef{Peername: ds.Peername, Name: ds.Name}
+	if err := tr.Instance.repo.DeleteRef(ref); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = m.Fsck(tr.Ctx, &FsckParams{}); err != nil {
+		t.Fatal(err)
+	}
+	expect := []base.FsckProblem{
+		{Kind: base.FsckRefstore, Ref: "peer/cities", Message: "missing dataset"},
+	}
+	if diff := cmp.Diff(expect, res.Problems); diff != "" {
+		t.Errorf("problems mismatch (-want +got):\n%s", diff)
+	}
+
+	if res, err = m.Fsck(tr.Ctx, &FsckParams{Repair: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Repaired || len(res.Remaining) != 0 {
+		t.Errorf("expected repair to fix all problems. got: %v", res.Remaining)
+	}
+	got, err := tr.Instance.repo.GetRef(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != ds.Path {
+		t.Errorf("expected repaired ref path to be %s. got: %s", ds.Path, got.Path)
+	}
+}
+
+func TestFsckSkipsVersionsNotStoredLocally(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	first := tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+	tr.MustSaveFromBody(t, "cities", tr.MustWriteTmpFile(t, "body.csv", "city,pop\ntoronto,50000000\n"))
+
+	// older versions of a pulled dataset are often never fetched
+	if err := tr.Instance.repo.Filesystem().Delete(tr.Ctx, first.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	// logs fetched from a remote can reference versions that were never pulled
+	book := tr.Instance.repo.Logbook()
+	initID, err := book.WriteDatasetInit(tr.Ctx, "fetched")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched := &dataset.Dataset{
+		Peername: "peer",
+		Name:     "fetched",
+		Path:     "/mem/QmVersionThatWasNeverPulled",
+		Commit:   &dataset.Commit{Title: "never pulled"},
+	}
+	if err := book.WriteVersionSave(tr.Ctx, initID, fetched, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewRepoMethods(tr.Instance)
+	res, err := m.Fsck(tr.Ctx, &FsckParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) != 0 {
+		t.Errorf("expected versions that aren't stored locally to be skipped. got: %v", res.Problems)
+	}
+
+	if _, err := m.Fsck(tr.Ctx, &FsckParams{Repair: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Instance.repo.GetRef(reporef.DatasetRef{Peername: "peer", Name: "fetched"}); err == nil {
+		t.Error("expected repair to skip a dataset with a head that isn't stored locally")
+	}
+}
+
+func TestFsckMissingLocalHead(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	first := tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+	head := tr.MustSaveFromBody(t, "cities", tr.MustWriteTmpFile(t, "body.csv", "city,pop\ntoronto,50000000\n"))
+
+	// the refstore claims the dataset is local, but the logbook head is gone
+	if err := tr.Instance.repo.Filesystem().Delete(tr.Ctx, head.Path); err != nil {
+		t.Fatal(err)
+	}
+	ref := reporef.DatasetRef{Peername: first.Peername, ProfileID: tr.Instance.repo.Profiles().Owner().ID, Name: first.Name, Path: first.Path}
+	if err := tr.Instance.repo.PutRef(ref); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewRepoMethods(tr.Instance)
+	res, err := m.Fsck(tr.Ctx, &FsckParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []base.FsckProblem{
+		{Kind: base.FsckBlocks, Ref: "peer/cities", Message: fmt.Sprintf("logbook head %s isn't in the repo filesystem", head.Path)},
+	}
+	if diff := cmp.Diff(expect, res.Problems); diff != "" {
+		t.Errorf("problems mismatch (-want +got):\n%s", diff)
+	}
+}
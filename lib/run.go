@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/transform/run"
+)
+
+// newRunStore creates a store of transform run records at repoPath/runs.
+// Instances without a repo path keep runs in memory
+func newRunStore(repoPath string) run.Store {
+	if repoPath == "" {
+		return run.NewMemStore()
+	}
+	store, err := run.NewLocalStore(filepath.Join(repoPath, "runs"))
+	if err != nil {
+		log.Errorw("creating run store, keeping runs in memory", "err", err)
+		return run.NewMemStore()
+	}
+	return store
+}
+
+// putRun records a transform run. Runs that can't be recorded are logged &
+// ignored, so a failure to record never masks the result of the run
+func (inst *Instance) putRun(ctx context.Context, rs *run.State) {
+	if inst.runs == nil {
+		return
+	}
+	if err := inst.runs.Put(ctx, rs); err != nil {
+		log.Errorw("recording transform run", "runID", rs.ID, "err", err)
+	}
+}
+
+// putFailedRun records a run that exited with an error. Runs that fail before
+// the transform starts are stamped with the current time, so they list in
+// order with runs that started
+func (inst *Instance) putFailedRun(ctx context.Context, rs *run.State, msg string) {
+	rs.Status = run.RSFailed
+	rs.Message = msg
+	if rs.StartTime == nil {
+		now := time.Now()
+		rs.StartTime = &now
+	}
+	inst.putRun(ctx, rs)
+}
+
+// pruneRuns drops all but the newest run records of each dataset, keeping
+// the number of runs set in transform config, returning the IDs of dropped
+// runs. When dryRun is true runs are listed but not dropped
+func (inst *Instance) pruneRuns(ctx context.Context, dryRun bool) ([]string, error) {
+	keep := config.DefaultKeepRuns
+	if inst.cfg != nil && inst.cfg.Transform != nil {
+		keep = inst.cfg.Transform.KeepRuns
+	}
+	if inst.runs == nil || keep == 0 {
+		return []string{}, nil
+	}
+	return inst.runs.Prune(ctx, keep, dryRun)
+}
+
+// RunMethods encapsulates business logic for inspecting transform runs
+type RunMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (RunMethods) CoreRequestsName() string { return "run" }
+
+// NewRunMethods creates a RunMethods pointer from a qri instance
+func NewRunMethods(inst *Instance) *RunMethods {
+	return &RunMethods{
+		inst: inst,
+	}
+}
+
+// RunListParams defines parameters for listing the transform runs of a
+// dataset
+type RunListParams struct {
+	Ref    string
+	Offset int
+	Limit  int
+}
+
+// RunParams defines parameters for fetching a transform run
+type RunParams struct {
+	ID string
+}
+
+// List shows the transform runs of a dataset, newest first. Runs are recorded
+// each time a transform is applied, whether the run saves a version or not,
+// including scheduled runs & runs that fail. Garbage collection drops old runs
+func (m *RunMethods) List(ctx context.Context, p *RunListParams) ([]*run.State, error) {
+	if m.inst.http != nil {
+		res := []*run.State{}
+		if err := m.inst.http.Call(ctx, AERuns, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	limit := p.Limit
+	if limit == 0 {
+		limit = -1
+	}
+	return m.inst.runs.List(ctx, ref.InitID, p.Offset, limit)
+}
+
+// Get fetches the full record of a transform run, including step timings &
+// output
+func (m *RunMethods) Get(ctx context.Context, p *RunParams) (*run.State, error) {
+	if m.inst.http != nil {
+		res := &run.State{}
+		if err := m.inst.http.Call(ctx, AERun, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	return m.inst.runs.Get(ctx, p.ID)
+}
@@ -0,0 +1,161 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform/run"
+)
+
+func TestRunMethods(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	okScript := tr.MustWriteTmpFile(t, "ok.star", `def transform(ds, ctx):
+  print("setting body")
+  ds.set_body([[1, 2]])
+`)
+	failScript := tr.MustWriteTmpFile(t, "fail.star", `def transform(ds, ctx):
+  print("about to fail")
+  error("broken transform")
+`)
+
+	saved, err := tr.SaveWithParams(&SaveParams{
+		Ref:     "me/run_ds",
+		Dataset: &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: okScript}},
+		Apply:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:     "me/run_ds",
+		Dataset: &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: failScript}},
+		Apply:   true,
+	}); err == nil {
+		t.Fatal("expected failing transform to error")
+	}
+
+	m := NewRunMethods(tr.Instance)
+	runs, err := m.List(tr.Ctx, &RunListParams{Ref: "me/run_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got: %d", len(runs))
+	}
+	failed, succeeded := runs[0], runs[1]
+	if failed.Status != run.RSFailed || failed.Path != "" {
+		t.Errorf("expected newest run to have failed without saving. got status: %q path: %q", failed.Status, failed.Path)
+	}
+	if succeeded.Status != run.RSSucceeded || succeeded.Path != saved.Path {
+		t.Errorf("expected oldest run to succeed & save version %q. got status: %q path: %q", saved.Path, succeeded.Status, succeeded.Path)
+	}
+
+	rs, err := m.Get(tr.Ctx, &RunParams{ID: failed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []string{}
+	for _, e := range rs.Output {
+		if msg, ok := e.Payload.(event.TransformMessage); ok {
+			msgs = append(msgs, msg.Msg)
+		}
+	}
+	if len(msgs) != 2 || msgs[0] != "about to fail" {
+		t.Errorf("expected the failed run to keep printed output & the error. got: %v", msgs)
+	}
+
+	if _, err := m.Get(tr.Ctx, &RunParams{ID: "unknown"}); !errors.Is(err, run.ErrNotFound) {
+		t.Errorf("expected an unknown run to return ErrNotFound. got: %v", err)
+	}
+}
+
+func TestRunsRecordedOutsideSave(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	script := tr.MustWriteTmpFile(t, "ok.star", `def transform(ds, ctx):
+  ds.set_body([[1, 2]])
+`)
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:     "me/run_ds",
+		Dataset: &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: script}},
+		Apply:   true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := NewTransformMethods(tr.Instance)
+	applied, err := tm.Apply(tr.Ctx, &ApplyParams{
+		Refstr:    "me/run_ds",
+		Transform: &dataset.Transform{ScriptPath: script},
+		Wait:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// runs that fail before the transform starts, like a scheduled save
+	// replaying a missing recording, are recorded
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:       "me/run_ds",
+		Apply:     true,
+		ReplayRun: "missing_run",
+	}); err == nil {
+		t.Fatal("expected replaying a missing run to fail")
+	}
+
+	m := NewRunMethods(tr.Instance)
+	runs, err := m.List(tr.Ctx, &RunListParams{Ref: "me/run_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got: %d", len(runs))
+	}
+	if runs[0].Status != run.RSFailed || runs[0].Message == "" {
+		t.Errorf("expected newest run to fail with a message. got status: %q message: %q", runs[0].Status, runs[0].Message)
+	}
+	if runs[1].ID != applied.RunID || runs[1].Status != run.RSSucceeded || runs[1].Path != "" {
+		t.Errorf("expected the applied run to succeed without saving. got: %#v", runs[1])
+	}
+
+	testScript := tr.MustWriteTmpFile(t, "tested.star", `def transform(ds, ctx):
+  ds.set_body([[1]])
+`)
+	tr.MustWriteTmpFile(t, "tested_test.star", `def test_body():
+  ds = new_dataset()
+  transform(ds, new_context())
+  assert_eq(ds.get_body(), [[1]])
+`)
+	report, err := tm.Test(tr.Ctx, &TransformTestParams{ScriptPath: testScript})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := m.Get(tr.Ctx, &RunParams{ID: report.RunID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Status != run.RSSucceeded || rs.Message != "1 tests passed" {
+		t.Errorf("expected the test run to be recorded. got status: %q message: %q", rs.Status, rs.Message)
+	}
+
+	// gc keeps the newest runs of each dataset
+	tr.Instance.cfg.Transform.KeepRuns = 1
+	res, err := NewRepoMethods(tr.Instance).GC(tr.Ctx, &GCParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.RunsRemoved) != 2 {
+		t.Errorf("expected gc to remove 2 runs. got: %v", res.RunsRemoved)
+	}
+	if runs, err = m.List(tr.Ctx, &RunListParams{Ref: "me/run_ds"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != run.RSFailed {
+		t.Errorf("expected only the newest run to remain. got: %v", runs)
+	}
+}
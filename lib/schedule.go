@@ -0,0 +1,282 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/scheduler"
+)
+
+// JobTypeScheduledSave is the type of jobs that apply the transform of a
+// scheduled dataset
+const JobTypeScheduledSave = "scheduled-save"
+
+// ErrNoScheduler indicates an instance can't run scheduled transforms
+var ErrNoScheduler = fmt.Errorf("scheduler is not available")
+
+// newScheduler creates a scheduler from configuration. Scheduled runs save
+// the dataset, applying its transform in a background job
+func newScheduler(inst *Instance, cfg *config.Config) *scheduler.Scheduler {
+	schedCfg := config.DefaultScheduler()
+	if cfg != nil && cfg.Scheduler != nil {
+		schedCfg = cfg.Scheduler
+	}
+	backoff, err := schedCfg.Backoff()
+	if err != nil {
+		log.Debugw("reading scheduler backoff, using defaults", "err", err)
+	}
+
+	s := scheduler.New(inst.runScheduledSave, backoff)
+	for _, sch := range schedCfg.Schedules {
+		if err := s.Set(sch.Ref, sch.Periodicity); err != nil {
+			log.Errorw("invalid dataset schedule", "ref", sch.Ref, "err", err)
+		}
+	}
+	return s
+}
+
+// runScheduledSave applies the transform of a dataset in a job, blocking
+// until the job stops. Running a transform that produces no changes is not an
+// error. Saving records each transform run in the logbook
+func (inst *Instance) runScheduledSave(ctx context.Context, ref string) error {
+	j, err := inst.startScheduledSave(ref)
+	if err != nil {
+		return err
+	}
+	if j, err = inst.jobs.wait(ctx, j.ID); err != nil {
+		return err
+	}
+	if j.Status != JSSucceeded {
+		return errors.New(j.Error)
+	}
+	return nil
+}
+
+func (inst *Instance) startScheduledSave(ref string) (Job, error) {
+	if inst.jobs == nil {
+		return Job{}, ErrNoJobRegistry
+	}
+	dsm := NewDatasetMethods(inst)
+	return inst.jobs.start(JobTypeScheduledSave, ref, func(ctx context.Context) (string, error) {
+		// scheduled runs exist to fetch fresh data. the step cache keys
+		// downloads on the script, not on when they ran, so skip it
+		ds, err := dsm.Save(ctx, &SaveParams{Ref: ref, Apply: true, NoCache: true})
+		if errors.Is(err, dsfs.ErrNoChanges) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		return ds.Path, nil
+	}), nil
+}
+
+// StartScheduler runs scheduled transforms until ctx is done. StartScheduler
+// doesn't block, and does nothing if the scheduler is disabled in config
+func (inst *Instance) StartScheduler(ctx context.Context) {
+	if inst.scheduler == nil || inst.cfg == nil || (inst.cfg.Scheduler != nil && !inst.cfg.Scheduler.Enabled) {
+		log.Debugw("scheduler disabled")
+		return
+	}
+	go inst.scheduler.Start(ctx)
+}
+
+// ScheduleMethods encapsulates business logic for running dataset
+// transforms on a schedule
+type ScheduleMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (ScheduleMethods) CoreRequestsName() string { return "schedule" }
+
+// NewScheduleMethods creates a ScheduleMethods pointer from a qri instance
+func NewScheduleMethods(inst *Instance) *ScheduleMethods {
+	return &ScheduleMethods{
+		inst: inst,
+	}
+}
+
+// ScheduleParams defines parameters for scheduling a dataset
+type ScheduleParams struct {
+	Ref string
+	// cron expression or ISO-8601 repeating interval. Intervals without a
+	// start time start now, eg: "R/P1D" runs once a day from now
+	Periodicity string
+}
+
+// ScheduleListParams defines parameters for listing scheduled datasets
+type ScheduleListParams struct{}
+
+// Add schedules a dataset's transform to run periodically, replacing any
+// existing schedule for the dataset. Schedules are stored in config
+func (m *ScheduleMethods) Add(ctx context.Context, p *ScheduleParams) (*scheduler.Status, error) {
+	if m.inst.http != nil {
+		res := &scheduler.Status{}
+		if err := m.inst.http.Call(ctx, AEScheduleAdd, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+
+	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	tfds, err := base.LoadRevs(ctx, m.inst.qfs, ref, []*dsref.Rev{{Field: "tf", Gen: 1}})
+	if err != nil {
+		return nil, fmt.Errorf("loading transform component from history: %w", err)
+	}
+	if tfds.Transform == nil {
+		return nil, fmt.Errorf("cannot schedule %s: dataset has no transform", ref.Human())
+	}
+
+	periodicity := scheduler.AnchorInterval(p.Periodicity, time.Now())
+	if _, err := scheduler.ParsePeriodicity(periodicity); err != nil {
+		return nil, err
+	}
+
+	refstr := ref.Human()
+	cfg := m.inst.cfg.Copy()
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = config.DefaultScheduler()
+	}
+	replaced := false
+	for _, sch := range cfg.Scheduler.Schedules {
+		if sch.Ref == refstr {
+			sch.Periodicity = periodicity
+			replaced = true
+		}
+	}
+	if !replaced {
+		cfg.Scheduler.Schedules = append(cfg.Scheduler.Schedules, &config.Schedule{Ref: refstr, Periodicity: periodicity})
+	}
+	if err := m.inst.ChangeConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := m.inst.scheduler.Set(refstr, periodicity); err != nil {
+		return nil, err
+	}
+	st, err := m.inst.scheduler.Get(refstr)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// List shows scheduled datasets, sorted by reference
+func (m *ScheduleMethods) List(ctx context.Context, p *ScheduleListParams) ([]scheduler.Status, error) {
+	if m.inst.http != nil {
+		res := []scheduler.Status{}
+		if err := m.inst.http.Call(ctx, AESchedules, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+	return m.inst.scheduler.List(), nil
+}
+
+// Remove stops running a dataset's transform on a schedule, returning the
+// last status of the removed schedule
+func (m *ScheduleMethods) Remove(ctx context.Context, p *ScheduleParams) (*scheduler.Status, error) {
+	if m.inst.http != nil {
+		res := &scheduler.Status{}
+		if err := m.inst.http.Call(ctx, AEScheduleRemove, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+
+	refstr, err := m.scheduledRef(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	st, err := m.inst.scheduler.Get(refstr)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := m.inst.cfg.Copy()
+	if cfg.Scheduler != nil {
+		schedules := cfg.Scheduler.Schedules[:0]
+		for _, sch := range cfg.Scheduler.Schedules {
+			if sch.Ref != refstr {
+				schedules = append(schedules, sch)
+			}
+		}
+		cfg.Scheduler.Schedules = schedules
+		if err := m.inst.ChangeConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.inst.scheduler.Remove(refstr); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// RunNow applies the transform of a scheduled dataset immediately, blocking
+// until the run finishes. The result counts toward the dataset's backoff
+func (m *ScheduleMethods) RunNow(ctx context.Context, p *ScheduleParams) (*Job, error) {
+	if m.inst.http != nil {
+		res := &Job{}
+		if err := m.inst.http.Call(ctx, AEScheduleRun, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+
+	refstr, err := m.scheduledRef(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.inst.scheduler.Get(refstr); err != nil {
+		return nil, err
+	}
+
+	j, err := m.inst.startScheduledSave(refstr)
+	if err != nil {
+		return nil, err
+	}
+	if j, err = m.inst.jobs.wait(ctx, j.ID); err != nil {
+		return nil, err
+	}
+	var runErr error
+	if j.Status != JSSucceeded {
+		runErr = errors.New(j.Error)
+	}
+	m.inst.scheduler.Record(refstr, time.Now(), runErr)
+	return &j, nil
+}
+
+// scheduledRef converts a reference string to the form schedules are stored
+// in. Datasets don't need to exist to have a schedule removed
+func (m *ScheduleMethods) scheduledRef(refstr string) (string, error) {
+	ref, err := dsref.Parse(refstr)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid dataset reference: %w", refstr, err)
+	}
+	if ref.Username == "me" {
+		ref.Username = m.inst.cfg.Profile.Peername
+	}
+	return ref.Human(), nil
+}
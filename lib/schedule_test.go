@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/scheduler"
+	"github.com/qri-io/qri/transform"
+)
+
+func TestScheduleMethods(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	ctx, cancel := context.WithTimeout(tr.Ctx, time.Second*10)
+	defer cancel()
+
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "me/hello", FilePaths: []string{"testdata/tf/transform.star"}, Apply: true}); err != nil {
+		t.Fatal(err)
+	}
+	tr.MustSaveFromBody(t, "no_transform", "testdata/cities_2/body.csv")
+
+	m := NewScheduleMethods(tr.Instance)
+	if _, err := m.Add(ctx, &ScheduleParams{Ref: "me/no_transform", Periodicity: "0 * * * *"}); err == nil {
+		t.Error("expected scheduling a dataset without a transform to fail")
+	}
+	if _, err := m.Add(ctx, &ScheduleParams{Ref: "me/hello", Periodicity: "not a schedule"}); err == nil {
+		t.Error("expected scheduling with an invalid periodicity to fail")
+	}
+
+	st, err := m.Add(ctx, &ScheduleParams{Ref: "me/hello", Periodicity: "R/P1D"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Ref != "peer/hello" || st.NextRun == nil {
+		t.Errorf("unexpected status: %#v", st)
+	}
+	if cfg := tr.Instance.Config().Scheduler; len(cfg.Schedules) != 1 || cfg.Schedules[0].Ref != "peer/hello" {
+		t.Errorf("expected schedule to be stored in config, got: %#v", cfg.Schedules)
+	}
+
+	// re-adding a dataset replaces its schedule
+	if _, err = m.Add(ctx, &ScheduleParams{Ref: "me/hello", Periodicity: "*/5 * * * *"}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := m.List(ctx, &ScheduleListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Periodicity != "*/5 * * * *" {
+		t.Errorf("unexpected schedule list: %#v", list)
+	}
+
+	// the transform produces the same body, which isn't a failed run
+	job, err := m.RunNow(ctx, &ScheduleParams{Ref: "me/hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Type != JobTypeScheduledSave || job.Status != JSSucceeded {
+		t.Errorf("expected scheduled save job to succeed, got: %#v", job)
+	}
+	if list, _ = m.List(ctx, &ScheduleListParams{}); list[0].LastRun == nil || list[0].Failures != 0 {
+		t.Errorf("expected run to be recorded, got: %#v", list[0])
+	}
+
+	if _, err = m.RunNow(ctx, &ScheduleParams{Ref: "me/no_transform"}); !errors.Is(err, scheduler.ErrNotScheduled) {
+		t.Errorf("expected running an unscheduled dataset to return ErrNotScheduled, got: %v", err)
+	}
+
+	if _, err = m.Remove(ctx, &ScheduleParams{Ref: "me/hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ = m.List(ctx, &ScheduleListParams{}); len(list) != 0 {
+		t.Errorf("expected no schedules after removal, got: %#v", list)
+	}
+	if cfg := tr.Instance.Config().Scheduler; len(cfg.Schedules) != 0 {
+		t.Errorf("expected removal to update config, got: %#v", cfg.Schedules)
+	}
+}
+
+func TestScheduledSaveSkipsStepCache(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+	tr.Instance.transform = transform.NewService(tr.Ctx, tr.Instance.repo, transform.NewMemStepCache())
+
+	ctx, cancel := context.WithTimeout(tr.Ctx, time.Second*10)
+	defer cancel()
+
+	// each request downloads a different body
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `[["run",%d]]`, requests)
+	}))
+	defer s.Close()
+
+	tf := &dataset.Transform{
+		Steps: []*dataset.TransformStep{
+			{Syntax: "starlark", Category: "setup", Script: "load(\"http.star\", \"http\")"},
+			{Syntax: "starlark", Category: "download", Script: fmt.Sprintf("def download(ctx):\n\treturn http.get(%q).json()", s.URL)},
+			{Syntax: "starlark", Category: "transform", Script: "def transform(ds, ctx):\n\tds.set_body(ctx.download)"},
+		},
+	}
+	if _, err := tr.SaveWithParams(&SaveParams{Ref: "me/fetched", Dataset: &dataset.Dataset{Transform: tf}, Apply: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewScheduleMethods(tr.Instance)
+	if _, err := m.Add(ctx, &ScheduleParams{Ref: "me/fetched", Periodicity: "R/P1D"}); err != nil {
+		t.Fatal(err)
+	}
+	body := func() string {
+		res, err := NewDatasetMethods(tr.Instance).Get(ctx, &GetParams{Refstr: "me/fetched", Selector: "body", Format: "json", All: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(res.Bytes)
+	}
+	prev := body()
+	for i := 1; i <= 2; i++ {
+		job, err := m.RunNow(ctx, &ScheduleParams{Ref: "me/fetched"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != JSSucceeded || job.Path == "" {
+			t.Fatalf("expected scheduled run %d to save a version, got: %#v", i, job)
+		}
+		got := body()
+		if got == prev {
+			t.Errorf("expected scheduled run %d to download a fresh body. got: %s", i, got)
+		}
+		prev = got
+	}
+}
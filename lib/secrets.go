@@ -0,0 +1,176 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+// ErrNoSecretStore indicates an instance can't store secrets
+var ErrNoSecretStore = fmt.Errorf("secret store is not available")
+
+// SecretMethods encapsulates business logic for the secrets transforms can
+// read. Secret values are never returned, only names
+type SecretMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (SecretMethods) CoreRequestsName() string { return "secrets" }
+
+// NewSecretMethods creates a SecretMethods pointer from a qri instance
+func NewSecretMethods(inst *Instance) *SecretMethods {
+	return &SecretMethods{
+		inst: inst,
+	}
+}
+
+// SecretParams defines parameters for changing a stored secret
+type SecretParams struct {
+	Name  string
+	Value string
+}
+
+// SecretListParams defines parameters for listing stored secrets
+type SecretListParams struct{}
+
+// SecretGrantParams defines parameters for changing which datasets can read a
+// stored secret
+type SecretGrantParams struct {
+	Name string
+	// Dataset is a "username/name" reference to the dataset whose transforms
+	// can read the secret
+	Dataset string
+}
+
+// Set stores a secret, replacing any existing value. Transforms can only read
+// stored secrets that are granted to their dataset
+func (m *SecretMethods) Set(ctx context.Context, p *SecretParams) (string, error) {
+	if m.inst.http != nil {
+		var res string
+		if err := m.inst.http.Call(ctx, AESecretSet, p, &res); err != nil {
+			return "", err
+		}
+		return res, nil
+	}
+	if m.inst.secrets == nil {
+		return "", ErrNoSecretStore
+	}
+	if err := m.inst.secrets.Set(p.Name, p.Value); err != nil {
+		return "", err
+	}
+	return p.Name, nil
+}
+
+// List shows the names of stored secrets in sorted order
+func (m *SecretMethods) List(ctx context.Context, p *SecretListParams) ([]string, error) {
+	if m.inst.http != nil {
+		res := []string{}
+		if err := m.inst.http.Call(ctx, AESecrets, p, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.secrets == nil {
+		return nil, ErrNoSecretStore
+	}
+	return m.inst.secrets.Names(), nil
+}
+
+// Remove drops a stored secret
+func (m *SecretMethods) Remove(ctx context.Context, p *SecretParams) (string, error) {
+	if m.inst.http != nil {
+		var res string
+		if err := m.inst.http.Call(ctx, AESecretRemove, p, &res); err != nil {
+			return "", err
+		}
+		return res, nil
+	}
+	if m.inst.secrets == nil {
+		return "", ErrNoSecretStore
+	}
+	if err := m.inst.secrets.Remove(p.Name); err != nil {
+		return "", err
+	}
+	return p.Name, nil
+}
+
+// Grant allows transforms of a dataset to read a stored secret, returning the
+// dataset reference the secret is granted to
+func (m *SecretMethods) Grant(ctx context.Context, p *SecretGrantParams) (string, error) {
+	if m.inst.http != nil {
+		var res string
+		if err := m.inst.http.Call(ctx, AESecretGrant, p, &res); err != nil {
+			return "", err
+		}
+		return res, nil
+	}
+	if m.inst.secrets == nil {
+		return "", ErrNoSecretStore
+	}
+	ref, err := m.inst.secretGrantRef(p.Dataset)
+	if err != nil {
+		return "", err
+	}
+	if err := m.inst.secrets.Grant(p.Name, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// Revoke stops transforms of a dataset from reading a stored secret, returning
+// the dataset reference the grant was removed from
+func (m *SecretMethods) Revoke(ctx context.Context, p *SecretGrantParams) (string, error) {
+	if m.inst.http != nil {
+		var res string
+		if err := m.inst.http.Call(ctx, AESecretRevoke, p, &res); err != nil {
+			return "", err
+		}
+		return res, nil
+	}
+	if m.inst.secrets == nil {
+		return "", ErrNoSecretStore
+	}
+	ref, err := m.inst.secretGrantRef(p.Dataset)
+	if err != nil {
+		return "", err
+	}
+	if err := m.inst.secrets.Revoke(p.Name, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// secretGrantRef parses the dataset reference of a grant into the
+// "username/name" form grants are stored with
+func (inst *Instance) secretGrantRef(refstr string) (string, error) {
+	ref, err := dsref.Parse(refstr)
+	if err != nil {
+		return "", err
+	}
+	if ref.Path != "" {
+		return "", fmt.Errorf("secrets are granted to datasets, not versions. remove the path from %q", refstr)
+	}
+	if ref.Username == "me" {
+		ref.Username = inst.cfg.Profile.Peername
+	}
+	return ref.Alias(), nil
+}
+
+// transformSecrets combines secrets passed to a transform with the stored
+// secrets granted to the dataset the transform belongs to. Passed secrets
+// take precedence over stored values with the same name
+func (inst *Instance) transformSecrets(ref dsref.Ref, passed map[string]string) map[string]string {
+	if inst.secrets == nil || ref.Username == "" || ref.Name == "" {
+		return passed
+	}
+	granted := inst.secrets.Granted(ref.Alias())
+	if len(granted) == 0 {
+		return passed
+	}
+	for name, val := range passed {
+		granted[name] = val
+	}
+	return granted
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/secrets"
+)
+
+func TestSecretMethods(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	m := NewSecretMethods(tr.Instance)
+	if _, err := m.Set(tr.Ctx, &SecretParams{Name: "API_KEY", Value: "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Set(tr.Ctx, &SecretParams{Name: "TOKEN", Value: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Set(tr.Ctx, &SecretParams{Name: "not valid", Value: "abc"}); !errors.Is(err, secrets.ErrInvalidName) {
+		t.Errorf("expected an invalid name to return ErrInvalidName. got: %v", err)
+	}
+
+	names, err := m.List(tr.Ctx, &SecretListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"API_KEY", "TOKEN"}, names); diff != "" {
+		t.Errorf("secret names mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := m.Remove(tr.Ctx, &SecretParams{Name: "TOKEN"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Remove(tr.Ctx, &SecretParams{Name: "TOKEN"}); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("expected removing a missing secret to return ErrNotFound. got: %v", err)
+	}
+
+	// transforms can only read secrets granted to their dataset
+	scriptPath := tr.MustWriteTmpFile(t, "secret.star", `def transform(ds, ctx):
+  key = ctx.get_secret("API_KEY")
+  print("key: %s" % key)
+  ds.set_body([[len(key)]])
+`)
+	save := func(scriptPath string, out io.Writer) (*dataset.Dataset, error) {
+		return NewDatasetMethods(tr.Instance).Save(tr.Ctx, &SaveParams{
+			Ref:          "me/secret_ds",
+			Dataset:      &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: scriptPath}},
+			Apply:        true,
+			ScriptOutput: out,
+		})
+	}
+	if _, err := save(scriptPath, nil); err == nil {
+		t.Error("expected a transform to be denied a secret that isn't granted")
+	}
+	if _, err := m.Grant(tr.Ctx, &SecretGrantParams{Name: "TOKEN", Dataset: "me/secret_ds"}); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("expected granting a missing secret to return ErrNotFound. got: %v", err)
+	}
+	granted, err := m.Grant(tr.Ctx, &SecretGrantParams{Name: "API_KEY", Dataset: "me/secret_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "peer/secret_ds"; granted != expect {
+		t.Errorf("granted dataset mismatch. expected: %q, got: %q", expect, granted)
+	}
+
+	// granted secret values are redacted from output
+	out := &bytes.Buffer{}
+	if _, err := save(scriptPath, out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), "key: [REDACTED]") {
+		t.Errorf("expected script output to redact the secret. got: %q", out.String())
+	}
+
+	// secret values are redacted from the messages of failed runs
+	failPath := tr.MustWriteTmpFile(t, "secret_fail.star", `def transform(ds, ctx):
+  error("request with %s failed" % ctx.get_secret("API_KEY"))
+`)
+	if _, err := save(failPath, nil); err == nil {
+		t.Fatal("expected failing transform to return an error")
+	}
+	runs, err := NewRunMethods(tr.Instance).List(tr.Ctx, &RunListParams{Ref: "me/secret_ds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) == 0 || runs[0].Message == "" {
+		t.Fatalf("expected the failed run to be recorded with a message. got: %v", runs)
+	}
+	if msg := runs[0].Message; strings.Contains(msg, "hunter2") || !strings.Contains(msg, "[REDACTED]") {
+		t.Errorf("expected run message to redact the secret. got: %q", msg)
+	}
+
+	if _, err := m.Revoke(tr.Ctx, &SecretGrantParams{Name: "API_KEY", Dataset: "peer/secret_ds"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := save(scriptPath, nil); err == nil {
+		t.Error("expected a revoked secret to be unreadable")
+	}
+}
@@ -3,9 +3,17 @@ package lib
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/sql"
+	"github.com/qri-io/qri/sql/preprocess"
+	"github.com/qri-io/qri/transform"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 // SQLMethods encapsulates business logic for the qri search command
@@ -27,7 +35,13 @@ func (m SQLMethods) CoreRequestsName() string { return "sql" }
 type SQLQueryParams struct {
 	Query        string
 	OutputFormat string
+	// ResolverMode is the default source for resolving tables. tables can
+	// override the default with a source prefix, eg: "registry:b5/world_bank_population"
 	ResolverMode string
+	// SaveTo is a dataset reference to save query results to. The query is
+	// stored as a SQL transform step, which runs again when saving with apply.
+	// When set, results are the saved dataset encoded as JSON
+	SaveTo string
 }
 
 // Exec runs an SQL query
@@ -40,13 +54,21 @@ func (m *SQLMethods) Exec(p *SQLQueryParams, results *[]byte) error {
 	}
 	ctx := context.TODO()
 
-	resolver, err := m.inst.resolverForMode(p.ResolverMode)
+	// pass in the configured peername, allowing the "me" alias in reference strings
+	loadDataset, err := m.inst.newSourceLoader(m.inst.cfg.Profile.Peername, p.ResolverMode)
 	if err != nil {
 		return err
 	}
-	// create a loader sql will use to load & fetch datasets
-	// pass in the configured peername, allowing the "me" alias in reference strings
-	loadDataset := NewParseResolveLoadFunc(m.inst.cfg.Profile.Peername, resolver, m.inst)
+
+	if p.SaveTo != "" {
+		ds, err := m.saveQuery(ctx, p, loadDataset)
+		if err != nil {
+			return err
+		}
+		*results, err = json.Marshal(ds)
+		return err
+	}
+
 	svc := sql.New(m.inst.repo, loadDataset)
 
 	buf := &bytes.Buffer{}
@@ -57,3 +79,59 @@ func (m *SQLMethods) Exec(p *SQLQueryParams, results *[]byte) error {
 	*results = buf.Bytes()
 	return nil
 }
+
+// saveQuery runs a query as a SQL transform step, saving results as the body
+// of a new dataset version
+func (m *SQLMethods) saveQuery(ctx context.Context, p *SQLQueryParams, loadDataset dsref.ParseResolveLoad) (*dataset.Dataset, error) {
+	query, err := expandMeRefs(p.Query, m.inst.cfg.Profile.Peername)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			Steps: []*dataset.TransformStep{
+				{Name: "query", Syntax: transform.SyntaxSQL, Category: "transform", Script: query},
+			},
+		},
+	}
+
+	limits, err := m.inst.transformLimits(ctx, startf.Limits{})
+	if err != nil {
+		return nil, err
+	}
+
+	runID := transform.NewRunID()
+	str := m.inst.node.LocalStreams
+	if err := m.inst.transform.Apply(ctx, ds, loadDataset, runID, m.inst.bus, true, str, nil, nil, false, startf.SetLimits(limits)); err != nil {
+		return nil, err
+	}
+
+	return NewDatasetMethods(m.inst).Save(ctx, &SaveParams{
+		Ref:     p.SaveTo,
+		Dataset: ds,
+	})
+}
+
+// expandMeRefs replaces the "me" shorthand in table references with a
+// username, so a stored query reads the same tables no matter who runs it
+func expandMeRefs(query, username string) (string, error) {
+	_, tables, err := preprocess.Query(query)
+	if err != nil {
+		return "", err
+	}
+	for _, table := range tables {
+		source, ref := sql.SplitTableSource(table)
+		if !strings.HasPrefix(ref, "me/") {
+			continue
+		}
+		expanded := username + strings.TrimPrefix(ref, "me")
+		if source != "" {
+			expanded = source + ":" + expanded
+		}
+		// table references are delimited by whitespace, commas & parens
+		re := regexp.MustCompile(`(^|[\s,(])` + regexp.QuoteMeta(table) + `($|[\s,)])`)
+		query = re.ReplaceAllString(query, "${1}"+expanded+"${2}")
+	}
+	return query, nil
+}
@@ -0,0 +1,26 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestExpandMeRefs(t *testing.T) {
+	cases := []struct {
+		query, expect string
+	}{
+		{"SELECT * FROM me/cities as c", "SELECT * FROM peer/cities as c"},
+		{"SELECT * FROM me/cities c, registry:me/countries", "SELECT * FROM peer/cities c, registry:peer/countries"},
+		{"SELECT * FROM (SELECT 1 FROM me/cities a) t1", "SELECT * FROM (SELECT 1 FROM peer/cities a) t1"},
+		{"SELECT * FROM b5/me/cities as c", "SELECT * FROM b5/me/cities as c"},
+		{"SELECT * FROM other/cities as c", "SELECT * FROM other/cities as c"},
+	}
+	for _, c := range cases {
+		got, err := expandMeRefs(c.query, "peer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.expect {
+			t.Errorf("%q: expected %q, got %q", c.query, c.expect, got)
+		}
+	}
+}
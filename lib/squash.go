@@ -0,0 +1,189 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+)
+
+// SquashParams defines parameters for squashing dataset history. Exactly one
+// of Before or Range selects the versions to squash
+type SquashParams struct {
+	// Ref is the dataset to squash
+	Ref string
+	// Before squashes every version committed before a date, formatted either
+	// as YYYY-MM-DD or an RFC3339 timestamp
+	Before string
+	// Range squashes versions by their position in the dataset log, formatted
+	// as FROM-TO. Versions are numbered from 1, newest first, the way
+	// `qri log` lists them
+	Range string
+}
+
+// SquashResult describes a rewritten dataset history
+type SquashResult struct {
+	// Ref is the dataset, including the path of its new head
+	Ref string `json:"ref"`
+	// Squashed lists the paths of versions collapsed into a single version
+	Squashed []string `json:"squashed"`
+	// Rewritten lists the paths of newer versions that were rewritten on top
+	// of the squashed version
+	Rewritten []string `json:"rewritten"`
+	// Remotes lists the addresses of remotes that were sent the rewritten
+	// history
+	Remotes []string `json:"remotes,omitempty"`
+	// RemoteErrors describes remotes that couldn't be sent the rewritten
+	// history
+	RemoteErrors []string `json:"remoteErrors,omitempty"`
+}
+
+// Squash collapses a run of versions in a dataset history into a single
+// version. Versions newer than the squashed run are rewritten on top of it,
+// and the rewrite is recorded in the logbook. Replaced versions become
+// unreferenced & are removed by garbage collection. Remotes the dataset has
+// been pushed to are sent the rewritten history
+func (m *DatasetMethods) Squash(ctx context.Context, p *SquashParams) (*SquashResult, error) {
+	if m.inst.http != nil {
+		res := &SquashResult{}
+		if err := m.inst.http.Call(ctx, AESquash, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if p.Ref == "" {
+		return nil, fmt.Errorf("dataset reference is required")
+	}
+	if (p.Before == "") == (p.Range == "") {
+		return nil, fmt.Errorf("squash requires exactly one of before or range")
+	}
+
+	ref, _, err := m.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	if ref.ProfileID != m.inst.repo.Profiles().Owner().ID.String() {
+		return nil, fmt.Errorf("can only squash datasets you own")
+	}
+
+	items, err := m.inst.repo.Logbook().Items(ctx, ref, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to int
+	if p.Range != "" {
+		from, to, err = parseSquashRange(p.Range, len(items))
+	} else {
+		from, to, err = squashBefore(p.Before, items)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the versions from the oldest squashed version up to head,
+	// oldest-first. runs that didn't save a version are dropped
+	versions := []dsref.VersionInfo{}
+	n := 0
+	for i := to; i >= 0; i-- {
+		if items[i].Path == "" {
+			continue
+		}
+		if i >= from {
+			n++
+		}
+		versions = append(versions, items[i])
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("squashing requires at least two versions, found %d", n)
+	}
+
+	head, err := base.SquashVersions(ctx, m.inst.repo, ref, versions, n)
+	if err != nil {
+		return nil, err
+	}
+	ref.Path = head.Path
+
+	res := &SquashResult{
+		Ref:       ref.String(),
+		Squashed:  make([]string, 0, n),
+		Rewritten: make([]string, 0, len(versions)-n),
+	}
+	for i, vi := range versions {
+		if i < n {
+			res.Squashed = append(res.Squashed, vi.Path)
+		} else {
+			res.Rewritten = append(res.Rewritten, vi.Path)
+		}
+	}
+
+	addrs, err := m.inst.repo.Logbook().PublishedRemotes(ctx, ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if err := m.syncSquash(ctx, ref, addr); err != nil {
+			log.Debugw("sending rewritten history to remote", "addr", addr, "err", err)
+			res.RemoteErrors = append(res.RemoteErrors, fmt.Sprintf("%s: %s", addr, err))
+			continue
+		}
+		res.Remotes = append(res.Remotes, addr)
+	}
+
+	return res, nil
+}
+
+// syncSquash pushes a rewritten dataset to a remote. Pushing sends the
+// dataset log through logsync, the remote merges the ops that record the
+// rewrite into its copy of the history
+func (m *DatasetMethods) syncSquash(ctx context.Context, ref dsref.Ref, addr string) error {
+	client := m.inst.RemoteClient()
+	if client == nil {
+		return fmt.Errorf("not connected to the network")
+	}
+	return client.PushDataset(ctx, ref, addr)
+}
+
+// parseSquashRange parses a FROM-TO range of log positions into indexes of a
+// newest-first list of items
+func parseSquashRange(rng string, numItems int) (from, to int, err error) {
+	parts := strings.Split(rng, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q, expected FROM-TO", rng)
+	}
+	if from, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q, expected FROM-TO", rng)
+	}
+	if to, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q, expected FROM-TO", rng)
+	}
+	if from > to {
+		from, to = to, from
+	}
+	if from < 1 || to > numItems {
+		return 0, 0, fmt.Errorf("range %q is out of bounds, the log has %d entries", rng, numItems)
+	}
+	return from - 1, to - 1, nil
+}
+
+// squashBefore selects every item in a newest-first list committed before a
+// date
+func squashBefore(before string, items []dsref.VersionInfo) (from, to int, err error) {
+	t, err := time.Parse("2006-01-02", before)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, before); err != nil {
+			return 0, 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or an RFC3339 timestamp", before)
+		}
+	}
+	for i, item := range items {
+		if item.CommitTime.Before(t) {
+			return i, len(items) - 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no versions were committed before %s", before)
+}
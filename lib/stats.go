@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/stats"
+)
+
+// StatsMethods encapsulates business logic for managing the stats cache
+type StatsMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requests interface
+func (StatsMethods) CoreRequestsName() string { return "stats" }
+
+// NewStatsMethods creates a StatsMethods pointer from a qri instance
+func NewStatsMethods(inst *Instance) *StatsMethods {
+	return &StatsMethods{
+		inst: inst,
+	}
+}
+
+// StatsCacheInfo describes the contents & performance of the stats cache
+type StatsCacheInfo struct {
+	Entries []stats.CacheEntry `json:"entries"`
+	// total size of all entries, in bytes
+	Size     int64               `json:"size"`
+	Counters stats.CacheCounters `json:"counters"`
+}
+
+// StatsCachePruneResult lists entries dropped by a prune
+type StatsCachePruneResult struct {
+	Removed []stats.CacheEntry `json:"removed"`
+	// bytes freed by the prune
+	Reclaimed int64 `json:"reclaimed"`
+}
+
+// StatsCacheParams is the (empty) parameter set for stats cache methods
+type StatsCacheParams struct{}
+
+// CacheList shows the contents of the stats cache. Hit, miss & eviction
+// counts cover the lifetime of the instance
+func (m *StatsMethods) CacheList(ctx context.Context, p *StatsCacheParams) (*StatsCacheInfo, error) {
+	if m.inst.http != nil {
+		res := &StatsCacheInfo{}
+		if err := m.inst.http.Call(ctx, AEStatsCache, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.stats == nil {
+		return nil, stats.ErrNoCache
+	}
+
+	entries, err := m.inst.stats.CacheEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &StatsCacheInfo{
+		Entries:  entries,
+		Counters: m.inst.stats.CacheCounters(),
+	}
+	for _, ent := range entries {
+		res.Size += ent.Size
+	}
+	return res, nil
+}
+
+// CacheClear drops all cached stats
+func (m *StatsMethods) CacheClear(ctx context.Context, p *StatsCacheParams) error {
+	if m.inst.http != nil {
+		res := false
+		return m.inst.http.Call(ctx, AEStatsCacheClear, p, &res)
+	}
+	if m.inst.stats == nil {
+		return stats.ErrNoCache
+	}
+	return m.inst.stats.ClearCache(ctx)
+}
+
+// CachePrune drops cached stats for dataset versions that are no longer in
+// the logbook, and stats for local files that have changed since they were
+// cached
+func (m *StatsMethods) CachePrune(ctx context.Context, p *StatsCacheParams) (*StatsCachePruneResult, error) {
+	if m.inst.http != nil {
+		res := &StatsCachePruneResult{}
+		if err := m.inst.http.Call(ctx, AEStatsCachePrune, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if m.inst.stats == nil {
+		return nil, stats.ErrNoCache
+	}
+
+	removed, err := m.inst.pruneStatsCache(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &StatsCachePruneResult{Removed: removed}
+	for _, ent := range removed {
+		res.Reclaimed += ent.Size
+	}
+	return res, nil
+}
+
+// pruneStatsCache drops cached stats for dataset versions that are no longer
+// in the logbook & stale local files, returning the dropped entries. When
+// dryRun is true entries are listed but not dropped
+func (inst *Instance) pruneStatsCache(ctx context.Context, dryRun bool) ([]stats.CacheEntry, error) {
+	if inst.logbook == nil {
+		return nil, fmt.Errorf("pruning the stats cache requires a logbook")
+	}
+
+	paths, err := inst.logbook.AllReferencedDatasetPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]struct{}{}
+	for path := range paths {
+		keep[path] = struct{}{}
+		// stats can be keyed by something other than the dataset path.
+		// versions we can't load only protect their dataset path
+		ds, err := dsfs.LoadDatasetRefs(ctx, inst.qfs, path)
+		if err != nil {
+			log.Debugw("loading referenced dataset for stats cache prune", "path", path, "err", err)
+			continue
+		}
+		ds.Path = path
+		if key, err := inst.stats.CacheKey(ds); err == nil {
+			keep[key] = struct{}{}
+		}
+	}
+
+	keepKey := func(key string) bool {
+		// local files are only pruned when they've changed
+		if qfs.PathKind(key) == "local" {
+			return true
+		}
+		_, ok := keep[key]
+		return ok
+	}
+	if dryRun {
+		return inst.stats.PrunableCacheEntries(ctx, keepKey)
+	}
+	return inst.stats.PruneCache(ctx, keepKey)
+}
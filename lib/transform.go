@@ -2,14 +2,23 @@ package lib
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/localfs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/transform"
+	"github.com/qri-io/qri/transform/run"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 // TransformMethods encapsulates business logic for transforms
@@ -33,6 +42,18 @@ type ApplyParams struct {
 	Transform *dataset.Transform
 	Secrets   map[string]string
 	Wait      bool
+	// NoCache runs every transform step, instead of skipping steps that are
+	// unchanged since they last ran
+	NoCache bool
+	// execution limits for starlark steps. zero values use the limits set in
+	// the transform section of config. remote callers can only lower
+	// configured limits
+	MaxSteps    uint64
+	StepTimeout time.Duration
+	MaxBodySize int64
+	// ReplayRun answers HTTP requests the transform makes with the responses
+	// recorded by a previous run of the dataset, instead of the network
+	ReplayRun string
 
 	Source string
 	// TODO(arqu): substitute with websockets when working over the wire
@@ -53,7 +74,8 @@ type ApplyResult struct {
 	RunID string `json:"runID"`
 }
 
-// Apply runs a transform script
+// Apply runs a transform script. Runs are recorded like runs that save, but
+// never have a version path
 func (m *TransformMethods) Apply(ctx context.Context, p *ApplyParams) (*ApplyResult, error) {
 	err := p.Valid()
 	if err != nil {
@@ -88,12 +110,45 @@ func (m *TransformMethods) Apply(ctx context.Context, p *ApplyParams) (*ApplyRes
 		ds.Transform.OpenScriptFile(ctx, m.inst.repo.Filesystem())
 	}
 
+	limits, err := m.inst.transformLimits(ctx, startf.Limits{
+		MaxSteps:    p.MaxSteps,
+		StepTimeout: p.StepTimeout,
+		MaxBodySize: p.MaxBodySize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts := []func(*startf.ExecOpts){startf.SetLimits(limits)}
+	if p.ReplayRun != "" {
+		if ref.InitID == "" {
+			return nil, fmt.Errorf("replaying a run requires a dataset reference")
+		}
+		replay, err := m.inst.loadHTTPRecording(ctx, ref.InitID, p.ReplayRun)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, startf.ReplayHTTP(replay))
+	}
+
 	str := m.inst.node.LocalStreams
-	loader := NewParseResolveLoadFunc("", m.inst.defaultResolver(), m.inst)
+	loader, err := m.inst.newSourceLoader("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := m.inst.transformSecrets(ref, p.Secrets)
+	redact := startf.NewRedactor(secrets)
 
-	// allocate an ID for the transform, for now just log the events it produces
+	// allocate an ID for the transform & build up a record of the run. runs
+	// that don't wait are recorded when the transform stops
 	runID := transform.NewRunID()
+	runState := run.NewState(runID)
+	runState.InitID = ref.InitID
 	m.inst.bus.SubscribeID(func(ctx context.Context, e event.Event) error {
+		runState.AddTransformEvent(e)
+		if e.Type == event.ETTransformStop && !p.Wait {
+			m.inst.putRun(ctx, runState)
+		}
 		go func() {
 			log.Debugw("apply transform event", "type", e.Type, "payload", e.Payload)
 			if e.Type == event.ETTransformPrint {
@@ -109,10 +164,14 @@ func (m *TransformMethods) Apply(ctx context.Context, p *ApplyParams) (*ApplyRes
 	}, runID)
 
 	scriptOut := p.ScriptOutput
-	err = m.inst.transform.Apply(ctx, ds, loader, runID, m.inst.bus, p.Wait, str, scriptOut, p.Secrets)
+	err = m.inst.transform.Apply(ctx, ds, loader, runID, m.inst.bus, p.Wait, str, scriptOut, secrets, p.NoCache, opts...)
 	if err != nil {
+		m.inst.putFailedRun(ctx, runState, redact.Redact(err.Error()))
 		return nil, err
 	}
+	if p.Wait {
+		m.inst.putRun(ctx, runState)
+	}
 
 	if p.Wait {
 		if err = base.InlineJSONBody(ds); err != nil && err != base.ErrNoBodyToInline {
@@ -123,3 +182,223 @@ func (m *TransformMethods) Apply(ctx context.Context, p *ApplyParams) (*ApplyRes
 	res.RunID = runID
 	return res, nil
 }
+
+// transformLimits combines the execution limits set in config with
+// overrides. Non-zero override fields replace configured limits. Configured
+// limits are ceilings for remote callers, their overrides can only lower a
+// limit
+func (inst *Instance) transformLimits(ctx context.Context, override startf.Limits) (startf.Limits, error) {
+	limits := startf.Limits{}
+	if cfg := inst.cfg.Transform; cfg != nil {
+		timeout, err := cfg.StepTimeoutDuration()
+		if err != nil {
+			return limits, err
+		}
+		limits = startf.Limits{
+			MaxSteps:    cfg.MaxSteps,
+			StepTimeout: timeout,
+			MaxBodySize: cfg.MaxBodySize,
+		}
+	}
+
+	// a zero limit is no limit, any override lowers it
+	local := !isRemoteCaller(ctx)
+	if override.MaxSteps != 0 && (local || limits.MaxSteps == 0 || override.MaxSteps < limits.MaxSteps) {
+		limits.MaxSteps = override.MaxSteps
+	}
+	if override.StepTimeout != 0 && (local || limits.StepTimeout == 0 || override.StepTimeout < limits.StepTimeout) {
+		limits.StepTimeout = override.StepTimeout
+	}
+	if override.MaxBodySize != 0 && (local || limits.MaxBodySize == 0 || override.MaxBodySize < limits.MaxBodySize) {
+		limits.MaxBodySize = override.MaxBodySize
+	}
+	return limits, nil
+}
+
+// saveHTTPRecording writes the HTTP recording of a transform run to the
+// repo filesystem, returning its path
+func (inst *Instance) saveHTTPRecording(ctx context.Context, rec *startf.HTTPRecording) (string, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	return inst.qfs.DefaultWriteFS().Put(ctx, qfs.NewMemfileBytes("http_recording.json", data))
+}
+
+// loadHTTPRecording reads the HTTP recording of a dataset's transform run
+func (inst *Instance) loadHTTPRecording(ctx context.Context, initID, runID string) (*startf.HTTPRecording, error) {
+	path, err := inst.logbook.RunHTTPRecording(ctx, initID, runID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := inst.qfs.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading http recording: %w", err)
+	}
+	defer f.Close()
+
+	rec := &startf.HTTPRecording{}
+	if err := json.NewDecoder(f).Decode(rec); err != nil {
+		return nil, fmt.Errorf("reading http recording: %w", err)
+	}
+	return rec, nil
+}
+
+// TransformTestParams are parameters for testing a transform script
+type TransformTestParams struct {
+	// path to a transform script, or a test file ending in "_test.star". Tests
+	// are read from the script & the companion test file, if one exists
+	ScriptPath string
+	// map of dataset references to local files that load_dataset reads from.
+	// files can be dataset documents (.json, .yaml) or body files
+	Fixtures map[string]string
+	// only run tests with names matching this regular expression
+	Run string
+	// default secrets for test contexts
+	Secrets map[string]string
+}
+
+// Valid returns an error if TransformTestParams fields are in an invalid state
+func (p *TransformTestParams) Valid() error {
+	if !strings.HasSuffix(p.ScriptPath, ".star") {
+		return fmt.Errorf("a transform script ending in .star is required")
+	}
+	return nil
+}
+
+// Test runs the test functions of a transform script. Tests never touch
+// datasets in the repo: load_dataset reads local fixture files instead. Each
+// test run is recorded, failing tests are reported in the result, not as an
+// error
+func (m *TransformMethods) Test(ctx context.Context, p *TransformTestParams) (*startf.TestReport, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	if m.inst.http != nil {
+		res := &startf.TestReport{}
+		if err := m.inst.http.Call(ctx, AEApplyTest, p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	script := p.ScriptPath
+	testFile := strings.TrimSuffix(script, ".star") + "_test.star"
+	if strings.HasSuffix(script, "_test.star") {
+		testFile = script
+		script = strings.TrimSuffix(script, "_test.star") + ".star"
+	}
+
+	paths := []string{}
+	for _, path := range []string{script, testFile} {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		} else if path == p.ScriptPath {
+			return nil, err
+		}
+	}
+
+	files := make([]qfs.File, len(paths))
+	for i, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		files[i] = qfs.NewMemfileReader(filepath.Base(path), f)
+	}
+
+	// test runs are recorded without a dataset, they can be fetched by the ID
+	// in the report
+	runState := run.NewState(transform.NewRunID())
+	start := time.Now()
+	runState.StartTime = &start
+	redact := startf.NewRedactor(p.Secrets)
+
+	limits, err := m.inst.transformLimits(ctx, startf.Limits{})
+	if err != nil {
+		return nil, err
+	}
+	loader := fixtureLoader(filepath.Dir(script), p.Fixtures)
+	report, err := startf.RunTests(ctx, files, p.Run,
+		startf.AddDatasetLoader(loader),
+		startf.SetSecrets(p.Secrets),
+		startf.SetLimits(limits),
+	)
+	if err != nil {
+		m.inst.putFailedRun(ctx, runState, redact.Redact(err.Error()))
+		return nil, err
+	}
+
+	stop := time.Now()
+	runState.StopTime = &stop
+	runState.Duration = int(stop.Sub(start))
+	runState.Status = run.RSSucceeded
+	runState.Message = fmt.Sprintf("%d tests passed", len(report.Tests))
+	if !report.Passed {
+		runState.Status = run.RSFailed
+		runState.Message = fmt.Sprintf("%d of %d tests failed", report.Failed(), len(report.Tests))
+	}
+	m.inst.putRun(ctx, runState)
+	report.RunID = runState.ID
+	return report, nil
+}
+
+// fixtureLoader creates a dataset loader that reads local files. References
+// are looked up in fixtures, falling back to treating the reference as a path
+// relative to dir
+func fixtureLoader(dir string, fixtures map[string]string) dsref.ParseResolveLoad {
+	return func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		path, ok := fixtures[refstr]
+		if !ok {
+			path = refstr
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("no fixture for %q", refstr)
+			}
+		}
+		return loadFixture(ctx, path)
+	}
+}
+
+// loadFixture reads a dataset from a local file, opening the body. Dataset
+// documents are read like `qri save --file`, other files are read as a body
+func loadFixture(ctx context.Context, path string) (*dataset.Dataset, error) {
+	ds := &dataset.Dataset{BodyPath: path}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		if doc, err := ReadDatasetFiles(path); err == nil {
+			ds = doc
+			if ds.BodyPath != "" && !filepath.IsAbs(ds.BodyPath) {
+				ds.BodyPath = filepath.Join(filepath.Dir(path), ds.BodyPath)
+			}
+		}
+	}
+
+	if ds.Body != nil {
+		data, err := json.Marshal(ds.Body)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %q: encoding body: %w", path, err)
+		}
+		ds.Body = nil
+		ds.BodyBytes = data
+		ds.BodyPath = "body.json"
+	}
+
+	fs, err := localfs.NewFS(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := base.OpenDataset(ctx, fs, ds); err != nil {
+		return nil, fmt.Errorf("fixture %q: %w", path, err)
+	}
+	if ds.BodyFile() != nil && (ds.Structure == nil || ds.Structure.Schema == nil) {
+		if err := base.InferStructure(ds); err != nil {
+			return nil, fmt.Errorf("fixture %q: %w", path, err)
+		}
+	}
+	return ds, nil
+}
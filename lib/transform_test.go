@@ -2,10 +2,17 @@ package lib
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 func TestApplyTransform(t *testing.T) {
@@ -43,3 +50,119 @@ func TestApplyTransform(t *testing.T) {
 		t.Errorf("qri list (-want +got):\n%s", diff)
 	}
 }
+
+func TestTransformHTTPRecordReplay(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[["a",1],["b",2]]`))
+	}))
+
+	scriptPath := tr.MustWriteTmpFile(t, "fetch.star", `load("http.star", "http")
+
+def download(ctx):
+  return http.get(ctx.get_config("url")).json()
+
+def transform(ds, ctx):
+  ds.set_body(ctx.download)
+`)
+	newTransform := func() *dataset.Transform {
+		return &dataset.Transform{
+			ScriptPath: scriptPath,
+			Config:     map[string]interface{}{"url": s.URL},
+		}
+	}
+
+	ref, err := tr.SaveWithParams(&SaveParams{
+		Ref:        "me/recorded_ds",
+		Dataset:    &dataset.Dataset{Transform: newTransform()},
+		Apply:      true,
+		RecordHTTP: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := tr.MustGet(t, ref.Alias())
+	if saved.Commit == nil || saved.Commit.RunID == "" {
+		t.Fatal("expected saved commit to have a run ID")
+	}
+
+	// replays must not touch the network
+	s.Close()
+	ds, err := tr.ApplyWithParams(tr.Ctx, &ApplyParams{
+		Refstr:    ref.Alias(),
+		Transform: newTransform(),
+		ReplayRun: saved.Commit.RunID,
+		Wait:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(ds.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[["a",1],["b",2]]`; string(data) != expect {
+		t.Errorf("replayed body mismatch. expected: %q, got: %q", expect, string(data))
+	}
+
+	_, err = tr.ApplyWithParams(tr.Ctx, &ApplyParams{
+		Refstr:    ref.Alias(),
+		Transform: newTransform(),
+		ReplayRun: "unknown-run",
+		Wait:      true,
+	})
+	if !errors.Is(err, logbook.ErrNotFound) {
+		t.Errorf("expected replaying an unknown run to return ErrNotFound. got: %v", err)
+	}
+}
+
+func TestTransformLimits(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	inst := tr.Instance
+	inst.cfg.Transform = &config.Transform{MaxSteps: 1000, StepTimeout: "1m"}
+
+	got, err := inst.transformLimits(tr.Ctx, startf.Limits{MaxSteps: 50, MaxBodySize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := startf.Limits{MaxSteps: 50, StepTimeout: time.Minute, MaxBodySize: 2048}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("limits mismatch (-want +got):\n%s", diff)
+	}
+
+	// local callers can raise configured limits
+	if got, err = inst.transformLimits(tr.Ctx, startf.Limits{MaxSteps: 5000, StepTimeout: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	expect = startf.Limits{MaxSteps: 5000, StepTimeout: time.Hour}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("local limits mismatch (-want +got):\n%s", diff)
+	}
+
+	// remote callers can only lower them. an unset limit can be set
+	remote := WithRemoteCaller(tr.Ctx)
+	if got, err = inst.transformLimits(remote, startf.Limits{MaxSteps: 5000, StepTimeout: time.Second, MaxBodySize: 2048}); err != nil {
+		t.Fatal(err)
+	}
+	expect = startf.Limits{MaxSteps: 1000, StepTimeout: time.Second, MaxBodySize: 2048}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("remote limits mismatch (-want +got):\n%s", diff)
+	}
+
+	inst.cfg.Transform = nil
+	if got, err = inst.transformLimits(tr.Ctx, startf.Limits{}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(startf.Limits{}, got); diff != "" {
+		t.Errorf("expected no limits without config (-want +got):\n%s", diff)
+	}
+
+	inst.cfg.Transform = &config.Transform{StepTimeout: "forever"}
+	if _, err = inst.transformLimits(tr.Ctx, startf.Limits{}); err == nil {
+		t.Error("expected an invalid configured timeout to error")
+	}
+}
@@ -0,0 +1,236 @@
+package logbook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// Rights that can be granted to other profiles with an access control list
+const (
+	// ACLRead allows fetching a dataset, eg: pulling from a remote
+	ACLRead = "read"
+	// ACLWrite allows modifying a dataset, eg: removing it from a remote
+	ACLWrite = "write"
+	// ACLPush allows sending new versions of a dataset to a remote
+	ACLPush = "push"
+)
+
+var (
+	// ErrNoACL indicates a dataset has never recorded access control entries.
+	// Callers should fall back to other access control mechanisms
+	ErrNoACL = fmt.Errorf("logbook: dataset has no access control list")
+	// ACLRights lists all valid rights in canonical order
+	ACLRights = []string{ACLRead, ACLWrite, ACLPush}
+)
+
+// ACLEntry lists the rights a profile has been granted for a dataset
+type ACLEntry struct {
+	ProfileID string   `json:"profileID"`
+	Rights    []string `json:"rights"`
+}
+
+// Can returns true if the entry includes a right
+func (e ACLEntry) Can(right string) bool {
+	for _, r := range e.Rights {
+		if r == right {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidACLRights returns an error if any of the given rights are unknown
+func ValidACLRights(rights []string) error {
+	for _, r := range rights {
+		if r != ACLRead && r != ACLWrite && r != ACLPush {
+			return fmt.Errorf("invalid access right %q, must be one of %v", r, ACLRights)
+		}
+	}
+	return nil
+}
+
+// WriteACLGrant adds rights for a profile to a dataset's access control list.
+// Rights are additive, granting a right a profile already has is a no-op
+func (book *Book) WriteACLGrant(ctx context.Context, initID, profileID string, rights ...string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if len(rights) == 0 {
+		return fmt.Errorf("at least one right is required")
+	}
+	if err := ValidACLRights(rights); err != nil {
+		return err
+	}
+	log.Debugw("WriteACLGrant", "initID", initID, "profileID", profileID, "rights", rights)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+
+	current := aclEntry(ACLFromLog(dsLog.l), profileID)
+	next := normalizeRights(append(current.Rights, rights...))
+	if len(next) == len(current.Rights) {
+		return nil
+	}
+
+	dsLog.Append(oplog.Op{
+		Type:      oplog.OpTypeAmend,
+		Model:     ACLModel,
+		AuthorID:  book.AuthorID(),
+		Name:      profileID,
+		Relations: next,
+		Timestamp: NewTimestamp(),
+	})
+	return book.save(ctx)
+}
+
+// WriteACLRevoke removes rights for a profile from a dataset's access control
+// list. Calling WriteACLRevoke without any rights removes all access. Revoking
+// rights a profile doesn't have is a no-op
+func (book *Book) WriteACLRevoke(ctx context.Context, initID, profileID string, rights ...string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if err := ValidACLRights(rights); err != nil {
+		return err
+	}
+	log.Debugw("WriteACLRevoke", "initID", initID, "profileID", profileID, "rights", rights)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+
+	current := aclEntry(ACLFromLog(dsLog.l), profileID)
+	if len(current.Rights) == 0 {
+		return nil
+	}
+
+	remaining := []string{}
+	for _, r := range current.Rights {
+		if len(rights) > 0 && !(ACLEntry{Rights: rights}).Can(r) {
+			remaining = append(remaining, r)
+		}
+	}
+	if len(remaining) == len(current.Rights) {
+		return nil
+	}
+
+	op := oplog.Op{
+		Type:      oplog.OpTypeRemove,
+		Model:     ACLModel,
+		AuthorID:  book.AuthorID(),
+		Name:      profileID,
+		Timestamp: NewTimestamp(),
+	}
+	if len(remaining) > 0 {
+		op.Type = oplog.OpTypeAmend
+		op.Relations = remaining
+	}
+	dsLog.Append(op)
+	return book.save(ctx)
+}
+
+// ACL returns the access control list for a dataset
+func (book *Book) ACL(ctx context.Context, initID string) ([]ACLEntry, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+	return ACLFromLog(dsLog.l), nil
+}
+
+// CheckACL checks a dataset's access control list for a right. The dataset
+// owner always has all rights. CheckACL returns ErrNoACL if the dataset has
+// never recorded access control entries, and ErrAccessDenied if the profile
+// hasn't been granted the right
+func (book *Book) CheckACL(ctx context.Context, initID, profileID, right string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if !hasACLOps(dsLog.l) {
+		return ErrNoACL
+	}
+	if authorLog, err := book.store.Get(ctx, dsLog.l.Author()); err == nil && authorLog.Ops[0].AuthorID == profileID {
+		return nil
+	}
+	if aclEntry(ACLFromLog(dsLog.l), profileID).Can(right) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q right not granted", ErrAccessDenied, right)
+}
+
+// ACLFromLog folds the access control operations in an oplog modelling a
+// dataset into a list of entries, sorted by profileID. Only operations
+// written by the dataset author are considered
+func ACLFromLog(dsLog *oplog.Log) []ACLEntry {
+	if len(dsLog.Ops) == 0 {
+		return nil
+	}
+	owner := dsLog.Ops[0].AuthorID
+	rights := map[string][]string{}
+	for _, op := range dsLog.Ops {
+		if op.Model != ACLModel || op.AuthorID != owner {
+			continue
+		}
+		switch op.Type {
+		case oplog.OpTypeInit, oplog.OpTypeAmend:
+			rights[op.Name] = op.Relations
+		case oplog.OpTypeRemove:
+			delete(rights, op.Name)
+		}
+	}
+
+	entries := make([]ACLEntry, 0, len(rights))
+	for pid, r := range rights {
+		entries = append(entries, ACLEntry{ProfileID: pid, Rights: normalizeRights(r)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ProfileID < entries[j].ProfileID })
+	return entries
+}
+
+func hasACLOps(dsLog *oplog.Log) bool {
+	for _, op := range dsLog.Ops {
+		if op.Model == ACLModel {
+			return true
+		}
+	}
+	return false
+}
+
+func aclEntry(entries []ACLEntry, profileID string) ACLEntry {
+	for _, e := range entries {
+		if e.ProfileID == profileID {
+			return e
+		}
+	}
+	return ACLEntry{ProfileID: profileID}
+}
+
+// normalizeRights de-duplicates rights, placing them in canonical order
+func normalizeRights(rights []string) []string {
+	res := []string{}
+	for _, r := range ACLRights {
+		if (ACLEntry{Rights: rights}).Can(r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
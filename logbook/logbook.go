@@ -38,6 +38,12 @@ var (
 	// ErrAccessDenied indicates insufficent privileges to perform a logbook
 	// operation
 	ErrAccessDenied = fmt.Errorf("access denied")
+	// ErrBranchNotFound indicates a named branch doesn't exist within a dataset
+	// history
+	ErrBranchNotFound = fmt.Errorf("logbook: branch not found")
+	// ErrBranchExists indicates an attempt to create a branch that is already
+	// present within a dataset history
+	ErrBranchExists = fmt.Errorf("logbook: branch already exists")
 
 	// NewTimestamp generates the current unix nanosecond time.
 	// This is mainly here for tests to override
@@ -64,15 +70,29 @@ const (
 )
 
 const (
-	// DefaultBranchName is the default name all branch-level logbook data is read
-	// from and written to. we currently don't present branches as a user-facing
-	// feature in qri, but logbook supports them
+	// DefaultBranchName is the name of the branch every dataset history starts
+	// with. Branch-level logbook data is read from and written to the active
+	// branch, which is DefaultBranchName until a dataset switches branches.
+	// The active branch is local state kept by ActiveBranches, it's never
+	// written to dataset logs
 	DefaultBranchName = "main"
 	// runIDRelPrefix is a string prefix for op.Relations when recording commit ops
 	// that have a non-empty Commit.RunID field. A commit operation that has a
 	// related runID will have op.Relations = [...,"runID:run-uuid-string",...],
 	// This prefix disambiguates from other types of identifiers
 	runIDRelPrefix = "runID:"
+	// mergeRelPrefix is a string prefix for op.Relations when recording branch
+	// merge ops. A merge operation has op.Relations = ["merge:branch-name"]
+	mergeRelPrefix = "merge:"
+	// httpRecordingRelPrefix is a string prefix for op.Relations when recording
+	// transform runs that saved their HTTP requests. A run operation with a
+	// recording has op.Relations = ["httpRecording:/ipfs/Qm..."]
+	httpRecordingRelPrefix = "httpRecording:"
+	// rewriteRelPrefix is a string prefix for op.Relations when recording
+	// commit ops written by a history rewrite. A rewritten commit operation has
+	// op.Relations = ["rewrite:/ipfs/Qm...",...], listing each version it
+	// replaces
+	rewriteRelPrefix = "rewrite:"
 )
 
 // ModelString gets a unique string descriptor for an integral model identifier
@@ -109,11 +129,55 @@ type Book struct {
 	fs         qfs.Filesystem
 
 	publisher event.Publisher
+	branches  ActiveBranches
+}
+
+// ActiveBranches keeps the branch new versions of each dataset are written to.
+// Switching branches is a local choice, so active branches are kept outside
+// of dataset logs: peers that sync a dataset never see another peer switch
+type ActiveBranches interface {
+	// ActiveBranch returns the name of the active branch for a dataset initID,
+	// or the empty string if the dataset uses DefaultBranchName
+	ActiveBranch(initID string) (string, error)
+	// SetActiveBranch sets the active branch for a dataset initID
+	SetActiveBranch(initID, name string) error
+}
+
+// memActiveBranches is an in-memory implementation of ActiveBranches
+type memActiveBranches struct {
+	sync.Mutex
+	names map[string]string
+}
+
+// NewMemActiveBranches creates an in-memory ActiveBranches
+func NewMemActiveBranches() ActiveBranches {
+	return &memActiveBranches{names: map[string]string{}}
+}
+
+// ActiveBranch implements the ActiveBranches interface
+func (b *memActiveBranches) ActiveBranch(initID string) (string, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.names[initID], nil
+}
+
+// SetActiveBranch implements the ActiveBranches interface
+func (b *memActiveBranches) SetActiveBranch(initID, name string) error {
+	b.Lock()
+	defer b.Unlock()
+	b.names[initID] = name
+	return nil
 }
 
 // NewBook creates a book with a user-provided logstore
 func NewBook(pk crypto.PrivKey, store oplog.Logstore) *Book {
-	return &Book{pk: pk, store: store}
+	return &Book{pk: pk, store: store, branches: NewMemActiveBranches()}
+}
+
+// SetActiveBranches replaces the store of active branches. Books keep active
+// branches in memory unless a persistent store is set
+func (book *Book) SetActiveBranches(branches ActiveBranches) {
+	book.branches = branches
 }
 
 // NewJournal initializes a logbook owned by a single author, reading any
@@ -141,6 +205,7 @@ func NewJournal(pk crypto.PrivKey, username string, bus event.Bus, fs qfs.Filesy
 		authorName: username,
 		fsLocation: location,
 		publisher:  bus,
+		branches:   NewMemActiveBranches(),
 	}
 
 	if err := book.load(ctx); err != nil {
@@ -188,6 +253,7 @@ func NewJournalOverwriteWithProfileID(pk crypto.PrivKey, username string, bus ev
 		authorName: username,
 		fsLocation: location,
 		publisher:  bus,
+		branches:   NewMemActiveBranches(),
 	}
 
 	err := book.initialize(ctx, profileID)
@@ -494,16 +560,44 @@ func (book *Book) datasetLog(ctx context.Context, initID string) (*DatasetLog, e
 	return newDatasetLog(lg), nil
 }
 
-// Return a strongly typed BranchLog
+// Return a strongly typed BranchLog for the active branch of a dataset
 func (book *Book) branchLog(ctx context.Context, initID string) (*BranchLog, error) {
-	lg, err := book.store.Get(ctx, initID)
+	dsLog, err := book.datasetLog(ctx, initID)
 	if err != nil {
 		return nil, err
 	}
-	if len(lg.Logs) != 1 {
-		return nil, fmt.Errorf("expected dataset to have 1 branch, has %d", len(lg.Logs))
+	return dsLog.Branch(book.activeBranch(dsLog))
+}
+
+// activeBranch returns the name of the branch new versions of a dataset are
+// written to
+func (book *Book) activeBranch(dsLog *DatasetLog) string {
+	if book.branches == nil {
+		return DefaultBranchName
 	}
-	return newBranchLog(lg.Logs[0]), nil
+	name, err := book.branches.ActiveBranch(dsLog.InitID())
+	if err != nil {
+		log.Errorf("reading active branch of %q: %s", dsLog.InitID(), err)
+	}
+	if name == "" {
+		return DefaultBranchName
+	}
+	return name
+}
+
+// ActiveBranchLog returns the log of the branch new versions of a dataset are
+// written to, returning nil if no such branch exists
+func (book *Book) ActiveBranchLog(dsLog *oplog.Log) *oplog.Log {
+	return NamedBranchLog(dsLog, book.activeBranch(newDatasetLog(dsLog)))
+}
+
+// Return a strongly typed BranchLog for a named branch of a dataset
+func (book *Book) namedBranchLog(ctx context.Context, initID, name string) (*BranchLog, error) {
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+	return dsLog.Branch(name)
 }
 
 // hasWriteAccess is a simple author-matching check
@@ -546,6 +640,243 @@ func (book *Book) WriteDatasetDelete(ctx context.Context, initID string) error {
 	return book.save(ctx)
 }
 
+// WriteBranchInit creates a new named branch within a dataset history. The new
+// branch starts as a copy of the active branch, sharing all versions written
+// to the active branch so far
+func (book *Book) WriteBranchInit(ctx context.Context, initID, name string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if !dsref.IsValidName(name) {
+		return fmt.Errorf("logbook: branch name %q invalid", name)
+	}
+	log.Debugw("WriteBranchInit", "initID", initID, "name", name)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+	if _, err := dsLog.Branch(name); err == nil {
+		return fmt.Errorf("%w: %q", ErrBranchExists, name)
+	}
+
+	from, err := dsLog.Branch(book.activeBranch(dsLog))
+	if err != nil {
+		return err
+	}
+
+	branch := oplog.InitLog(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     BranchModel,
+		AuthorID:  book.AuthorID(),
+		Name:      name,
+		Ref:       book.latestSavePath(from.l),
+		Prev:      from.l.ID(),
+		Timestamp: NewTimestamp(),
+	})
+	// copy history from the source branch, skipping the init operation
+	for _, op := range from.Ops()[1:] {
+		branch.Append(op)
+	}
+	dsLog.l.AddChild(branch)
+
+	return book.save(ctx)
+}
+
+// SwitchBranch sets the active branch for a dataset. All subsequent
+// branch-level operations (saves, removes, pushes) are written to the active
+// branch, and dataset references resolve to the head of the active branch.
+// The active branch is local state, switching doesn't write to the dataset log
+func (book *Book) SwitchBranch(ctx context.Context, initID, name string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	log.Debugw("SwitchBranch", "initID", initID, "name", name)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+
+	branchLog, err := dsLog.Branch(name)
+	if err != nil {
+		return err
+	}
+	if book.activeBranch(dsLog) == name {
+		return nil
+	}
+	if book.branches == nil {
+		book.branches = NewMemActiveBranches()
+	}
+	if err := book.branches.SetActiveBranch(initID, name); err != nil {
+		return err
+	}
+
+	items := branchToVersionInfos(branchLog, dsref.Ref{}, 0, -1, false)
+	if len(items) > 0 {
+		head := items[0]
+		err = book.publisher.Publish(ctx, event.ETDatasetCommitChange, event.DsChange{
+			InitID:   initID,
+			TopIndex: len(items),
+			HeadRef:  head.Path,
+			Info:     &head,
+		})
+		if err != nil {
+			log.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// BranchInfo describes a single named line of history within a dataset
+type BranchInfo struct {
+	// Name of the branch
+	Name string `json:"name"`
+	// Path of the most recent version saved to the branch
+	HeadPath string `json:"headPath,omitempty"`
+	// Number of versions in the branch
+	Versions int `json:"versions"`
+	// Active is true for the branch new versions are saved to
+	Active bool `json:"active,omitempty"`
+}
+
+// Branches lists all branches of a dataset history
+func (book *Book) Branches(ctx context.Context, initID string) ([]BranchInfo, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := book.activeBranch(dsLog)
+	res := []BranchInfo{}
+	for _, l := range dsLog.l.Logs {
+		if l.Removed() {
+			continue
+		}
+		bl := newBranchLog(l)
+		res = append(res, BranchInfo{
+			Name:     bl.Name(),
+			HeadPath: book.latestSavePath(l),
+			Versions: len(branchToVersionInfos(bl, dsref.Ref{}, 0, -1, true)),
+			Active:   bl.Name() == active,
+		})
+	}
+	return res, nil
+}
+
+// BranchItems collapses the history of a named dataset branch into linear log
+// items, ordered newest-first
+func (book *Book) BranchItems(ctx context.Context, ref dsref.Ref, branch string, offset, limit int) ([]dsref.VersionInfo, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	initID, err := book.RefToInitID(dsref.Ref{Username: ref.Username, Name: ref.Name})
+	if err != nil {
+		return nil, err
+	}
+	branchLog, err := book.namedBranchLog(ctx, initID, branch)
+	if err != nil {
+		return nil, err
+	}
+	return branchToVersionInfos(branchLog, ref, offset, limit, true), nil
+}
+
+// MergeBase finds the path of the most recent version two branches of a
+// dataset history have in common, returning the empty string if the branches
+// share no versions
+func (book *Book) MergeBase(ctx context.Context, initID, a, b string) (string, error) {
+	if book == nil {
+		return "", ErrNoLogbook
+	}
+	aLog, err := book.namedBranchLog(ctx, initID, a)
+	if err != nil {
+		return "", err
+	}
+	bLog, err := book.namedBranchLog(ctx, initID, b)
+	if err != nil {
+		return "", err
+	}
+
+	inA := map[string]struct{}{}
+	for _, vi := range branchToVersionInfos(aLog, dsref.Ref{}, 0, -1, true) {
+		if vi.Path != "" {
+			inA[vi.Path] = struct{}{}
+		}
+	}
+	// versions merged into branch a count as shared history
+	for _, op := range aLog.Ops() {
+		if isMergeOp(op) {
+			inA[op.Ref] = struct{}{}
+		}
+	}
+	// version infos are ordered newest first, the first match is the merge base
+	for _, vi := range branchToVersionInfos(bLog, dsref.Ref{}, 0, -1, true) {
+		if _, ok := inA[vi.Path]; ok {
+			return vi.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// WriteBranchMerge records that the head of the named branch has been merged
+// into the active branch of a dataset history. Merge records don't create
+// versions, they're used to find the common history of two branches
+func (book *Book) WriteBranchMerge(ctx context.Context, initID, from string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	log.Debugw("WriteBranchMerge", "initID", initID, "from", from)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+	fromLog, err := dsLog.Branch(from)
+	if err != nil {
+		return err
+	}
+	active, err := dsLog.Branch(book.activeBranch(dsLog))
+	if err != nil {
+		return err
+	}
+
+	active.Append(oplog.Op{
+		Type:      oplog.OpTypeAmend,
+		Model:     BranchModel,
+		AuthorID:  book.AuthorID(),
+		Ref:       book.latestSavePath(fromLog.l),
+		Relations: []string{fmt.Sprintf("%s%s", mergeRelPrefix, from)},
+		Timestamp: NewTimestamp(),
+	})
+
+	return book.save(ctx)
+}
+
+func isMergeOp(op oplog.Op) bool {
+	if op.Model != BranchModel || op.Type != oplog.OpTypeAmend {
+		return false
+	}
+	for _, str := range op.Relations {
+		if strings.HasPrefix(str, mergeRelPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // WriteVersionSave adds 1 or 2 operations marking the creation of a dataset
 // version. If the run.State arg is nil only one commit operation is written
 //
@@ -661,12 +992,39 @@ func (book *Book) appendTransformRun(blog *BranchLog, rs *run.State) int {
 	if rs.StartTime != nil {
 		op.Timestamp = rs.StartTime.UnixNano()
 	}
+	if rs.HTTPRecording != "" {
+		op.Relations = []string{fmt.Sprintf("%s%s", httpRecordingRelPrefix, rs.HTTPRecording)}
+	}
 
 	blog.Append(op)
 
 	return blog.Size() - 1
 }
 
+// RunHTTPRecording returns the path to the HTTP recording of a transform run
+func (book *Book) RunHTTPRecording(ctx context.Context, initID, runID string) (string, error) {
+	if book == nil {
+		return "", ErrNoLogbook
+	}
+
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return "", err
+	}
+	for _, op := range branchLog.Ops() {
+		if op.Model != RunModel || op.Ref != runID {
+			continue
+		}
+		for _, rel := range op.Relations {
+			if strings.HasPrefix(rel, httpRecordingRelPrefix) {
+				return strings.TrimPrefix(rel, httpRecordingRelPrefix), nil
+			}
+		}
+		return "", fmt.Errorf("%w: run %q didn't record HTTP requests", ErrNotFound, runID)
+	}
+	return "", fmt.Errorf("%w: run %q", ErrNotFound, runID)
+}
+
 // WriteVersionAmend adds an operation to a log when a dataset amends a commit
 // TODO(dustmop): Currently unused by codebase, only called in tests.
 func (book *Book) WriteVersionAmend(ctx context.Context, initID string, ds *dataset.Dataset) error {
@@ -739,6 +1097,93 @@ func (book *Book) WriteVersionDelete(ctx context.Context, initID string, revisio
 	return book.save(ctx)
 }
 
+// WriteVersionSquash records a rewrite of dataset history. A number of
+// sequential versions from HEAD are marked as removed, and the versions that
+// replace them are appended in their place. versions are ordered oldest-first,
+// replaces lists the paths of the versions each replacement stands in for
+func (book *Book) WriteVersionSquash(ctx context.Context, initID string, removed int, versions []*dataset.Dataset, replaces [][]string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	log.Debugw("WriteVersionSquash", "initID", initID, "removed", removed, "versions", len(versions))
+	if len(versions) == 0 || len(versions) != len(replaces) {
+		return fmt.Errorf("squash requires a list of replaced versions for each new version")
+	}
+
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(branchLog.l); err != nil {
+		return err
+	}
+
+	branchLog.Append(oplog.Op{
+		Type:      oplog.OpTypeRemove,
+		Model:     CommitModel,
+		Size:      int64(removed),
+		Timestamp: NewTimestamp(),
+	})
+	topIndex := 0
+	for i, ds := range versions {
+		topIndex = book.appendVersionSave(branchLog, ds)
+		op := &branchLog.l.Ops[topIndex]
+		for _, path := range replaces[i] {
+			op.Relations = append(op.Relations, fmt.Sprintf("%s%s", rewriteRelPrefix, path))
+		}
+	}
+
+	if err := book.save(ctx); err != nil {
+		return err
+	}
+
+	info := dsref.ConvertDatasetToVersionInfo(versions[len(versions)-1])
+	err = book.publisher.Publish(ctx, event.ETDatasetCommitChange, event.DsChange{
+		InitID:   initID,
+		TopIndex: topIndex,
+		HeadRef:  info.Path,
+		Info:     &info,
+	})
+	if err != nil {
+		log.Error(err)
+	}
+
+	return nil
+}
+
+// PublishedRemotes lists the addresses of remotes the active branch of a
+// dataset was most recently pushed to, and not removed from
+func (book *Book) PublishedRemotes(ctx context.Context, initID string) ([]string, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+
+	published := map[string]bool{}
+	addrs := []string{}
+	for _, op := range branchLog.Ops() {
+		if op.Model != PushModel || len(op.Relations) == 0 {
+			continue
+		}
+		addr := op.Relations[0]
+		if _, seen := published[addr]; !seen {
+			addrs = append(addrs, addr)
+		}
+		published[addr] = op.Type == oplog.OpTypeInit
+	}
+
+	res := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if published[addr] {
+			res = append(res, addr)
+		}
+	}
+	return res, nil
+}
+
 // WriteRemotePush adds an operation to a log marking the publication of a
 // number of versions to a remote address. It returns a rollback function that
 // removes the operation when called
@@ -898,6 +1343,37 @@ func addReferencedPaths(log *oplog.Log, paths map[string]struct{}) {
 	}
 }
 
+// AllReferencedHTTPRecordingPaths scans an entire logbook looking for the
+// paths of HTTP recordings made by transform runs
+func (book *Book) AllReferencedHTTPRecordingPaths(ctx context.Context) (map[string]struct{}, error) {
+	paths := map[string]struct{}{}
+	logs, err := book.ListAllLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range logs {
+		addHTTPRecordingPaths(l, paths)
+	}
+	return paths, nil
+}
+
+func addHTTPRecordingPaths(log *oplog.Log, paths map[string]struct{}) {
+	for _, op := range log.Ops {
+		if op.Model != RunModel {
+			continue
+		}
+		for _, rel := range op.Relations {
+			if strings.HasPrefix(rel, httpRecordingRelPrefix) {
+				paths[strings.TrimPrefix(rel, httpRecordingRelPrefix)] = struct{}{}
+			}
+		}
+	}
+	for _, l := range log.Logs {
+		addHTTPRecordingPaths(l, paths)
+	}
+}
+
 // Log gets a log for a given ID
 func (book Book) Log(ctx context.Context, id string) (*oplog.Log, error) {
 	return book.store.Get(ctx, id)
@@ -991,11 +1467,8 @@ func (book Book) UserDatasetBranchesLog(ctx context.Context, datasetInitID strin
 }
 
 // DatasetRef gets a dataset log and all branches. Dataset logs describe
-// activity affecting an entire dataset. Things like dataset name changes and
-// access control changes are kept in the dataset log
-//
-// currently all logs are hardcoded to only accept one branch name. This
-// function will always return a single branch
+// activity affecting an entire dataset. Things like dataset name changes,
+// access control changes, and the active branch are kept in the dataset log
 //
 // TODO(dustmop): Do not add new callers to this, transition away (preferring datasetLog instead),
 // and delete it.
@@ -1010,11 +1483,8 @@ func (book Book) DatasetRef(ctx context.Context, ref dsref.Ref) (*oplog.Log, err
 	return book.store.HeadRef(ctx, ref.Username, ref.Name)
 }
 
-// BranchRef gets a branch log for a dataset reference. Branch logs describe
-// a line of commits
-//
-// currently all logs are hardcoded to only accept one branch name. This
-// function always returns
+// BranchRef gets the active branch log for a dataset reference. Branch logs
+// describe a line of commits
 //
 // TODO(dustmop): Do not add new callers to this, transition away (preferring branchLog instead),
 // and delete it.
@@ -1026,7 +1496,15 @@ func (book Book) BranchRef(ctx context.Context, ref dsref.Ref) (*oplog.Log, erro
 		return nil, fmt.Errorf("logbook: ref.Name is required")
 	}
 
-	return book.store.HeadRef(ctx, ref.Username, ref.Name, DefaultBranchName)
+	dsLog, err := book.store.HeadRef(ctx, ref.Username, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	branchLog := book.ActiveBranchLog(dsLog)
+	if branchLog == nil {
+		return nil, oplog.ErrNotFound
+	}
+	return branchLog, nil
 }
 
 // SignLog populates the signature field of a log using the author's private key
@@ -1227,7 +1705,7 @@ func branchToVersionInfos(blog *BranchLog, ref dsref.Ref, offset, limit int, col
 				refs[len(refs)-1] = versionInfoFromOp(ref, op)
 			case oplog.OpTypeRemove:
 				if collapseAllDeletes {
-					refs = refs[:len(refs)-int(op.Size)]
+					refs = dropVersions(refs, int(op.Size))
 				} else {
 					deleteAtEnd += int(op.Size)
 				}
@@ -1250,11 +1728,7 @@ func branchToVersionInfos(blog *BranchLog, ref dsref.Ref, offset, limit int, col
 	}
 
 	if deleteAtEnd > 0 {
-		if deleteAtEnd < len(refs) {
-			refs = refs[:len(refs)-deleteAtEnd]
-		} else {
-			refs = []dsref.VersionInfo{}
-		}
+		refs = dropVersions(refs, deleteAtEnd)
 	}
 
 	// reverse the slice, placing newest first
@@ -1276,6 +1750,21 @@ func branchToVersionInfos(blog *BranchLog, ref dsref.Ref, offset, limit int, col
 	return refs
 }
 
+// dropVersions removes the n most recent versions from a list of items ordered
+// oldest-first. remove operations count versions, so runs that didn't save a
+// version & were recorded after the earliest dropped version are dropped with
+// them
+func dropVersions(refs []dsref.VersionInfo, n int) []dsref.VersionInfo {
+	i := len(refs)
+	for n > 0 && i > 0 {
+		i--
+		if refs[i].Path != "" {
+			n--
+		}
+	}
+	return refs[:i]
+}
+
 // LogEntry is a simplified representation of a log operation
 type LogEntry struct {
 	Timestamp time.Time
@@ -22,6 +22,7 @@ import (
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/logbook/oplog"
 	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/transform/run"
 )
 
 func Example() {
@@ -908,6 +909,176 @@ func TestItems(t *testing.T) {
 	}
 }
 
+func TestItemsRemoveAfterRun(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteMoreWorldBankCommits(t, initID)
+	book := tr.Book
+
+	before, err := book.Items(tr.Ctx, tr.WorldBankRef(), 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a run that didn't save a version, followed by removing the latest
+	// version. remove ops count versions, so the run is dropped along with it
+	if err := book.WriteTransformRun(tr.Ctx, initID, &run.State{ID: "unchanged-run", Status: run.RSUnchanged}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteVersionDelete(tr.Ctx, initID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := book.Items(tr.Ctx, tr.WorldBankRef(), 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(before[1:], items); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunHTTPRecording(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	book := tr.Book
+
+	recorded := &run.State{ID: "recorded-run", Status: run.RSSucceeded, HTTPRecording: "/mem/QmRecording"}
+	if err := book.WriteTransformRun(tr.Ctx, initID, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteTransformRun(tr.Ctx, initID, &run.State{ID: "unrecorded-run", Status: run.RSFailed}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := book.RunHTTPRecording(tr.Ctx, initID, "recorded-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/mem/QmRecording" {
+		t.Errorf("recording path mismatch. expected: %q, got: %q", "/mem/QmRecording", path)
+	}
+
+	if _, err := book.RunHTTPRecording(tr.Ctx, initID, "unrecorded-run"); !errors.Is(err, logbook.ErrNotFound) {
+		t.Errorf("expected a run without a recording to return ErrNotFound. got: %v", err)
+	}
+	if _, err := book.RunHTTPRecording(tr.Ctx, initID, "missing-run"); !errors.Is(err, logbook.ErrNotFound) {
+		t.Errorf("expected a missing run to return ErrNotFound. got: %v", err)
+	}
+
+	paths, err := book.AllReferencedHTTPRecordingPaths(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]struct{}{"/mem/QmRecording": {}}, paths); diff != "" {
+		t.Errorf("referenced recordings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteVersionSquash(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	book := tr.Book
+	name := "hourly"
+	initID, err := book.WriteDatasetInit(tr.Ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := func(path, prev, title string, day int) *dataset.Dataset {
+		return &dataset.Dataset{
+			Peername: tr.Username,
+			Name:     name,
+			Commit: &dataset.Commit{
+				Timestamp: time.Date(2000, time.January, day, 0, 0, 0, 0, time.UTC),
+				Title:     title,
+			},
+			Path:         path,
+			PreviousPath: prev,
+		}
+	}
+
+	if err := book.WriteVersionSave(tr.Ctx, initID, version("QmV1", "", "one", 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteVersionSave(tr.Ctx, initID, version("QmV2", "QmV1", "two", 2), nil); err != nil {
+		t.Fatal(err)
+	}
+	// a run that didn't save a version
+	if err := book.WriteTransformRun(tr.Ctx, initID, &run.State{ID: "unchanged-run", Status: run.RSUnchanged}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteVersionSave(tr.Ctx, initID, version("QmV3", "QmV2", "three", 3), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := book.WriteRemotePush(tr.Ctx, initID, 1, "registry.qri.cloud"); err != nil {
+		t.Fatal(err)
+	}
+
+	// squash versions one & two, rewriting three on top
+	versions := []*dataset.Dataset{
+		version("QmSquashed", "", "squashed 2 versions", 2),
+		version("QmRewritten3", "QmSquashed", "three", 3),
+	}
+	replaces := [][]string{{"QmV1", "QmV2"}, {"QmV3"}}
+	if err := book.WriteVersionSquash(tr.Ctx, initID, 3, versions, replaces); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := book.Items(tr.Ctx, dsref.Ref{Username: tr.Username, Name: name}, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{}
+	for _, item := range items {
+		paths = append(paths, item.Path)
+	}
+	if diff := cmp.Diff([]string{"QmRewritten3", "QmSquashed"}, paths); diff != "" {
+		t.Errorf("history mismatch (-want +got):\n%s", diff)
+	}
+
+	referenced, err := book.AllReferencedDatasetPaths(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"QmV1", "QmV2", "QmV3"} {
+		if _, ok := referenced[p]; ok {
+			t.Errorf("expected squashed version %s to be unreferenced", p)
+		}
+	}
+	for _, p := range []string{"QmSquashed", "QmRewritten3"} {
+		if _, ok := referenced[p]; !ok {
+			t.Errorf("expected new version %s to be referenced", p)
+		}
+	}
+
+	remotes, err := book.PublishedRemotes(tr.Ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"registry.qri.cloud"}, remotes); diff != "" {
+		t.Errorf("published remotes mismatch (-want +got):\n%s", diff)
+	}
+	if _, _, err := book.WriteRemoteDelete(tr.Ctx, initID, 1, "registry.qri.cloud"); err != nil {
+		t.Fatal(err)
+	}
+	if remotes, err = book.PublishedRemotes(tr.Ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	if len(remotes) != 0 {
+		t.Errorf("expected no published remotes after removing. got: %v", remotes)
+	}
+
+	if err := book.WriteVersionSquash(tr.Ctx, initID, 1, versions, nil); err == nil {
+		t.Error("expected squashing without a list of replaced versions to fail")
+	}
+}
+
 func TestConstructDatasetLog(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
@@ -1327,3 +1498,216 @@ func makeLogbookOneCommit(ctx context.Context, t *testing.T, ref dsref.Ref, comm
 	builder.Commit(ctx, t, id, commitMessage, dsPath)
 	return builder.Logbook()
 }
+
+func TestBranches(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	ctx := tr.Ctx
+	book := tr.Book
+	initID := tr.WriteWorldBankExample(t)
+
+	if err := book.WriteBranchInit(ctx, initID, "experiment"); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteBranchInit(ctx, initID, "experiment"); !errors.Is(err, logbook.ErrBranchExists) {
+		t.Errorf("expected creating a duplicate branch to fail with ErrBranchExists, got: %v", err)
+	}
+	if err := book.SwitchBranch(ctx, initID, "nope"); !errors.Is(err, logbook.ErrBranchNotFound) {
+		t.Errorf("expected switching to a missing branch to fail with ErrBranchNotFound, got: %v", err)
+	}
+
+	if err := book.SwitchBranch(ctx, initID, "experiment"); err != nil {
+		t.Fatal(err)
+	}
+	tr.WriteMoreWorldBankCommits(t, initID)
+
+	branches, err := book.Branches(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []logbook.BranchInfo{
+		{Name: "main", HeadPath: "QmHashOfVersion3", Versions: 1},
+		{Name: "experiment", HeadPath: "QmHashOfVersion5", Versions: 3, Active: true},
+	}
+	if diff := cmp.Diff(expect, branches); diff != "" {
+		t.Errorf("branches mismatch (-want +got):\n%s", diff)
+	}
+
+	// dataset references resolve to the head of the active branch
+	items, err := book.Items(ctx, tr.WorldBankRef(), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Path != "QmHashOfVersion5" {
+		t.Errorf("expected head of active branch to be %q, got %q", "QmHashOfVersion5", items[0].Path)
+	}
+
+	mainItems, err := book.BranchItems(ctx, tr.WorldBankRef(), "main", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mainItems) != 1 || mainItems[0].Path != "QmHashOfVersion3" {
+		t.Errorf("expected main branch to contain only QmHashOfVersion3, got: %v", mainItems)
+	}
+
+	base, err := book.MergeBase(ctx, initID, "main", "experiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != "QmHashOfVersion3" {
+		t.Errorf("merge base mismatch. want %q, got %q", "QmHashOfVersion3", base)
+	}
+
+	if err := book.SwitchBranch(ctx, initID, "main"); err != nil {
+		t.Fatal(err)
+	}
+	items, err = book.Items(ctx, tr.WorldBankRef(), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Path != "QmHashOfVersion3" {
+		t.Errorf("expected head after switching back to be %q, got %q", "QmHashOfVersion3", items[0].Path)
+	}
+
+	// recording a merge makes the merged head part of the shared history
+	if err := book.WriteBranchMerge(ctx, initID, "experiment"); err != nil {
+		t.Fatal(err)
+	}
+	base, err = book.MergeBase(ctx, initID, "main", "experiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != "QmHashOfVersion5" {
+		t.Errorf("merge base after merge mismatch. want %q, got %q", "QmHashOfVersion5", base)
+	}
+	if mainItems, err = book.BranchItems(ctx, tr.WorldBankRef(), "main", 0, -1); err != nil {
+		t.Fatal(err)
+	}
+	if len(mainItems) != 1 {
+		t.Errorf("expected merge record to not add versions, got: %v", mainItems)
+	}
+
+	// switching is local state, only merge records are written to the log, and
+	// they're attributed to the author like any other op
+	l, err := book.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range l.Logs[0].Ops {
+		if op.Model == logbook.BranchModel {
+			t.Errorf("expected switching branches to not write to the dataset log, found op: %#v", op)
+		}
+	}
+	amends := 0
+	var checkAuthors func(l *oplog.Log)
+	checkAuthors = func(l *oplog.Log) {
+		for _, op := range l.Ops {
+			if op.Model == logbook.BranchModel && op.Type == oplog.OpTypeAmend {
+				amends++
+				if op.AuthorID != book.AuthorID() {
+					t.Errorf("expected branch op to have author %q, got %q", book.AuthorID(), op.AuthorID)
+				}
+			}
+		}
+		for _, child := range l.Logs {
+			checkAuthors(child)
+		}
+	}
+	checkAuthors(l)
+	if amends != 1 {
+		t.Errorf("expected 1 merge record, got %d", amends)
+	}
+}
+
+func TestACL(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	ctx := tr.Ctx
+	book := tr.Book
+	initID := tr.WriteWorldBankExample(t)
+	ownerID, err := profile.KeyIDFromPriv(testPrivKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	granteeID, err := profile.KeyIDFromPriv(testPrivKey2(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := book.CheckACL(ctx, initID, granteeID, logbook.ACLRead); !errors.Is(err, logbook.ErrNoACL) {
+		t.Errorf("expected ErrNoACL before any access is granted, got: %v", err)
+	}
+	if err := book.WriteACLGrant(ctx, initID, granteeID, "admin"); err == nil {
+		t.Error("expected granting an unknown right to fail")
+	}
+
+	if err := book.WriteACLGrant(ctx, initID, granteeID, logbook.ACLPush, logbook.ACLRead); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteACLGrant(ctx, initID, granteeID, logbook.ACLRead); err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := book.ACL(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []logbook.ACLEntry{{ProfileID: granteeID, Rights: []string{"read", "push"}}}
+	if diff := cmp.Diff(expect, acl); diff != "" {
+		t.Errorf("acl mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := book.CheckACL(ctx, initID, granteeID, logbook.ACLPush); err != nil {
+		t.Errorf("expected push to be granted, got: %s", err)
+	}
+	if err := book.CheckACL(ctx, initID, granteeID, logbook.ACLWrite); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected write to be denied, got: %v", err)
+	}
+	if err := book.CheckACL(ctx, initID, ownerID, logbook.ACLWrite); err != nil {
+		t.Errorf("expected owner to have all rights, got: %s", err)
+	}
+
+	// revoking a right the profile doesn't have doesn't write an operation
+	dsLog, err := book.DatasetRef(ctx, tr.WorldBankRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+	numOps := len(dsLog.Ops)
+	if err := book.WriteACLRevoke(ctx, initID, granteeID, logbook.ACLWrite); err != nil {
+		t.Fatal(err)
+	}
+	if dsLog, err = book.DatasetRef(ctx, tr.WorldBankRef()); err != nil {
+		t.Fatal(err)
+	}
+	if len(dsLog.Ops) != numOps {
+		t.Errorf("expected revoking a right that wasn't granted to not add an operation")
+	}
+
+	if err := book.WriteACLRevoke(ctx, initID, granteeID, logbook.ACLPush); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.CheckACL(ctx, initID, granteeID, logbook.ACLPush); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected push to be denied after revoke, got: %v", err)
+	}
+
+	if err := book.WriteACLRevoke(ctx, initID, granteeID); err != nil {
+		t.Fatal(err)
+	}
+	if acl, err = book.ACL(ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	if len(acl) != 0 {
+		t.Errorf("expected revoking all rights to empty the access control list, got: %v", acl)
+	}
+
+	// access control operations must not interfere with dataset naming
+	items, err := book.Items(ctx, tr.WorldBankRef(), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Errorf("expected acl operations to not add versions, got %d items", len(items))
+	}
+}
@@ -1,6 +1,8 @@
 package logbook
 
 import (
+	"fmt"
+
 	"github.com/qri-io/qri/logbook/oplog"
 )
 
@@ -48,7 +50,7 @@ func newDatasetLog(log *oplog.Log) *DatasetLog {
 
 // Append adds an op to the DatasetLog
 func (dlog *DatasetLog) Append(op oplog.Op) {
-	if op.Model != DatasetModel {
+	if op.Model != DatasetModel && op.Model != ACLModel {
 		log.Errorf("cannot Append, incorrect model %d for DatasetLog", op.Model)
 		return
 	}
@@ -60,6 +62,26 @@ func (dlog *DatasetLog) InitID() string {
 	return dlog.l.ID()
 }
 
+// Branch returns the branch log with the given name
+func (dlog *DatasetLog) Branch(name string) (*BranchLog, error) {
+	l := NamedBranchLog(dlog.l, name)
+	if l == nil {
+		return nil, fmt.Errorf("%w: branch %q", ErrBranchNotFound, name)
+	}
+	return newBranchLog(l), nil
+}
+
+// NamedBranchLog returns the branch log of a dataset log with the given name,
+// returning nil if no such branch exists
+func NamedBranchLog(dsLog *oplog.Log, name string) *oplog.Log {
+	for _, l := range dsLog.Logs {
+		if l.Name() == name && !l.Removed() {
+			return l
+		}
+	}
+	return nil
+}
+
 // BranchLog is the bottom-level log representing a branch of a dataset history
 type BranchLog struct {
 	l *oplog.Log
@@ -92,3 +114,8 @@ func (blog *BranchLog) Size() int {
 func (blog *BranchLog) Ops() []oplog.Op {
 	return blog.l.Ops
 }
+
+// Name returns the name of the branch
+func (blog *BranchLog) Name() string {
+	return blog.l.Name()
+}
@@ -292,10 +292,8 @@ func (r *Remote) RemoveDataset(ctx context.Context, params map[string]string) er
 	log.Debugf("remove dataset %s", ref)
 
 	pid := subj.ID
-	if r.policy != nil {
-		if err := r.policy.Enforce(subj, access.ResourceStrFromRef(ref), "remote:remove"); err != nil {
-			return err
-		}
+	if err := r.enforce(ctx, subj, ref, "remote:remove"); err != nil {
+		return err
 	}
 
 	// run pre check hook
@@ -347,10 +345,8 @@ func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[str
 	}
 
 	pid := subj.ID
-	if r.policy != nil {
-		if err := r.policy.Enforce(subj, access.ResourceStrFromRef(ref), "remote:push"); err != nil {
-			return err
-		}
+	if err := r.enforce(ctx, subj, ref, "remote:push"); err != nil {
+		return err
 	}
 
 	if r.acceptSizeMax == 0 {
@@ -435,10 +431,8 @@ func (r *Remote) dsRemovePreCheck(ctx context.Context, info dag.Info, meta map[s
 
 	pid := subj.ID
 
-	if r.policy != nil {
-		if err := r.policy.Enforce(subj, access.ResourceStrFromRef(ref), "remote:remove"); err != nil {
-			return err
-		}
+	if err := r.enforce(ctx, subj, ref, "remote:remove"); err != nil {
+		return err
 	}
 
 	if r.datasetRemovePreCheck != nil {
@@ -458,6 +452,12 @@ func (r *Remote) dsGetDagInfo(ctx context.Context, into dag.Info, meta map[strin
 	pid := subj.ID
 	log.Debugf("pid %s pulling ref %s", pid.String(), ref.String())
 
+	// the remote policy isn't checked for block fetches, but access control
+	// lists recorded in the logbook are
+	if err := r.checkACL(ctx, subj, ref, "remote:pull"); err != nil {
+		return err
+	}
+
 	if r.datasetPulled != nil {
 		if err = r.datasetPulled(ctx, pid, ref); err != nil {
 			log.Errorf("dataset pulled hook: %s", err.Error())
@@ -467,6 +467,50 @@ func (r *Remote) dsGetDagInfo(ctx context.Context, into dag.Info, meta map[strin
 	return nil
 }
 
+// aclRights maps remote actions to the access control list right required to
+// perform them
+var aclRights = map[string]string{
+	"remote:pull":   logbook.ACLRead,
+	"remote:push":   logbook.ACLPush,
+	"remote:remove": logbook.ACLWrite,
+}
+
+// enforce checks a subject can perform an action on a dataset. The dataset's
+// access control list is enforced alongside the remote policy, both must
+// allow the action. Datasets with an access control list deny actions that
+// aren't granted, a grant never overrides the policy of the remote
+func (r *Remote) enforce(ctx context.Context, subj *profile.Profile, ref dsref.Ref, action string) error {
+	if err := r.checkACL(ctx, subj, ref, action); err != nil {
+		return err
+	}
+	if r.policy != nil {
+		return r.policy.Enforce(subj, access.ResourceStrFromRef(ref), action)
+	}
+	return nil
+}
+
+// checkACL checks the logbook access control list of a dataset the remote
+// already has, returning an error if the list denies the action. Unknown
+// datasets and datasets without an access control list don't error
+func (r *Remote) checkACL(ctx context.Context, subj *profile.Profile, ref dsref.Ref, action string) error {
+	right, ok := aclRights[action]
+	if !ok || r.logbook == nil {
+		return nil
+	}
+	initID, err := r.logbook.RefToInitID(dsref.Ref{Username: ref.Username, Name: ref.Name})
+	if err != nil {
+		return nil
+	}
+
+	err = r.logbook.CheckACL(ctx, initID, subj.ID.String(), right)
+	if errors.Is(err, logbook.ErrAccessDenied) {
+		log.Debugw("acl denied", "profileID", subj.ID.String(), "ref", ref.String(), "action", action)
+		return fmt.Errorf("%w: %q right not granted", access.ErrAccessDenied, right)
+	}
+	// other errors mean the dataset has no access control list
+	return nil
+}
+
 func (r *Remote) subjAndRefFromMeta(meta map[string]string) (*profile.Profile, dsref.Ref, error) {
 	ref := dsref.Ref{
 		Username:  meta["username"],
@@ -532,15 +576,12 @@ func (r *Remote) logPreCheckHook(name string, action string, h Hook) logsync.Hoo
 			return err
 		}
 
-		if r.policy != nil {
-			pro := &profile.Profile{
-				ID:       pid,
-				Peername: author.Username(),
-			}
-			resource := access.ResourceStrFromRef(ref)
-			if err = r.policy.Enforce(pro, resource, action); err != nil {
-				return err
-			}
+		pro := &profile.Profile{
+			ID:       pid,
+			Peername: author.Username(),
+		}
+		if err = r.enforce(ctx, pro, ref, action); err != nil {
+			return err
 		}
 
 		if h != nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -397,6 +398,74 @@ func TestAccess(t *testing.T) {
 	}
 }
 
+func TestEnforceACL(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	ref := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	book := tr.NodeA.Repo.Logbook()
+	subj := tr.NodeB.Repo.Profiles().Owner()
+
+	// empty policy denies all actions
+	rem := tr.NodeARemote(t, OptPolicy(&access.Policy{}))
+
+	allowAllPullsPolicy := &access.Policy{}
+	mustJSON(`
+	[
+		{
+			"title": "allow pulls of all datasets",
+			"effect": "allow",
+			"subject": "*",
+			"resources": [
+				"dataset:*"
+			],
+			"actions": [
+				"remote:pull"
+			]
+		}
+	]
+	`, allowAllPullsPolicy)
+
+	// datasets without an access control list fall back to the remote policy
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:pull"); !errors.Is(err, access.ErrAccessDenied) {
+		t.Errorf("expected a dataset without an acl to use the policy & deny pulls, got: %v", err)
+	}
+	rem.policy = allowAllPullsPolicy
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:pull"); err != nil {
+		t.Errorf("expected a dataset without an acl to use the policy & allow pulls, got: %v", err)
+	}
+
+	// an acl grant doesn't override a policy that denies the action
+	rem.policy = &access.Policy{}
+	if err := book.WriteACLGrant(tr.Ctx, ref.InitID, subj.ID.String(), logbook.ACLRead); err != nil {
+		t.Fatal(err)
+	}
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:pull"); !errors.Is(err, access.ErrAccessDenied) {
+		t.Errorf("expected a policy that denies pulls to deny a granted profile, got: %v", err)
+	}
+	if err := rem.checkACL(tr.Ctx, subj, ref, "remote:pull"); err != nil {
+		t.Errorf("expected an acl grant to allow block fetches, got: %v", err)
+	}
+
+	// both the acl & the policy must allow the action
+	rem.policy = allowAllPullsPolicy
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:pull"); err != nil {
+		t.Errorf("expected an acl grant & a policy that allows pulls to allow pulls, got: %v", err)
+	}
+
+	// datasets with an acl deny actions that aren't granted, even if the
+	// policy allows them
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:push"); !errors.Is(err, access.ErrAccessDenied) {
+		t.Errorf("expected an acl without a push grant to deny pushes, got: %v", err)
+	}
+	if err := book.WriteACLRevoke(tr.Ctx, ref.InitID, subj.ID.String(), logbook.ACLRead); err != nil {
+		t.Fatal(err)
+	}
+	if err := rem.enforce(tr.Ctx, subj, ref, "remote:pull"); !errors.Is(err, access.ErrAccessDenied) {
+		t.Errorf("expected a revoked acl grant to deny pulls, got: %v", err)
+	}
+}
+
 type testRunner struct {
 	Ctx          context.Context
 	NodeA, NodeB *p2p.QriNode
@@ -0,0 +1,65 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/qri-io/qri/logbook"
+)
+
+// ActiveBranches is a file-based implementation of the logbook.ActiveBranches
+// interface. It stores the active branch of each dataset in a json file,
+// keyed by initID. Active branches are local to this repo, they're never
+// written to the logbook
+type ActiveBranches struct {
+	basepath
+	file File
+}
+
+var _ logbook.ActiveBranches = (*ActiveBranches)(nil)
+
+// ActiveBranch returns the active branch of a dataset
+func (ab ActiveBranches) ActiveBranch(initID string) (string, error) {
+	names, err := ab.names()
+	if err != nil {
+		return "", err
+	}
+	return names[initID], nil
+}
+
+// SetActiveBranch sets the active branch of a dataset
+func (ab ActiveBranches) SetActiveBranch(initID, name string) error {
+	names, err := ab.names()
+	if err != nil {
+		return err
+	}
+	if name == "" || name == logbook.DefaultBranchName {
+		delete(names, initID)
+	} else {
+		names[initID] = name
+	}
+
+	data, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(ab.filepath(ab.file), data, os.ModePerm)
+}
+
+func (ab ActiveBranches) names() (map[string]string, error) {
+	names := map[string]string{}
+	data, err := ioutil.ReadFile(ab.filepath(ab.file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// empty is ok
+			return names, nil
+		}
+		return nil, fmt.Errorf("error loading active branches: %w", err)
+	}
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
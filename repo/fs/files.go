@@ -61,6 +61,8 @@ const (
 	FileSelectedRefs
 	// FileChangeRequests is a file of change requests
 	FileChangeRequests
+	// FileActiveBranches records the active branch of each local dataset
+	FileActiveBranches
 )
 
 var paths = map[File]string{
@@ -78,6 +80,7 @@ var paths = map[File]string{
 	FileSearchIndex:    "/index.bleve",
 	FileSelectedRefs:   "/selected_refs.json",
 	FileChangeRequests: "/change_requests.json",
+	FileActiveBranches: "/active_branches.json",
 }
 
 // Filepath gives the relative filepath to a repofiles
@@ -76,6 +76,9 @@ func NewRepo(path string, fsys *muxfs.Mux, book *logbook.Book, cache *dscache.Ds
 	if _, err := maybeCreateFlatbufferRefsFile(path); err != nil {
 		return nil, err
 	}
+	if book != nil {
+		book.SetActiveBranches(ActiveBranches{basepath: bp, file: FileActiveBranches})
+	}
 
 	own := pro.Owner()
 	// add our own profile to the store if it doesn't already exist.
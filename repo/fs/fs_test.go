@@ -139,3 +139,32 @@ func TestResolveRef(t *testing.T) {
 		return r.Logbook().MergeLog(ctx, author, log)
 	})
 }
+
+func TestActiveBranches(t *testing.T) {
+	path, err := ioutil.TempDir("", "qri_active_branches_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	ab := ActiveBranches{basepath: basepath(path), file: FileActiveBranches}
+	if name, err := ab.ActiveBranch("init_id"); err != nil || name != "" {
+		t.Errorf("expected no active branch before switching, got: %q, %v", name, err)
+	}
+	if err := ab.SetActiveBranch("init_id", "feature"); err != nil {
+		t.Fatal(err)
+	}
+
+	// active branches are read back from disk
+	ab = ActiveBranches{basepath: basepath(path), file: FileActiveBranches}
+	if name, err := ab.ActiveBranch("init_id"); err != nil || name != "feature" {
+		t.Errorf("expected active branch %q, got: %q, %v", "feature", name, err)
+	}
+
+	if err := ab.SetActiveBranch("init_id", logbook.DefaultBranchName); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := ab.ActiveBranch("init_id"); err != nil || name != "" {
+		t.Errorf("expected switching to the default branch to clear the active branch, got: %q, %v", name, err)
+	}
+}
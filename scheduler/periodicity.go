@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Periodicity describes when a schedule repeats
+type Periodicity interface {
+	// Next returns the first time strictly after t the schedule fires, or the
+	// zero time if the schedule never fires again
+	Next(t time.Time) time.Time
+	// String returns the periodicity as it was written
+	String() string
+}
+
+// ParsePeriodicity reads a schedule written either as a five-field cron
+// expression ("*/15 * * * *"), or an ISO-8601 repeating interval
+// ("R/2021-01-01T00:00:00Z/P1D"). Repeating intervals must have a start
+// time, see AnchorInterval
+func ParsePeriodicity(s string) (Periodicity, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "R") {
+		return parseInterval(s)
+	}
+	return parseCron(s)
+}
+
+// AnchorInterval adds a start time to repeating intervals that don't have
+// one, so "R/P1D" becomes "R/2021-01-01T00:00:00Z/P1D". Other periodicities
+// are returned unchanged
+func AnchorInterval(s string, start time.Time) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "R") {
+		return s
+	}
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return s
+	}
+	return fmt.Sprintf("%s/%s/%s", parts[0], start.UTC().Format(time.RFC3339), parts[1])
+}
+
+// cron is a parsed cron expression. each field is a set of allowed values
+type cron struct {
+	src                           string
+	minute, hour, dom, month, dow map[int]bool
+	// cron matches days on day-of-month OR day-of-week when both are
+	// restricted, and on the restricted field when only one is
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(s string) (*cron, error) {
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid periodicity %q: expected a cron expression with 5 fields or an ISO-8601 repeating interval", s)
+	}
+
+	sets := make([]map[int]bool, len(fields))
+	for i, f := range fields {
+		set, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid periodicity %q: %w", s, err)
+		}
+		sets[i] = set
+	}
+	// sunday can be written as 0 or 7
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+
+	return &cron{
+		src:     s,
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField reads a comma separated list of values, ranges, and steps,
+// eg: "*/15", "1-5", "0,30"
+func parseCronField(s string, f cronField) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s step %q", f.name, part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", f.name, part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid %s value %q", f.name, part)
+				}
+			} else if step > 1 {
+				// "5/15" means every 15 starting at 5
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return nil, fmt.Errorf("%s %q out of range %d-%d", f.name, part, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// cronSearchLimit bounds how far ahead Next looks for a matching time.
+// expressions like "0 0 30 2 *" never match
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// Next implements the Periodicity interface
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if !c.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dow
+	case c.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// String implements the Periodicity interface
+func (c *cron) String() string { return c.src }
+
+// interval is an ISO-8601 repeating interval
+type interval struct {
+	src   string
+	start time.Time
+	// number of repetitions after the start, -1 repeats forever
+	repeat              int
+	years, months, days int
+	dur                 time.Duration
+}
+
+// isoDuration matches ISO-8601 durations like P1Y2M10DT2H30M, P2W, PT0.5S
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+func parseInterval(s string) (*interval, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid periodicity %q: repeating intervals must be written as R[n]/start/duration", s)
+	}
+
+	iv := &interval{src: s, repeat: -1}
+	if n := parts[0][1:]; n != "" {
+		repeat, err := strconv.Atoi(n)
+		if err != nil || repeat < 0 {
+			return nil, fmt.Errorf("invalid periodicity %q: invalid repetitions %q", s, parts[0])
+		}
+		iv.repeat = repeat
+	}
+
+	var err error
+	if iv.start, err = time.Parse(time.RFC3339, parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid periodicity %q: invalid start time: %w", s, err)
+	}
+
+	m := isoDuration.FindStringSubmatch(parts[2])
+	if m == nil || parts[2] == "P" || strings.HasSuffix(parts[2], "T") {
+		return nil, fmt.Errorf("invalid periodicity %q: invalid duration %q", s, parts[2])
+	}
+	num := func(i int) int {
+		n, _ := strconv.Atoi(m[i])
+		return n
+	}
+	iv.years, iv.months, iv.days = num(1), num(2), num(3)*7+num(4)
+	secs, _ := strconv.ParseFloat(m[7], 64)
+	iv.dur = time.Duration(num(5))*time.Hour + time.Duration(num(6))*time.Minute + time.Duration(secs*float64(time.Second))
+	if iv.years == 0 && iv.months == 0 && iv.days == 0 && iv.dur <= 0 {
+		return nil, fmt.Errorf("invalid periodicity %q: duration must be greater than zero", s)
+	}
+	return iv, nil
+}
+
+// occurrence returns the start time of the nth repetition
+func (iv *interval) occurrence(n int) time.Time {
+	return iv.start.AddDate(iv.years*n, iv.months*n, iv.days*n).Add(iv.dur * time.Duration(n))
+}
+
+// Next implements the Periodicity interface
+func (iv *interval) Next(t time.Time) time.Time {
+	n := 0
+	if t.After(iv.start) && iv.years == 0 && iv.months == 0 && iv.days == 0 {
+		// fixed-length intervals can skip straight to the right repetition
+		n = int(t.Sub(iv.start) / iv.dur)
+	}
+	for {
+		if iv.repeat >= 0 && n > iv.repeat {
+			return time.Time{}
+		}
+		if next := iv.occurrence(n); next.After(t) {
+			return next
+		}
+		n++
+	}
+}
+
+// String implements the Periodicity interface
+func (iv *interval) String() string { return iv.src }
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	tm, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func TestPeriodicityNext(t *testing.T) {
+	cases := []struct {
+		periodicity, from, expect string
+	}{
+		{"* * * * *", "2021-01-01T00:00:30Z", "2021-01-01T00:01:00Z"},
+		{"*/15 * * * *", "2021-01-01T00:15:00Z", "2021-01-01T00:30:00Z"},
+		{"30 4 * * *", "2021-01-01T05:00:00Z", "2021-01-02T04:30:00Z"},
+		{"0 9 * * 1-5", "2021-01-01T10:00:00Z", "2021-01-04T09:00:00Z"}, // friday -> monday
+		{"0 0 1 */3 *", "2021-01-15T00:00:00Z", "2021-04-01T00:00:00Z"},
+		{"0 0 29 2 *", "2021-01-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+		// day of month OR day of week when both are restricted
+		{"0 0 15 * 0", "2021-01-01T00:00:00Z", "2021-01-03T00:00:00Z"},
+		{"0 0 * * 7", "2021-01-01T00:00:00Z", "2021-01-03T00:00:00Z"},
+		{"0 0 30 2 *", "2021-01-01T00:00:00Z", ""},
+
+		{"R/2021-01-01T00:00:00Z/PT1H", "2020-06-01T00:00:00Z", "2021-01-01T00:00:00Z"},
+		{"R/2021-01-01T00:00:00Z/PT1H", "2021-01-01T00:00:00Z", "2021-01-01T01:00:00Z"},
+		{"R/2021-01-01T00:00:00Z/PT1H30M", "2021-01-03T10:10:00Z", "2021-01-03T10:30:00Z"},
+		{"R/2021-01-31T00:00:00Z/P1M", "2021-02-01T00:00:00Z", "2021-03-03T00:00:00Z"},
+		{"R/2021-01-01T00:00:00Z/P1W", "2021-01-01T00:00:01Z", "2021-01-08T00:00:00Z"},
+		{"R2/2021-01-01T00:00:00Z/P1D", "2021-01-02T12:00:00Z", "2021-01-03T00:00:00Z"},
+		{"R2/2021-01-01T00:00:00Z/P1D", "2021-01-03T00:00:00Z", ""},
+	}
+
+	for _, c := range cases {
+		p, err := ParsePeriodicity(c.periodicity)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.periodicity, err)
+			continue
+		}
+		got := p.Next(mustTime(t, c.from))
+		if c.expect == "" {
+			if !got.IsZero() {
+				t.Errorf("%q from %s: expected no next run, got %s", c.periodicity, c.from, got)
+			}
+			continue
+		}
+		if expect := mustTime(t, c.expect); !got.Equal(expect) {
+			t.Errorf("%q from %s: expected %s, got %s", c.periodicity, c.from, expect, got)
+		}
+	}
+}
+
+func TestParsePeriodicityErrors(t *testing.T) {
+	bad := map[string]string{
+		"":                            `invalid periodicity "": expected a cron expression with 5 fields or an ISO-8601 repeating interval`,
+		"* * * *":                     `invalid periodicity "* * * *": expected a cron expression with 5 fields or an ISO-8601 repeating interval`,
+		"60 * * * *":                  `invalid periodicity "60 * * * *": minute "60" out of range 0-59`,
+		"*/0 * * * *":                 `invalid periodicity "*/0 * * * *": invalid minute step "*/0"`,
+		"* * * jan *":                 `invalid periodicity "* * * jan *": invalid month value "jan"`,
+		"R/P1D":                       `invalid periodicity "R/P1D": repeating intervals must be written as R[n]/start/duration`,
+		"R/2021-01-01/P1D":            `invalid periodicity "R/2021-01-01/P1D": invalid start time: parsing time "2021-01-01" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`,
+		"R/2021-01-01T00:00:00Z/P":    `invalid periodicity "R/2021-01-01T00:00:00Z/P": invalid duration "P"`,
+		"R/2021-01-01T00:00:00Z/PT0S": `invalid periodicity "R/2021-01-01T00:00:00Z/PT0S": duration must be greater than zero`,
+	}
+	for s, expect := range bad {
+		_, err := ParsePeriodicity(s)
+		if err == nil {
+			t.Errorf("%q: expected error", s)
+			continue
+		}
+		if err.Error() != expect {
+			t.Errorf("%q: error mismatch.\nwant: %s\ngot:  %s", s, expect, err)
+		}
+	}
+}
+
+func TestAnchorInterval(t *testing.T) {
+	start := mustTime(t, "2021-01-01T12:00:00Z")
+	cases := map[string]string{
+		"R/P1D":                      "R/2021-01-01T12:00:00Z/P1D",
+		"R5/PT1H":                    "R5/2021-01-01T12:00:00Z/PT1H",
+		"R/2020-01-01T00:00:00Z/P1D": "R/2020-01-01T00:00:00Z/P1D",
+		"0 * * * *":                  "0 * * * *",
+	}
+	for in, expect := range cases {
+		if got := AnchorInterval(in, start); got != expect {
+			t.Errorf("%q: expected %q, got %q", in, expect, got)
+		}
+	}
+}
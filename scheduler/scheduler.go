@@ -0,0 +1,296 @@
+// Package scheduler runs dataset transforms on a schedule. Each scheduled
+// dataset has a periodicity, written as a cron expression or an ISO-8601
+// repeating interval. A Scheduler calls a RunFunc for datasets as they come
+// due, backing off datasets that fail repeatedly
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+)
+
+var log = golog.Logger("scheduler")
+
+// ErrNotScheduled indicates a dataset has no schedule
+var ErrNotScheduled = fmt.Errorf("dataset is not scheduled")
+
+// RunFunc runs the transform of a scheduled dataset
+type RunFunc func(ctx context.Context, ref string) error
+
+// Backoff delays runs of a dataset that's failing. After n consecutive
+// failures the next run waits at least Base * 2^(n-1), capped at Max
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the minimum wait after the given number of consecutive
+// failures
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures < 1 || b.Base <= 0 {
+		return 0
+	}
+	d := b.Base
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+	if b.Max > 0 && d > b.Max {
+		return b.Max
+	}
+	return d
+}
+
+// DefaultBackoff starts at a minute, doubling up to a day
+var DefaultBackoff = Backoff{Base: time.Minute, Max: 24 * time.Hour}
+
+// Status describes a scheduled dataset
+type Status struct {
+	Ref         string `json:"ref"`
+	Periodicity string `json:"periodicity"`
+	// next time the dataset will run, nil if the schedule has ended
+	NextRun *time.Time `json:"nextRun,omitempty"`
+	LastRun *time.Time `json:"lastRun,omitempty"`
+	// error from the last run, empty if the last run succeeded
+	LastError string `json:"lastError,omitempty"`
+	// number of consecutive failed runs
+	Failures int  `json:"failures,omitempty"`
+	Running  bool `json:"running,omitempty"`
+}
+
+// entry is the scheduler's record of a scheduled dataset
+type entry struct {
+	periodicity Periodicity
+	next        time.Time
+	lastRun     time.Time
+	lastError   string
+	failures    int
+	running     bool
+}
+
+// Scheduler tracks scheduled datasets and runs them as they come due.
+// Scheduler is safe for concurrent use
+type Scheduler struct {
+	run     RunFunc
+	backoff Backoff
+	now     func() time.Time
+
+	lk      sync.Mutex
+	entries map[string]*entry
+	wake    chan struct{}
+}
+
+// New creates a scheduler that runs datasets with run
+func New(run RunFunc, backoff Backoff) *Scheduler {
+	return &Scheduler{
+		run:     run,
+		backoff: backoff,
+		now:     time.Now,
+		entries: map[string]*entry{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Set schedules a dataset, replacing any existing schedule for ref. The
+// dataset next runs at the first time the periodicity fires after now
+func (s *Scheduler) Set(ref, periodicity string) error {
+	p, err := ParsePeriodicity(periodicity)
+	if err != nil {
+		return err
+	}
+
+	s.lk.Lock()
+	e, ok := s.entries[ref]
+	if !ok {
+		e = &entry{}
+		s.entries[ref] = e
+	}
+	e.periodicity = p
+	e.next = s.nextRun(e, s.now())
+	s.lk.Unlock()
+
+	s.notify()
+	return nil
+}
+
+// Remove drops the schedule for a dataset. Removing a dataset that isn't
+// scheduled returns ErrNotScheduled
+func (s *Scheduler) Remove(ref string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if _, ok := s.entries[ref]; !ok {
+		return fmt.Errorf("%w: %q", ErrNotScheduled, ref)
+	}
+	delete(s.entries, ref)
+	return nil
+}
+
+// Get returns the status of a scheduled dataset
+func (s *Scheduler) Get(ref string) (Status, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	e, ok := s.entries[ref]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %q", ErrNotScheduled, ref)
+	}
+	return e.status(ref), nil
+}
+
+// List returns the status of all scheduled datasets, sorted by reference
+func (s *Scheduler) List() []Status {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := make([]Status, 0, len(s.entries))
+	for ref, e := range s.entries {
+		res = append(res, e.status(ref))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Ref < res[j].Ref })
+	return res
+}
+
+// Record folds the result of a run started outside the scheduler (eg: a
+// manual run) into the schedule of a dataset, updating backoff state.
+// Recording a run of a dataset that isn't scheduled is a no-op
+func (s *Scheduler) Record(ref string, at time.Time, err error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if e, ok := s.entries[ref]; ok {
+		s.record(e, at, err)
+	}
+}
+
+// Start runs datasets as they come due, blocking until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	log.Debugw("starting scheduler", "scheduled", len(s.List()))
+	for {
+		wait := time.Hour
+		if next, ok := s.nextDue(); ok {
+			wait = next.Sub(s.now())
+		}
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+			s.runDue(ctx, s.now())
+		}
+	}
+}
+
+// notify wakes the run loop to recalculate the next due dataset
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextDue returns the earliest next run time of datasets that aren't running
+func (s *Scheduler) nextDue() (time.Time, bool) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	var next time.Time
+	for _, e := range s.entries {
+		if e.running || e.next.IsZero() {
+			continue
+		}
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// runDue starts runs of every dataset due at now. The returned WaitGroup
+// completes when those runs finish
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for ref, e := range s.entries {
+		if e.running || e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		e.running = true
+		wg.Add(1)
+		go func(ref string, e *entry) {
+			defer wg.Done()
+			log.Debugw("running scheduled dataset", "ref", ref)
+			err := s.run(ctx, ref)
+			if err != nil {
+				log.Debugw("scheduled run failed", "ref", ref, "err", err)
+			}
+
+			s.lk.Lock()
+			e.running = false
+			// the schedule may have been removed while running
+			if s.entries[ref] == e {
+				s.record(e, s.now(), err)
+			}
+			s.lk.Unlock()
+			s.notify()
+		}(ref, e)
+	}
+	return wg
+}
+
+// record updates an entry with the result of a run. the scheduler lock must
+// be held
+func (s *Scheduler) record(e *entry, at time.Time, err error) {
+	e.lastRun = at
+	if err != nil {
+		e.failures++
+		e.lastError = err.Error()
+	} else {
+		e.failures = 0
+		e.lastError = ""
+	}
+	e.next = s.nextRun(e, at)
+}
+
+// nextRun calculates when an entry should next run after t, applying backoff
+// to failing entries
+func (s *Scheduler) nextRun(e *entry, t time.Time) time.Time {
+	next := e.periodicity.Next(t)
+	if next.IsZero() || e.failures == 0 {
+		return next
+	}
+	earliest := e.lastRun.Add(s.backoff.Delay(e.failures))
+	for next.Before(earliest) {
+		if next = e.periodicity.Next(next); next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
+
+func (e *entry) status(ref string) Status {
+	st := Status{
+		Ref:         ref,
+		Periodicity: e.periodicity.String(),
+		LastError:   e.lastError,
+		Failures:    e.failures,
+		Running:     e.running,
+	}
+	if !e.next.IsZero() {
+		next := e.next
+		st.NextRun = &next
+	}
+	if !e.lastRun.IsZero() {
+		last := e.lastRun
+		st.LastRun = &last
+	}
+	return st
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Base: time.Minute, Max: 10 * time.Minute}
+	expect := []time.Duration{0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for failures, want := range expect {
+		if got := b.Delay(failures); got != want {
+			t.Errorf("%d failures: expected %s, got %s", failures, want, got)
+		}
+	}
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	ctx := context.Background()
+	now := mustTime(t, "2021-01-01T00:00:00Z")
+
+	lk := sync.Mutex{}
+	runs := map[string]int{}
+	fail := map[string]bool{"me/broken": true}
+	s := New(func(ctx context.Context, ref string) error {
+		lk.Lock()
+		defer lk.Unlock()
+		runs[ref]++
+		if fail[ref] {
+			return errors.New("oh no")
+		}
+		return nil
+	}, Backoff{Base: 10 * time.Minute, Max: time.Hour})
+	s.now = func() time.Time { return now }
+
+	if err := s.Set("me/every_minute", "* * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("me/broken", "* * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("me/hourly", "0 * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("me/bad", "nope"); err == nil {
+		t.Error("expected setting an invalid periodicity to error")
+	}
+
+	// nothing is due yet
+	s.runDue(ctx, now).Wait()
+	if len(runs) != 0 {
+		t.Fatalf("expected no runs, got %v", runs)
+	}
+
+	now = now.Add(time.Minute)
+	s.runDue(ctx, now).Wait()
+	if runs["me/every_minute"] != 1 || runs["me/broken"] != 1 || runs["me/hourly"] != 0 {
+		t.Fatalf("unexpected runs after a minute: %v", runs)
+	}
+
+	st, err := s.Get("me/broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Failures != 1 || st.LastError != "oh no" {
+		t.Errorf("expected a recorded failure, got %#v", st)
+	}
+	// a failing dataset backs off for 10 minutes instead of running next minute
+	if expect := now.Add(10 * time.Minute); !st.NextRun.Equal(expect) {
+		t.Errorf("expected failing dataset to back off until %s, got %s", expect, st.NextRun)
+	}
+
+	now = now.Add(time.Minute)
+	s.runDue(ctx, now).Wait()
+	if runs["me/every_minute"] != 2 || runs["me/broken"] != 1 {
+		t.Errorf("expected backoff to skip the failing dataset: %v", runs)
+	}
+
+	// a successful manual run resets backoff
+	s.Record("me/broken", now, nil)
+	if st, _ = s.Get("me/broken"); st.Failures != 0 || !st.NextRun.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected a successful run to reset backoff, got %#v", st)
+	}
+
+	if err := s.Remove("me/hourly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("me/hourly"); !errors.Is(err, ErrNotScheduled) {
+		t.Errorf("expected removing an unscheduled dataset to return ErrNotScheduled, got %v", err)
+	}
+
+	list := s.List()
+	if len(list) != 2 || list[0].Ref != "me/broken" || list[1].Ref != "me/every_minute" {
+		t.Errorf("unexpected schedule list: %#v", list)
+	}
+}
+
+func TestSchedulerStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ran := make(chan string, 1)
+	s := New(func(ctx context.Context, ref string) error {
+		ran <- ref
+		return nil
+	}, DefaultBackoff)
+	go s.Start(ctx)
+
+	start := time.Now().Add(20 * time.Millisecond).UTC().Format(time.RFC3339Nano)
+	if err := s.Set("me/soon", "R0/"+start+"/PT1H"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ref := <-ran:
+		if ref != "me/soon" {
+			t.Errorf("unexpected run of %q", ref)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for scheduled run")
+	}
+}
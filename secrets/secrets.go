@@ -0,0 +1,331 @@
+// Package secrets stores named values like API keys that dataset transforms
+// can read. Transforms can only read the secrets granted to their dataset.
+// Stores are encrypted at rest with a private key, so only the profile that
+// wrote a store can read it
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+var (
+	// ErrNotFound indicates a secret isn't in the store
+	ErrNotFound = fmt.Errorf("secret not found")
+	// ErrNotGranted indicates a secret isn't granted to a dataset
+	ErrNotGranted = fmt.Errorf("secret is not granted to dataset")
+	// ErrInvalidName indicates a secret name isn't valid
+	ErrInvalidName = fmt.Errorf("secret names must start with a letter or underscore & contain only letters, numbers, underscores & dashes")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// ValidName returns an error if name can't be used as a secret name
+func ValidName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Store is a set of named secret values. Store is safe for concurrent use
+type Store struct {
+	pk   crypto.PrivKey
+	path string
+
+	lk     sync.Mutex
+	values map[string]string
+	// grants maps secret names to the datasets that can read them
+	grants map[string][]string
+}
+
+// storeData is the encrypted content of a store file
+type storeData struct {
+	Values map[string]string   `json:"values"`
+	Grants map[string][]string `json:"grants,omitempty"`
+}
+
+// NewStore opens the store at path, encrypting values with a private key.
+// Stores with an empty path only keep values in memory
+func NewStore(pk crypto.PrivKey, path string) (*Store, error) {
+	if pk == nil {
+		return nil, fmt.Errorf("secret store requires a private key")
+	}
+	s := &Store{
+		pk:     pk,
+		path:   path,
+		values: map[string]string{},
+		grants: map[string][]string{},
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the value of a secret
+func (s *Store) Get(name string) (string, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	val, ok := s.values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return val, nil
+}
+
+// Values returns the values of a set of secrets, failing if any are missing
+func (s *Store) Values(names ...string) (map[string]string, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := make(map[string]string, len(names))
+	for _, name := range names {
+		val, ok := s.values[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+		}
+		res[name] = val
+	}
+	return res, nil
+}
+
+// Names lists the names of all secrets in the store in sorted order
+func (s *Store) Names() []string {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set adds a secret to the store, replacing any existing value
+func (s *Store) Set(name, value string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	prev, existed := s.values[name]
+	s.values[name] = value
+	if err := s.save(); err != nil {
+		if existed {
+			s.values[name] = prev
+		} else {
+			delete(s.values, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove drops a secret from the store, along with its grants
+func (s *Store) Remove(name string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	prev, ok := s.values[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	prevGrants, granted := s.grants[name]
+	delete(s.values, name)
+	delete(s.grants, name)
+	if err := s.save(); err != nil {
+		s.values[name] = prev
+		if granted {
+			s.grants[name] = prevGrants
+		}
+		return err
+	}
+	return nil
+}
+
+// Grant allows transforms of a dataset to read a secret. Datasets are
+// identified by a "username/name" reference
+func (s *Store) Grant(name, dataset string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if _, ok := s.values[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	prev := s.grants[name]
+	for _, ds := range prev {
+		if ds == dataset {
+			return nil
+		}
+	}
+	next := append(append([]string{}, prev...), dataset)
+	sort.Strings(next)
+	s.grants[name] = next
+	if err := s.save(); err != nil {
+		s.setGrants(name, prev)
+		return err
+	}
+	return nil
+}
+
+// Revoke stops transforms of a dataset from reading a secret
+func (s *Store) Revoke(name, dataset string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	prev := s.grants[name]
+	next := make([]string, 0, len(prev))
+	for _, ds := range prev {
+		if ds != dataset {
+			next = append(next, ds)
+		}
+	}
+	if len(next) == len(prev) {
+		return fmt.Errorf("%w: %q, %q", ErrNotGranted, name, dataset)
+	}
+	s.setGrants(name, next)
+	if err := s.save(); err != nil {
+		s.setGrants(name, prev)
+		return err
+	}
+	return nil
+}
+
+// Grants lists the datasets a secret is granted to in sorted order
+func (s *Store) Grants(name string) []string {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return append([]string{}, s.grants[name]...)
+}
+
+// Granted returns the values of every secret granted to a dataset
+func (s *Store) Granted(dataset string) map[string]string {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := map[string]string{}
+	for name, datasets := range s.grants {
+		for _, ds := range datasets {
+			if ds == dataset {
+				res[name] = s.values[name]
+				break
+			}
+		}
+	}
+	return res
+}
+
+func (s *Store) setGrants(name string, datasets []string) {
+	if len(datasets) == 0 {
+		delete(s.grants, name)
+		return
+	}
+	s.grants[name] = datasets
+}
+
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading secret store: %w", err)
+	}
+	if data, err = s.decrypt(data); err != nil {
+		return fmt.Errorf("decrypting secret store: %w", err)
+	}
+	sd := storeData{}
+	if err = json.Unmarshal(data, &sd); err != nil {
+		return err
+	}
+	if sd.Values != nil {
+		s.values = sd.Values
+	}
+	if sd.Grants != nil {
+		s.grants = sd.Grants
+	}
+	return nil
+}
+
+// save writes the store to a temp file that replaces the store file, so
+// failed writes don't corrupt existing secrets
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(storeData{Values: s.values, Grants: s.grants})
+	if err != nil {
+		return err
+	}
+	if data, err = s.encrypt(data); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), ".secrets")
+	if err != nil {
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	if err = os.Rename(f.Name(), s.path); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) cipher() (cipher.AEAD, error) {
+	pkBytes, err := s.pk.Raw()
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256(pkBytes)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *Store) encrypt(data []byte) ([]byte, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *Store) decrypt(data []byte) ([]byte, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("invalid ciphertext")
+	}
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testPeers "github.com/qri-io/qri/config/test"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.enc")
+	pk := testPeers.GetTestPeerInfo(0).PrivKey
+
+	s, err := NewStore(pk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("API_KEY", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("token", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("has space", "nope"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("expected an invalid name to return ErrInvalidName. got: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Error("expected secret values to be encrypted at rest")
+	}
+
+	// reopening the store reads values written by the first store
+	s, err = NewStore(pk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"API_KEY", "token"}, s.Names()); diff != "" {
+		t.Errorf("names mismatch (-want +got):\n%s", diff)
+	}
+	vals, err := s.Values("API_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"API_KEY": "hunter2"}, vals); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.Values("API_KEY", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a missing secret to return ErrNotFound. got: %v", err)
+	}
+
+	if err := s.Remove("token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a removed secret to return ErrNotFound. got: %v", err)
+	}
+	if err := s.Remove("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected removing a missing secret to return ErrNotFound. got: %v", err)
+	}
+
+	// other keys can't read the store
+	if _, err := NewStore(testPeers.GetTestPeerInfo(1).PrivKey, path); err == nil {
+		t.Error("expected opening a store with a different key to fail")
+	}
+}
+
+func TestStoreGrants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.enc")
+	pk := testPeers.GetTestPeerInfo(0).PrivKey
+
+	s, err := NewStore(pk, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("API_KEY", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("TOKEN", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Grant("missing", "peer/ds"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected granting a missing secret to return ErrNotFound. got: %v", err)
+	}
+	if err := s.Grant("API_KEY", "peer/ds"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Grant("API_KEY", "peer/other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Grant("TOKEN", "peer/other"); err != nil {
+		t.Fatal(err)
+	}
+
+	// reopening the store reads grants written by the first store
+	if s, err = NewStore(pk, path); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"peer/ds", "peer/other"}, s.Grants("API_KEY")); diff != "" {
+		t.Errorf("grants mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"API_KEY": "hunter2"}, s.Granted("peer/ds")); diff != "" {
+		t.Errorf("granted values mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{}, s.Granted("peer/not_granted")); diff != "" {
+		t.Errorf("expected no secrets for a dataset without grants (-want +got):\n%s", diff)
+	}
+
+	if err := s.Revoke("API_KEY", "peer/ds"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke("API_KEY", "peer/ds"); !errors.Is(err, ErrNotGranted) {
+		t.Errorf("expected revoking a missing grant to return ErrNotGranted. got: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{}, s.Granted("peer/ds")); diff != "" {
+		t.Errorf("expected revoked secret to be dropped (-want +got):\n%s", diff)
+	}
+
+	// removing a secret drops its grants
+	if err := s.Remove("TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("TOKEN", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"API_KEY": "hunter2"}, s.Granted("peer/other")); diff != "" {
+		t.Errorf("expected removed secret grants to be dropped (-want +got):\n%s", diff)
+	}
+}
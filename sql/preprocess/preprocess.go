@@ -282,6 +282,7 @@ func (p *processor) newTableName() string {
 
 func toLegalName(refStr string) string {
 	refStr = strings.Replace(refStr, "@", "_at_", 1)
+	refStr = strings.Replace(refStr, ":", "_", 1)
 	refStr = strings.ReplaceAll(refStr, "/", "_")
 	return strings.ReplaceAll(refStr, "-", "_")
 }
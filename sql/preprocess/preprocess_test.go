@@ -78,6 +78,14 @@ func TestPrepropcess(t *testing.T) {
 			},
 		},
 
+		{
+			"select a.name, b.pop from me/cities a join registry:b5/populations b on a.name = b.name",
+			"select a.name, b.pop from me_cities a join registry_b5_populations b on a.name = b.name",
+			map[string]string{
+				"me_cities":               "me/cities",
+				"registry_b5_populations": "registry:b5/populations",
+			},
+		},
 		{
 			"SELECT (SELECT 1)",
 			"SELECT (SELECT 1)",
@@ -0,0 +1,20 @@
+package sql
+
+import "strings"
+
+// SplitTableSource separates an optional source prefix from a table
+// reference in a query. Sources name the resolver a table is fetched
+// through, and can be "local", "network", "registry", "p2p", or the name of a
+// configured remote:
+//
+//	SplitTableSource("registry:b5/country_codes") // "registry", "b5/country_codes"
+//	SplitTableSource("b5/country_codes")          // "", "b5/country_codes"
+//
+// tables without a source use the default resolver for the query
+func SplitTableSource(table string) (source, ref string) {
+	i := strings.Index(table, ":")
+	if i <= 0 || strings.ContainsAny(table[:i], "/@") {
+		return "", table
+	}
+	return table[:i], table[i+1:]
+}
@@ -44,6 +44,29 @@ func New(r repo.Repo, loadDataset dsref.ParseResolveLoad) *Service {
 
 // Exec runs an SQL query against a given dataset mapping
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
+	var out output.Output
+	switch outFormat {
+	case "table":
+		out = table.NewOutput(w, false)
+	case "table_row_separated":
+		out = table.NewOutput(w, true)
+	case "json":
+		out = jsonoutput.NewOutput(w)
+	case "csv":
+		out = csvoutput.NewOutput(',', w)
+	case "tabbed":
+		out = csvoutput.NewOutput('\t', w)
+	default:
+		err := fmt.Errorf("invalid output type: %s", w)
+		log.Error(err)
+		return err
+	}
+
+	return svc.exec(ctx, out, query)
+}
+
+// exec runs an SQL query, writing results to out
+func (svc *Service) exec(ctx context.Context, out output.Output, query string) error {
 	processedQuery, sources, err := preprocess.Query(query)
 	if err != nil {
 		log.Errorf("mapping query: %s", err)
@@ -77,24 +100,6 @@ func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query stri
 		return err
 	}
 
-	var out output.Output
-	switch outFormat {
-	case "table":
-		out = table.NewOutput(w, false)
-	case "table_row_separated":
-		out = table.NewOutput(w, true)
-	case "json":
-		out = jsonoutput.NewOutput(w)
-	case "csv":
-		out = csvoutput.NewOutput(',', w)
-	case "tabbed":
-		out = csvoutput.NewOutput('\t', w)
-	default:
-		err = fmt.Errorf("invalid output type: %s", w)
-		log.Error(err)
-		return err
-	}
-
 	app := app.NewApp(cfg, dataSourceRepository, out, false)
 
 	// Parse query
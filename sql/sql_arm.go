@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/repo"
 )
@@ -23,3 +24,16 @@ func New(r repo.Repo, loadDataset dsref.ParseResolveLoad) *Service {
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
 	return errors.New("sql command is not available on 32-bit systems")
 }
+
+// StepRunner represents running SQL transform steps
+type StepRunner struct{}
+
+// NewStepRunner returns a new StepRunner
+func NewStepRunner(r repo.Repo, loadDataset dsref.ParseResolveLoad) *StepRunner {
+	return &StepRunner{}
+}
+
+// RunStep fails to execute on 32-bit systems
+func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *dataset.TransformStep) error {
+	return errors.New("sql transform steps are not available on 32-bit systems")
+}
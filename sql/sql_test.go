@@ -7,3 +7,21 @@ import (
 func TestExec(t *testing.T) {
 	t.Skip("TODO (b5): finish test")
 }
+
+func TestSplitTableSource(t *testing.T) {
+	cases := []struct {
+		table, source, ref string
+	}{
+		{"b5/country_codes", "", "b5/country_codes"},
+		{"registry:b5/country_codes", "registry", "b5/country_codes"},
+		{"my_remote:b5/country_codes@/ipfs/QmFoo", "my_remote", "b5/country_codes@/ipfs/QmFoo"},
+		{"b5/country_codes@/ipfs/Qm:Foo", "", "b5/country_codes@/ipfs/Qm:Foo"},
+		{":b5/country_codes", "", ":b5/country_codes"},
+	}
+	for _, c := range cases {
+		source, ref := SplitTableSource(c.table)
+		if source != c.source || ref != c.ref {
+			t.Errorf("%q: expected (%q, %q), got (%q, %q)", c.table, c.source, c.ref, source, ref)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// +build !arm
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cube2222/octosql"
+	"github.com/cube2222/octosql/execution"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+)
+
+// StepRunner runs transform steps written in SQL. The script of a SQL step is
+// a query, the results of which become the body of the dataset being
+// transformed
+type StepRunner struct {
+	svc *Service
+}
+
+// NewStepRunner creates a SQL step runner that loads tables with loadDataset
+func NewStepRunner(r repo.Repo, loadDataset dsref.ParseResolveLoad) *StepRunner {
+	return &StepRunner{svc: New(r, loadDataset)}
+}
+
+// RunStep executes a SQL transform step, replacing the body of ds with query
+// results. The body is written as CSV & the structure is inferred from
+// results
+func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *dataset.TransformStep) error {
+	query, ok := st.Script.(string)
+	if !ok {
+		return fmt.Errorf("sql step Script must be a string. got %T", st.Script)
+	}
+
+	out := &bodyOutput{}
+	if err := r.svc.exec(ctx, out, query); err != nil {
+		return err
+	}
+
+	ds.BodyPath = ""
+	ds.Structure = nil
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.csv", out.buf.Bytes()))
+	return base.InferStructure(ds)
+}
+
+// bodyOutput is an octosql output that writes records as a CSV dataset body.
+// unlike octosql's CSV output values are written raw: strings aren't quoted,
+// and nulls are empty
+type bodyOutput struct {
+	records []*execution.Record
+	buf     bytes.Buffer
+}
+
+func (o *bodyOutput) WriteRecord(record *execution.Record) error {
+	o.records = append(o.records, record)
+	return nil
+}
+
+func (o *bodyOutput) Close() error {
+	var fields []octosql.VariableName
+	seen := map[octosql.VariableName]bool{}
+	for _, record := range o.records {
+		for _, field := range record.Fields() {
+			if !seen[field.Name] {
+				seen[field.Name] = true
+				fields = append(fields, field.Name)
+			}
+		}
+	}
+
+	w := csv.NewWriter(&o.buf)
+	if err := w.Write(columnTitles(fields)); err != nil {
+		return err
+	}
+	for _, record := range o.records {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = rawValueString(record.Value(field))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// columnTitles drops table aliases from field names where the result is
+// unambiguous. "a.name" becomes "name", unless another table also has a
+// "name" field, in which case both become "a_name" and "b_name"
+func columnTitles(fields []octosql.VariableName) []string {
+	counts := map[string]int{}
+	for _, f := range fields {
+		counts[f.Name()]++
+	}
+	titles := make([]string, len(fields))
+	for i, f := range fields {
+		if counts[f.Name()] == 1 {
+			titles[i] = f.Name()
+		} else {
+			titles[i] = strings.ReplaceAll(f.String(), ".", "_")
+		}
+	}
+	return titles
+}
+
+func rawValueString(v octosql.Value) string {
+	switch v.GetType() {
+	case octosql.TypeZero, octosql.TypeNull, octosql.TypePhantom:
+		return ""
+	case octosql.TypeString:
+		return v.AsString()
+	case octosql.TypeTime:
+		return v.AsTime().Format(time.RFC3339Nano)
+	default:
+		return v.Show()
+	}
+}
@@ -0,0 +1,29 @@
+// +build !arm
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cube2222/octosql"
+	"github.com/cube2222/octosql/execution"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBodyOutput(t *testing.T) {
+	fields := []octosql.VariableName{"a.name", "a.id", "b.id", "b.added", "b.note"}
+	added := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := &bodyOutput{}
+	out.WriteRecord(execution.NewRecordFromSlice(fields, []octosql.Value{
+		octosql.MakeString("toronto"), octosql.MakeInt(1), octosql.MakeInt(2), octosql.MakeTime(added), octosql.MakeNull(),
+	}))
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "name,a_id,b_id,added,note\ntoronto,1,2,2020-01-02T03:04:05Z,\n"
+	if diff := cmp.Diff(expect, out.buf.String()); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+}
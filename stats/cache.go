@@ -10,7 +10,9 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/didmod"
@@ -41,6 +43,76 @@ type Cache interface {
 	PutStats(ctx context.Context, key string, sa *dataset.Stats) error
 	// GetStats the stats component for a given key
 	GetStats(ctx context.Context, key string) (sa *dataset.Stats, err error)
+	// Entries lists the contents of the cache
+	Entries(ctx context.Context) ([]CacheEntry, error)
+	// Remove drops cached stats for the given keys. Removing a key that isn't
+	// in the cache is not an error
+	Remove(ctx context.Context, keys ...string) error
+	// Clear drops all cached stats
+	Clear(ctx context.Context) error
+	// Counters reports cache performance since the cache was created
+	Counters() CacheCounters
+}
+
+// CacheEntry describes a single cached stats component
+type CacheEntry struct {
+	// key the stats are stored under, either a dataset path, a dataset body
+	// path, or the path to a body file on the local filesystem
+	Key string `json:"key"`
+	// size of the cached stats, in bytes
+	Size int64 `json:"size"`
+	// Stale is true for entries keyed by a local file that has changed or been
+	// removed since the stats were cached. Stale entries are never returned by
+	// GetStats
+	Stale bool `json:"stale,omitempty"`
+}
+
+// CacheCounters tracks how well a cache is performing
+type CacheCounters struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+// counters is an embeddable, concurrency-safe implementation of cache counting
+type counters struct {
+	hits, misses, evictions int64
+}
+
+func (c *counters) hit()   { atomic.AddInt64(&c.hits, 1) }
+func (c *counters) miss()  { atomic.AddInt64(&c.misses, 1) }
+func (c *counters) evict() { atomic.AddInt64(&c.evictions, 1) }
+
+// Counters reports cache performance
+func (c *counters) Counters() CacheCounters {
+	return CacheCounters{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Evictions: atomic.LoadInt64(&c.evictions),
+	}
+}
+
+// targetProps records the state of keys that are local filepaths, so changes
+// to the file can invalidate cached stats
+func targetProps(key string) didmod.Props {
+	if qfs.PathKind(key) == "local" {
+		props, _ := didmod.NewProps(key)
+		return props
+	}
+	return didmod.Props{}
+}
+
+// isStale returns true if key is a local filepath that no longer matches the
+// recorded file properties
+func isStale(key string, props didmod.Props) bool {
+	if qfs.PathKind(key) != "local" {
+		return false
+	}
+	current, err := didmod.NewProps(key)
+	if err != nil {
+		return true
+	}
+	return !props.Equal(current)
 }
 
 // nilCache is a stand in for not having a cache
@@ -59,8 +131,30 @@ func (nilCache) GetStats(ctx context.Context, key string) (sa *dataset.Stats, er
 	return nil, ErrCacheMiss
 }
 
+// Entries always returns ErrNoCache
+func (nilCache) Entries(ctx context.Context) ([]CacheEntry, error) {
+	return nil, ErrNoCache
+}
+
+// Remove always returns ErrNoCache
+func (nilCache) Remove(ctx context.Context, keys ...string) error {
+	return ErrNoCache
+}
+
+// Clear always returns ErrNoCache
+func (nilCache) Clear(ctx context.Context) error {
+	return ErrNoCache
+}
+
+// Counters always reports zero values
+func (nilCache) Counters() CacheCounters {
+	return CacheCounters{}
+}
+
 // localCache is a stats cache stored in a directory on the local operating system
 type localCache struct {
+	counters
+
 	root    string
 	maxSize int64
 
@@ -133,8 +227,11 @@ func (c *localCache) GetStats(ctx context.Context, key string) (sa *dataset.Stat
 	cacheKey := c.cacheKey(key)
 	log.Debugw("getting stats", "key", key, "cacheKey", cacheKey)
 
+	c.infoLk.Lock()
 	targetFileProps, exists := c.info.TargetFileProps[cacheKey]
+	c.infoLk.Unlock()
 	if !exists {
+		c.miss()
 		return nil, ErrCacheMiss
 	}
 
@@ -144,6 +241,7 @@ func (c *localCache) GetStats(ctx context.Context, key string) (sa *dataset.Stat
 				// note: returning ErrCacheMiss here will probably lead to re-calcualtion
 				// and subsequent overwriting by cache consumers, so we shouldn't need
 				// to proactively drop the stale cache here
+				c.miss()
 				return nil, ErrCacheMiss
 			}
 		}
@@ -151,13 +249,72 @@ func (c *localCache) GetStats(ctx context.Context, key string) (sa *dataset.Stat
 
 	f, err := os.Open(c.componentFilepath(cacheKey))
 	if err != nil {
+		c.miss()
 		return nil, err
 	}
 	defer f.Close()
 
 	sa = &dataset.Stats{}
-	err = json.NewDecoder(f).Decode(sa)
-	return sa, err
+	if err = json.NewDecoder(f).Decode(sa); err != nil {
+		c.miss()
+		return nil, err
+	}
+	c.hit()
+	return sa, nil
+}
+
+// Entries lists the contents of the cache
+func (c *localCache) Entries(ctx context.Context) ([]CacheEntry, error) {
+	c.infoLk.Lock()
+	defer c.infoLk.Unlock()
+
+	entries := make([]CacheEntry, 0, len(c.info.StatFileProps))
+	for cacheKey, props := range c.info.StatFileProps {
+		key, err := b32Enc.DecodeString(cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid key %q", ErrCacheCorrupt, cacheKey)
+		}
+		entries = append(entries, CacheEntry{
+			Key:   string(key),
+			Size:  props.Size,
+			Stale: isStale(string(key), c.info.TargetFileProps[cacheKey]),
+		})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Remove drops cached stats for the given keys
+func (c *localCache) Remove(ctx context.Context, keys ...string) error {
+	c.infoLk.Lock()
+	for _, key := range keys {
+		cacheKey := c.cacheKey(key)
+		if _, ok := c.info.StatFileProps[cacheKey]; !ok {
+			continue
+		}
+		if err := os.Remove(c.componentFilepath(cacheKey)); err != nil && !os.IsNotExist(err) {
+			c.infoLk.Unlock()
+			return err
+		}
+		delete(c.info.StatFileProps, cacheKey)
+		delete(c.info.TargetFileProps, cacheKey)
+	}
+	c.infoLk.Unlock()
+	return c.writeCacheInfo()
+}
+
+// Clear drops all cached stats
+func (c *localCache) Clear(ctx context.Context) error {
+	c.infoLk.Lock()
+	for cacheKey := range c.info.StatFileProps {
+		if err := os.Remove(c.componentFilepath(cacheKey)); err != nil && !os.IsNotExist(err) {
+			c.infoLk.Unlock()
+			return err
+		}
+	}
+	c.info = newCacheInfo()
+	c.infoLk.Unlock()
+	return c.writeCacheInfo()
 }
 
 var b32Enc = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
@@ -187,8 +344,9 @@ func (c *localCache) addAndPurgeExpired(cacheKey string, statProps, targetProps
 		lowestModTime = math.MaxInt64
 
 		for key, fileProps := range c.info.StatFileProps {
-			if fileProps.Mtime.Unix() < lowestModTime && key != cacheKey {
+			if fileProps.Mtime.UnixNano() < lowestModTime && key != cacheKey {
 				lowestKey = key
+				lowestModTime = fileProps.Mtime.UnixNano()
 			}
 		}
 		if lowestKey == "" {
@@ -200,6 +358,7 @@ func (c *localCache) addAndPurgeExpired(cacheKey string, statProps, targetProps
 		}
 		delete(c.info.StatFileProps, lowestKey)
 		delete(c.info.TargetFileProps, lowestKey)
+		c.evict()
 	}
 }
 
@@ -261,3 +420,7 @@ func (c *localCache) writeCacheInfo() error {
 
 	return ioutil.WriteFile(name, data, 0644)
 }
+
+func sortEntries(entries []CacheEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+}
@@ -119,3 +119,153 @@ func TestLocalCache(t *testing.T) {
 		t.Errorf("expected local cached stats to return ErrCacheMiss after local path file permissions change. got error: %q", err)
 	}
 }
+
+func TestMemCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemCache(100)
+
+	statsA := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{"a"}}
+	statsB := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{"b"}}
+	if err := cache.PutStats(ctx, "/mem/statsA", statsA); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.PutStats(ctx, "/mem/statsB", statsB); err != nil {
+		t.Fatal(err)
+	}
+
+	// reading statsA makes statsB the least recently used entry
+	got, err := cache.GetStats(ctx, "/mem/statsA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(statsA, got); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+
+	statsC := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{strings.Repeat("c", 40)}}
+	if err := cache.PutStats(ctx, "/mem/statsC", statsC); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetStats(ctx, "/mem/statsB"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected least recently used entry to be evicted. got error: %v", err)
+	}
+	if _, err := cache.GetStats(ctx, "/mem/statsA"); err != nil {
+		t.Errorf("expected recently used entry to remain cached. got error: %v", err)
+	}
+
+	expectCounters := CacheCounters{Hits: 2, Misses: 1, Evictions: 1}
+	if diff := cmp.Diff(expectCounters, cache.Counters()); diff != "" {
+		t.Errorf("counters mismatch (-want +got):\n%s", diff)
+	}
+
+	entries, err := cache.Entries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key != "/mem/statsA" || entries[1].Key != "/mem/statsC" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+
+	if err := cache.Remove(ctx, "/mem/statsA", "/mem/unknown"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ = cache.Entries(ctx); len(entries) != 0 {
+		t.Errorf("expected no entries after clear. got: %v", entries)
+	}
+}
+
+func TestContentCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "test_content_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	ctx := context.Background()
+	// two caches pointed at the same directory share entries
+	a, err := NewContentCache(tmp, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewContentCache(tmp, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statsA := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{map[string]interface{}{"type": "numeric"}}}
+	if err := a.PutStats(ctx, "/ipfs/QmBody", statsA); err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.GetStats(ctx, "/ipfs/QmBody")
+	if err != nil {
+		t.Fatalf("expected stats put in one cache to be readable from another sharing a directory. got: %v", err)
+	}
+	if diff := cmp.Diff(statsA, got); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+
+	f, err := ioutil.TempFile("", "stats_content_cache_local_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	if err := a.PutStats(ctx, path, statsA); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0621); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetStats(ctx, path); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected changed local file to miss. got: %v", err)
+	}
+
+	svc := New(a)
+	removed, err := svc.PruneCache(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Key != path {
+		t.Errorf("expected prune to remove stale local entry. got: %v", removed)
+	}
+
+	ds := &dataset.Dataset{Path: "/ipfs/QmVersion", BodyPath: "/ipfs/QmBody"}
+	key, err := svc.CacheKey(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "/ipfs/QmBody" {
+		t.Errorf("expected content cache to key by body path. got: %q", key)
+	}
+
+	small, err := NewContentCache(tmp, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statsB := &dataset.Stats{Qri: dataset.KindStats.String(), Stats: []interface{}{"b"}}
+	if err := small.PutStats(ctx, "/ipfs/QmOtherBody", statsB); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := small.GetStats(ctx, "/ipfs/QmBody"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected oldest entry to be evicted. got: %v", err)
+	}
+	if c := small.Counters(); c.Evictions != 1 {
+		t.Errorf("expected 1 eviction. got: %d", c.Evictions)
+	}
+
+	if err := a.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := b.Entries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries after clear. got: %v", entries)
+	}
+}
@@ -0,0 +1,236 @@
+package stats
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/didmod"
+)
+
+// contentCache is a stats cache keyed by the content of a dataset body instead
+// of a dataset version. Versions that share a body share stats, and because
+// the cache keeps no index file, a single directory can be shared by
+// multiple qri repos
+//
+// each entry is a standalone file named by the hash of its key. Entries are
+// written to a temp file and renamed into place so concurrent writers never
+// see partial data. Reading an entry updates it's modification time, which
+// the cache uses to evict the least recently used entries
+type contentCache struct {
+	counters
+
+	root    string
+	maxSize int64
+}
+
+// contentEntry is the on-disk format of a content cache entry
+type contentEntry struct {
+	Key         string         `json:"key"`
+	TargetProps didmod.Props   `json:"targetProps"`
+	Stats       *dataset.Stats `json:"stats"`
+}
+
+var (
+	_ Cache     = (*contentCache)(nil)
+	_ bodyKeyed = (*contentCache)(nil)
+)
+
+// bodyKeyed is implemented by caches that want stats keyed by body path
+type bodyKeyed interface {
+	keyByBody()
+}
+
+// NewContentCache creates a content-addressed stats cache in a local
+// directory. The stats service keys content caches by body path rather than
+// dataset path
+func NewContentCache(rootDir string, maxSize int64) (Cache, error) {
+	if err := os.MkdirAll(rootDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &contentCache{
+		root:    rootDir,
+		maxSize: maxSize,
+	}, nil
+}
+
+func (c *contentCache) keyByBody() {}
+
+// PutStats places stats in the cache, keyed by body path
+func (c *contentCache) PutStats(ctx context.Context, key string, sa *dataset.Stats) error {
+	data, err := json.Marshal(contentEntry{
+		Key:         key,
+		TargetProps: targetProps(key),
+		Stats:       sa,
+	})
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.maxSize {
+		return fmt.Errorf("stats component size exceeds maximum size of cache")
+	}
+
+	f, err := ioutil.TempFile(c.root, ".put-*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err = os.Rename(f.Name(), c.entryFilepath(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return c.purge(key)
+}
+
+// GetStats gets cached stats for a body path
+func (c *contentCache) GetStats(ctx context.Context, key string) (*dataset.Stats, error) {
+	path := c.entryFilepath(key)
+	ent, err := c.readEntry(path)
+	if err != nil || ent.Key != key || isStale(key, ent.TargetProps) {
+		c.miss()
+		return nil, ErrCacheMiss
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		log.Debugw("touching content cache entry", "key", key, "err", err)
+	}
+	c.hit()
+	return ent.Stats, nil
+}
+
+// Entries lists the contents of the cache
+func (c *contentCache) Entries(ctx context.Context) ([]CacheEntry, error) {
+	infos, err := c.entryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CacheEntry, 0, len(infos))
+	for _, fi := range infos {
+		ent, err := c.readEntry(filepath.Join(c.root, fi.Name()))
+		if err != nil {
+			// another process may have evicted this entry after listing
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, CacheEntry{
+			Key:   ent.Key,
+			Size:  fi.Size(),
+			Stale: isStale(ent.Key, ent.TargetProps),
+		})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Remove drops cached stats for the given keys
+func (c *contentCache) Remove(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := os.Remove(c.entryFilepath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear drops all cached stats
+func (c *contentCache) Clear(ctx context.Context) error {
+	infos, err := c.entryFiles()
+	if err != nil {
+		return err
+	}
+	for _, fi := range infos {
+		if err := os.Remove(filepath.Join(c.root, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// purge drops least recently used entries until the cache is under it's max
+// size, never dropping the entry for the given key
+func (c *contentCache) purge(key string) error {
+	infos, err := c.entryFiles()
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, fi := range infos {
+		size += fi.Size()
+	}
+	if size <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	keep := filepath.Base(c.entryFilepath(key))
+	for _, fi := range infos {
+		if size <= c.maxSize {
+			break
+		}
+		if fi.Name() == keep {
+			continue
+		}
+		log.Debugw("dropping stats component from content cache", "file", fi.Name(), "size", fi.Size())
+		if err := os.Remove(filepath.Join(c.root, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= fi.Size()
+		c.evict()
+	}
+	return nil
+}
+
+func (c *contentCache) entryFilepath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.root, hex.EncodeToString(sum[:])+".json")
+}
+
+// entryFiles lists entry files, skipping in-progress writes
+func (c *contentCache) entryFiles() ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return nil, err
+	}
+	res := infos[:0]
+	for _, fi := range infos {
+		if !fi.IsDir() && strings.HasSuffix(fi.Name(), ".json") && !strings.HasPrefix(fi.Name(), ".") {
+			res = append(res, fi)
+		}
+	}
+	return res, nil
+}
+
+func (c *contentCache) readEntry(path string) (*contentEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ent := &contentEntry{}
+	if err := json.Unmarshal(data, ent); err != nil {
+		return nil, fmt.Errorf("%w: decoding %s: %s", ErrCacheCorrupt, filepath.Base(path), err)
+	}
+	return ent, nil
+}
@@ -0,0 +1,472 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dsstats"
+)
+
+// Mode selects how stats are calculated
+type Mode string
+
+const (
+	// ModeExact reads every entry in a body. Exact mode is the default
+	ModeExact = Mode("exact")
+	// ModeEstimate streams a body through fixed-size sketches, trading
+	// precision for memory use that doesn't grow with the size of the body
+	ModeEstimate = Mode("estimate")
+)
+
+// ParseMode interprets a string as a stats mode. The empty string is
+// ModeExact
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeExact:
+		return ModeExact, nil
+	case ModeEstimate:
+		return ModeEstimate, nil
+	default:
+		return "", fmt.Errorf("invalid stats mode %q. must be one of [exact,estimate]", s)
+	}
+}
+
+var (
+	// ReservoirSize is the number of values estimated string stats sample to
+	// report value frequencies
+	ReservoirSize = 10000
+	// EstimateHistogramBins is the number of bins in estimated numeric
+	// histograms
+	EstimateHistogramBins = 20
+	// estimateQuantiles are the quantiles reported by estimated numeric stats
+	estimateQuantiles = []float64{0.01, 0.05, 0.25, 0.5, 0.75, 0.95, 0.99}
+)
+
+// IsEstimate returns true if a stats component was calculated in estimate
+// mode
+func IsEstimate(sa *dataset.Stats) bool {
+	if sa == nil {
+		return false
+	}
+	switch stats := sa.Stats.(type) {
+	case []map[string]interface{}:
+		return len(stats) > 0 && stats[0]["estimate"] == true
+	case []interface{}:
+		if len(stats) > 0 {
+			if m, ok := stats[0].(map[string]interface{}); ok {
+				return m["estimate"] == true
+			}
+		}
+	}
+	return false
+}
+
+// estimator calculates approximate stats for a stream of entries. Estimated
+// stats have the same shape as exact stats, with these differences:
+//   - every stat has an "estimate": true field
+//   - numeric medians, histograms & quantiles come from a t-digest
+//   - numeric & string unique counts come from a HyperLogLog sketch
+//   - string frequencies are scaled up from a reservoir sample
+//
+// counts, mins, maxes & means are exact in both modes
+type estimator struct {
+	st  *dataset.Structure
+	acc estAcc
+	// a single random source keeps sampling deterministic for the same body
+	rnd *rand.Rand
+}
+
+var (
+	_ dsio.EntryWriter = (*estimator)(nil)
+	_ dsstats.Statser  = (*estimator)(nil)
+)
+
+func newEstimator(st *dataset.Structure) *estimator {
+	return &estimator{st: st, rnd: rand.New(rand.NewSource(1))}
+}
+
+// Structure gives the structure being estimated
+func (e *estimator) Structure() *dataset.Structure {
+	return e.st
+}
+
+// WriteEntry adds one row of structured data to estimated stats
+func (e *estimator) WriteEntry(ent dsio.Entry) error {
+	if e.acc == nil {
+		e.acc = newEstAcc(ent.Value, e.rnd)
+	}
+	e.acc.write(ent.Value)
+	return nil
+}
+
+// Close finalizes the estimator. sketches are read lazily, so Close is a no-op
+func (e *estimator) Close() error {
+	return nil
+}
+
+// Stats returns estimated stats
+func (e *estimator) Stats() []dsstats.Stat {
+	if e.acc == nil {
+		return nil
+	}
+	if s, ok := e.acc.(dsstats.Statser); ok {
+		return s.Stats()
+	}
+	return []dsstats.Stat{e.acc}
+}
+
+// estAcc is the internal interface for estimated stat accumulators
+type estAcc interface {
+	dsstats.Stat
+	write(v interface{})
+}
+
+func newEstAcc(v interface{}, rnd *rand.Rand) estAcc {
+	switch v.(type) {
+	case float64, float32:
+		return newEstNumericAcc("number")
+	case int, int32, int64:
+		return newEstNumericAcc("integer")
+	case string:
+		return newEstStringAcc(rnd)
+	case bool:
+		return &estBoolAcc{}
+	case map[string]interface{}:
+		return &estObjectAcc{children: map[string]estAcc{}, rnd: rnd}
+	case []interface{}:
+		return &estArrayAcc{rnd: rnd}
+	default:
+		return &estNullAcc{}
+	}
+}
+
+type estObjectAcc struct {
+	children map[string]estAcc
+	rnd      *rand.Rand
+}
+
+// Stats returns child stats, ordered by key
+func (acc *estObjectAcc) Stats() []dsstats.Stat {
+	keys := make([]string, 0, len(acc.children))
+	for key := range acc.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	stats := make([]dsstats.Stat, len(keys))
+	for i, key := range keys {
+		stats[i] = keyedEstStat{Stat: acc.children[key], key: key}
+	}
+	return stats
+}
+
+func (acc *estObjectAcc) Type() string { return "object" }
+
+func (acc *estObjectAcc) write(v interface{}) {
+	if obj, ok := v.(map[string]interface{}); ok {
+		for key, val := range obj {
+			if _, ok := acc.children[key]; !ok {
+				acc.children[key] = newEstAcc(val, acc.rnd)
+			}
+			acc.children[key].write(val)
+		}
+	}
+}
+
+func (acc *estObjectAcc) Map() map[string]interface{} {
+	vals := map[string]interface{}{}
+	for key, val := range acc.children {
+		vals[key] = val.Map()
+	}
+	return vals
+}
+
+type estArrayAcc struct {
+	children []estAcc
+	rnd      *rand.Rand
+}
+
+// Stats returns child stats, ordered by index
+func (acc *estArrayAcc) Stats() []dsstats.Stat {
+	stats := make([]dsstats.Stat, len(acc.children))
+	for i, ch := range acc.children {
+		stats[i] = ch
+	}
+	return stats
+}
+
+func (acc *estArrayAcc) Type() string { return "array" }
+
+func (acc *estArrayAcc) write(v interface{}) {
+	if arr, ok := v.([]interface{}); ok {
+		for i, val := range arr {
+			if len(acc.children) == i {
+				acc.children = append(acc.children, newEstAcc(val, acc.rnd))
+			}
+			acc.children[i].write(val)
+		}
+	}
+}
+
+func (acc *estArrayAcc) Map() map[string]interface{} {
+	vals := make([]map[string]interface{}, len(acc.children))
+	for i, val := range acc.children {
+		vals[i] = val.Map()
+	}
+	return map[string]interface{}{"values": vals, "estimate": true}
+}
+
+type estNumericAcc struct {
+	typ      string
+	count    int
+	sum      float64
+	min, max float64
+	digest   *tdigest
+	hll      *hyperloglog.Sketch
+}
+
+func newEstNumericAcc(typ string) *estNumericAcc {
+	return &estNumericAcc{
+		typ:    typ,
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+		digest: newTDigest(defaultCompression),
+		hll:    hyperloglog.New16(),
+	}
+}
+
+func (acc *estNumericAcc) Type() string { return "numeric" }
+
+func (acc *estNumericAcc) write(v interface{}) {
+	var f float64
+	switch x := v.(type) {
+	case int:
+		f = float64(x)
+	case int32:
+		f = float64(x)
+	case int64:
+		f = float64(x)
+	case float32:
+		f = float64(x)
+	case float64:
+		f = x
+	default:
+		return
+	}
+
+	acc.count++
+	acc.sum += f
+	if f < acc.min {
+		acc.min = f
+	}
+	if f > acc.max {
+		acc.max = f
+	}
+	acc.digest.add(f)
+	acc.hll.Insert([]byte(strconv.FormatFloat(f, 'g', -1, 64)))
+}
+
+func (acc *estNumericAcc) Map() map[string]interface{} {
+	if acc.count == 0 {
+		return map[string]interface{}{"count": 0, "estimate": true}
+	}
+
+	quantiles := map[string]float64{}
+	for _, q := range estimateQuantiles {
+		quantiles["p"+strconv.FormatFloat(q*100, 'f', -1, 64)] = acc.digest.quantile(q)
+	}
+
+	return map[string]interface{}{
+		"estimate":  true,
+		"count":     acc.count,
+		"min":       acc.min,
+		"max":       acc.max,
+		"mean":      acc.sum / float64(acc.count),
+		"median":    acc.digest.quantile(0.5),
+		"unique":    int(acc.hll.Estimate()),
+		"quantiles": quantiles,
+		"histogram": acc.histogram(),
+	}
+}
+
+// histogram divides the range of values into equal-width bins, estimating
+// bin frequencies from the digest
+func (acc *estNumericAcc) histogram() map[string][]float64 {
+	if acc.min == acc.max {
+		return map[string][]float64{
+			"bins":        {acc.min, acc.max},
+			"frequencies": {float64(acc.count)},
+		}
+	}
+
+	n := EstimateHistogramBins
+	width := (acc.max - acc.min) / float64(n)
+	bins := make([]float64, n+1)
+	freqs := make([]float64, n)
+	prev := 0.0
+	for i := range bins {
+		bins[i] = acc.min + width*float64(i)
+		if i == 0 {
+			continue
+		}
+		cdf := 1.0
+		if i < n {
+			cdf = acc.digest.cdf(bins[i])
+		}
+		freqs[i-1] = math.Round((cdf - prev) * float64(acc.count))
+		prev = cdf
+	}
+	return map[string][]float64{
+		"bins":        bins,
+		"frequencies": freqs,
+	}
+}
+
+type estStringAcc struct {
+	count     int
+	minLength int
+	maxLength int
+	hll       *hyperloglog.Sketch
+	sample    []string
+	rnd       *rand.Rand
+}
+
+func newEstStringAcc(rnd *rand.Rand) *estStringAcc {
+	return &estStringAcc{
+		minLength: math.MaxInt32,
+		hll:       hyperloglog.New16(),
+		rnd:       rnd,
+	}
+}
+
+func (acc *estStringAcc) Type() string { return "string" }
+
+func (acc *estStringAcc) write(v interface{}) {
+	str, ok := v.(string)
+	if !ok {
+		return
+	}
+	acc.count++
+	if len(str) < acc.minLength {
+		acc.minLength = len(str)
+	}
+	if len(str) > acc.maxLength {
+		acc.maxLength = len(str)
+	}
+	acc.hll.Insert([]byte(str))
+
+	// reservoir sampling: keep each value with probability size/count
+	if len(acc.sample) < ReservoirSize {
+		acc.sample = append(acc.sample, str)
+	} else if i := acc.rnd.Intn(acc.count); i < ReservoirSize {
+		acc.sample[i] = str
+	}
+}
+
+func (acc *estStringAcc) Map() map[string]interface{} {
+	if acc.count == 0 {
+		return map[string]interface{}{"count": 0, "estimate": true}
+	}
+	return map[string]interface{}{
+		"estimate":    true,
+		"count":       acc.count,
+		"minLength":   acc.minLength,
+		"maxLength":   acc.maxLength,
+		"unique":      int(acc.hll.Estimate()),
+		"sampleSize":  len(acc.sample),
+		"frequencies": acc.frequencies(),
+	}
+}
+
+// frequencies scales value counts in the sample up to the full count,
+// keeping the most frequent values
+func (acc *estStringAcc) frequencies() map[string]int {
+	counts := map[string]int{}
+	for _, s := range acc.sample {
+		counts[s]++
+	}
+
+	type freq struct {
+		val   string
+		count int
+	}
+	freqs := make([]freq, 0, len(counts))
+	for val, count := range counts {
+		freqs = append(freqs, freq{val, count})
+	}
+	sort.Slice(freqs, func(i, j int) bool {
+		if freqs[i].count == freqs[j].count {
+			return freqs[i].val < freqs[j].val
+		}
+		return freqs[i].count > freqs[j].count
+	})
+	if len(freqs) > dsstats.StopFreqCountThreshold {
+		freqs = freqs[:dsstats.StopFreqCountThreshold]
+	}
+
+	scale := float64(acc.count) / float64(len(acc.sample))
+	res := make(map[string]int, len(freqs))
+	for _, f := range freqs {
+		res[f.val] = int(math.Round(float64(f.count) * scale))
+	}
+	return res
+}
+
+type estBoolAcc struct {
+	count, trueCount, falseCount int
+}
+
+func (acc *estBoolAcc) Type() string { return "boolean" }
+
+func (acc *estBoolAcc) write(v interface{}) {
+	if b, ok := v.(bool); ok {
+		acc.count++
+		if b {
+			acc.trueCount++
+		} else {
+			acc.falseCount++
+		}
+	}
+}
+
+func (acc *estBoolAcc) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"estimate":   true,
+		"count":      acc.count,
+		"trueCount":  acc.trueCount,
+		"falseCount": acc.falseCount,
+	}
+}
+
+type estNullAcc struct {
+	count int
+}
+
+func (acc *estNullAcc) Type() string { return "null" }
+
+func (acc *estNullAcc) write(v interface{}) {
+	if v == nil {
+		acc.count++
+	}
+}
+
+func (acc *estNullAcc) Map() map[string]interface{} {
+	return map[string]interface{}{"count": acc.count, "estimate": true}
+}
+
+type keyedEstStat struct {
+	dsstats.Stat
+	key string
+}
+
+// Map adds the key the stat belongs to
+func (ks keyedEstStat) Map() map[string]interface{} {
+	v := ks.Stat.Map()
+	v["key"] = ks.key
+	return v
+}
@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/dsstats"
+)
+
+func TestTDigest(t *testing.T) {
+	td := newTDigest(defaultCompression)
+	for i := 1; i <= 100000; i++ {
+		td.add(float64(i))
+	}
+
+	cases := []struct {
+		q, expect float64
+	}{
+		{0, 1},
+		{0.01, 1000},
+		{0.5, 50000},
+		{0.99, 99000},
+		{1, 100000},
+	}
+	for _, c := range cases {
+		got := td.quantile(c.q)
+		if math.Abs(got-c.expect)/c.expect > 0.01 {
+			t.Errorf("quantile %v: expected %v within 1%%, got %v", c.q, c.expect, got)
+		}
+	}
+
+	if got := td.cdf(25000); math.Abs(got-0.25) > 0.01 {
+		t.Errorf("expected cdf(25000) to be about 0.25, got %v", got)
+	}
+	if n := len(td.centroids); n > defaultCompression {
+		t.Errorf("expected digest to stay bounded, got %d centroids", n)
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	est := newEstimator(&dataset.Structure{Format: "csv"})
+	n := 50000
+	for i := 0; i < n; i++ {
+		city := fmt.Sprintf("city_%d", i%100)
+		if i%2 == 0 {
+			city = "toronto"
+		}
+		row := []interface{}{city, int64(i), i%4 == 0, nil}
+		if err := est.WriteEntry(dsio.Entry{Index: i, Value: row}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := est.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := dsstats.ToMap(est)
+	if len(stats) != 4 {
+		t.Fatalf("expected 4 column stats, got %d", len(stats))
+	}
+	for i, st := range stats {
+		if st["estimate"] != true {
+			t.Errorf("column %d: expected stats to be marked as an estimate", i)
+		}
+	}
+	if !IsEstimate(&dataset.Stats{Stats: stats}) {
+		t.Error("expected IsEstimate to detect estimated stats")
+	}
+
+	str := stats[0]
+	if str["type"] != "string" || str["count"] != n {
+		t.Errorf("unexpected string stats: %v", str)
+	}
+	if unique := str["unique"].(int); math.Abs(float64(unique-51)) > 2 {
+		t.Errorf("expected about 51 unique strings, got %d", unique)
+	}
+	freq := str["frequencies"].(map[string]int)["toronto"]
+	if math.Abs(float64(freq-n/2))/float64(n/2) > 0.05 {
+		t.Errorf("expected toronto frequency near %d, got %d", n/2, freq)
+	}
+
+	num := stats[1]
+	if num["type"] != "numeric" || num["count"] != n || num["min"] != float64(0) || num["max"] != float64(n-1) {
+		t.Errorf("unexpected numeric stats: %v", num)
+	}
+	if median := num["median"].(float64); math.Abs(median-float64(n)/2)/float64(n) > 0.01 {
+		t.Errorf("expected median near %d, got %v", n/2, median)
+	}
+	hist := num["histogram"].(map[string][]float64)
+	var total float64
+	for _, f := range hist["frequencies"] {
+		total += f
+	}
+	if len(hist["bins"]) != EstimateHistogramBins+1 || math.Abs(total-float64(n)) > float64(EstimateHistogramBins) {
+		t.Errorf("unexpected histogram. bins: %d, total frequency: %v", len(hist["bins"]), total)
+	}
+
+	if b := stats[2]; b["type"] != "boolean" || b["trueCount"] != n/4 {
+		t.Errorf("unexpected boolean stats: %v", b)
+	}
+	if null := stats[3]; null["type"] != "null" || null["count"] != n {
+		t.Errorf("unexpected null stats: %v", null)
+	}
+
+	if IsEstimate(&dataset.Stats{Stats: []interface{}{map[string]interface{}{"count": 1}}}) {
+		t.Error("expected exact stats not to be detected as an estimate")
+	}
+}
@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/didmod"
+)
+
+// memCache is an in-memory stats cache that evicts the least recently used
+// entries once the cache exceeds its max size
+type memCache struct {
+	counters
+
+	maxSize int64
+
+	lk    sync.Mutex
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+// memEntry is an item in the LRU list. stats are stored as encoded JSON so
+// cached values can't be mutated by callers, and size is measured the same
+// way as other caches
+type memEntry struct {
+	key   string
+	data  []byte
+	props didmod.Props
+}
+
+var _ Cache = (*memCache)(nil)
+
+// NewMemCache creates an in-memory stats cache that holds up to maxSize bytes
+// of encoded stats. When the cache is full the least recently used stats are
+// dropped first. Keys that are local filepaths are checked for changes the
+// same way the local cache does
+func NewMemCache(maxSize int64) Cache {
+	return &memCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		items:   map[string]*list.Element{},
+	}
+}
+
+// PutStats places stats in the cache, keyed by path
+func (c *memCache) PutStats(ctx context.Context, key string, sa *dataset.Stats) error {
+	data, err := json.Marshal(sa)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.maxSize {
+		return ErrCacheMiss
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.size -= int64(len(el.Value.(*memEntry).data))
+		c.order.Remove(el)
+	}
+	c.items[key] = c.order.PushFront(&memEntry{key: key, data: data, props: targetProps(key)})
+	c.size += int64(len(data))
+
+	for c.size > c.maxSize {
+		el := c.order.Back()
+		if el == nil {
+			break
+		}
+		ent := el.Value.(*memEntry)
+		log.Debugw("dropping stats component from memory cache", "key", ent.key, "size", len(ent.data))
+		c.removeElement(el)
+		c.evict()
+	}
+	return nil
+}
+
+// GetStats gets cached stats for a key
+func (c *memCache) GetStats(ctx context.Context, key string) (*dataset.Stats, error) {
+	c.lk.Lock()
+	el, ok := c.items[key]
+	if !ok || isStale(key, el.Value.(*memEntry).props) {
+		c.lk.Unlock()
+		c.miss()
+		return nil, ErrCacheMiss
+	}
+	c.order.MoveToFront(el)
+	data := el.Value.(*memEntry).data
+	c.lk.Unlock()
+
+	sa := &dataset.Stats{}
+	if err := json.Unmarshal(data, sa); err != nil {
+		c.miss()
+		return nil, err
+	}
+	c.hit()
+	return sa, nil
+}
+
+// Entries lists the contents of the cache
+func (c *memCache) Entries(ctx context.Context) ([]CacheEntry, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	entries := make([]CacheEntry, 0, len(c.items))
+	for key, el := range c.items {
+		ent := el.Value.(*memEntry)
+		entries = append(entries, CacheEntry{
+			Key:   key,
+			Size:  int64(len(ent.data)),
+			Stale: isStale(key, ent.props),
+		})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Remove drops cached stats for the given keys
+func (c *memCache) Remove(ctx context.Context, keys ...string) error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+	return nil
+}
+
+// Clear drops all cached stats
+func (c *memCache) Clear(ctx context.Context) error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.size = 0
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+	return nil
+}
+
+// removeElement drops an entry. the cache lock must be held
+func (c *memCache) removeElement(el *list.Element) {
+	ent := el.Value.(*memEntry)
+	c.order.Remove(el)
+	delete(c.items, ent.key)
+	c.size -= int64(len(ent.data))
+}
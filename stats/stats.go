@@ -7,7 +7,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"path/filepath"
 
 	logger "github.com/ipfs/go-log"
@@ -40,16 +39,42 @@ func New(cache Cache) *Service {
 // Stats gets the stats component for a dataset, possibly calculating
 // by consuming the open dataset body file
 func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stats, error) {
-	if ds.Stats != nil {
+	return s.calculate(ctx, ds, ModeExact)
+}
+
+// Estimate gets an approximate stats component for a dataset, streaming the
+// open dataset body file through fixed-size sketches. Estimate uses memory
+// that doesn't grow with the size of the body, and returns cached exact stats
+// when they're available
+func (s *Service) Estimate(ctx context.Context, ds *dataset.Dataset) (*dataset.Stats, error) {
+	return s.calculate(ctx, ds, ModeEstimate)
+}
+
+// StatsMode gets stats for a dataset, calculated with the given mode
+func (s *Service) StatsMode(ctx context.Context, ds *dataset.Dataset, mode Mode) (*dataset.Stats, error) {
+	switch mode {
+	case ModeEstimate:
+		return s.Estimate(ctx, ds)
+	case ModeExact, "":
+		return s.Stats(ctx, ds)
+	default:
+		return nil, fmt.Errorf("invalid stats mode %q", mode)
+	}
+}
+
+func (s *Service) calculate(ctx context.Context, ds *dataset.Dataset, mode Mode) (*dataset.Stats, error) {
+	if ds.Stats != nil && (mode == ModeEstimate || !IsEstimate(ds.Stats)) {
 		return ds.Stats, nil
 	}
 
-	key, err := s.cacheKey(ds)
+	key, err := s.CacheKey(ds)
 	if err != nil {
 		return nil, err
 	}
 
-	if sa, err := s.cache.GetStats(ctx, key); err == nil {
+	// estimates & exact stats share a cache key. exact stats can stand in for
+	// an estimate, but not the other way around
+	if sa, err := s.cache.GetStats(ctx, key); err == nil && (mode == ModeEstimate || !IsEstimate(sa)) {
 		log.Debugw("found cached stats", "key", key)
 		return sa, nil
 	}
@@ -62,23 +87,19 @@ func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stat
 	if ds.Structure == nil || ds.Structure.IsEmpty() {
 		log.Debugw("inferring structure to calculate stats")
 		ds.Structure = &dataset.Structure{}
-		data, err := ioutil.ReadAll(ds.BodyFile())
-		if err != nil {
-			panic(err)
-		}
-		log.Debugw("data", "data", string(data))
+		ds.Structure.Format = filepath.Ext(body.FileName())
+		// keep only the bytes schema detection reads, instead of the entire
+		// body
 		buf := &bytes.Buffer{}
-		tr := io.TeeReader(bytes.NewBuffer(data), buf)
-		ds.Structure.Format = filepath.Ext(ds.BodyFile().FileName())
-		ds.Structure.Schema, _, err = detect.Schema(ds.Structure, tr)
+		ds.Structure.Schema, _, err = detect.Schema(ds.Structure, io.TeeReader(body, buf))
 		if err != nil {
 			log.Debugw("error inferring schema", "error", err)
 			return nil, fmt.Errorf("couldn't infer schema: %w", err)
 		}
 
 		// glue read bytes back onto reader
-		mr := io.MultiReader(buf, ds.BodyFile())
-		ds.SetBodyFile(qfs.NewMemfileReader(ds.BodyFile().FullPath(), mr))
+		mr := io.MultiReader(buf, body)
+		ds.SetBodyFile(qfs.NewMemfileReader(body.FullPath(), mr))
 	}
 
 	rdr, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
@@ -86,8 +107,19 @@ func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stat
 		return nil, err
 	}
 
-	acc := dsstats.NewAccumulator(ds.Structure)
+	var acc interface {
+		dsio.EntryWriter
+		dsstats.Statser
+	}
+	if mode == ModeEstimate {
+		acc = newEstimator(ds.Structure)
+	} else {
+		acc = dsstats.NewAccumulator(ds.Structure)
+	}
 	err = dsio.EachEntry(rdr, func(i int, ent dsio.Entry, e error) error {
+		if e != nil {
+			return e
+		}
 		return acc.WriteEntry(ent)
 	})
 	if err != nil {
@@ -109,7 +141,73 @@ func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stat
 	return sa, nil
 }
 
-func (s *Service) cacheKey(ds *dataset.Dataset) (string, error) {
+// CacheEntries lists the contents of the stats cache
+func (s *Service) CacheEntries(ctx context.Context) ([]CacheEntry, error) {
+	return s.cache.Entries(ctx)
+}
+
+// CacheCounters reports stats cache hits, misses & evictions
+func (s *Service) CacheCounters() CacheCounters {
+	return s.cache.Counters()
+}
+
+// ClearCache drops all cached stats
+func (s *Service) ClearCache(ctx context.Context) error {
+	return s.cache.Clear(ctx)
+}
+
+// PruneCache drops stale cache entries, and entries keep returns false for.
+// a nil keep func only drops stale entries. PruneCache returns the dropped
+// entries
+func (s *Service) PruneCache(ctx context.Context, keep func(key string) bool) ([]CacheEntry, error) {
+	removed, err := s.PrunableCacheEntries(ctx, keep)
+	if err != nil {
+		return nil, err
+	}
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	keys := make([]string, len(removed))
+	for i, ent := range removed {
+		keys[i] = ent.Key
+	}
+	if err := s.cache.Remove(ctx, keys...); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// PrunableCacheEntries lists the entries PruneCache would drop, without
+// dropping them
+func (s *Service) PrunableCacheEntries(ctx context.Context, keep func(key string) bool) ([]CacheEntry, error) {
+	entries, err := s.cache.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prunable := []CacheEntry{}
+	for _, ent := range entries {
+		if ent.Stale || (keep != nil && !keep(ent.Key)) {
+			prunable = append(prunable, ent)
+		}
+	}
+	return prunable, nil
+}
+
+// CacheKey returns the key stats for a dataset are cached under
+func (s *Service) CacheKey(ds *dataset.Dataset) (string, error) {
+	if _, ok := s.cache.(bodyKeyed); ok && ds.BodyPath != "" {
+		// body-keyed caches share stats between versions with the same body.
+		// the same body can produce different stats under a different
+		// structure, so a stored structure is part of the key. local body
+		// files are checked for changes directly & must be used as-is
+		if qfs.PathKind(ds.BodyPath) != "local" && ds.Structure != nil && ds.Structure.Path != "" {
+			return ds.BodyPath + "?structure=" + ds.Structure.Path, nil
+		}
+		return ds.BodyPath, nil
+	}
+
 	if fsi.IsFSIPath(ds.Path) {
 		// if the passed-in dataset is FSI-linked, use the body file
 		// as a basis for the cache key
@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// tdigest is a merging t-digest, a sketch for estimating quantiles of a
+// stream of numbers in constant memory. Accuracy is highest at the tails of
+// the distribution. See Dunning & Ertl, "Computing Extremely Accurate
+// Quantiles Using t-Digests" (https://arxiv.org/abs/1902.04023)
+type tdigest struct {
+	compression float64
+	centroids   []tdCentroid
+	buffer      []tdCentroid
+	count       float64
+	min, max    float64
+}
+
+type tdCentroid struct {
+	mean, weight float64
+}
+
+// defaultCompression bounds a digest to roughly compression/2 centroids
+const defaultCompression = 100
+
+func newTDigest(compression float64) *tdigest {
+	return &tdigest{
+		compression: compression,
+		buffer:      make([]tdCentroid, 0, int(compression)*5),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// add places a single value in the digest
+func (td *tdigest) add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	td.buffer = append(td.buffer, tdCentroid{mean: v, weight: 1})
+	td.count++
+	if v < td.min {
+		td.min = v
+	}
+	if v > td.max {
+		td.max = v
+	}
+	if len(td.buffer) == cap(td.buffer) {
+		td.merge()
+	}
+}
+
+// merge folds buffered values into the digest's centroids
+func (td *tdigest) merge() {
+	if len(td.buffer) == 0 {
+		return
+	}
+	all := append(td.centroids, td.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]tdCentroid, 0, len(td.centroids)+1)
+	cur := all[0]
+	var sofar float64
+	kLeft := td.scale(0)
+	for _, c := range all[1:] {
+		// a centroid may span at most one unit of the scale function, which
+		// keeps centroids near the tails of the distribution small
+		if td.scale((sofar+cur.weight+c.weight)/td.count)-kLeft <= 1 {
+			cur.mean += (c.mean - cur.mean) * c.weight / (cur.weight + c.weight)
+			cur.weight += c.weight
+			continue
+		}
+		sofar += cur.weight
+		kLeft = td.scale(sofar / td.count)
+		merged = append(merged, cur)
+		cur = c
+	}
+	merged = append(merged, cur)
+
+	td.centroids = merged
+	td.buffer = td.buffer[:0]
+}
+
+// scale is the k1 scale function from the t-digest paper, mapping a quantile
+// to a position in the range [-compression/4, compression/4]
+func (td *tdigest) scale(q float64) float64 {
+	return td.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// quantile estimates the value at quantile q, which must be in the range 0-1
+func (td *tdigest) quantile(q float64) float64 {
+	td.merge()
+	if len(td.centroids) == 0 {
+		return math.NaN()
+	}
+	if len(td.centroids) == 1 || q <= 0 {
+		if q >= 1 {
+			return td.max
+		}
+		if q <= 0 {
+			return td.min
+		}
+		return td.centroids[0].mean
+	}
+	if q >= 1 {
+		return td.max
+	}
+
+	target := q * td.count
+	// each centroid's weight is centered on it's mean. interpolate between
+	// neighbouring centroid midpoints, using min & max at the edges
+	var cumulative float64
+	prevMean, prevMid := td.min, 0.0
+	for _, c := range td.centroids {
+		mid := cumulative + c.weight/2
+		if target < mid {
+			if mid == prevMid {
+				return c.mean
+			}
+			return prevMean + (target-prevMid)/(mid-prevMid)*(c.mean-prevMean)
+		}
+		cumulative += c.weight
+		prevMean, prevMid = c.mean, mid
+	}
+	if td.count == prevMid {
+		return td.max
+	}
+	return prevMean + (target-prevMid)/(td.count-prevMid)*(td.max-prevMean)
+}
+
+// cdf estimates the fraction of values less than or equal to v
+func (td *tdigest) cdf(v float64) float64 {
+	td.merge()
+	if len(td.centroids) == 0 {
+		return math.NaN()
+	}
+	if v < td.min {
+		return 0
+	}
+	if v >= td.max {
+		return 1
+	}
+
+	var cumulative float64
+	prevMean, prevMid := td.min, 0.0
+	for _, c := range td.centroids {
+		mid := cumulative + c.weight/2
+		if v < c.mean {
+			if c.mean == prevMean {
+				return mid / td.count
+			}
+			return (prevMid + (v-prevMean)/(c.mean-prevMean)*(mid-prevMid)) / td.count
+		}
+		cumulative += c.weight
+		prevMean, prevMid = c.mean, mid
+	}
+	if td.max == prevMean {
+		return 1
+	}
+	return (prevMid + (v-prevMean)/(td.max-prevMean)*(td.count-prevMid)) / td.count
+}
@@ -8,9 +8,12 @@ import (
 
 	golog "github.com/ipfs/go-log"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/preview"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/sql"
 	"github.com/qri-io/qri/transform/run"
 	"github.com/qri-io/qri/transform/startf"
 )
@@ -21,6 +24,9 @@ const (
 	// SyntaxStarlark identifies steps & scripts written in starlark syntax
 	// they're executed by the startf subpackage
 	SyntaxStarlark = "starlark"
+	// SyntaxSQL identifies steps written as SQL queries. query results become
+	// the body of the transformed dataset. They're executed by the sql package
+	SyntaxSQL = "sql"
 	// SyntaxQri is not currently in use. It's planned for deprecation & removal
 	SyntaxQri = "qri"
 )
@@ -38,6 +44,15 @@ const (
 	StatusSkipped = "skipped"
 )
 
+// AllowedHostsConfigKey is the transform config key that lists the hosts a
+// transform can make HTTP requests to. Transforms that don't set it can make
+// requests to any host, eg:
+//
+//	transform:
+//	  config:
+//	    allowedHosts: ["api.example.com", "*.data.gov"]
+const AllowedHostsConfigKey = "allowedHosts"
+
 // NewRunID aliases the run identifier creation function to avoid requiring the
 // run package to invoke Apply
 var NewRunID = run.NewID
@@ -45,18 +60,30 @@ var NewRunID = run.NewID
 // Service applies transform scripts to datasets
 type Service struct {
 	bgCtx context.Context
+	repo  repo.Repo
+	cache StepCache
 }
 
 // NewService constructs a transform service, it accepts a background context
 // that any async transform application will be bound to. Generally bgCtx should
-// be long running, matched to the length of the qri application process
-func NewService(bgCtx context.Context) *Service {
+// be long running, matched to the length of the qri application process.
+// The repo is used to read tables in SQL steps, and may be nil if SQL steps
+// won't be run. Step results are stored in cache, which may be nil to disable
+// step caching
+func NewService(bgCtx context.Context, r repo.Repo, cache StepCache) *Service {
 	return &Service{
 		bgCtx: bgCtx,
+		repo:  r,
+		cache: cache,
 	}
 }
 
-// Apply applies the transform script to a target dataset
+// Apply applies the transform script to a target dataset. Steps that are
+// unchanged since a previous run are restored from the step cache & skipped,
+// unless noCache is true. Steps always write their results to the cache.
+// opts configure starlark execution, eg: startf.SetLimits bounds the
+// resources each step can use. Steps that exceed a limit fail with an
+// ETTransformError event that describes the limit
 func (svc *Service) Apply(
 	ctx context.Context,
 	target *dataset.Dataset,
@@ -67,6 +94,8 @@ func (svc *Service) Apply(
 	str ioes.IOStreams,
 	scriptOut io.Writer,
 	secrets map[string]string,
+	noCache bool,
+	opts ...func(*startf.ExecOpts),
 ) error {
 	if svc == nil {
 		return fmt.Errorf("transform service does not exist")
@@ -100,17 +129,37 @@ func (svc *Service) Apply(
 	}
 
 	eventsCh := make(chan event.Event)
+	redactor := startf.NewRedactor(secrets)
 
 	// create a check func from a record of all the parts that the datasetPod is changing,
 	// the startf package will use this function to ensure the same components aren't modified
 	mutateCheck := startf.MutatedComponentsFunc(target)
 
-	opts := []func(*startf.ExecOpts){
+	// steps load datasets through a recorder, so cached steps can confirm the
+	// datasets they read haven't changed
+	recorder := newLoadRecorder(loader)
+
+	allowedHosts, err := transformAllowedHosts(target.Transform)
+	if err != nil {
+		return err
+	}
+
+	opts = append([]func(*startf.ExecOpts){
 		startf.AddMutateFieldCheck(mutateCheck),
 		startf.SetErrWriter(scriptOut),
 		startf.SetSecrets(secrets),
-		startf.AddDatasetLoader(loader),
+		startf.AddDatasetLoader(recorder.Load),
 		startf.AddEventsChannel(eventsCh),
+	}, opts...)
+	// the allowlist belongs to the transform, not to a single run. set it last
+	// so caller options can't replace it
+	opts = append(opts, startf.SetAllowedHosts(allowedHosts))
+
+	var cacheKeys []string
+	if svc.cache != nil && len(target.Transform.Steps) > 0 {
+		if cacheKeys, err = stepCacheKeys(target, head, secrets); err != nil {
+			return err
+		}
 	}
 
 	doneCh := make(chan error)
@@ -128,15 +177,19 @@ func (svc *Service) Apply(
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
-		// Forward events from the events channel to the eventBus
+		// Forward events from the events channel to the eventBus. stopped closes
+		// once the stop event is published, so subscribers have seen every
+		// event of the run before apply returns
+		stopped := make(chan struct{})
 		go func() {
 			receivedTransformStopEvt := false
 			for {
 				select {
 				case e := <-eventsCh:
-					pub.PublishID(ctx, e.Type, runID, e.Payload)
+					pub.PublishID(ctx, e.Type, runID, redactPayload(redactor, e.Payload))
 					if e.Type == event.ETTransformStop {
 						receivedTransformStopEvt = true
+						close(stopped)
 					}
 				case <-ctx.Done():
 					if !receivedTransformStopEvt {
@@ -159,13 +212,7 @@ func (svc *Service) Apply(
 			runErr = startf.ExecScript(ctx, target, head, opts...)
 			if runErr != nil {
 				status = StatusFailed
-				eventsCh <- event.Event{
-					Type: event.ETTransformError,
-					Payload: event.TransformMessage{
-						Lvl: event.TransformMsgLvlError,
-						Msg: runErr.Error(),
-					},
-				}
+				eventsCh <- errorEvent(runErr)
 			}
 
 			eventsCh <- event.Event{
@@ -174,12 +221,16 @@ func (svc *Service) Apply(
 					Status: status,
 				},
 			}
+			<-stopped
 			doneCh <- runErr
 			return
 		}
 
 		// Run each step using a StepRunner
 		stepRunner := startf.NewStepRunner(head, opts...)
+		// steps are restored from the cache until the first step that runs.
+		// every step after that must also run
+		restoring := cacheKeys != nil && !noCache
 		for i, step := range target.Transform.Steps {
 			// If the transform has failed at some step, emit skip events for remaining steps.
 			if status != StatusSucceeded {
@@ -193,6 +244,21 @@ func (svc *Service) Apply(
 				continue
 			}
 
+			if restoring {
+				if svc.restoreStep(ctx, stepRunner, target, step, cacheKeys[i], loader) {
+					log.Debugw("restored cached step", "runID", runID, "index", i, "name", step.Name)
+					eventsCh <- event.Event{
+						Type: event.ETTransformStepSkip,
+						Payload: event.TransformStepLifecycle{
+							Name:     step.Name,
+							Category: step.Category,
+						},
+					}
+					continue
+				}
+				restoring = false
+			}
+
 			eventsCh <- event.Event{
 				Type: event.ETTransformStepStart,
 				Payload: event.TransformStepLifecycle{
@@ -201,21 +267,34 @@ func (svc *Service) Apply(
 				},
 			}
 
+			recorder.Reset()
 			switch step.Syntax {
 			case SyntaxStarlark:
 				runErr = stepRunner.RunStep(ctx, target, step)
 				if runErr != nil {
 					log.Debugw("error running transform step", "runID", runID, "index", i, "err", runErr)
-					eventsCh <- event.Event{
-						Type: event.ETTransformError,
-						Payload: event.TransformMessage{
-							Lvl: event.TransformMsgLvlError,
-							Msg: runErr.Error(),
-						},
-					}
+					eventsCh <- errorEvent(runErr)
 					status = StatusFailed
 				}
 				log.Debugw("ran starlark step", "runID", runID, "category", step.Category, "name", step.Name, "scriptLen", scriptLen(step))
+			case SyntaxSQL:
+				if svc.repo == nil {
+					runErr = fmt.Errorf("sql transform steps require a repo")
+				} else {
+					runErr = sql.NewStepRunner(svc.repo, recorder.Load).RunStep(ctx, target, step)
+				}
+				if runErr == nil {
+					var pview *dataset.Dataset
+					if pview, runErr = preview.Create(ctx, target); runErr == nil {
+						eventsCh <- event.Event{Type: event.ETTransformDatasetPreview, Payload: pview}
+					}
+				}
+				if runErr != nil {
+					log.Debugw("error running transform step", "runID", runID, "index", i, "err", runErr)
+					eventsCh <- errorEvent(runErr)
+					status = StatusFailed
+				}
+				log.Debugw("ran sql step", "runID", runID, "category", step.Category, "name", step.Name, "scriptLen", scriptLen(step))
 			default:
 				if step.Syntax == SyntaxQri && step.Name == "save" {
 					log.Infow("ignoring qri save step", "runID", runID)
@@ -232,6 +311,10 @@ func (svc *Service) Apply(
 				}
 			}
 
+			if status == StatusSucceeded && cacheKeys != nil {
+				svc.cacheStep(ctx, stepRunner, target, step, cacheKeys[i], recorder.Reset())
+			}
+
 			eventsCh <- event.Event{
 				Type: event.ETTransformStepStop,
 				Payload: event.TransformStepLifecycle{
@@ -248,6 +331,7 @@ func (svc *Service) Apply(
 				Status: status,
 			},
 		}
+		<-stopped
 		doneCh <- runErr
 	}()
 
@@ -255,6 +339,122 @@ func (svc *Service) Apply(
 	return err
 }
 
+// transformAllowedHosts reads the list of hosts a transform can make HTTP
+// requests to from transform config, returning nil if the list isn't set
+func transformAllowedHosts(tf *dataset.Transform) ([]string, error) {
+	return configStringList(tf, AllowedHostsConfigKey)
+}
+
+// configStringList reads a list of strings from transform config
+func configStringList(tf *dataset.Transform, key string) ([]string, error) {
+	v, ok := tf.Config[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []interface{}:
+		res := make([]string, len(list))
+		for i, el := range list {
+			str, ok := el.(string)
+			if !ok {
+				return nil, fmt.Errorf("transform config %q must be a list of strings", key)
+			}
+			res[i] = str
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("transform config %q must be a list of strings", key)
+}
+
+// redactPayload returns an event payload with secrets redacted from messages
+func redactPayload(r startf.Redactor, p interface{}) interface{} {
+	if msg, ok := p.(event.TransformMessage); ok {
+		msg.Msg = r.Redact(msg.Msg)
+		return msg
+	}
+	return p
+}
+
+// errorEvent creates an ETTransformError event for a failed transform,
+// describing the exceeded limit if the error was caused by one
+func errorEvent(err error) event.Event {
+	msg := event.TransformMessage{
+		Lvl: event.TransformMsgLvlError,
+		Msg: err.Error(),
+	}
+	var limitErr *startf.LimitError
+	if errors.As(err, &limitErr) {
+		msg.Limit = &event.TransformLimit{Name: limitErr.Limit, Max: limitErr.Max}
+	}
+	return event.Event{Type: event.ETTransformError, Payload: msg}
+}
+
+// restoreStep attempts to restore the state after a step from the cache,
+// returning true if the step can be skipped
+func (svc *Service) restoreStep(ctx context.Context, r *startf.StepRunner, target *dataset.Dataset, step *dataset.TransformStep, key string, loader dsref.ParseResolveLoad) bool {
+	cs, err := svc.cache.GetStep(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrStepCacheMiss) {
+			log.Debugw("reading cached step", "key", key, "err", err)
+		}
+		return false
+	}
+	if !cs.loadedUnchanged(ctx, loader) {
+		return false
+	}
+
+	switch step.Syntax {
+	case SyntaxStarlark:
+		// starlark steps always execute their script to define functions
+		// later steps may call
+		if err := r.RestoreStep(ctx, target, step, cs.Context); err != nil {
+			log.Debugw("restoring cached step", "key", key, "err", err)
+			return false
+		}
+	case SyntaxSQL:
+	default:
+		return false
+	}
+	cs.restoreDataset(target)
+	return true
+}
+
+// cacheStep records the state after a step ran. Steps that can't be cached
+// are logged & ignored
+func (svc *Service) cacheStep(ctx context.Context, r *startf.StepRunner, target *dataset.Dataset, step *dataset.TransformStep, key string, loaded map[string]string) {
+	cs := &CachedStep{Loaded: loaded}
+
+	switch step.Syntax {
+	case SyntaxStarlark:
+		state, err := r.ContextState()
+		if err != nil {
+			log.Debugw("step context can't be cached", "name", step.Name, "err", err)
+			return
+		}
+		cs.Context = state
+		if step.Category == "transform" {
+			if err := cs.snapshotDataset(target); err != nil {
+				log.Debugw("caching step dataset", "name", step.Name, "err", err)
+				return
+			}
+		}
+	case SyntaxSQL:
+		if err := cs.snapshotDataset(target); err != nil {
+			log.Debugw("caching step dataset", "name", step.Name, "err", err)
+			return
+		}
+	default:
+		return
+	}
+
+	if err := svc.cache.PutStep(ctx, key, cs); err != nil {
+		log.Debugw("writing cached step", "name", step.Name, "err", err)
+	}
+}
+
 // scriptLen returns the length of the script string, -1 if the script is not
 // a string type
 func scriptLen(step *dataset.TransformStep) int {
@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,6 +17,7 @@ import (
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform/startf"
 )
 
 func TestApply(t *testing.T) {
@@ -57,6 +63,21 @@ func TestApply(t *testing.T) {
 				{Type: event.ETTransformStop, Payload: event.TransformLifecycle{Status: StatusFailed}},
 			},
 		},
+
+		{"sql_step_without_repo",
+			&dataset.Transform{
+				Steps: []*dataset.TransformStep{
+					{Syntax: "sql", Category: "transform", Name: "query", Script: "SELECT * FROM me/cities as c"},
+				},
+			},
+			[]event.Event{
+				{Type: event.ETTransformStart, Payload: event.TransformLifecycle{StepCount: 1}},
+				{Type: event.ETTransformStepStart, Payload: event.TransformStepLifecycle{Name: "query", Category: "transform"}},
+				{Type: event.ETTransformError, Payload: event.TransformMessage{Lvl: event.TransformMsgLvlError, Msg: "sql transform steps require a repo"}},
+				{Type: event.ETTransformStepStop, Payload: event.TransformStepLifecycle{Name: "query", Category: "transform", Status: StatusFailed}},
+				{Type: event.ETTransformStop, Payload: event.TransformLifecycle{Status: StatusFailed}},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -67,9 +88,187 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApplyStepCache(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(ctx, nil, NewMemStepCache())
+	steps := func(transformScript string) *dataset.Transform {
+		return &dataset.Transform{
+			Steps: []*dataset.TransformStep{
+				{Syntax: "starlark", Category: "setup", Script: "def get_rows():\n\treturn [[1,2,3]]"},
+				{Syntax: "starlark", Category: "download", Script: "def download(ctx):\n\tctx.set(\"scale\", 1.5)\n\treturn get_rows()"},
+				{Syntax: "starlark", Category: "transform", Script: transformScript},
+			},
+		}
+	}
+	transformScript := "def transform(ds, ctx):\n\tds.set_body(ctx.download)"
+
+	log := applyTransform(t, svc, steps(transformScript), false, nil)
+	if got := stepEventTypes(log); got != "start,stop,start,stop,start,stop" {
+		t.Fatalf("expected first run to run every step. got: %s", got)
+	}
+
+	log = applyTransform(t, svc, steps(transformScript), false, nil)
+	if got := stepEventTypes(log); got != "skip,skip,skip" {
+		t.Errorf("expected unchanged steps to be skipped. got: %s", got)
+	}
+
+	// changing the last step reuses cached setup & download results, including
+	// values set on the context
+	log = applyTransform(t, svc, steps("def transform(ds, ctx):\n\tds.set_body([r + [ctx.get(\"scale\")] for r in ctx.download])"), false, nil)
+	if got := stepEventTypes(log); got != "skip,skip,start,stop" {
+		t.Errorf("expected only the changed step to run. got: %s", got)
+	}
+	if st := lastStepStatus(log); st != StatusSucceeded {
+		t.Errorf("expected changed step to succeed. got status %q, events: %#v", st, log)
+	}
+
+	log = applyTransform(t, svc, steps(transformScript), true, nil)
+	if got := stepEventTypes(log); got != "start,stop,start,stop,start,stop" {
+		t.Errorf("expected noCache to run every step. got: %s", got)
+	}
+}
+
+func TestApplyLimits(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(ctx, nil, nil)
+	cases := []struct {
+		name   string
+		script string
+		limits startf.Limits
+		expect *event.TransformLimit
+	}{
+		{"max_steps",
+			"def transform(ds, ctx):\n\tfor i in range(1000000):\n\t\tpass",
+			startf.Limits{MaxSteps: 1000},
+			&event.TransformLimit{Name: startf.LimitMaxSteps, Max: 1000},
+		},
+		{"step_timeout",
+			"def transform(ds, ctx):\n\tfor i in range(1000000000):\n\t\tpass",
+			startf.Limits{StepTimeout: 10 * time.Millisecond},
+			&event.TransformLimit{Name: startf.LimitStepTimeout, Max: int64(10 * time.Millisecond)},
+		},
+		{"max_body_size",
+			"def transform(ds, ctx):\n\tds.set_body([[i, i * 2] for i in range(100)])",
+			startf.Limits{MaxBodySize: 64},
+			&event.TransformLimit{Name: startf.LimitMaxBodySize, Max: 64},
+		},
+		{"within_limits",
+			"def transform(ds, ctx):\n\tds.set_body([[1, 2, 3]])",
+			startf.Limits{MaxSteps: 1000, StepTimeout: time.Minute, MaxBodySize: 64},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tf := &dataset.Transform{
+				Steps: []*dataset.TransformStep{
+					{Syntax: "starlark", Category: "transform", Script: c.script},
+				},
+			}
+			log := applyTransform(t, svc, tf, false, nil, startf.SetLimits(c.limits))
+
+			var got *event.TransformLimit
+			for _, e := range log {
+				if msg, ok := e.Payload.(event.TransformMessage); ok && e.Type == event.ETTransformError {
+					if msg.Limit == nil {
+						t.Fatalf("expected error event to describe a limit. got: %q", msg.Msg)
+					}
+					got = msg.Limit
+				}
+			}
+			if diff := cmp.Diff(c.expect, got); diff != "" {
+				t.Errorf("exceeded limit mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyAllowedHostsFromConfig(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[["a",1]]`))
+	}))
+	defer s.Close()
+
+	svc := NewService(context.Background(), nil, nil)
+	tf := &dataset.Transform{
+		Config: map[string]interface{}{AllowedHostsConfigKey: []interface{}{"example.com"}},
+		Steps: []*dataset.TransformStep{
+			{Syntax: "starlark", Category: "setup", Script: "load(\"http.star\", \"http\")"},
+			{Syntax: "starlark", Category: "download", Script: fmt.Sprintf("def download(ctx):\n\treturn http.get(%q).json()", s.URL)},
+		},
+	}
+	// the transform's allowlist can't be replaced by a caller's list
+	log := applyTransform(t, svc, tf, false, nil, startf.SetAllowedHosts([]string{"127.0.0.1"}))
+	if status := lastStepStatus(log); status != StatusFailed {
+		t.Errorf("expected a request to a host the transform doesn't allow to fail, got step status: %q", status)
+	}
+}
+
+func TestApplyRedactsSecrets(t *testing.T) {
+	svc := NewService(context.Background(), nil, nil)
+	tf := &dataset.Transform{
+		Steps: []*dataset.TransformStep{
+			{Syntax: "starlark", Category: "transform", Script: `def transform(ds, ctx):
+	key = ctx.get_secret("API_KEY")
+	print("using key %s" % key)
+	error("request with %s failed" % key)`},
+		},
+	}
+	log := applyTransform(t, svc, tf, false, map[string]string{"API_KEY": "hunter2", "EMPTY": ""})
+
+	msgs := []string{}
+	for _, e := range log {
+		if e.Type == event.ETTransformPrint || e.Type == event.ETTransformError {
+			msgs = append(msgs, e.Payload.(event.TransformMessage).Msg)
+		}
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected a print & an error message. got: %v", msgs)
+	}
+	if expect := "using key [REDACTED]"; msgs[0] != expect {
+		t.Errorf("print message mismatch. expected: %q, got: %q", expect, msgs[0])
+	}
+	if strings.Contains(msgs[1], "hunter2") || !strings.Contains(msgs[1], "request with [REDACTED] failed") {
+		t.Errorf("expected error message to redact the secret. got: %q", msgs[1])
+	}
+}
+
+// stepEventTypes summarizes the step lifecycle events in an event log
+func stepEventTypes(log []event.Event) string {
+	types := []string{}
+	for _, e := range log {
+		switch e.Type {
+		case event.ETTransformStepStart:
+			types = append(types, "start")
+		case event.ETTransformStepStop:
+			types = append(types, "stop")
+		case event.ETTransformStepSkip:
+			types = append(types, "skip")
+		}
+	}
+	return strings.Join(types, ",")
+}
+
+func lastStepStatus(log []event.Event) string {
+	status := ""
+	for _, e := range log {
+		if tsl, ok := e.Payload.(event.TransformStepLifecycle); ok && e.Type == event.ETTransformStepStop {
+			status = tsl.Status
+		}
+	}
+	return status
+}
+
 // run a transform script & capture the event log. transform runs against an
 // empty dataset history
 func applyNoHistoryTransform(t *testing.T, tf *dataset.Transform) []event.Event {
+	return applyTransform(t, NewService(context.Background(), nil, nil), tf, false, nil)
+}
+
+// applyTransform runs a transform with the given service against an empty
+// dataset history
+func applyTransform(t *testing.T, svc *Service, tf *dataset.Transform, noCache bool, secrets map[string]string, opts ...func(*startf.ExecOpts)) []event.Event {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -94,7 +293,7 @@ func applyNoHistoryTransform(t *testing.T, tf *dataset.Transform) []event.Event
 		return nil
 	}, runID)
 
-	if err := NewService(ctx).Apply(ctx, target, noHistoryLoader, runID, bus, false, streams, scriptOut, nil); err != nil {
+	if err := svc.Apply(ctx, target, noHistoryLoader, runID, bus, false, streams, scriptOut, secrets, noCache, opts...); err != nil {
 		t.Fatal(err)
 	}
 
@@ -0,0 +1,365 @@
+package transform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/transform/startf"
+)
+
+// ErrStepCacheMiss indicates a requested step isn't in the cache
+var ErrStepCacheMiss = errors.New("transform: step cache miss")
+
+// StepCache stores the results of transform steps, keyed by a hash of the
+// step & everything that went into running it. Consumers of a cache must not
+// rely on the cache for persistence. Implementations must be safe for
+// concurrent use
+type StepCache interface {
+	// GetStep fetches the result of a step, returning ErrStepCacheMiss if the
+	// key isn't present
+	GetStep(ctx context.Context, key string) (*CachedStep, error)
+	// PutStep stores the result of a step
+	PutStep(ctx context.Context, key string, cs *CachedStep) error
+}
+
+// CachedStep is the state of a transform after a step ran
+type CachedStep struct {
+	// starlark context state, for starlark steps
+	Context *startf.ContextState `json:"context,omitempty"`
+	// dataset state, for steps that modify the dataset. the body is stored
+	// separately in Body & BodyFilename
+	Dataset *dataset.Dataset `json:"dataset,omitempty"`
+	// body file contents
+	Body         []byte `json:"body,omitempty"`
+	BodyFilename string `json:"bodyFilename,omitempty"`
+	// datasets loaded while running the step, mapping the reference string
+	// passed to the loader to the path of the loaded version. A cached step
+	// is only valid while each reference still resolves to the same path
+	Loaded map[string]string `json:"loaded,omitempty"`
+}
+
+// snapshotDataset records the dataset state in a cached step. The body file
+// of ds is consumed & replaced with an in-memory copy
+func (cs *CachedStep) snapshotDataset(ds *dataset.Dataset) error {
+	snap := &dataset.Dataset{}
+	snap.Assign(ds)
+	if ds.Transform != nil {
+		// the transform component only carries resources forward
+		snap.Transform = &dataset.Transform{Resources: ds.Transform.Resources}
+	}
+
+	if f := ds.BodyFile(); f != nil {
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		name := f.FileName()
+		ds.SetBodyFile(qfs.NewMemfileBytes(name, data))
+		cs.Body = data
+		cs.BodyFilename = name
+	}
+	cs.Dataset = snap
+	return nil
+}
+
+// restoreDataset replaces the state of ds with the cached dataset. The
+// transform component of ds is kept, adding any cached resources
+func (cs *CachedStep) restoreDataset(ds *dataset.Dataset) {
+	if cs.Dataset == nil {
+		return
+	}
+	tf := ds.Transform
+	*ds = dataset.Dataset{}
+	ds.Assign(cs.Dataset)
+	ds.Transform = tf
+	if tf != nil && cs.Dataset.Transform != nil && len(cs.Dataset.Transform.Resources) > 0 {
+		if tf.Resources == nil {
+			tf.Resources = map[string]*dataset.TransformResource{}
+		}
+		for key, rsc := range cs.Dataset.Transform.Resources {
+			tf.Resources[key] = rsc
+		}
+	}
+	if cs.BodyFilename != "" {
+		ds.SetBodyFile(qfs.NewMemfileBytes(cs.BodyFilename, cs.Body))
+	}
+}
+
+// stepKeyParts is hashed to produce a step cache key
+type stepKeyParts struct {
+	Prev     string                 `json:"prev"`
+	Syntax   string                 `json:"syntax"`
+	Category string                 `json:"category"`
+	Name     string                 `json:"name"`
+	Script   interface{}            `json:"script"`
+	Input    string                 `json:"input,omitempty"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+	Secrets  []string               `json:"secrets,omitempty"`
+	HeadPath string                 `json:"headPath,omitempty"`
+}
+
+// stepCacheKeys calculates a cache key for each step of a transform. Keys are
+// chained: each key includes the key of the step before it, so a change to
+// one step invalidates every step that follows. Secrets are keyed by name,
+// never by value
+func stepCacheKeys(target, head *dataset.Dataset, secrets map[string]string) ([]string, error) {
+	// the first step's input is the target dataset, minus the transform that's
+	// being applied & the commit, which only describes the version
+	input := &dataset.Dataset{}
+	input.Assign(target)
+	input.Transform = nil
+	input.Commit = nil
+	inputData, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headPath := ""
+	if head != nil {
+		headPath = head.Path
+	}
+
+	keys := make([]string, len(target.Transform.Steps))
+	prev := ""
+	for i, st := range target.Transform.Steps {
+		parts := stepKeyParts{
+			Prev:     prev,
+			Syntax:   st.Syntax,
+			Category: st.Category,
+			Name:     st.Name,
+			Script:   st.Script,
+			Config:   target.Transform.Config,
+			Secrets:  names,
+		}
+		if i == 0 {
+			parts.Input = string(inputData)
+		}
+		// transform functions are handed the previous version of the dataset.
+		// other steps don't see it, so saving a new version doesn't invalidate
+		// downloads
+		if st.Category == "transform" {
+			parts.HeadPath = headPath
+		}
+
+		data, err := json.Marshal(parts)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: calculating cache key: %w", i, err)
+		}
+		sum := sha256.Sum256(data)
+		prev = hex.EncodeToString(sum[:])
+		keys[i] = prev
+	}
+	return keys, nil
+}
+
+// loadRecorder wraps a dataset loader, recording the path of each dataset it
+// loads
+type loadRecorder struct {
+	load dsref.ParseResolveLoad
+
+	lock   sync.Mutex
+	loaded map[string]string
+}
+
+func newLoadRecorder(load dsref.ParseResolveLoad) *loadRecorder {
+	return &loadRecorder{load: load, loaded: map[string]string{}}
+}
+
+// Load implements dsref.ParseResolveLoad
+func (r *loadRecorder) Load(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+	if r.load == nil {
+		return nil, fmt.Errorf("no dataset loader")
+	}
+	ds, err := r.load(ctx, refstr)
+	if err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	r.loaded[refstr] = ds.Path
+	r.lock.Unlock()
+	return ds, nil
+}
+
+// Reset returns the datasets loaded since the last reset
+func (r *loadRecorder) Reset() map[string]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	loaded := r.loaded
+	r.loaded = map[string]string{}
+	if len(loaded) == 0 {
+		return nil
+	}
+	return loaded
+}
+
+// loadedUnchanged confirms every dataset a cached step loaded still resolves
+// to the same version
+func (cs *CachedStep) loadedUnchanged(ctx context.Context, load dsref.ParseResolveLoad) bool {
+	for refstr, path := range cs.Loaded {
+		if load == nil {
+			return false
+		}
+		ds, err := load(ctx, refstr)
+		if err != nil || ds.Path != path {
+			return false
+		}
+		if f := ds.BodyFile(); f != nil {
+			f.Close()
+		}
+	}
+	return true
+}
+
+// localStepCache stores cached steps as JSON files in a directory. When the
+// total size of cached steps exceeds maxSize the least recently used steps
+// are dropped
+type localStepCache struct {
+	root    string
+	maxSize int64
+	lock    sync.Mutex
+}
+
+var _ StepCache = (*localStepCache)(nil)
+
+// NewLocalStepCache creates a step cache in a local directory, holding up to
+// maxSize bytes. A maxSize of zero or less doesn't bound the cache
+func NewLocalStepCache(rootDir string, maxSize int64) (StepCache, error) {
+	if err := os.MkdirAll(rootDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &localStepCache{root: rootDir, maxSize: maxSize}, nil
+}
+
+// GetStep implements the StepCache interface
+func (c *localStepCache) GetStep(ctx context.Context, key string) (*CachedStep, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	path := c.filepath(key)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrStepCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	cs := &CachedStep{}
+	if err := json.Unmarshal(data, cs); err != nil {
+		log.Debugw("dropping corrupt cached step", "key", key, "err", err)
+		os.Remove(path)
+		return nil, ErrStepCacheMiss
+	}
+	// touch the file to mark it as recently used
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return cs, nil
+}
+
+// PutStep implements the StepCache interface
+func (c *localStepCache) PutStep(ctx context.Context, key string, cs *CachedStep) error {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := ioutil.WriteFile(c.filepath(key), data, 0644); err != nil {
+		return err
+	}
+	return c.purge()
+}
+
+func (c *localStepCache) filepath(key string) string {
+	return filepath.Join(c.root, key+".json")
+}
+
+// purge drops least recently used steps until the cache is under maxSize
+func (c *localStepCache) purge() error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+	infos, err := ioutil.ReadDir(c.root)
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, fi := range infos {
+		size += fi.Size()
+	}
+	if size <= c.maxSize {
+		return nil
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	for _, fi := range infos {
+		if size <= c.maxSize {
+			break
+		}
+		if !strings.HasSuffix(fi.Name(), ".json") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.root, fi.Name())); err != nil {
+			return err
+		}
+		size -= fi.Size()
+	}
+	return nil
+}
+
+// memStepCache holds cached steps in memory
+type memStepCache struct {
+	lock  sync.Mutex
+	steps map[string][]byte
+}
+
+var _ StepCache = (*memStepCache)(nil)
+
+// NewMemStepCache creates an in-memory step cache
+func NewMemStepCache() StepCache {
+	return &memStepCache{steps: map[string][]byte{}}
+}
+
+// GetStep implements the StepCache interface
+func (c *memStepCache) GetStep(ctx context.Context, key string) (*CachedStep, error) {
+	c.lock.Lock()
+	data, ok := c.steps[key]
+	c.lock.Unlock()
+	if !ok {
+		return nil, ErrStepCacheMiss
+	}
+	// steps are stored encoded so callers can't modify cached state
+	cs := &CachedStep{}
+	err := json.Unmarshal(data, cs)
+	return cs, err
+}
+
+// PutStep implements the StepCache interface
+func (c *memStepCache) PutStep(ctx context.Context, key string, cs *CachedStep) error {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.steps[key] = data
+	return nil
+}
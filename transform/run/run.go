@@ -2,11 +2,13 @@
 package run
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/event"
 )
 
@@ -62,6 +64,16 @@ type State struct {
 	StopTime  *time.Time   `json:"stopTime"`
 	Duration  int          `json:"duration"`
 	Steps     []*StepState `json:"steps"`
+	// InitID identifies the dataset the run belongs to
+	InitID string `json:"initID,omitempty"`
+	// Path of the version the run saved, empty if the run didn't save a version
+	Path string `json:"path,omitempty"`
+	// Output logs events that occur outside of a step, like the print output &
+	// errors of transforms that don't have steps
+	Output []event.Event `json:"output,omitempty"`
+	// HTTPRecording is the path to a recording of the HTTP requests the run
+	// made, empty if requests weren't recorded
+	HTTPRecording string `json:"httpRecording,omitempty"`
 }
 
 // NewState is a simple constructor to remind package consumers that state
@@ -142,6 +154,10 @@ func (rs *State) lastStep() (*StepState, error) {
 }
 
 func (rs *State) appendStepOutputLog(e event.Event) error {
+	if len(rs.Steps) == 0 {
+		rs.Output = append(rs.Output, e)
+		return nil
+	}
 	step, err := rs.lastStep()
 	if err != nil {
 		return err
@@ -151,6 +167,25 @@ func (rs *State) appendStepOutputLog(e event.Event) error {
 	return nil
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding output
+// event payloads into the types transforms emit
+func (rs *State) UnmarshalJSON(data []byte) error {
+	type stateAlias State
+	v := struct {
+		*stateAlias
+		Output []jsonEvent `json:"output,omitempty"`
+	}{stateAlias: (*stateAlias)(rs)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	output, err := decodeEvents(v.Output)
+	if err != nil {
+		return err
+	}
+	rs.Output = output
+	return nil
+}
+
 // StepState describes the execution of a transform step
 type StepState struct {
 	Name      string        `json:"name"`
@@ -162,6 +197,66 @@ type StepState struct {
 	Output    []event.Event `json:"output"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding output
+// event payloads into the types transforms emit
+func (ss *StepState) UnmarshalJSON(data []byte) error {
+	type stepAlias StepState
+	v := struct {
+		*stepAlias
+		Output []jsonEvent `json:"output"`
+	}{stepAlias: (*stepAlias)(ss)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	output, err := decodeEvents(v.Output)
+	if err != nil {
+		return err
+	}
+	ss.Output = output
+	return nil
+}
+
+// jsonEvent is an event with a payload that hasn't been decoded yet
+type jsonEvent struct {
+	Type      event.Type
+	Timestamp int64
+	SessionID string
+	Payload   json.RawMessage
+}
+
+func decodeEvents(evts []jsonEvent) ([]event.Event, error) {
+	if evts == nil {
+		return nil, nil
+	}
+	res := make([]event.Event, len(evts))
+	for i, e := range evts {
+		res[i] = event.Event{Type: e.Type, Timestamp: e.Timestamp, SessionID: e.SessionID}
+		if len(e.Payload) == 0 || string(e.Payload) == "null" {
+			continue
+		}
+
+		var err error
+		switch e.Type {
+		case event.ETTransformPrint, event.ETTransformError:
+			msg := event.TransformMessage{}
+			err = json.Unmarshal(e.Payload, &msg)
+			res[i].Payload = msg
+		case event.ETTransformDatasetPreview:
+			ds := &dataset.Dataset{}
+			err = json.Unmarshal(e.Payload, ds)
+			res[i].Payload = ds
+		default:
+			var v interface{}
+			err = json.Unmarshal(e.Payload, &v)
+			res[i].Payload = v
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding %q event: %w", e.Type, err)
+		}
+	}
+	return res, nil
+}
+
 // NewStepStateFromEvent constructs StepState from an event
 func NewStepStateFromEvent(e event.Event) (*StepState, error) {
 	if tsl, ok := e.Payload.(event.TransformStepLifecycle); ok {
@@ -129,3 +129,15 @@ func TestStateAddTransformEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestStateOutputWithoutSteps(t *testing.T) {
+	runID := NewID()
+	rs := NewState(runID)
+	print := event.Event{Type: event.ETTransformPrint, SessionID: runID, Payload: event.TransformMessage{Msg: "hello"}}
+	if err := rs.AddTransformEvent(print); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]event.Event{print}, rs.Output); diff != "" {
+		t.Errorf("expected output outside of a step to be logged on the run (-want +got):\n%s", diff)
+	}
+}
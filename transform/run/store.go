@@ -0,0 +1,303 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	golog "github.com/ipfs/go-log"
+)
+
+var log = golog.Logger("run")
+
+// ErrNotFound indicates a run isn't in a store
+var ErrNotFound = fmt.Errorf("run not found")
+
+// Store persists the full record of transform runs, including step timings &
+// output, so runs can be inspected after they finish
+type Store interface {
+	// Put adds a run to the store, replacing any run with the same ID
+	Put(ctx context.Context, rs *State) error
+	// Get fetches a run by ID
+	Get(ctx context.Context, id string) (*State, error)
+	// List returns the runs of a dataset, newest first. a limit of -1 returns
+	// all runs
+	List(ctx context.Context, initID string, offset, limit int) ([]*State, error)
+	// Prune removes all but the keep newest runs of each dataset, returning
+	// the IDs of removed runs. Runs that don't belong to a dataset are pruned
+	// as a group. When dryRun is true runs are listed but not removed
+	Prune(ctx context.Context, keep int, dryRun bool) ([]string, error)
+}
+
+// MemStore is an in-memory implementation of the run Store interface.
+// MemStore is safe for concurrent use
+type MemStore struct {
+	lk   sync.Mutex
+	runs map[string][]byte
+}
+
+// compile-time assertion that MemStore is a Store
+var _ Store = (*MemStore)(nil)
+
+// NewMemStore creates an empty in-memory run store
+func NewMemStore() *MemStore {
+	return &MemStore{runs: map[string][]byte{}}
+}
+
+// Put adds a run to the store. runs are copied on write
+func (s *MemStore) Put(ctx context.Context, rs *State) error {
+	if rs.ID == "" {
+		return fmt.Errorf("run ID is required")
+	}
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.runs[rs.ID] = data
+	return nil
+}
+
+// Get fetches a run by ID
+func (s *MemStore) Get(ctx context.Context, id string) (*State, error) {
+	s.lk.Lock()
+	data, ok := s.runs[id]
+	s.lk.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	rs := &State{}
+	err := json.Unmarshal(data, rs)
+	return rs, err
+}
+
+// List returns the runs of a dataset, newest first
+func (s *MemStore) List(ctx context.Context, initID string, offset, limit int) ([]*State, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	runs, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+	return page(withInitID(runs, initID), offset, limit), nil
+}
+
+// Prune removes all but the keep newest runs of each dataset
+func (s *MemStore) Prune(ctx context.Context, keep int, dryRun bool) ([]string, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	runs, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+	ids := prunable(runs, keep)
+	if !dryRun {
+		for _, id := range ids {
+			delete(s.runs, id)
+		}
+	}
+	return ids, nil
+}
+
+// all decodes every run in the store. callers must hold the lock
+func (s *MemStore) all() ([]*State, error) {
+	res := make([]*State, 0, len(s.runs))
+	for _, data := range s.runs {
+		rs := &State{}
+		if err := json.Unmarshal(data, rs); err != nil {
+			return nil, err
+		}
+		res = append(res, rs)
+	}
+	return res, nil
+}
+
+// LocalStore keeps runs as JSON files in a local directory, one file per run.
+// LocalStore is safe for concurrent use
+type LocalStore struct {
+	dir string
+	lk  sync.Mutex
+}
+
+// compile-time assertion that LocalStore is a Store
+var _ Store = (*LocalStore)(nil)
+
+// NewLocalStore creates a run store that writes to dir, creating the
+// directory if it doesn't exist
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating run store directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put adds a run to the store
+func (s *LocalStore) Put(ctx context.Context, rs *State) error {
+	if err := validID(rs.ID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return ioutil.WriteFile(s.runPath(rs.ID), data, 0644)
+}
+
+// Get fetches a run by ID
+func (s *LocalStore) Get(ctx context.Context, id string) (*State, error) {
+	if err := validID(id); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	s.lk.Lock()
+	data, err := ioutil.ReadFile(s.runPath(id))
+	s.lk.Unlock()
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	} else if err != nil {
+		return nil, err
+	}
+	rs := &State{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, fmt.Errorf("reading run %q: %w", id, err)
+	}
+	return rs, nil
+}
+
+// List returns the runs of a dataset, newest first
+func (s *LocalStore) List(ctx context.Context, initID string, offset, limit int) ([]*State, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	runs, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+	return page(withInitID(runs, initID), offset, limit), nil
+}
+
+// Prune removes all but the keep newest runs of each dataset, deleting the
+// files of removed runs
+func (s *LocalStore) Prune(ctx context.Context, keep int, dryRun bool) ([]string, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	runs, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+	ids := prunable(runs, keep)
+	if !dryRun {
+		for _, id := range ids {
+			if err := os.Remove(s.runPath(id)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+// all reads every run in the store directory, skipping files that can't be
+// read as runs. callers must hold the lock
+func (s *LocalStore) all() ([]*State, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*State{}
+	for _, fi := range infos {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rs := &State{}
+		if err := json.Unmarshal(data, rs); err != nil {
+			log.Debugw("skipping unreadable run", "file", fi.Name(), "err", err)
+			continue
+		}
+		res = append(res, rs)
+	}
+	return res, nil
+}
+
+func (s *LocalStore) runPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// validID guards against IDs that would write outside the store directory
+func validID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return fmt.Errorf("invalid run ID: %q", id)
+	}
+	return nil
+}
+
+// withInitID filters runs to those of a single dataset
+func withInitID(runs []*State, initID string) []*State {
+	res := []*State{}
+	for _, rs := range runs {
+		if rs.InitID == initID {
+			res = append(res, rs)
+		}
+	}
+	return res
+}
+
+// prunable lists the IDs of runs older than the keep newest runs of each
+// dataset
+func prunable(runs []*State, keep int) []string {
+	if keep < 0 {
+		keep = 0
+	}
+	groups := map[string][]*State{}
+	for _, rs := range runs {
+		groups[rs.InitID] = append(groups[rs.InitID], rs)
+	}
+	ids := []string{}
+	for _, group := range groups {
+		for _, rs := range page(group, keep, -1) {
+			ids = append(ids, rs.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// page sorts runs newest first, returning the requested page
+func page(runs []*State, offset, limit int) []*State {
+	sort.Slice(runs, func(i, j int) bool {
+		a, b := runs[i].StartTime, runs[j].StartTime
+		switch {
+		case a == nil && b == nil:
+			return runs[i].ID < runs[j].ID
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case a.Equal(*b):
+			return runs[i].ID < runs[j].ID
+		}
+		return a.After(*b)
+	})
+
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(runs) {
+		offset = len(runs)
+	}
+	runs = runs[offset:]
+	if limit >= 0 && limit < len(runs) {
+		runs = runs[:limit]
+	}
+	return runs
+}
@@ -0,0 +1,118 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/event"
+)
+
+func TestMemStore(t *testing.T) {
+	testStore(t, NewMemStore())
+}
+
+func TestLocalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "run_store_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	if err := s.Put(context.Background(), &State{ID: "../escape"}); err == nil {
+		t.Error("expected a run ID with a path separator to fail")
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	failed := &State{
+		ID:        "run-a",
+		InitID:    "dataset",
+		Status:    RSFailed,
+		StartTime: toTimePointer(1000),
+		Steps: []*StepState{
+			{Name: "transform", Status: RSFailed, Output: []event.Event{
+				{Type: event.ETTransformPrint, Timestamp: 1100, Payload: event.TransformMessage{Msg: "hello"}},
+				{Type: event.ETTransformError, Timestamp: 1200, Payload: event.TransformMessage{Lvl: event.TransformMsgLvlError, Msg: "oh no"}},
+			}},
+		},
+	}
+	saved := &State{ID: "run-b", InitID: "dataset", Status: RSSucceeded, StartTime: toTimePointer(2000), Path: "/mem/QmVersion"}
+	other := &State{ID: "run-c", InitID: "other_dataset", Status: RSSucceeded, StartTime: toTimePointer(3000)}
+	for _, rs := range []*State{failed, saved, other} {
+		if err := s.Put(ctx, rs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.Get(ctx, "run-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(failed, got); diff != "" {
+		t.Errorf("run mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a missing run to return ErrNotFound. got: %v", err)
+	}
+
+	runs, err := s.List(ctx, "dataset", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, rs := range runs {
+		ids = append(ids, rs.ID)
+	}
+	if diff := cmp.Diff([]string{"run-b", "run-a"}, ids); diff != "" {
+		t.Errorf("expected runs of the dataset, newest first (-want +got):\n%s", diff)
+	}
+
+	if runs, err = s.List(ctx, "dataset", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ID != "run-a" {
+		t.Errorf("expected offset & limit to page runs. got: %v", runs)
+	}
+
+	applied := &State{ID: "run-d", Status: RSSucceeded, StartTime: toTimePointer(4000)}
+	if err := s.Put(ctx, applied); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := s.Prune(ctx, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"run-a"}, pruned); diff != "" {
+		t.Errorf("expected pruning to drop all but the newest run of each dataset (-want +got):\n%s", diff)
+	}
+	if _, err := s.Get(ctx, "run-a"); err != nil {
+		t.Errorf("expected a dry run to keep runs. got: %v", err)
+	}
+
+	if pruned, err = s.Prune(ctx, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"run-a"}, pruned); diff != "" {
+		t.Errorf("pruned runs mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.Get(ctx, "run-a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a pruned run to be removed. got: %v", err)
+	}
+	for _, id := range []string{"run-b", "run-c", "run-d"} {
+		if _, err := s.Get(ctx, id); err != nil {
+			t.Errorf("expected run %q to be kept. got: %v", id, err)
+		}
+	}
+}
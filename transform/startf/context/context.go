@@ -49,6 +49,22 @@ func (c *Context) SetResult(name string, value starlark.Value) {
 	c.results[name] = value
 }
 
+// Results returns the results of special function calls
+func (c *Context) Results() starlark.StringDict {
+	return c.results
+}
+
+// Values returns values set with the context "set" method
+func (c *Context) Values() starlark.StringDict {
+	return c.values
+}
+
+// SetValue places a value in the context, as if it were set with the context
+// "set" method
+func (c *Context) SetValue(name string, value starlark.Value) {
+	c.values[name] = value
+}
+
 func (c *Context) setValue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var (
 		key   starlark.String
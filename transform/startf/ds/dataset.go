@@ -49,6 +49,8 @@ type Dataset struct {
 	bodyCache starlark.Iterable
 	check     MutateFieldCheck
 	modBody   bool
+	// maximum size of a body written by set_body, 0 means no limit
+	maxBodySize int64
 }
 
 // NewDataset creates a dataset object, intended to be called from go-land to prepare datasets
@@ -68,6 +70,31 @@ func (d *Dataset) SetMutable(ds *dataset.Dataset) {
 	d.write = ds
 }
 
+// SetMaxBodySize limits the size in bytes of bodies written with set_body. A
+// max of 0 means no limit
+func (d *Dataset) SetMaxBodySize(max int64) {
+	d.maxBodySize = max
+}
+
+// BodySizeError is returned when set_body writes a body larger than the
+// maximum body size
+type BodySizeError struct {
+	Size, Max int64
+}
+
+// Error implements the error interface
+func (e *BodySizeError) Error() string {
+	return fmt.Sprintf("body is %d bytes, exceeding the limit of %d bytes", e.Size, e.Max)
+}
+
+// checkBodySize errors if a body of size bytes exceeds the maximum body size
+func (d *Dataset) checkBodySize(size int) error {
+	if d.maxBodySize > 0 && int64(size) > d.maxBodySize {
+		return &BodySizeError{Size: int64(size), Max: d.maxBodySize}
+	}
+	return nil
+}
+
 // IsBodyModified returns whether the body has been modified by set_body
 func (d *Dataset) IsBodyModified() bool {
 	return d.modBody
@@ -310,6 +337,10 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 			return starlark.None, fmt.Errorf("expected data for '%s' format to be a string", df)
 		}
 
+		if err := d.checkBodySize(len(str)); err != nil {
+			return starlark.None, err
+		}
+
 		d.write.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", df), []byte(string(str))))
 		d.modBody = true
 		d.bodyCache = nil
@@ -335,6 +366,9 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	if err := w.Close(); err != nil {
 		return starlark.None, err
 	}
+	if err := d.checkBodySize(len(w.Bytes())); err != nil {
+		return starlark.None, err
+	}
 
 	d.write.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", d.write.Structure.Format), w.Bytes()))
 	d.modBody = true
@@ -32,7 +32,9 @@ type StepRunner struct {
 	globals     starlark.StringDict
 	bodyFile    qfs.File
 	eventsCh    chan event.Event
-	thread      *starlark.Thread
+	load        ModuleLoader
+	limits      Limits
+	httpGuard   *HTTPGuard
 
 	download starlark.Iterable
 }
@@ -58,26 +60,108 @@ func NewStepRunner(prev *dataset.Dataset, opts ...func(o *ExecOpts)) *StepRunner
 		starlark.Universe[key] = val
 	}
 
-	thread := &starlark.Thread{Load: o.ModuleLoader}
-
 	// starCtx := skyctx.NewContext(o.Config, o.Secrets)
 	starCtx := skyctx.NewContext(nil, o.Secrets)
 
+	guard := &HTTPGuard{AllowedHosts: o.AllowedHosts}
 	r := &StepRunner{
 		starCtx:     starCtx,
 		loadDataset: o.DatasetLoader,
 		eventsCh:    o.EventsCh,
 		prev:        prev,
 		checkFunc:   o.MutateFieldCheck,
-		thread:      thread,
+		load:        httpModuleLoader(o.ModuleLoader, o.httpClient(guard), guard),
+		limits:      o.Limits,
+		httpGuard:   guard,
 		globals:     starlark.StringDict{},
 	}
 
 	return r
 }
 
-// RunStep runs the single transform step using the dataset
+// RunStep runs the single transform step using the dataset. Steps that exceed
+// the runner's limits fail with a *LimitError
 func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *dataset.TransformStep) error {
+	return r.limits.run(ctx, r.load, func(thread *starlark.Thread) error {
+		if err := r.defineStep(ctx, thread, ds, st); err != nil {
+			return err
+		}
+		return r.callStepFunc(ctx, thread, st.Category, ds)
+	})
+}
+
+// RestoreStep prepares the runner as if a step had run & left the context in
+// the given state, without calling the step function. The step script is
+// still executed, defining any functions later steps depend on
+func (r *StepRunner) RestoreStep(ctx context.Context, ds *dataset.Dataset, st *dataset.TransformStep, state *ContextState) error {
+	err := r.limits.run(ctx, r.load, func(thread *starlark.Thread) error {
+		return r.defineStep(ctx, thread, ds, st)
+	})
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil
+	}
+
+	for name, src := range state.Results {
+		v, err := DecodeLiteral(src)
+		if err != nil {
+			return fmt.Errorf("restoring context result %q: %w", name, err)
+		}
+		r.starCtx.SetResult(name, v)
+	}
+	for name, src := range state.Values {
+		v, err := DecodeLiteral(src)
+		if err != nil {
+			return fmt.Errorf("restoring context value %q: %w", name, err)
+		}
+		r.starCtx.SetValue(name, v)
+	}
+	return nil
+}
+
+// ContextState is a snapshot of the values a transform context carries
+// between steps, encoded as starlark literals
+type ContextState struct {
+	// results of special functions, eg: "download"
+	Results map[string]string `json:"results,omitempty"`
+	// values set with ctx.set
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// ContextState snapshots the transform context. It errors if the context
+// holds values that can't be encoded as literals
+func (r *StepRunner) ContextState() (*ContextState, error) {
+	results, err := encodeLiterals(r.starCtx.Results())
+	if err != nil {
+		return nil, err
+	}
+	values, err := encodeLiterals(r.starCtx.Values())
+	if err != nil {
+		return nil, err
+	}
+	return &ContextState{Results: results, Values: values}, nil
+}
+
+func encodeLiterals(d starlark.StringDict) (map[string]string, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	enc := make(map[string]string, len(d))
+	for name, v := range d {
+		src, err := EncodeLiteral(v)
+		if err != nil {
+			return nil, fmt.Errorf("context %q: %w", name, err)
+		}
+		enc[name] = src
+	}
+	return enc, nil
+}
+
+// defineStep executes a step script, adding the values it defines to runner
+// globals
+func (r *StepRunner) defineStep(ctx context.Context, thread *starlark.Thread, ds *dataset.Dataset, st *dataset.TransformStep) error {
 	r.globals["print"] = starlark.NewBuiltin("print", r.print)
 	r.globals["load_dataset"] = starlark.NewBuiltin("load_dataset", r.LoadDatasetFunc(ctx, ds))
 
@@ -86,7 +170,7 @@ func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *datas
 		return fmt.Errorf("starlark step Script must be a string. got %T", st.Script)
 	}
 
-	globals, err := starlark.ExecFile(r.thread, fmt.Sprintf("%s.star", st.Name), strings.NewReader(script), r.globals)
+	globals, err := starlark.ExecFile(thread, fmt.Sprintf("%s.star", st.Name), strings.NewReader(script), r.globals)
 	if err != nil {
 		if evalErr, ok := err.(*starlark.EvalError); ok {
 			return fmt.Errorf(evalErr.Backtrace())
@@ -97,11 +181,6 @@ func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *datas
 	for key, val := range globals {
 		r.globals[key] = val
 	}
-
-	if err := r.callStepFunc(ctx, r.thread, st.Category, ds); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -140,8 +219,8 @@ func (r *StepRunner) globalFunc(name string) (fn *starlark.Function, err error)
 type specialFunc func(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error)
 
 func (r *StepRunner) callDownloadFunc(thread *starlark.Thread, download *starlark.Function) (err error) {
-	httpGuard.EnableNtwk()
-	defer httpGuard.DisableNtwk()
+	r.httpGuard.EnableNtwk()
+	defer r.httpGuard.DisableNtwk()
 
 	val, err := starlark.Call(thread, download, starlark.Tuple{r.starCtx.Struct()}, nil)
 	if err != nil {
@@ -155,6 +234,7 @@ func (r *StepRunner) callDownloadFunc(thread *starlark.Thread, download *starlar
 func (r *StepRunner) callTransformFunc(ctx context.Context, thread *starlark.Thread, transform *starlark.Function, ds *dataset.Dataset) (err error) {
 	d := skyds.NewDataset(r.prev, r.checkFunc)
 	d.SetMutable(ds)
+	d.SetMaxBodySize(r.limits.MaxBodySize)
 	if _, err = starlark.Call(thread, transform, starlark.Tuple{d.Methods(), r.starCtx.Struct()}, nil); err != nil {
 		return err
 	}
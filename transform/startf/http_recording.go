@@ -0,0 +1,191 @@
+package startf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	starhttp "github.com/qri-io/starlib/http"
+)
+
+// ErrNoRecordedResponse is returned when a replayed transform makes a request
+// that isn't in the recording
+var ErrNoRecordedResponse = fmt.Errorf("no recorded response for request")
+
+// HTTPExchange is a recorded HTTP request & the response it received
+type HTTPExchange struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody []byte      `json:"requestBody,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// HTTPRecording lists the HTTP exchanges of a transform run, in the order
+// requests were made. HTTPRecording is safe for concurrent use
+type HTTPRecording struct {
+	lk        sync.Mutex
+	Exchanges []*HTTPExchange `json:"exchanges"`
+	// replayed marks exchanges that have already been served during replay
+	replayed map[int]bool
+}
+
+// NewHTTPRecording creates an empty recording
+func NewHTTPRecording() *HTTPRecording {
+	return &HTTPRecording{Exchanges: []*HTTPExchange{}}
+}
+
+// RecordHTTP adds every HTTP request scripts make to a recording
+func RecordHTTP(rec *HTTPRecording) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPRecording = rec
+	}
+}
+
+// ReplayHTTP answers HTTP requests from a recording instead of the network
+func ReplayHTTP(rec *HTTPRecording) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPReplay = rec
+	}
+}
+
+// SetAllowedHosts restricts the hosts scripts can make HTTP requests to. An
+// empty list allows all hosts
+func SetAllowedHosts(hosts []string) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.AllowedHosts = hosts
+	}
+}
+
+// httpClient creates the client scripts make requests with. guard checks
+// each redirect, the same as requests scripts make. Secrets are redacted from
+// recorded requests
+func (o *ExecOpts) httpClient(guard *HTTPGuard) *http.Client {
+	var cli http.Client
+	switch {
+	case o.HTTPReplay != nil:
+		cli = http.Client{Transport: o.HTTPReplay.replayer(o.redactor())}
+	case o.HTTPRecording != nil:
+		cli = http.Client{Transport: o.HTTPRecording.recorder(http.DefaultTransport, o.redactor())}
+	default:
+		cli = *starhttp.Client
+	}
+	cli.CheckRedirect = guard.checkRedirect
+	return &cli
+}
+
+// roundTripFunc adapts a function to the http.RoundTripper interface
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements the http.RoundTripper interface
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// recorder returns a transport that makes requests with next, adding each
+// exchange to the recording. Secrets are redacted from the recorded URL &
+// request body
+func (r *HTTPRecording) recorder(next http.RoundTripper, redact Redactor) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		reqBody, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		r.lk.Lock()
+		r.Exchanges = append(r.Exchanges, &HTTPExchange{
+			Method:      req.Method,
+			URL:         redact.Redact(req.URL.String()),
+			RequestBody: redactBytes(redact, reqBody),
+			Status:      res.StatusCode,
+			Header:      res.Header,
+			Body:        body,
+		})
+		r.lk.Unlock()
+		return res, nil
+	})
+}
+
+// replayer returns a transport that answers requests from the recording.
+// Requests are redacted the same way they were when recorded before they're
+// matched
+func (r *HTTPRecording) replayer(redact Redactor) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return r.replay(req, redact)
+	})
+}
+
+// replay answers a request with the first recorded exchange with a matching
+// method, URL & body that hasn't been served yet
+func (r *HTTPRecording) replay(req *http.Request, redact Redactor) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	reqBody = redactBytes(redact, reqBody)
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if r.replayed == nil {
+		r.replayed = map[int]bool{}
+	}
+	url := redact.Redact(req.URL.String())
+	for i, x := range r.Exchanges {
+		if r.replayed[i] || x.Method != req.Method || x.URL != url || !bytes.Equal(x.RequestBody, reqBody) {
+			continue
+		}
+		r.replayed[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", x.Status, http.StatusText(x.Status)),
+			StatusCode:    x.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        x.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(x.Body)),
+			ContentLength: int64(len(x.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoRecordedResponse, req.Method, url)
+}
+
+// readRequestBody reads the body of a request, leaving the request body
+// readable
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// redactBytes replaces secret values in a byte slice
+func redactBytes(redact Redactor, data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return []byte(redact.Redact(string(data)))
+}
@@ -0,0 +1,156 @@
+package startf
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+const fetchScript = `load("http.star", "http")
+
+def download(ctx):
+  return http.get(ctx.get_config("url")).json()["foo"]
+
+def transform(ds, ctx):
+  ds.set_body(ctx.download)
+`
+
+func execFetch(t *testing.T, url string, opts ...func(o *ExecOpts)) (string, error) {
+	ctx := context.Background()
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{Config: map[string]interface{}{"url": url}},
+	}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(fetchScript)))
+	if err := ExecScript(ctx, ds, nil, opts...); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), nil
+}
+
+func TestHTTPRecordReplay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":["bar","baz","bat"]}`))
+	}))
+	url := s.URL + "/data.json"
+
+	rec := NewHTTPRecording()
+	recorded, err := execFetch(t, url, RecordHTTP(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Exchanges) != 1 {
+		t.Fatalf("expected 1 recorded exchange. got: %d", len(rec.Exchanges))
+	}
+	if x := rec.Exchanges[0]; x.Method != "GET" || x.URL != url || x.Status != http.StatusOK {
+		t.Errorf("unexpected recorded exchange: %s %s %d", x.Method, x.URL, x.Status)
+	}
+
+	// replays must not touch the network
+	s.Close()
+	replayed, err := execFetch(t, url, ReplayHTTP(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed body mismatch. expected: %q, got: %q", recorded, replayed)
+	}
+
+	_, err = execFetch(t, s.URL+"/other.json", ReplayHTTP(rec))
+	if err == nil || !strings.Contains(err.Error(), ErrNoRecordedResponse.Error()) {
+		t.Errorf("expected an unrecorded request to fail with ErrNoRecordedResponse. got: %v", err)
+	}
+}
+
+func TestHTTPRecordRedactsSecrets(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":[1]}`))
+	}))
+	defer s.Close()
+	url := s.URL + "/data.json?key=hunter2"
+	secrets := SetSecrets(map[string]string{"API_KEY": "hunter2"})
+
+	rec := NewHTTPRecording()
+	if _, err := execFetch(t, url, RecordHTTP(rec), secrets); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Exchanges) != 1 {
+		t.Fatalf("expected 1 recorded exchange. got: %d", len(rec.Exchanges))
+	}
+	if expect := s.URL + "/data.json?key=[REDACTED]"; rec.Exchanges[0].URL != expect {
+		t.Errorf("recorded URL mismatch. expected: %q, got: %q", expect, rec.Exchanges[0].URL)
+	}
+
+	// replays with the same secrets match redacted requests
+	s.Close()
+	if _, err := execFetch(t, url, ReplayHTTP(rec), secrets); err != nil {
+		t.Errorf("expected replaying a redacted request to succeed. got: %s", err)
+	}
+}
+
+func TestHTTPAllowedHosts(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":[1]}`))
+	}))
+	defer s.Close()
+
+	if _, err := execFetch(t, s.URL, SetAllowedHosts([]string{"127.0.0.1"})); err != nil {
+		t.Errorf("expected request to an allowed host to succeed. got: %s", err)
+	}
+	_, err := execFetch(t, s.URL, SetAllowedHosts([]string{"example.com"}))
+	if err == nil || !strings.Contains(err.Error(), ErrHostNotAllowed.Error()) {
+		t.Errorf("expected request to a host that isn't allowed to fail. got: %v", err)
+	}
+
+	guard := &HTTPGuard{NetworkEnabled: true, AllowedHosts: []string{"*.example.com", "data.gov"}}
+	cases := map[string]bool{
+		"https://api.example.com/x": true,
+		"https://EXAMPLE.com/x":     false,
+		"https://data.gov":          true,
+		"https://www.data.gov":      false,
+		"https://notexample.com":    false,
+	}
+	for u, expect := range cases {
+		req, _ := http.NewRequest("GET", u, nil)
+		if got := guard.Allowed(req) == nil; got != expect {
+			t.Errorf("%s: expected allowed to be %t", u, expect)
+		}
+	}
+}
+
+func TestHTTPAllowedHostsRedirect(t *testing.T) {
+	deniedHit := false
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deniedHit = true
+		w.Write([]byte(`{"foo":[1]}`))
+	}))
+	defer denied.Close()
+
+	// the same server, addressed by a host that isn't allowed
+	deniedURL := strings.Replace(denied.URL, "127.0.0.1", "localhost", 1)
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, deniedURL, http.StatusFound)
+	}))
+	defer allowed.Close()
+
+	_, err := execFetch(t, allowed.URL, SetAllowedHosts([]string{"127.0.0.1"}))
+	if err == nil || !strings.Contains(err.Error(), ErrHostNotAllowed.Error()) {
+		t.Errorf("expected a redirect to a host that isn't allowed to fail. got: %v", err)
+	}
+	if deniedHit {
+		t.Error("expected the redirect to a host that isn't allowed not to be followed")
+	}
+
+	if _, err := execFetch(t, allowed.URL, SetAllowedHosts([]string{"127.0.0.1", "localhost"})); err != nil {
+		t.Errorf("expected a redirect to an allowed host to succeed. got: %s", err)
+	}
+}
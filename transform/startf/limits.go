@@ -0,0 +1,105 @@
+package startf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	skyds "github.com/qri-io/qri/transform/startf/ds"
+	"go.starlark.net/starlark"
+)
+
+// Limits bounds the resources a transform script can use. A zero value for
+// any field means no limit
+type Limits struct {
+	// MaxSteps caps the number of starlark execution steps each transform
+	// step can take
+	MaxSteps uint64 `json:"maxSteps,omitempty"`
+	// StepTimeout caps the wall-clock duration of each transform step
+	StepTimeout time.Duration `json:"stepTimeout,omitempty"`
+	// MaxBodySize caps the size in bytes of a body written with set_body
+	MaxBodySize int64 `json:"maxBodySize,omitempty"`
+}
+
+const (
+	// LimitMaxSteps names the execution step limit
+	LimitMaxSteps = "maxSteps"
+	// LimitStepTimeout names the per-step timeout
+	LimitStepTimeout = "stepTimeout"
+	// LimitMaxBodySize names the body size limit
+	LimitMaxBodySize = "maxBodySize"
+)
+
+// SetLimits bounds the resources scripts can use
+func SetLimits(l Limits) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.Limits = l
+	}
+}
+
+// LimitError is returned when a transform exceeds one of its Limits
+type LimitError struct {
+	// Limit is the name of the exceeded limit, one of LimitMaxSteps,
+	// LimitStepTimeout, or LimitMaxBodySize
+	Limit string
+	// Max is the configured limit: a number of steps, nanoseconds, or bytes
+	Max int64
+}
+
+// Error implements the error interface
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitMaxSteps:
+		return fmt.Sprintf("transform step exceeded the limit of %d execution steps", e.Max)
+	case LimitStepTimeout:
+		return fmt.Sprintf("transform step exceeded the timeout of %s", time.Duration(e.Max))
+	case LimitMaxBodySize:
+		return fmt.Sprintf("transform body exceeded the limit of %d bytes", e.Max)
+	default:
+		return fmt.Sprintf("transform exceeded limit %q", e.Limit)
+	}
+}
+
+// run calls fn on a fresh starlark thread with limits applied, converting
+// errors caused by a limit into a *LimitError. The thread is cancelled if ctx
+// is done before fn returns
+func (l Limits) run(ctx context.Context, load ModuleLoader, fn func(thread *starlark.Thread) error) error {
+	thread := &starlark.Thread{Load: load}
+	if l.MaxSteps > 0 {
+		thread.SetMaxExecutionSteps(l.MaxSteps)
+	}
+
+	runCtx := ctx
+	if l.StepTimeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, l.StepTimeout)
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-runCtx.Done():
+			thread.Cancel(runCtx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	err := fn(thread)
+	if err == nil {
+		return nil
+	}
+
+	var sizeErr *skyds.BodySizeError
+	switch {
+	case l.StepTimeout > 0 && runCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil:
+		return &LimitError{Limit: LimitStepTimeout, Max: int64(l.StepTimeout)}
+	case l.MaxSteps > 0 && thread.ExecutionSteps() >= l.MaxSteps:
+		return &LimitError{Limit: LimitMaxSteps, Max: int64(l.MaxSteps)}
+	case errors.As(err, &sizeErr):
+		return &LimitError{Limit: LimitMaxBodySize, Max: sizeErr.Max}
+	}
+	return err
+}
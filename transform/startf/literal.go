@@ -0,0 +1,90 @@
+package startf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+// EncodeLiteral writes a starlark value as source code that evaluates to an
+// equal value. Only None, booleans, numbers, strings, lists, tuples & dicts
+// can be encoded. Unlike value.String(), floats are written without losing
+// precision
+func EncodeLiteral(v starlark.Value) (string, error) {
+	buf := &strings.Builder{}
+	if err := writeLiteral(buf, v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// DecodeLiteral evaluates source written by EncodeLiteral. Decoding enables
+// floating point numbers in the starlark resolver
+func DecodeLiteral(src string) (starlark.Value, error) {
+	resolve.AllowFloat = true
+	return starlark.Eval(&starlark.Thread{Name: "literal"}, "literal", src, nil)
+}
+
+func writeLiteral(buf *strings.Builder, v starlark.Value) error {
+	switch x := v.(type) {
+	case starlark.NoneType, starlark.Bool, starlark.Int, starlark.String:
+		buf.WriteString(x.String())
+	case starlark.Float:
+		f := float64(x)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("can't encode non-finite float %s", x.String())
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		buf.WriteString(s)
+	case *starlark.List:
+		buf.WriteByte('[')
+		for i := 0; i < x.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeLiteral(buf, x.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case starlark.Tuple:
+		buf.WriteByte('(')
+		for i, el := range x {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeLiteral(buf, el); err != nil {
+				return err
+			}
+		}
+		if len(x) == 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte(')')
+	case *starlark.Dict:
+		buf.WriteByte('{')
+		for i, item := range x.Items() {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeLiteral(buf, item[0]); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := writeLiteral(buf, item[1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("can't encode starlark %s value", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package startf
+
+import (
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestLiteralRoundTrip(t *testing.T) {
+	list := starlark.NewList([]starlark.Value{starlark.MakeInt(1), starlark.Float(1.0 / 3), starlark.String("quote\"d\n")})
+	dict := starlark.NewDict(2)
+	dict.SetKey(starlark.String("rows"), list)
+	dict.SetKey(starlark.MakeInt(2), starlark.Tuple{starlark.None})
+
+	cases := []struct {
+		v      starlark.Value
+		expect string
+	}{
+		{starlark.None, "None"},
+		{starlark.True, "True"},
+		{starlark.Float(2), "2.0"},
+		{starlark.Float(1e21), "1e+21"},
+		{starlark.Tuple{starlark.MakeInt(1)}, "(1,)"},
+		{list, `[1, 0.3333333333333333, "quote\"d\n"]`},
+		{dict, `{"rows": [1, 0.3333333333333333, "quote\"d\n"], 2: (None,)}`},
+	}
+
+	for _, c := range cases {
+		src, err := EncodeLiteral(c.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src != c.expect {
+			t.Errorf("encoding mismatch. want: %s got: %s", c.expect, src)
+		}
+		got, err := DecodeLiteral(src)
+		if err != nil {
+			t.Fatalf("decoding %s: %s", src, err)
+		}
+		if eq, err := starlark.Equal(c.v, got); err != nil || !eq {
+			t.Errorf("round trip mismatch. want: %s got: %s", c.v, got)
+		}
+	}
+
+	if _, err := EncodeLiteral(starlark.NewBuiltin("print", nil)); err == nil {
+		t.Error("expected encoding a function to error")
+	}
+}
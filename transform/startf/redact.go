@@ -0,0 +1,78 @@
+package startf
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secret values in transform output
+const Redacted = "[REDACTED]"
+
+// Redactor replaces secret values in text, so secrets don't leak into print
+// output, run logs or HTTP recordings. The zero Redactor leaves text as-is
+type Redactor struct {
+	r *strings.Replacer
+}
+
+// NewRedactor creates a Redactor for a set of secret values
+func NewRedactor(secrets map[string]string) Redactor {
+	vals := make([]string, 0, len(secrets))
+	for _, v := range secrets {
+		if v != "" {
+			vals = append(vals, v)
+		}
+	}
+	if len(vals) == 0 {
+		return Redactor{}
+	}
+	// replace longer values first, so a secret that contains another secret is
+	// redacted whole
+	sort.Slice(vals, func(i, j int) bool { return len(vals[i]) > len(vals[j]) })
+	pairs := make([]string, 0, len(vals)*2)
+	for _, v := range vals {
+		pairs = append(pairs, v, Redacted)
+	}
+	return Redactor{r: strings.NewReplacer(pairs...)}
+}
+
+// Redact replaces secret values in a string
+func (r Redactor) Redact(s string) string {
+	if r.r == nil {
+		return s
+	}
+	return r.r.Replace(s)
+}
+
+// Writer wraps a writer, redacting secrets from each write
+func (r Redactor) Writer(w io.Writer) io.Writer {
+	if r.r == nil {
+		return w
+	}
+	return redactWriter{w: w, r: r.r}
+}
+
+type redactWriter struct {
+	w io.Writer
+	r *strings.Replacer
+}
+
+// Write implements the io.Writer interface. it reports the length of p as
+// written, so callers aren't confused by redaction changing the length
+func (rw redactWriter) Write(p []byte) (int, error) {
+	if _, err := rw.r.WriteString(rw.w, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactor creates a Redactor for the secrets passed to a script
+func (o *ExecOpts) redactor() Redactor {
+	secrets := make(map[string]string, len(o.Secrets))
+	for key, val := range o.Secrets {
+		if str, ok := val.(string); ok {
+			secrets[key] = str
+		}
+	}
+	return NewRedactor(secrets)
+}
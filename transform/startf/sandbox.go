@@ -3,19 +3,29 @@ package startf
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	starhttp "github.com/qri-io/starlib/http"
+	"go.starlark.net/starlark"
 )
 
 var (
 	httpGuard = &HTTPGuard{}
 	// ErrNtwkDisabled is returned whenever a network call is attempted but h.NetworkEnabled is false
 	ErrNtwkDisabled = fmt.Errorf("network use is disabled. http can only be used during download step")
+	// ErrHostNotAllowed is returned when a request is made to a host that isn't
+	// in the allowlist
+	ErrHostNotAllowed = fmt.Errorf("host is not in the transform's list of allowed hosts")
 )
 
 // HTTPGuard protects network requests, only allowing when network is enabled
 type HTTPGuard struct {
 	NetworkEnabled bool
+	// AllowedHosts restricts requests to a list of hosts. entries that start
+	// with "*." match any subdomain, eg: "*.example.com". An empty list allows
+	// requests to any host
+	AllowedHosts []string
 }
 
 // Allowed implements starlib/http RequestGuard
@@ -23,9 +33,43 @@ func (h *HTTPGuard) Allowed(req *http.Request) error {
 	if !h.NetworkEnabled {
 		return ErrNtwkDisabled
 	}
+	if host := req.URL.Hostname(); !h.hostAllowed(host) {
+		return fmt.Errorf("%w: %q", ErrHostNotAllowed, host)
+	}
 	return nil
 }
 
+func (h *HTTPGuard) hostAllowed(host string) bool {
+	if len(h.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range h.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// maxRedirects matches the redirect limit of the default http client
+const maxRedirects = 10
+
+// checkRedirect implements http.Client CheckRedirect. Redirected requests go
+// through the same checks as the first request, an allowed host can't
+// redirect to a host that isn't allowed
+func (h *HTTPGuard) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return h.Allowed(req)
+}
+
 // EnableNtwk allows network calls
 func (h *HTTPGuard) EnableNtwk() {
 	h.NetworkEnabled = true
@@ -40,3 +84,35 @@ func init() {
 	// connect httpGuard instance to starlib http guard
 	starhttp.Guard = httpGuard
 }
+
+// httpModuleLock serializes swapping the starlib http package globals
+var httpModuleLock sync.Mutex
+
+// newHTTPModule creates a starlib http module that makes requests with cli,
+// checking each request with guard
+func newHTTPModule(cli *http.Client, guard *HTTPGuard) (starlark.StringDict, error) {
+	httpModuleLock.Lock()
+	defer httpModuleLock.Unlock()
+
+	// starlib http modules capture the package client & guard when loaded
+	prevCli, prevGuard := starhttp.Client, starhttp.Guard
+	defer func() {
+		starhttp.Client, starhttp.Guard = prevCli, prevGuard
+	}()
+	starhttp.Client, starhttp.Guard = cli, guard
+	return starhttp.LoadModule()
+}
+
+// httpModuleLoader wraps a module loader, loading the http module with a
+// client & guard that are specific to one transform
+func httpModuleLoader(load ModuleLoader, cli *http.Client, guard *HTTPGuard) ModuleLoader {
+	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		if module == starhttp.ModuleName {
+			return newHTTPModule(cli, guard)
+		}
+		if load == nil {
+			return nil, fmt.Errorf("couldn't load module: %s", module)
+		}
+		return load(thread, module)
+	}
+}
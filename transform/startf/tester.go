@@ -0,0 +1,366 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+	skyctx "github.com/qri-io/qri/transform/startf/context"
+	skyds "github.com/qri-io/qri/transform/startf/ds"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+// TestFuncPrefix marks starlark functions as tests
+const TestFuncPrefix = "test_"
+
+// TestResult is the outcome of running one starlark test function
+type TestResult struct {
+	Name string `json:"name"`
+	// file the test function is defined in
+	File   string `json:"file"`
+	Passed bool   `json:"passed"`
+	// failure message, prefixed with the file & line that failed
+	Error string `json:"error,omitempty"`
+	// lines written with print while the test ran
+	Output []string `json:"output,omitempty"`
+	// test duration in seconds
+	Elapsed float64 `json:"elapsed"`
+}
+
+// TestReport summarizes a test run
+type TestReport struct {
+	// name of the script under test
+	Script string        `json:"script"`
+	Passed bool          `json:"passed"`
+	Tests  []*TestResult `json:"tests"`
+	// run duration in seconds
+	Elapsed float64 `json:"elapsed"`
+	// RunID identifies the record of the test run, if one was kept
+	RunID string `json:"runID,omitempty"`
+}
+
+// Failed returns the number of tests that didn't pass
+func (r *TestReport) Failed() int {
+	n := 0
+	for _, t := range r.Tests {
+		if !t.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// RunTests executes a transform script & any test files, then calls each
+// function named with TestFuncPrefix, in the order they're defined. Test
+// files share globals with the script, so tests can call the script's
+// transform & download functions. Only tests with names that match the
+// regular expression match are run, an empty string matches all tests.
+//
+// Tests run in the same sandbox as transforms: the network is disabled, each
+// file & test runs within the limits set by opts, and load_dataset reads
+// through the DatasetLoader, which should return fixture datasets. Tests can
+// also call these builtins:
+//
+//	new_dataset(ref=None)                mutable dataset to pass to a transform,
+//	                                     reading from a fixture if ref is given
+//	new_context(config={}, secrets={})   transform context
+//	assert_eq(actual, expect, msg="")    fail unless actual == expect
+//	assert_true(cond, msg="")            fail unless cond is truthy
+//	fail(msg)                            fail the test
+func RunTests(ctx context.Context, files []qfs.File, match string, opts ...func(o *ExecOpts)) (*TestReport, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no script to test")
+	}
+	filter, err := regexp.Compile(match)
+	if err != nil {
+		return nil, fmt.Errorf("invalid test filter: %w", err)
+	}
+
+	o := &ExecOpts{}
+	DefaultExecOpts(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	resolve.AllowFloat = o.AllowFloat
+	resolve.AllowSet = o.AllowSet
+	resolve.AllowLambda = o.AllowLambda
+	resolve.AllowNestedDef = o.AllowNestedDef
+
+	starlark.Universe["error"] = starlark.NewBuiltin("error", Error)
+	for key, val := range o.Globals {
+		starlark.Universe[key] = val
+	}
+
+	// tests get their own guard that never enables the network, instead of
+	// sharing the package guard with transforms running alongside them
+	guard := &HTTPGuard{}
+	t := &tester{
+		ctx:         ctx,
+		loadDataset: o.DatasetLoader,
+		secrets:     o.Secrets,
+		load:        httpModuleLoader(o.ModuleLoader, o.httpClient(guard), guard),
+		limits:      o.Limits,
+	}
+	t.globals = starlark.StringDict{
+		"print":        starlark.NewBuiltin("print", t.print),
+		"load_dataset": starlark.NewBuiltin("load_dataset", t.LoadDataset),
+		"new_dataset":  starlark.NewBuiltin("new_dataset", t.newDataset),
+		"new_context":  starlark.NewBuiltin("new_context", t.newContext),
+		"assert_eq":    starlark.NewBuiltin("assert_eq", assertEq),
+		"assert_true":  starlark.NewBuiltin("assert_true", assertTrue),
+		"fail":         starlark.NewBuiltin("fail", fail),
+	}
+
+	// execute every file, collecting globals
+	order := map[string]int{}
+	for i, f := range files {
+		order[f.FileName()] = i
+		var globals starlark.StringDict
+		err := t.limits.run(ctx, t.load, func(thread *starlark.Thread) (err error) {
+			globals, err = starlark.ExecFile(thread, f.FileName(), f, t.globals)
+			return err
+		})
+		if err != nil {
+			if evalErr, ok := err.(*starlark.EvalError); ok {
+				return nil, fmt.Errorf(evalErr.Backtrace())
+			}
+			return nil, err
+		}
+		for key, val := range globals {
+			t.globals[key] = val
+		}
+	}
+
+	tests := []*starlark.Function{}
+	for name, val := range t.globals {
+		if fn, ok := val.(*starlark.Function); ok && strings.HasPrefix(name, TestFuncPrefix) && filter.MatchString(name) {
+			tests = append(tests, fn)
+		}
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i].Position(), tests[j].Position()
+		if a.Filename() != b.Filename() {
+			return order[a.Filename()] < order[b.Filename()]
+		}
+		return a.Line < b.Line
+	})
+
+	report := &TestReport{Script: files[0].FileName(), Passed: true, Tests: []*TestResult{}}
+	start := time.Now()
+	for _, fn := range tests {
+		res := t.run(fn)
+		if !res.Passed {
+			report.Passed = false
+		}
+		report.Tests = append(report.Tests, res)
+	}
+	report.Elapsed = time.Since(start).Seconds()
+	return report, nil
+}
+
+// tester holds the state of a test run
+type tester struct {
+	ctx         context.Context
+	loadDataset dsref.ParseResolveLoad
+	secrets     map[string]interface{}
+	load        ModuleLoader
+	limits      Limits
+	globals     starlark.StringDict
+	// result of the running test
+	current *TestResult
+}
+
+func (t *tester) run(fn *starlark.Function) *TestResult {
+	res := &TestResult{Name: fn.Name(), File: fn.Position().Filename(), Passed: true}
+	t.current = res
+	defer func() { t.current = nil }()
+
+	start := time.Now()
+	defer func() { res.Elapsed = time.Since(start).Seconds() }()
+
+	if fn.NumParams() != 0 {
+		res.Passed = false
+		res.Error = fmt.Sprintf("%s: test functions must not take arguments", fn.Position())
+		return res
+	}
+	err := t.limits.run(t.ctx, t.load, func(thread *starlark.Thread) error {
+		_, err := starlark.Call(thread, fn, nil, nil)
+		return err
+	})
+	if err != nil {
+		res.Passed = false
+		res.Error = testFailure(err)
+	}
+	return res
+}
+
+// testFailure describes a test error, prefixed with the position in the
+// script that failed
+func testFailure(err error) string {
+	evalErr, ok := err.(*starlark.EvalError)
+	if !ok {
+		return err.Error()
+	}
+	for i := 0; i < len(evalErr.CallStack); i++ {
+		if pos := evalErr.CallStack.At(i).Pos; pos.IsValid() && pos.Filename() != "<builtin>" {
+			return fmt.Sprintf("%s:%d: %s", pos.Filename(), pos.Line, evalErr.Msg)
+		}
+	}
+	return evalErr.Msg
+}
+
+func (t *tester) print(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var message starlark.String
+	if err := starlark.UnpackArgs("print", args, kwargs, "message", &message); err != nil {
+		return starlark.None, err
+	}
+	if t.current != nil {
+		t.current.Output = append(t.current.Output, message.GoString())
+	}
+	return starlark.None, nil
+}
+
+// LoadDataset implements the starlark load_dataset function for tests
+func (t *tester) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr starlark.String
+	if err := starlark.UnpackArgs("load_dataset", args, kwargs, "ref", &refstr); err != nil {
+		return starlark.None, err
+	}
+	ds, err := t.fixture(refstr.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	return skyds.NewDataset(ds, nil).Methods(), nil
+}
+
+func (t *tester) newDataset(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr starlark.String
+	if err := starlark.UnpackArgs("new_dataset", args, kwargs, "ref?", &refstr); err != nil {
+		return starlark.None, err
+	}
+
+	prev := &dataset.Dataset{}
+	if refstr != "" {
+		var err error
+		if prev, err = t.fixture(refstr.GoString()); err != nil {
+			return starlark.None, err
+		}
+	}
+	d := skyds.NewDataset(prev, nil)
+	d.SetMutable(&dataset.Dataset{})
+	return d.Methods(), nil
+}
+
+func (t *tester) newContext(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var config, secrets *starlark.Dict
+	if err := starlark.UnpackArgs("new_context", args, kwargs, "config?", &config, "secrets?", &secrets); err != nil {
+		return starlark.None, err
+	}
+
+	cfg, err := unmarshalDict(config)
+	if err != nil {
+		return starlark.None, fmt.Errorf("new_context: config: %w", err)
+	}
+	sec := t.secrets
+	if secrets != nil {
+		if sec, err = unmarshalDict(secrets); err != nil {
+			return starlark.None, fmt.Errorf("new_context: secrets: %w", err)
+		}
+	}
+	return skyctx.NewContext(cfg, sec).Struct(), nil
+}
+
+// fixture loads a dataset with an open body file
+func (t *tester) fixture(refstr string) (*dataset.Dataset, error) {
+	if t.loadDataset == nil {
+		return nil, fmt.Errorf("no fixture for %q", refstr)
+	}
+	ds, err := t.loadDataset(t.ctx, refstr)
+	if err != nil {
+		return nil, err
+	}
+	// copy the body into memory so a fixture can be read more than once
+	if f := ds.BodyFile(); f != nil {
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes(f.FileName(), data))
+	}
+	return ds, nil
+}
+
+func unmarshalDict(d *starlark.Dict) (map[string]interface{}, error) {
+	if d == nil {
+		return nil, nil
+	}
+	v, err := util.Unmarshal(d)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a dict with string keys")
+	}
+	return m, nil
+}
+
+func assertEq(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		actual, expect starlark.Value
+		msg            starlark.String
+	)
+	if err := starlark.UnpackArgs("assert_eq", args, kwargs, "actual", &actual, "expect", &expect, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	eq, err := starlark.Equal(actual, expect)
+	if err != nil {
+		return starlark.None, err
+	}
+	if !eq {
+		return starlark.None, failure(msg, "expected %s, got %s", expect, actual)
+	}
+	return starlark.None, nil
+}
+
+func assertTrue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		cond starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs("assert_true", args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	if !cond.Truth() {
+		return starlark.None, failure(msg, "expected a true value, got %s", cond)
+	}
+	return starlark.None, nil
+}
+
+func fail(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var msg starlark.String
+	if err := starlark.UnpackArgs("fail", args, kwargs, "msg", &msg); err != nil {
+		return starlark.None, err
+	}
+	return starlark.None, fmt.Errorf("%s", msg.GoString())
+}
+
+// failure creates an assertion error, prefixed by a user message if one is
+// given
+func failure(msg starlark.String, format string, args ...interface{}) error {
+	err := fmt.Sprintf(format, args...)
+	if msg != "" {
+		err = fmt.Sprintf("%s: %s", msg.GoString(), err)
+	}
+	return fmt.Errorf("%s", err)
+}
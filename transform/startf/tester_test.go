@@ -0,0 +1,121 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func openTestFiles(t *testing.T, paths ...string) []qfs.File {
+	t.Helper()
+	files := []qfs.File{}
+	for _, p := range paths {
+		f, err := os.Open(filepath.Join("testdata/tester", p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, qfs.NewMemfileReader(p, f))
+	}
+	return files
+}
+
+func citiesFixture(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+	if refstr != "me/cities" {
+		return nil, fmt.Errorf("no fixture for %q", refstr)
+	}
+	ds := &dataset.Dataset{
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[["toronto",2731571],["chatham",40467],["new york",8398748]]`)))
+	return ds, nil
+}
+
+func TestRunTests(t *testing.T) {
+	ctx := context.Background()
+	files := openTestFiles(t, "cities.star", "cities_test.star")
+	report, err := RunTests(ctx, files, "", AddDatasetLoader(citiesFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		Name, File, Error string
+		Passed            bool
+		Output            []string
+	}
+	got := []result{}
+	for _, r := range report.Tests {
+		got = append(got, result{r.Name, r.File, r.Error, r.Passed, r.Output})
+	}
+	expect := []result{
+		{Name: "test_total_pop", File: "cities.star", Passed: true},
+		{Name: "test_transform_filters_cities", File: "cities_test.star", Passed: true, Output: []string{"kept 2 cities"}},
+		{Name: "test_fixture_unchanged", File: "cities_test.star", Passed: true},
+		{Name: "test_fails", File: "cities_test.star", Error: "cities_test.star:13: total: expected 2, got 1"},
+		{Name: "test_secret", File: "cities_test.star", Passed: true},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("results mismatch (-want +got):\n%s", diff)
+	}
+	if report.Passed || report.Failed() != 1 || report.Script != "cities.star" {
+		t.Errorf("unexpected report summary: passed=%t failed=%d script=%q", report.Passed, report.Failed(), report.Script)
+	}
+
+	files = openTestFiles(t, "cities.star", "cities_test.star")
+	if report, err = RunTests(ctx, files, "pop$", AddDatasetLoader(citiesFixture)); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Tests) != 1 || !report.Passed {
+		t.Errorf("expected filter to run one passing test, got %d tests, passed: %t", len(report.Tests), report.Passed)
+	}
+
+	if _, err = RunTests(ctx, openTestFiles(t, "cities.star"), "("); err == nil {
+		t.Error("expected invalid filter to error")
+	}
+}
+
+func TestRunTestsSandbox(t *testing.T) {
+	// tests use their own guard, enabling the package guard for a transform
+	// running alongside them doesn't give tests network access
+	httpGuard.EnableNtwk()
+	defer httpGuard.DisableNtwk()
+
+	script := qfs.NewMemfileBytes("sandbox_test.star", []byte(`load("http.star", "http")
+
+def test_forever():
+	for i in range(100000000):
+		pass
+
+def test_network():
+	http.get("http://example.com")
+
+def test_ok():
+	assert_true(True)
+`))
+	report, err := RunTests(context.Background(), []qfs.File{script}, "", SetLimits(Limits{MaxSteps: 10000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := map[string]string{}
+	for _, r := range report.Tests {
+		errs[r.Name] = r.Error
+	}
+	expect := map[string]string{
+		"test_forever": (&LimitError{Limit: LimitMaxSteps, Max: 10000}).Error(),
+		"test_network": ErrNtwkDisabled.Error(),
+		"test_ok":      "",
+	}
+	for name, want := range expect {
+		if got, ok := errs[name]; !ok || !strings.Contains(got, want) {
+			t.Errorf("%s: expected error containing %q, got: %q", name, want, got)
+		}
+	}
+}
@@ -52,6 +52,14 @@ type ExecOpts struct {
 	ModuleLoader ModuleLoader
 	// channel to send events on
 	EventsCh chan event.Event
+	// resources scripts are allowed to use
+	Limits Limits
+	// hosts scripts can make http requests to, empty allows all hosts
+	AllowedHosts []string
+	// record of http requests scripts make, nil disables recording
+	HTTPRecording *HTTPRecording
+	// recording to answer http requests from instead of the network
+	HTTPReplay *HTTPRecording
 }
 
 // AddDatasetLoader is required to enable the load_dataset starlark builtin
@@ -131,6 +139,8 @@ type transform struct {
 	bodyFile     qfs.File
 	stderr       io.Writer
 	moduleLoader ModuleLoader
+	maxBodySize  int64
+	httpGuard    *HTTPGuard
 
 	download starlark.Iterable
 }
@@ -183,6 +193,7 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 	tr := io.TeeReader(script, buf)
 	pipeScript := qfs.NewMemfileReader(script.FileName(), tr)
 
+	guard := &HTTPGuard{AllowedHosts: o.AllowedHosts}
 	t := &transform{
 		ctx:          ctx,
 		loadDataset:  o.DatasetLoader,
@@ -191,48 +202,42 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		prev:         prev,
 		skyqri:       skyqri.NewModule(o.Repo),
 		checkFunc:    o.MutateFieldCheck,
-		stderr:       o.ErrWriter,
-		moduleLoader: o.ModuleLoader,
+		stderr:       o.redactor().Writer(o.ErrWriter),
+		moduleLoader: httpModuleLoader(o.ModuleLoader, o.httpClient(guard), guard),
+		maxBodySize:  o.Limits.MaxBodySize,
+		httpGuard:    guard,
 	}
 
 	skyCtx := skyctx.NewContext(next.Transform.Config, o.Secrets)
-	thread := &starlark.Thread{Load: t.ModuleLoader}
 
-	// execute the transformation
-	t.globals, err = starlark.ExecFile(thread, pipeScript.FileName(), pipeScript, t.locals())
-	if err != nil {
-		if evalErr, ok := err.(*starlark.EvalError); ok {
-			return fmt.Errorf(evalErr.Backtrace())
+	// the whole script runs as a single step within limits
+	err = o.Limits.run(ctx, t.ModuleLoader, func(thread *starlark.Thread) error {
+		// execute the transformation
+		var err error
+		if t.globals, err = starlark.ExecFile(thread, pipeScript.FileName(), pipeScript, t.locals()); err != nil {
+			return err
 		}
-		return err
-	}
-
-	funcs, err := t.specialFuncs()
-	if err != nil {
-		return err
-	}
-
-	for name, fn := range funcs {
-		val, err := fn(t, thread, skyCtx)
 
+		funcs, err := t.specialFuncs()
 		if err != nil {
-			if evalErr, ok := err.(*starlark.EvalError); ok {
-				return fmt.Errorf(evalErr.Backtrace())
-			}
 			return err
 		}
 
-		skyCtx.SetResult(name, val)
-	}
+		for name, fn := range funcs {
+			val, err := fn(t, thread, skyCtx)
+			if err != nil {
+				return err
+			}
+			skyCtx.SetResult(name, val)
+		}
 
-	err = callTransformFunc(t, thread, skyCtx)
+		// restore consumed script file
+		defer next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", buf.Bytes()))
+		return callTransformFunc(t, thread, skyCtx)
+	})
 	if evalErr, ok := err.(*starlark.EvalError); ok {
 		return fmt.Errorf(evalErr.Backtrace())
 	}
-
-	// restore consumed script file
-	next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", buf.Bytes()))
-
 	return err
 }
 
@@ -283,8 +288,8 @@ func (t *transform) specialFuncs() (defined map[string]specialFunc, err error) {
 }
 
 func callDownloadFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error) {
-	httpGuard.EnableNtwk()
-	defer httpGuard.DisableNtwk()
+	t.httpGuard.EnableNtwk()
+	defer t.httpGuard.DisableNtwk()
 
 	var download *starlark.Function
 	if download, err = t.globalFunc("download"); err != nil {
@@ -308,6 +313,7 @@ func callTransformFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Contex
 
 	d := skyds.NewDataset(t.prev, t.checkFunc)
 	d.SetMutable(t.next)
+	d.SetMaxBodySize(t.maxBodySize)
 	if _, err = starlark.Call(thread, transform, starlark.Tuple{d.Methods(), ctx.Struct()}, nil); err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
@@ -50,6 +51,21 @@ func TestOpts(t *testing.T) {
 	}
 }
 
+func TestExecScriptRedactsSecrets(t *testing.T) {
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(`def transform(ds, ctx):
+  print("key: %s" % ctx.get_secret("API_KEY"))
+`)))
+
+	stderr := &bytes.Buffer{}
+	if err := ExecScript(context.Background(), ds, nil, SetErrWriter(stderr), SetSecrets(map[string]string{"API_KEY": "hunter2"})); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "key: [REDACTED]\n"; stderr.String() != expect {
+		t.Errorf("stderr mismatch. expected: %q, got: %q", expect, stderr.String())
+	}
+}
+
 func TestExecScript(t *testing.T) {
 	ctx := context.Background()
 	ds := &dataset.Dataset{
@@ -168,6 +184,39 @@ def transform(ds, ctx):
 	}
 }
 
+func TestExecScriptLimits(t *testing.T) {
+	ctx := context.Background()
+	exec := func(script string, l Limits) error {
+		ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+		ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(script)))
+		return ExecScript(ctx, ds, nil, SetLimits(l))
+	}
+
+	loop := "def transform(ds, ctx):\n\tfor i in range(1000000000):\n\t\tpass"
+	err := exec(loop, Limits{MaxSteps: 500})
+	expect := &LimitError{Limit: LimitMaxSteps, Max: 500}
+	if diff := cmp.Diff(expect, err); diff != "" {
+		t.Errorf("max steps error mismatch (-want +got):\n%s", diff)
+	}
+
+	err = exec(loop, Limits{StepTimeout: 10 * time.Millisecond})
+	expect = &LimitError{Limit: LimitStepTimeout, Max: int64(10 * time.Millisecond)}
+	if diff := cmp.Diff(expect, err); diff != "" {
+		t.Errorf("step timeout error mismatch (-want +got):\n%s", diff)
+	}
+
+	err = exec(`def transform(ds, ctx):
+	ds.set_body("a,b,c\n1,2,3\n", parse_as="csv")`, Limits{MaxBodySize: 4})
+	expect = &LimitError{Limit: LimitMaxBodySize, Max: 4}
+	if diff := cmp.Diff(expect, err); diff != "" {
+		t.Errorf("max body size error mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := exec("def transform(ds, ctx):\n\tds.set_body([1, 2])", Limits{MaxSteps: 500, MaxBodySize: 10}); err != nil {
+		t.Errorf("expected script within limits to succeed. got: %s", err)
+	}
+}
+
 func TestLoadDataset(t *testing.T) {
 	ctx := context.Background()
 	r := testRepo(t)